@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/lager"
+	"github.com/tedsuo/rata"
+)
+
+// The extension API serves the parts of guardian which the garden API has no
+// routes for, alongside the garden server.
+const (
+	Capacity     = "Capacity"
+	NetIn        = "NetIn"
+	PortMappings = "PortMappings"
+	RemoveNetIn  = "RemoveNetIn"
+	NetOutRules  = "NetOutRules"
+	RevokeNetOut = "RevokeNetOut"
+	BulkNetOut   = "BulkNetOut"
+)
+
+var Routes = rata.Routes{
+	{Path: "/capacity", Method: "GET", Name: Capacity},
+	{Path: "/containers/:handle/net/in", Method: "POST", Name: NetIn},
+	{Path: "/containers/:handle/net/in", Method: "GET", Name: PortMappings},
+	{Path: "/containers/:handle/net/in/:host_port/:container_port", Method: "DELETE", Name: RemoveNetIn},
+	{Path: "/containers/:handle/net/out", Method: "GET", Name: NetOutRules},
+	{Path: "/containers/:handle/net/out", Method: "POST", Name: BulkNetOut},
+	{Path: "/containers/:handle/net/out/:id", Method: "DELETE", Name: RevokeNetOut},
+}
+
+//go:generate counterfeiter . Backend
+type Backend interface {
+	CommittedCapacity() (gardener.CommittedCapacity, error)
+	Lookup(handle string) (garden.Container, error)
+}
+
+// BadRequestError is returned when a request body can not be decoded
+type BadRequestError struct {
+	Message string
+}
+
+func (e BadRequestError) Error() string {
+	return e.Message
+}
+
+type handler struct {
+	backend Backend
+	logger  lager.Logger
+}
+
+func New(backend Backend, logger lager.Logger) (http.Handler, error) {
+	h := &handler{
+		backend: backend,
+		logger:  logger,
+	}
+
+	return rata.NewRouter(Routes, rata.Handlers{
+		Capacity:     http.HandlerFunc(h.handleCapacity),
+		NetIn:        http.HandlerFunc(h.handleNetIn),
+		PortMappings: http.HandlerFunc(h.handlePortMappings),
+		RemoveNetIn:  http.HandlerFunc(h.handleRemoveNetIn),
+		NetOutRules:  http.HandlerFunc(h.handleNetOutRules),
+		RevokeNetOut: http.HandlerFunc(h.handleRevokeNetOut),
+		BulkNetOut:   http.HandlerFunc(h.handleBulkNetOut),
+	})
+}
+
+func (h *handler) handleCapacity(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.Session("capacity")
+
+	capacity, err := h.backend.CommittedCapacity()
+	if err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	h.writeResponse(log, w, capacity)
+}
+
+// lookup validates the handle of a container route and looks the container up
+func (h *handler) lookup(r *http.Request) (garden.Container, error) {
+	handle := rata.Param(r, "handle")
+	if err := gardener.ValidateHandle(handle); err != nil {
+		return nil, err
+	}
+
+	return h.backend.Lookup(handle)
+}
+
+func (h *handler) readRequest(r *http.Request, body interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+		return BadRequestError{Message: fmt.Sprintf("invalid request body: %s", err)}
+	}
+
+	return nil
+}
+
+func (h *handler) writeResponse(log lager.Logger, w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Error("encode-response-failed", err)
+	}
+}
+
+func (h *handler) writeError(log lager.Logger, w http.ResponseWriter, err error) {
+	status := StatusCode(err)
+	if status == http.StatusInternalServerError {
+		log.Error("request-failed", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
+}
+
+// StatusCode returns the HTTP status an error is served with. The garden
+// server has no client error status, so errors caused by a bad request, such
+// as an invalid handle, are only reported as such by the extension API.
+func StatusCode(err error) int {
+	switch err.(type) {
+	case gardener.InvalidHandleError, gardener.InvalidNetInProtocolError, BadRequestError:
+		return http.StatusBadRequest
+	case garden.ContainerNotFoundError, gardener.PortMappingNotFoundError:
+		return http.StatusNotFound
+	}
+
+	return http.StatusInternalServerError
+}
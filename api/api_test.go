@@ -0,0 +1,289 @@
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/api"
+	"code.cloudfoundry.org/guardian/api/apifakes"
+	"code.cloudfoundry.org/guardian/gardener"
+	gardenerfakes "code.cloudfoundry.org/guardian/gardener/gardenerfakes"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Api", func() {
+	var (
+		backend         *apifakes.FakeBackend
+		networker       *gardenerfakes.FakeNetworker
+		propertyManager *gardenerfakes.FakePropertyManager
+		handler         http.Handler
+		recorder        *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		backend = new(apifakes.FakeBackend)
+		networker = new(gardenerfakes.FakeNetworker)
+		propertyManager = new(gardenerfakes.FakePropertyManager)
+
+		gdnr := &gardener.Gardener{
+			Networker:       networker,
+			PropertyManager: propertyManager,
+			Logger:          lagertest.NewTestLogger("gardener"),
+		}
+		backend.LookupStub = gdnr.Lookup
+
+		var err error
+		handler, err = api.New(backend, lagertest.NewTestLogger("test"))
+		Expect(err).NotTo(HaveOccurred())
+
+		recorder = httptest.NewRecorder()
+	})
+
+	Describe("GET /capacity", func() {
+		It("returns the committed capacity of the backend", func() {
+			backend.CommittedCapacityReturns(gardener.CommittedCapacity{
+				Capacity:               garden.Capacity{MemoryInBytes: 1024, MaxContainers: 10},
+				CommittedMemoryInBytes: 512,
+				ContainersInUse:        3,
+				Pools: map[string]gardener.PoolCapacity{
+					"dmz": {Capacity: 64, Available: 60},
+				},
+			}, nil)
+
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/capacity", nil))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var capacity gardener.CommittedCapacity
+			Expect(json.NewDecoder(recorder.Body).Decode(&capacity)).To(Succeed())
+			Expect(capacity.MemoryInBytes).To(BeEquivalentTo(1024))
+			Expect(capacity.MaxContainers).To(BeEquivalentTo(10))
+			Expect(capacity.CommittedMemoryInBytes).To(BeEquivalentTo(512))
+			Expect(capacity.ContainersInUse).To(BeEquivalentTo(3))
+			Expect(capacity.Pools).To(Equal(map[string]gardener.PoolCapacity{
+				"dmz": {Capacity: 64, Available: 60},
+			}))
+		})
+
+		Context("when the backend fails", func() {
+			It("returns an internal server error", func() {
+				backend.CommittedCapacityReturns(gardener.CommittedCapacity{}, errors.New("boom"))
+
+				handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/capacity", nil))
+				Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+				Expect(recorder.Body.String()).To(ContainSubstring("boom"))
+			})
+		})
+	})
+
+	Describe("POST /containers/:handle/net/in", func() {
+		It("maps the port for the protocol", func() {
+			networker.NetInReturns(60000, 53, nil)
+
+			handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/containers/some-handle/net/in", strings.NewReader(
+				`{"container_port":53,"protocol":"tcp+udp"}`,
+			)))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var response api.NetInResponse
+			Expect(json.NewDecoder(recorder.Body).Decode(&response)).To(Succeed())
+			Expect(response).To(Equal(api.NetInResponse{HostPort: 60000, ContainerPort: 53}))
+
+			_, handle, hostPort, containerPort, protocol := networker.NetInArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(hostPort).To(BeEquivalentTo(0))
+			Expect(containerPort).To(BeEquivalentTo(53))
+			Expect(protocol).To(Equal("tcp+udp"))
+		})
+
+		Context("when the handle is invalid", func() {
+			It("returns bad request without looking the container up", func() {
+				handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/containers/bad%20handle/net/in", strings.NewReader(`{}`)))
+				Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(backend.LookupCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the body is not valid JSON", func() {
+			It("returns bad request", func() {
+				handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/containers/some-handle/net/in", strings.NewReader(`potato`)))
+				Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(networker.NetInCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the protocol is invalid", func() {
+			It("returns bad request", func() {
+				networker.NetInReturns(0, 0, gardener.InvalidNetInProtocolError{Protocol: "sctp"})
+
+				handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/containers/some-handle/net/in", strings.NewReader(`{"protocol":"sctp"}`)))
+				Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(recorder.Body.String()).To(ContainSubstring("invalid netin protocol: sctp"))
+			})
+		})
+
+		Context("when mapping the port fails", func() {
+			It("returns an internal server error", func() {
+				networker.NetInReturns(0, 0, errors.New("no ports left"))
+
+				handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/containers/some-handle/net/in", strings.NewReader(`{}`)))
+				Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+				Expect(recorder.Body.String()).To(ContainSubstring("no ports left"))
+			})
+		})
+	})
+
+	Describe("GET /containers/:handle/net/in", func() {
+		It("returns the port mappings with their protocols", func() {
+			propertyManager.GetReturns(`[{"HostPort":60000,"ContainerPort":53,"Protocol":"udp"}]`, true)
+
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/containers/some-handle/net/in", nil))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var mappings []gardener.PortMapping
+			Expect(json.NewDecoder(recorder.Body).Decode(&mappings)).To(Succeed())
+			Expect(mappings).To(Equal([]gardener.PortMapping{{HostPort: 60000, ContainerPort: 53, Protocol: "udp"}}))
+		})
+	})
+
+	Describe("DELETE /containers/:handle/net/in/:host_port/:container_port", func() {
+		It("removes the port mapping", func() {
+			handler.ServeHTTP(recorder, httptest.NewRequest("DELETE", "/containers/some-handle/net/in/60000/8080", nil))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			Expect(networker.RemoveNetInCallCount()).To(Equal(1))
+			_, handle, hostPort, containerPort, protocol := networker.RemoveNetInArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(hostPort).To(BeEquivalentTo(60000))
+			Expect(containerPort).To(BeEquivalentTo(8080))
+			Expect(protocol).To(BeEmpty())
+		})
+
+		It("removes the port mapping of the protocol given as a query parameter", func() {
+			handler.ServeHTTP(recorder, httptest.NewRequest("DELETE", "/containers/some-handle/net/in/60000/8080?protocol=udp", nil))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			_, _, _, _, protocol := networker.RemoveNetInArgsForCall(0)
+			Expect(protocol).To(Equal("udp"))
+		})
+
+		Context("when a port is not a number", func() {
+			It("returns bad request", func() {
+				handler.ServeHTTP(recorder, httptest.NewRequest("DELETE", "/containers/some-handle/net/in/potato/8080", nil))
+				Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(networker.RemoveNetInCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the port mapping does not exist", func() {
+			It("returns not found", func() {
+				networker.RemoveNetInReturns(gardener.PortMappingNotFoundError{HostPort: 60000, ContainerPort: 8080, Protocol: "tcp"})
+
+				handler.ServeHTTP(recorder, httptest.NewRequest("DELETE", "/containers/some-handle/net/in/60000/8080", nil))
+				Expect(recorder.Code).To(Equal(http.StatusNotFound))
+				Expect(recorder.Body.String()).To(ContainSubstring("port mapping not found: 60000:8080/tcp"))
+			})
+		})
+
+		Context("when removing the port mapping fails", func() {
+			It("returns an internal server error", func() {
+				networker.RemoveNetInReturns(errors.New("boom"))
+
+				handler.ServeHTTP(recorder, httptest.NewRequest("DELETE", "/containers/some-handle/net/in/60000/8080", nil))
+				Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+				Expect(recorder.Body.String()).To(ContainSubstring("boom"))
+			})
+		})
+	})
+
+	Describe("GET /containers/:handle/net/out", func() {
+		It("returns the net out rules of the container", func() {
+			networker.NetOutRulesReturns([]gardener.NetOutRule{
+				{ID: "1", Rule: garden.NetOutRule{Protocol: garden.ProtocolTCP}},
+			}, nil)
+
+			handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/containers/some-handle/net/out", nil))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var rules []gardener.NetOutRule
+			Expect(json.NewDecoder(recorder.Body).Decode(&rules)).To(Succeed())
+			Expect(rules).To(Equal([]gardener.NetOutRule{
+				{ID: "1", Rule: garden.NetOutRule{Protocol: garden.ProtocolTCP}},
+			}))
+
+			_, handle := networker.NetOutRulesArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+		})
+
+		Context("when the networker can not list net out rules", func() {
+			It("returns the error", func() {
+				networker.NetOutRulesReturns(nil, errors.New("not supported"))
+
+				handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/containers/some-handle/net/out", nil))
+				Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+				Expect(recorder.Body.String()).To(ContainSubstring("not supported"))
+			})
+		})
+	})
+
+	Describe("POST /containers/:handle/net/out", func() {
+		It("applies all of the rules at once", func() {
+			handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/containers/some-handle/net/out", strings.NewReader(
+				`[{"protocol":1},{"protocol":2}]`,
+			)))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			Expect(networker.BulkNetOutCallCount()).To(Equal(1))
+			_, handle, rules := networker.BulkNetOutArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(rules).To(Equal([]garden.NetOutRule{
+				{Protocol: garden.ProtocolTCP},
+				{Protocol: garden.ProtocolUDP},
+			}))
+		})
+
+		Context("when the body is not a list of rules", func() {
+			It("returns bad request", func() {
+				handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/containers/some-handle/net/out", strings.NewReader(`{}`)))
+				Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(networker.BulkNetOutCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("DELETE /containers/:handle/net/out/:id", func() {
+		It("revokes the net out rule", func() {
+			handler.ServeHTTP(recorder, httptest.NewRequest("DELETE", "/containers/some-handle/net/out/3", nil))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			Expect(networker.RevokeNetOutCallCount()).To(Equal(1))
+			_, handle, id := networker.RevokeNetOutArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(id).To(Equal("3"))
+		})
+	})
+
+	Describe("StatusCode", func() {
+		It("returns bad request for an invalid handle", func() {
+			Expect(api.StatusCode(gardener.InvalidHandleError{Handle: "../foo", Reason: "bad"})).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns bad request for an invalid port mapping protocol", func() {
+			Expect(api.StatusCode(gardener.InvalidNetInProtocolError{Protocol: "sctp"})).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns not found for a missing container", func() {
+			Expect(api.StatusCode(garden.ContainerNotFoundError{Handle: "foo"})).To(Equal(http.StatusNotFound))
+		})
+
+		It("returns internal server error for any other error", func() {
+			Expect(api.StatusCode(errors.New("boom"))).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
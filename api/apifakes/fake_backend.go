@@ -0,0 +1,115 @@
+// This file was generated by counterfeiter
+package apifakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/api"
+	"code.cloudfoundry.org/guardian/gardener"
+)
+
+type FakeBackend struct {
+	CommittedCapacityStub        func() (gardener.CommittedCapacity, error)
+	committedCapacityMutex       sync.RWMutex
+	committedCapacityArgsForCall []struct{}
+	committedCapacityReturns     struct {
+		result1 gardener.CommittedCapacity
+		result2 error
+	}
+	LookupStub        func(handle string) (garden.Container, error)
+	lookupMutex       sync.RWMutex
+	lookupArgsForCall []struct {
+		handle string
+	}
+	lookupReturns struct {
+		result1 garden.Container
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeBackend) CommittedCapacity() (gardener.CommittedCapacity, error) {
+	fake.committedCapacityMutex.Lock()
+	fake.committedCapacityArgsForCall = append(fake.committedCapacityArgsForCall, struct{}{})
+	fake.recordInvocation("CommittedCapacity", []interface{}{})
+	fake.committedCapacityMutex.Unlock()
+	if fake.CommittedCapacityStub != nil {
+		return fake.CommittedCapacityStub()
+	} else {
+		return fake.committedCapacityReturns.result1, fake.committedCapacityReturns.result2
+	}
+}
+
+func (fake *FakeBackend) CommittedCapacityCallCount() int {
+	fake.committedCapacityMutex.RLock()
+	defer fake.committedCapacityMutex.RUnlock()
+	return len(fake.committedCapacityArgsForCall)
+}
+
+func (fake *FakeBackend) CommittedCapacityReturns(result1 gardener.CommittedCapacity, result2 error) {
+	fake.CommittedCapacityStub = nil
+	fake.committedCapacityReturns = struct {
+		result1 gardener.CommittedCapacity
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBackend) Lookup(handle string) (garden.Container, error) {
+	fake.lookupMutex.Lock()
+	fake.lookupArgsForCall = append(fake.lookupArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Lookup", []interface{}{handle})
+	fake.lookupMutex.Unlock()
+	if fake.LookupStub != nil {
+		return fake.LookupStub(handle)
+	} else {
+		return fake.lookupReturns.result1, fake.lookupReturns.result2
+	}
+}
+
+func (fake *FakeBackend) LookupCallCount() int {
+	fake.lookupMutex.RLock()
+	defer fake.lookupMutex.RUnlock()
+	return len(fake.lookupArgsForCall)
+}
+
+func (fake *FakeBackend) LookupArgsForCall(i int) string {
+	fake.lookupMutex.RLock()
+	defer fake.lookupMutex.RUnlock()
+	return fake.lookupArgsForCall[i].handle
+}
+
+func (fake *FakeBackend) LookupReturns(result1 garden.Container, result2 error) {
+	fake.LookupStub = nil
+	fake.lookupReturns = struct {
+		result1 garden.Container
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBackend) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.committedCapacityMutex.RLock()
+	defer fake.committedCapacityMutex.RUnlock()
+	fake.lookupMutex.RLock()
+	defer fake.lookupMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeBackend) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ api.Backend = new(FakeBackend)
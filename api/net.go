@@ -0,0 +1,207 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	"github.com/tedsuo/rata"
+)
+
+var (
+	ErrPortMappingNotSupported        = errors.New("port mapping with protocols is not supported")
+	ErrPortMappingRemovalNotSupported = errors.New("removing port mappings is not supported")
+	ErrNetOutRulesNotSupported        = errors.New("managing net out rules is not supported")
+)
+
+type NetInRequest struct {
+	HostPort      uint32 `json:"host_port"`
+	ContainerPort uint32 `json:"container_port"`
+	// Protocol is "tcp", "udp" or "tcp+udp", defaulting to "tcp"
+	Protocol string `json:"protocol,omitempty"`
+}
+
+type NetInResponse struct {
+	HostPort      uint32 `json:"host_port"`
+	ContainerPort uint32 `json:"container_port"`
+}
+
+func (h *handler) handleNetIn(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.Session("net-in")
+
+	var request NetInRequest
+	if err := h.readRequest(r, &request); err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	container, err := h.lookup(r)
+	if err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	mapper, ok := container.(gardener.PortMapper)
+	if !ok {
+		h.writeError(log, w, ErrPortMappingNotSupported)
+		return
+	}
+
+	hostPort, containerPort, err := mapper.NetInWithProtocol(request.HostPort, request.ContainerPort, request.Protocol)
+	if err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	h.writeResponse(log, w, NetInResponse{
+		HostPort:      hostPort,
+		ContainerPort: containerPort,
+	})
+}
+
+func (h *handler) handlePortMappings(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.Session("port-mappings")
+
+	container, err := h.lookup(r)
+	if err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	mapper, ok := container.(gardener.PortMapper)
+	if !ok {
+		h.writeError(log, w, ErrPortMappingNotSupported)
+		return
+	}
+
+	mappings, err := mapper.PortMappings()
+	if err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	h.writeResponse(log, w, mappings)
+}
+
+// handleRemoveNetIn removes the mapping of the protocol given by the optional
+// protocol query parameter, which defaults to TCP
+func (h *handler) handleRemoveNetIn(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.Session("remove-net-in")
+
+	hostPort, err := portParam(r, "host_port")
+	if err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	containerPort, err := portParam(r, "container_port")
+	if err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	container, err := h.lookup(r)
+	if err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	remover, ok := container.(gardener.PortMappingRemover)
+	if !ok {
+		h.writeError(log, w, ErrPortMappingRemovalNotSupported)
+		return
+	}
+
+	if err := remover.RemoveNetIn(hostPort, containerPort, r.URL.Query().Get("protocol")); err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	h.writeResponse(log, w, struct{}{})
+}
+
+func portParam(r *http.Request, name string) (uint32, error) {
+	port, err := strconv.ParseUint(rata.Param(r, name), 10, 16)
+	if err != nil {
+		return 0, BadRequestError{Message: fmt.Sprintf("invalid %s: %s", name, rata.Param(r, name))}
+	}
+
+	return uint32(port), nil
+}
+
+func (h *handler) handleNetOutRules(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.Session("net-out-rules")
+
+	manager, err := h.netOutRuleManager(r)
+	if err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	rules, err := manager.NetOutRules()
+	if err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	h.writeResponse(log, w, rules)
+}
+
+func (h *handler) handleRevokeNetOut(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.Session("revoke-net-out")
+
+	manager, err := h.netOutRuleManager(r)
+	if err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	if err := manager.RevokeNetOut(rata.Param(r, "id")); err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	h.writeResponse(log, w, struct{}{})
+}
+
+// handleBulkNetOut applies all of the rules in the request body, so that
+// either all of them are applied or none are
+func (h *handler) handleBulkNetOut(w http.ResponseWriter, r *http.Request) {
+	log := h.logger.Session("bulk-net-out")
+
+	var rules []garden.NetOutRule
+	if err := h.readRequest(r, &rules); err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	manager, err := h.netOutRuleManager(r)
+	if err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	if err := manager.BulkNetOut(rules); err != nil {
+		h.writeError(log, w, err)
+		return
+	}
+
+	h.writeResponse(log, w, struct{}{})
+}
+
+func (h *handler) netOutRuleManager(r *http.Request) (gardener.NetOutRuleManager, error) {
+	container, err := h.lookup(r)
+	if err != nil {
+		return nil, err
+	}
+
+	manager, ok := container.(gardener.NetOutRuleManager)
+	if !ok {
+		return nil, ErrNetOutRulesNotSupported
+	}
+
+	return manager, nil
+}
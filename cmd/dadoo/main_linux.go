@@ -16,6 +16,7 @@ import (
 
 	"code.cloudfoundry.org/garden"
 	"code.cloudfoundry.org/guardian/rundmc/dadoo"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
 	"github.com/eapache/go-resiliency/retrier"
 	"github.com/kr/pty"
 	"github.com/opencontainers/runc/libcontainer/system"
@@ -26,6 +27,7 @@ var gid = flag.Int("gid", 0, "gid to chown console to")
 var rows = flag.Int("rows", 0, "rows for tty")
 var cols = flag.Int("cols", 0, "cols for tty")
 var tty = flag.Bool("tty", false, "tty requested")
+var runtimeFlavour = flag.String("runtime-flavour", "runc", "command line dialect of the runtime: runc, crun or runsc")
 
 func main() {
 	os.Exit(run())
@@ -38,6 +40,11 @@ func run() int {
 	dir := flag.Args()[2]     // bundlePath for run, processPath for exec
 	containerId := flag.Args()[3]
 
+	flavour, err := goci.ParseRuntimeFlavour(*runtimeFlavour)
+	check(err)
+
+	runtimeBinary := goci.RuntimeBinary{Path: runtime, Flavour: flavour}
+
 	signals := make(chan os.Signal, 100)
 	signal.Notify(signals, syscall.SIGCHLD)
 
@@ -48,12 +55,14 @@ func run() int {
 
 	stdin, stdout, stderr, winsz := openPipes(dir)
 
+	processJSONPath := fmt.Sprintf("/proc/%d/fd/0", os.Getpid())
+
 	var runcStartCmd *exec.Cmd
 	if *tty {
 		ttySlave := setupTty(stdin, stdout, pidFilePath, winsz, garden.WindowSize{Rows: *rows, Columns: *cols})
-		runcStartCmd = exec.Command(runtime, "-debug", "-log", logFile, "exec", "-d", "-tty", "-console", ttySlave.Name(), "-p", fmt.Sprintf("/proc/%d/fd/0", os.Getpid()), "-pid-file", pidFilePath, containerId)
+		runcStartCmd = runtimeBinary.DetachedExecCommand(containerId, processJSONPath, pidFilePath, logFile, ttySlave.Name())
 	} else {
-		runcStartCmd = exec.Command(runtime, "-debug", "-log", logFile, "exec", "-p", fmt.Sprintf("/proc/%d/fd/0", os.Getpid()), "-d", "-pid-file", pidFilePath, containerId)
+		runcStartCmd = runtimeBinary.DetachedExecCommand(containerId, processJSONPath, pidFilePath, logFile, "")
 		runcStartCmd.Stdin = stdin
 		runcStartCmd.Stdout = stdout
 		runcStartCmd.Stderr = stderr
@@ -69,7 +78,7 @@ func run() int {
 
 	var status syscall.WaitStatus
 	var rusage syscall.Rusage
-	_, err := syscall.Wait4(runcStartCmd.Process.Pid, &status, 0, &rusage)
+	_, err = syscall.Wait4(runcStartCmd.Process.Pid, &status, 0, &rusage)
 	check(err)    // Start succeeded but Wait4 failed, this can only be a programmer error
 	logFD.Close() // No more logs from runc so close fd
 
@@ -0,0 +1,174 @@
+package gardener
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/lager"
+)
+
+var ErrCreateQueueFull = errors.New("too many containers waiting to be created")
+
+// admission limits the number of containers being created at once and keeps
+// track of the memory committed to containers which are still being created
+type admission struct {
+	once  sync.Once
+	slots chan struct{}
+
+	mu      sync.Mutex
+	waiting int
+	pending map[string]bool
+}
+
+// admitCreate waits for a create slot, then checks that the memory limit of
+// the new container fits within the overcommitted total and commits it along
+// with its disk limit. The returned func must be called once the create has
+// finished.
+func (g *Gardener) admitCreate(log lager.Logger, handle string, limits garden.Limits) (release func(), err error) {
+	log = log.Session("admit")
+
+	freeSlot, err := g.acquireCreateSlot(log)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.commitResources(log, handle, limits.Memory.LimitInBytes, limits.Disk.ByteHard); err != nil {
+		freeSlot()
+		return nil, err
+	}
+
+	return func() {
+		g.admission.mu.Lock()
+		delete(g.admission.pending, handle)
+		g.admission.mu.Unlock()
+
+		freeSlot()
+	}, nil
+}
+
+func (g *Gardener) acquireCreateSlot(log lager.Logger) (release func(), err error) {
+	if g.MaxConcurrentCreates <= 0 {
+		return func() {}, nil
+	}
+
+	g.admission.once.Do(func() {
+		g.admission.slots = make(chan struct{}, g.MaxConcurrentCreates)
+	})
+
+	release = func() { <-g.admission.slots }
+
+	select {
+	case g.admission.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	g.admission.mu.Lock()
+	if g.admission.waiting >= g.CreateQueueSize {
+		g.admission.mu.Unlock()
+		log.Info("queue-full", lager.Data{"waiting": g.admission.waiting})
+		return nil, ErrCreateQueueFull
+	}
+	g.admission.waiting++
+	g.admission.mu.Unlock()
+
+	defer func() {
+		g.admission.mu.Lock()
+		g.admission.waiting--
+		g.admission.mu.Unlock()
+	}()
+
+	log.Info("queued")
+
+	var timeout <-chan time.Time
+	if g.CreateQueueTimeout > 0 {
+		timeout = time.After(g.CreateQueueTimeout)
+	}
+
+	select {
+	case g.admission.slots <- struct{}{}:
+		return release, nil
+	case <-timeout:
+		return nil, fmt.Errorf("timed out after %s waiting to create container", g.CreateQueueTimeout)
+	}
+}
+
+func (g *Gardener) commitResources(log lager.Logger, handle string, memoryLimit, diskLimit uint64) error {
+	g.admission.mu.Lock()
+	defer g.admission.mu.Unlock()
+
+	if g.MemoryOvercommitRatio > 0 && memoryLimit > 0 {
+		totalMemory, err := g.SysInfoProvider.TotalMemory()
+		if err != nil {
+			log.Error("total-memory-failed", err)
+			return err
+		}
+
+		committed, err := g.committed(CommittedMemoryKey)
+		if err != nil {
+			log.Error("committed-memory-failed", err)
+			return err
+		}
+
+		available := uint64(float64(totalMemory) * g.MemoryOvercommitRatio)
+		if committed+memoryLimit > available {
+			log.Info("insufficient-memory", lager.Data{"requested": memoryLimit, "committed": committed, "available": available})
+			return fmt.Errorf("insufficient memory: requested %d bytes, %d of %d bytes already committed", memoryLimit, committed, available)
+		}
+	}
+
+	if g.admission.pending == nil {
+		g.admission.pending = make(map[string]bool)
+	}
+
+	g.admission.pending[handle] = true
+	if memoryLimit > 0 {
+		g.PropertyManager.Set(handle, CommittedMemoryKey, strconv.FormatUint(memoryLimit, 10))
+	}
+
+	if diskLimit > 0 {
+		g.PropertyManager.Set(handle, CommittedDiskKey, strconv.FormatUint(diskLimit, 10))
+	}
+
+	return nil
+}
+
+// committed sums the limits recorded under the given property for existing
+// containers and for those still being created. The caller must hold the
+// admission lock.
+func (g *Gardener) committed(key string) (uint64, error) {
+	handles, err := g.Containerizer.Handles()
+	if err != nil {
+		return 0, err
+	}
+
+	counted := make(map[string]bool)
+	for handle := range g.admission.pending {
+		counted[handle] = true
+	}
+
+	for _, handle := range handles {
+		counted[handle] = true
+	}
+
+	var committed uint64
+	for handle := range counted {
+		value, ok := g.PropertyManager.Get(handle, key)
+		if !ok {
+			continue
+		}
+
+		limit, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		committed += limit
+	}
+
+	return committed, nil
+}
@@ -0,0 +1,83 @@
+package gardener
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/garden"
+)
+
+type bulkResult struct {
+	info    garden.ContainerInfo
+	metrics garden.Metrics
+	err     error
+}
+
+// bulk runs op for each handle on at most BulkConcurrency goroutines. When
+// BulkTimeout is set, it bounds the whole call: a handle whose op has not
+// returned, or not even started, by then gets an error result rather than
+// holding up the result of the others. The op of a timed out handle keeps its
+// goroutine slot until it does return, so that wedged ops do not pile up.
+func (g *Gardener) bulk(handles []string, op func(handle string) bulkResult) map[string]bulkResult {
+	concurrency := g.BulkConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]bulkResult, len(handles))
+		slots   = make(chan struct{}, concurrency)
+		expired chan struct{}
+	)
+
+	if g.BulkTimeout > 0 {
+		expired = make(chan struct{})
+		timer := time.AfterFunc(g.BulkTimeout, func() { close(expired) })
+		defer timer.Stop()
+	}
+
+	timedOut := bulkResult{err: fmt.Errorf("timed out after %s", g.BulkTimeout)}
+
+	for i, handle := range handles {
+		select {
+		case slots <- struct{}{}:
+		case <-expired:
+			mu.Lock()
+			for _, unstarted := range handles[i:] {
+				results[unstarted] = timedOut
+			}
+			mu.Unlock()
+
+			wg.Wait()
+			return results
+		}
+
+		wg.Add(1)
+
+		done := make(chan bulkResult, 1)
+		go func(handle string) {
+			defer func() { <-slots }()
+			done <- op(handle)
+		}(handle)
+
+		go func(handle string) {
+			defer wg.Done()
+
+			result := timedOut
+			select {
+			case result = <-done:
+			case <-expired:
+			}
+
+			mu.Lock()
+			results[handle] = result
+			mu.Unlock()
+		}(handle)
+	}
+
+	wg.Wait()
+	return results
+}
@@ -18,6 +18,29 @@ type container struct {
 	volumeCreator   VolumeCreator
 	networker       Networker
 	propertyManager PropertyManager
+	locks           *handleLocks
+	shutdown        *shutdown
+}
+
+// beginChange registers an operation which changes the container with the
+// shutdown, so that Stop waits for it before persisting state, and then
+// acquires the handle's lock
+func (c *container) beginChange() (release func(), err error) {
+	done, err := c.shutdown.begin()
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := c.locks.acquire(c.handle)
+	if err != nil {
+		done()
+		return nil, err
+	}
+
+	return func() {
+		unlock()
+		done()
+	}, nil
 }
 
 func (c *container) Handle() string {
@@ -25,14 +48,32 @@ func (c *container) Handle() string {
 }
 
 func (c *container) Run(spec garden.ProcessSpec, io garden.ProcessIO) (garden.Process, error) {
+	release, err := c.beginChange()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	return c.containerizer.Run(c.logger, c.handle, spec, io)
 }
 
 func (c *container) Attach(processID string, io garden.ProcessIO) (garden.Process, error) {
+	release, err := c.locks.acquire(c.handle)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	return c.containerizer.Attach(c.logger, c.handle, processID, io)
 }
 
 func (c *container) Stop(kill bool) error {
+	release, err := c.beginChange()
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	return c.containerizer.Stop(c.logger, c.handle, kill)
 }
 
@@ -75,6 +116,7 @@ func (c *container) Info() (garden.ContainerInfo, error) {
 		state = "stopped"
 	}
 
+	// garden.PortMapping has no protocol, see PortMappings for the protocols
 	json.Unmarshal([]byte(mappedPortsCfg), &mappedPorts)
 	return garden.ContainerInfo{
 		State:         state,
@@ -89,10 +131,22 @@ func (c *container) Info() (garden.ContainerInfo, error) {
 }
 
 func (c *container) StreamIn(spec garden.StreamInSpec) error {
+	release, err := c.beginChange()
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	return c.containerizer.StreamIn(c.logger, c.handle, spec)
 }
 
 func (c *container) StreamOut(spec garden.StreamOutSpec) (io.ReadCloser, error) {
+	release, err := c.locks.acquire(c.handle)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	return c.containerizer.StreamOut(c.logger, c.handle, spec)
 }
 
@@ -131,13 +185,141 @@ func (c *container) CurrentMemoryLimits() (garden.MemoryLimits, error) {
 }
 
 func (c *container) NetIn(hostPort, containerPort uint32) (uint32, uint32, error) {
-	return c.networker.NetIn(c.logger, c.handle, hostPort, containerPort)
+	release, err := c.beginChange()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer release()
+
+	return c.networker.NetIn(c.logger, c.handle, hostPort, containerPort, "")
+}
+
+// PortMapping is a port mapped by NetIn, along with its protocol, which is
+// empty for TCP
+type PortMapping struct {
+	HostPort      uint32
+	ContainerPort uint32
+	Protocol      string `json:",omitempty"`
+}
+
+// PortMapper is implemented by containers returned from the Gardener. It maps
+// ports for protocols other than TCP, which NetIn can not, and lists the
+// mappings with their protocols, which garden.ContainerInfo.MappedPorts has no
+// field for. It is not part of garden.Container, so callers must type-assert.
+type PortMapper interface {
+	NetInWithProtocol(hostPort, containerPort uint32, protocol string) (uint32, uint32, error)
+	PortMappings() ([]PortMapping, error)
+}
+
+func (c *container) NetInWithProtocol(hostPort, containerPort uint32, protocol string) (uint32, uint32, error) {
+	release, err := c.beginChange()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer release()
+
+	return c.networker.NetIn(c.logger, c.handle, hostPort, containerPort, protocol)
+}
+
+func (c *container) PortMappings() ([]PortMapping, error) {
+	mappings := []PortMapping{}
+	if mappingsJson, ok := c.propertyManager.Get(c.handle, MappedPortsKey); ok {
+		if err := json.Unmarshal([]byte(mappingsJson), &mappings); err != nil {
+			return nil, err
+		}
+	}
+
+	return mappings, nil
+}
+
+// PortMappingRemover is implemented by containers returned from the
+// Gardener. It is not part of garden.Container, so callers must type-assert.
+// The protocol selects between mappings of the same ports for different
+// protocols, and is TCP when empty.
+type PortMappingRemover interface {
+	RemoveNetIn(hostPort, containerPort uint32, protocol string) error
+}
+
+// InvalidNetInProtocolError is returned when a port mapping is given a
+// protocol other than tcp, udp or tcp+udp
+type InvalidNetInProtocolError struct {
+	Protocol string
+}
+
+func (e InvalidNetInProtocolError) Error() string {
+	return fmt.Sprintf("invalid netin protocol: %s", e.Protocol)
+}
+
+// PortMappingNotFoundError is returned when removing a port mapping which the
+// container does not have
+type PortMappingNotFoundError struct {
+	HostPort      uint32
+	ContainerPort uint32
+	Protocol      string
+}
+
+func (e PortMappingNotFoundError) Error() string {
+	return fmt.Sprintf("port mapping not found: %d:%d/%s", e.HostPort, e.ContainerPort, e.Protocol)
+}
+
+func (c *container) RemoveNetIn(hostPort, containerPort uint32, protocol string) error {
+	release, err := c.beginChange()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return c.networker.RemoveNetIn(c.logger, c.handle, hostPort, containerPort, protocol)
 }
 
 func (c *container) NetOut(netOutRule garden.NetOutRule) error {
+	release, err := c.beginChange()
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	return c.networker.NetOut(c.logger, c.handle, netOutRule)
 }
 
+// NetOutRuleManager is implemented by containers returned from the Gardener.
+// It is not part of garden.Container, so callers must type-assert.
+type NetOutRuleManager interface {
+	BulkNetOut(rules []garden.NetOutRule) error
+	NetOutRules() ([]NetOutRule, error)
+	RevokeNetOut(id string) error
+}
+
+func (c *container) BulkNetOut(rules []garden.NetOutRule) error {
+	release, err := c.beginChange()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return c.networker.BulkNetOut(c.logger, c.handle, rules)
+}
+
+func (c *container) NetOutRules() ([]NetOutRule, error) {
+	release, err := c.locks.acquire(c.handle)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return c.networker.NetOutRules(c.logger, c.handle)
+}
+
+func (c *container) RevokeNetOut(id string) error {
+	release, err := c.beginChange()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return c.networker.RevokeNetOut(c.logger, c.handle, id)
+}
+
 func (c *container) Metrics() (garden.Metrics, error) {
 	actualContainerMetrics, err := c.containerizer.Metrics(c.logger, c.handle)
 	if err != nil {
@@ -169,16 +351,42 @@ func (c *container) Property(name string) (string, error) {
 }
 
 func (c *container) SetProperty(name string, value string) error {
+	if err := checkClientProperty(name); err != nil {
+		return err
+	}
+
+	release, err := c.beginChange()
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	c.propertyManager.Set(c.handle, name, value)
 	return nil
 }
 
 func (c *container) RemoveProperty(name string) error {
+	if err := checkClientProperty(name); err != nil {
+		return err
+	}
+
+	release, err := c.beginChange()
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	c.propertyManager.Remove(c.handle, name)
 	return nil
 }
 
 func (c *container) SetGraceTime(t time.Duration) error {
+	release, err := c.beginChange()
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	c.propertyManager.Set(c.handle, GraceTimeKey, fmt.Sprintf("%d", t))
 	return nil
 }
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"code.cloudfoundry.org/garden"
@@ -18,13 +21,32 @@ import (
 //go:generate counterfeiter . UidGenerator
 //go:generate counterfeiter . PropertyManager
 //go:generate counterfeiter . Restorer
+//go:generate counterfeiter . Reconciler
+//go:generate counterfeiter . Inventory
 //go:generate counterfeiter . Starter
+//go:generate counterfeiter . Journal
+//go:generate counterfeiter . Stopper
+//go:generate counterfeiter . Persister
 
 const ContainerIPKey = "garden.network.container-ip"
+const ContainerIPv6Key = "garden.network.container-ipv6"
 const BridgeIPKey = "garden.network.host-ip"
 const ExternalIPKey = "garden.network.external-ip"
 const MappedPortsKey = "garden.network.mapped-ports"
+const NetworkPoolKey = "garden.network.pool"
 const GraceTimeKey = "garden.grace-time"
+const SeccompProfileKey = "garden.seccomp-profile"
+const CapAddKey = "garden.capabilities.add"
+const CapDropKey = "garden.capabilities.drop"
+const ReadOnlyRootFSKey = "garden.rootfs.read-only"
+const TmpfsMountsKey = "garden.tmpfs-mounts"
+const MaskedPathsKey = "garden.masked-paths"
+const ReadonlyPathsKey = "garden.read-only-paths"
+const ApparmorProfileKey = "garden.apparmor-profile"
+const NoNewPrivilegesKey = "garden.no-new-privileges"
+const RuntimeKey = "garden.runtime"
+const CommittedMemoryKey = "gardener.committed-memory"
+const CommittedDiskKey = "gardener.committed-disk"
 
 const RawRootFSScheme = "raw"
 
@@ -52,12 +74,32 @@ type Containerizer interface {
 type Networker interface {
 	Network(log lager.Logger, spec garden.ContainerSpec, pid int) error
 	Capacity() uint64
+	AvailableSubnets() uint64
+	AvailablePorts() uint64
+	PoolCapacities() map[string]PoolCapacity
 	Destroy(log lager.Logger, handle string) error
-	NetIn(log lager.Logger, handle string, hostPort, containerPort uint32) (uint32, uint32, error)
+	NetIn(log lager.Logger, handle string, hostPort, containerPort uint32, protocol string) (uint32, uint32, error)
+	RemoveNetIn(log lager.Logger, handle string, hostPort, containerPort uint32, protocol string) error
 	NetOut(log lager.Logger, handle string, rule garden.NetOutRule) error
+	BulkNetOut(log lager.Logger, handle string, rules []garden.NetOutRule) error
+	NetOutRules(log lager.Logger, handle string) ([]NetOutRule, error)
+	RevokeNetOut(log lager.Logger, handle string, id string) error
 	Restore(log lager.Logger, handle string) error
 }
 
+// PoolCapacity is the number of container IPs of a network pool, and how
+// many of them are not yet allocated
+type PoolCapacity struct {
+	Capacity  uint64 `json:"capacity"`
+	Available uint64 `json:"available"`
+}
+
+// NetOutRule is a rule applied by NetOut, with the ID used to revoke it
+type NetOutRule struct {
+	ID   string
+	Rule garden.NetOutRule
+}
+
 type VolumeCreator interface {
 	Create(log lager.Logger, handle string, spec rootfs_provider.Spec) (string, []string, error)
 	Destroy(log lager.Logger, handle string) error
@@ -86,6 +128,24 @@ type Restorer interface {
 	Restore(logger lager.Logger, handles []string) []string
 }
 
+// Journal records which steps of a create have been started, so that a
+// create interrupted by a crash can be rolled back on the next start
+type Journal interface {
+	Begin(handle string) error
+	Record(handle, step string) error
+	Steps(handle string) ([]string, error)
+	Finish(handle string) error
+	Handles() ([]string, error)
+}
+
+// The steps of a create, as recorded in the Journal
+const (
+	PropertiesStep = "properties"
+	VolumeStep     = "volume"
+	ContainerStep  = "container"
+	NetworkStep    = "network"
+)
+
 type UidGeneratorFunc func() string
 
 func (fn UidGeneratorFunc) Generate() string {
@@ -110,6 +170,38 @@ type DesiredContainerSpec struct {
 	Limits garden.Limits
 
 	Env []string
+
+	// Name of the seccomp profile to apply, empty for the default profile
+	SeccompProfile string
+
+	// Capabilities to add to and remove from the default set
+	CapAdd  []string
+	CapDrop []string
+
+	// Mount the rootfs read-only, so that only tmpfs mounts are writable
+	ReadOnlyRootFS bool
+
+	TmpfsMounts []TmpfsMount
+
+	// Paths to mask or make read-only, in addition to those of the base bundle
+	MaskedPaths   []string
+	ReadonlyPaths []string
+
+	// Name of the AppArmor profile to apply, empty for the default profile
+	ApparmorProfile string
+
+	// Prevent the container's processes from gaining privileges, e.g. via setuid binaries
+	NoNewPrivileges bool
+
+	// Name of the OCI runtime to run the container with, empty for the default runtime
+	Runtime string
+}
+
+type TmpfsMount struct {
+	Path string
+
+	// Size limit of the mount, zero for the kernel default
+	SizeInBytes uint64
 }
 
 type ActualContainerSpec struct {
@@ -166,11 +258,69 @@ type Gardener struct {
 	MaxContainers uint64
 
 	Restorer Restorer
+
+	// Reconciler checks the depot against the other places container state
+	// is kept on start
+	Reconciler Reconciler
+
+	// Journal records the progress of creates
+	Journal Journal
+
+	// BulkConcurrency limits the number of containers queried at once by
+	// BulkInfo and BulkMetrics
+	BulkConcurrency int
+
+	// BulkTimeout bounds the time spent by BulkInfo and BulkMetrics, after
+	// which containers without a result are reported as timed out, zero for
+	// no timeout
+	BulkTimeout time.Duration
+
+	// MaxConcurrentCreates limits the number of containers created at once,
+	// zero for no limit
+	MaxConcurrentCreates int
+
+	// CreateQueueSize limits the number of creates waiting for one of the
+	// MaxConcurrentCreates slots; further creates are rejected
+	CreateQueueSize int
+
+	// CreateQueueTimeout bounds the time a create waits for a slot, zero for
+	// no timeout
+	CreateQueueTimeout time.Duration
+
+	// MemoryOvercommitRatio is the multiple of the total memory which the
+	// memory limits of all containers may add up to, zero to disable the check
+	MemoryOvercommitRatio float64
+
+	// StopTimeout bounds the time Stop waits for in-flight creates and
+	// destroys, zero for no timeout
+	StopTimeout time.Duration
+
+	// Stoppers stop background tasks on Stop
+	Stoppers []Stopper
+
+	// Persisters save state on Stop, after the Stoppers have run
+	Persisters []Persister
+
+	locks     handleLocks
+	admission admission
+	shutdown  shutdown
 }
 
 // Create creates a container by combining the results of networker.Network,
 // volumizer.Create and containzer.Create.
 func (g *Gardener) Create(spec garden.ContainerSpec) (ctr garden.Container, err error) {
+	done, err := g.shutdown.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	if spec.Handle != "" {
+		if err := ValidateHandle(spec.Handle); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := g.checkDuplicateHandle(spec.Handle); err != nil {
 		return nil, err
 	}
@@ -182,30 +332,74 @@ func (g *Gardener) Create(spec garden.ContainerSpec) (ctr garden.Container, err
 	log := g.Logger.Session("create", lager.Data{"handle": spec.Handle})
 
 	log.Info("start")
+
+	if err := g.Journal.Begin(spec.Handle); err != nil {
+		log.Error("begin-journal-failed", err)
+		return nil, err
+	}
+
+	var steps []string
+	record := func(step string) error {
+		if err := g.Journal.Record(spec.Handle, step); err != nil {
+			log.Error("record-journal-failed", err, lager.Data{"step": step})
+			return err
+		}
+
+		steps = append(steps, step)
+		return nil
+	}
+
 	defer func() {
 		if err != nil {
 			log := log.Session("create-failed-cleaningup", lager.Data{
 				"cause": err.Error(),
+				"steps": steps,
 			})
 
 			log.Info("start")
 
-			err := g.destroy(log, spec.Handle)
-			if err != nil {
-				log.Error("destroy-failed", err)
+			if err := g.rollback(log, spec.Handle, steps); err != nil {
+				log.Error("rollback-failed", err)
+				return
 			}
 
 			log.Info("cleanedup")
 		} else {
 			log.Info("created")
 		}
+
+		if err := g.Journal.Finish(spec.Handle); err != nil {
+			log.Error("finish-journal-failed", err)
+		}
 	}()
 
+	if err := record(PropertiesStep); err != nil {
+		return nil, err
+	}
+
+	release, err := g.admitCreate(log, spec.Handle, spec.Limits)
+	if err != nil {
+		log.Error("admission-failed", err)
+		return nil, err
+	}
+	defer release()
+
 	rootFSURL, err := url.Parse(spec.RootFSPath)
 	if err != nil {
 		return nil, err
 	}
 
+	for name := range spec.Properties {
+		if err := checkClientProperty(name); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpfsMounts, err := parseTmpfsMounts(spec.Properties[TmpfsMountsKey])
+	if err != nil {
+		return nil, err
+	}
+
 	if err := g.VolumeCreator.GC(log); err != nil {
 		log.Error("graph-cleanup-failed", err)
 	}
@@ -216,6 +410,10 @@ func (g *Gardener) Create(spec garden.ContainerSpec) (ctr garden.Container, err
 	if rootFSURL.Scheme == RawRootFSScheme {
 		rootFSPath = rootFSURL.Path
 	} else {
+		if err := record(VolumeStep); err != nil {
+			return nil, err
+		}
+
 		var err error
 		rootFSPath, env, err = g.VolumeCreator.Create(log, spec.Handle, rootfs_provider.Spec{
 			RootFS:     rootFSURL,
@@ -228,14 +426,28 @@ func (g *Gardener) Create(spec garden.ContainerSpec) (ctr garden.Container, err
 		}
 	}
 
+	if err := record(ContainerStep); err != nil {
+		return nil, err
+	}
+
 	if err := g.Containerizer.Create(log, DesiredContainerSpec{
-		Handle:     spec.Handle,
-		RootFSPath: rootFSPath,
-		Hostname:   spec.Handle,
-		Privileged: spec.Privileged,
-		BindMounts: spec.BindMounts,
-		Limits:     spec.Limits,
-		Env:        append(env, spec.Env...),
+		Handle:          spec.Handle,
+		RootFSPath:      rootFSPath,
+		Hostname:        spec.Handle,
+		Privileged:      spec.Privileged,
+		BindMounts:      spec.BindMounts,
+		Limits:          spec.Limits,
+		Env:             append(env, spec.Env...),
+		SeccompProfile:  spec.Properties[SeccompProfileKey],
+		CapAdd:          splitList(spec.Properties[CapAddKey]),
+		CapDrop:         splitList(spec.Properties[CapDropKey]),
+		ReadOnlyRootFS:  spec.Properties[ReadOnlyRootFSKey] == "true",
+		TmpfsMounts:     tmpfsMounts,
+		MaskedPaths:     splitList(spec.Properties[MaskedPathsKey]),
+		ReadonlyPaths:   splitList(spec.Properties[ReadonlyPathsKey]),
+		ApparmorProfile: spec.Properties[ApparmorProfileKey],
+		NoNewPrivileges: spec.Properties[NoNewPrivilegesKey] == "true",
+		Runtime:         spec.Properties[RuntimeKey],
 	}); err != nil {
 		return nil, err
 	}
@@ -245,6 +457,10 @@ func (g *Gardener) Create(spec garden.ContainerSpec) (ctr garden.Container, err
 		return nil, err
 	}
 
+	if err := record(NetworkStep); err != nil {
+		return nil, err
+	}
+
 	if err = g.Networker.Network(log, spec, actualSpec.Pid); err != nil {
 		return nil, err
 	}
@@ -254,23 +470,86 @@ func (g *Gardener) Create(spec garden.ContainerSpec) (ctr garden.Container, err
 		return nil, err
 	}
 
+	// the properties are written directly rather than through the container,
+	// which would register another change with shutdown and so fail if Stop
+	// began while this create was in flight
 	if spec.GraceTime != 0 {
-		if err := container.SetGraceTime(spec.GraceTime); err != nil {
-			return nil, err
-		}
+		g.PropertyManager.Set(spec.Handle, GraceTimeKey, fmt.Sprintf("%d", spec.GraceTime))
 	}
 
 	for name, value := range spec.Properties {
-		if err := container.SetProperty(name, value); err != nil {
-			return nil, err
+		g.PropertyManager.Set(spec.Handle, name, value)
+	}
+
+	g.PropertyManager.Set(spec.Handle, "garden.state", "created")
+
+	return container, nil
+}
+
+// internalPropertyPrefixes are the prefixes of the properties in which
+// guardian records its own state about a container. Clients may not set or
+// remove them, since guardian relies on their values.
+var internalPropertyPrefixes = []string{"gardener.", "kawasaki.", "rundmc."}
+
+// checkClientProperty returns an error if the named property is internal
+func checkClientProperty(name string) error {
+	for _, prefix := range internalPropertyPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return fmt.Errorf("property is reserved: %s", name)
 		}
 	}
 
-	if err := container.SetProperty("garden.state", "created"); err != nil {
-		return nil, err
+	return nil
+}
+
+// splitList splits a comma-separated property value, ignoring empty entries
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
 	}
 
-	return container, nil
+	return list
+}
+
+// parseTmpfsMounts parses a comma-separated list of tmpfs mounts, each in the
+// form path[:size-in-bytes]. Paths must be absolute, may not contain '..' and
+// may not be the root directory.
+func parseTmpfsMounts(value string) ([]TmpfsMount, error) {
+	var mounts []TmpfsMount
+	for _, item := range splitList(value) {
+		parts := strings.SplitN(item, ":", 2)
+
+		if !filepath.IsAbs(parts[0]) {
+			return nil, fmt.Errorf("invalid tmpfs mount '%s': path must be absolute", item)
+		}
+
+		for _, element := range strings.Split(parts[0], "/") {
+			if element == ".." {
+				return nil, fmt.Errorf("invalid tmpfs mount '%s': path may not contain '..'", item)
+			}
+		}
+
+		mount := TmpfsMount{Path: filepath.Clean(parts[0])}
+		if mount.Path == "/" {
+			return nil, fmt.Errorf("invalid tmpfs mount '%s': path may not be '/'", item)
+		}
+
+		if len(parts) == 2 {
+			size, err := strconv.ParseUint(parts[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tmpfs mount '%s': %s", item, err)
+			}
+
+			mount.SizeInBytes = size
+		}
+
+		mounts = append(mounts, mount)
+	}
+
+	return mounts, nil
 }
 
 func (g *Gardener) Lookup(handle string) (garden.Container, error) {
@@ -285,10 +564,18 @@ func (g *Gardener) lookup(handle string) garden.Container {
 		volumeCreator:   g.VolumeCreator,
 		networker:       g.Networker,
 		propertyManager: g.PropertyManager,
+		locks:           &g.locks,
+		shutdown:        &g.shutdown,
 	}
 }
 
 func (g *Gardener) Destroy(handle string) error {
+	done, err := g.shutdown.begin()
+	if err != nil {
+		return err
+	}
+	defer done()
+
 	log := g.Logger.Session("destroy", lager.Data{"handle": handle})
 
 	log.Info("start")
@@ -303,6 +590,13 @@ func (g *Gardener) Destroy(handle string) error {
 		return garden.ContainerNotFoundError{Handle: handle}
 	}
 
+	release, err := g.locks.acquireForDestroy(handle)
+	if err != nil {
+		log.Error("acquire-lock-failed", err)
+		return err
+	}
+	defer release()
+
 	return g.destroy(log, handle)
 }
 
@@ -323,7 +617,41 @@ func (g *Gardener) destroy(log lager.Logger, handle string) error {
 	return g.PropertyManager.DestroyKeySpace(handle)
 }
 
-func (g *Gardener) Stop() {}
+// rollback undoes the given create steps, in the same order as destroy. Each
+// step is undone even if undoing an earlier one failed; the first error is
+// returned.
+func (g *Gardener) rollback(log lager.Logger, handle string, steps []string) error {
+	started := make(map[string]bool)
+	for _, step := range steps {
+		started[step] = true
+	}
+
+	undo := []struct {
+		step string
+		fn   func() error
+	}{
+		{ContainerStep, func() error { return g.Containerizer.Destroy(g.Logger, handle) }},
+		{NetworkStep, func() error { return g.Networker.Destroy(g.Logger, handle) }},
+		{VolumeStep, func() error { return g.VolumeCreator.Destroy(g.Logger, handle) }},
+		{PropertiesStep, func() error { return g.PropertyManager.DestroyKeySpace(handle) }},
+	}
+
+	var firstErr error
+	for _, u := range undo {
+		if !started[u.step] {
+			continue
+		}
+
+		if err := u.fn(); err != nil {
+			log.Error("undo-failed", err, lager.Data{"step": u.step})
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
 
 func (g *Gardener) GraceTime(container garden.Container) time.Duration {
 	property, ok := g.PropertyManager.Get(container.Handle(), GraceTimeKey)
@@ -365,6 +693,57 @@ func (g *Gardener) Capacity() (garden.Capacity, error) {
 	}, nil
 }
 
+// CommittedCapacity extends garden.Capacity with the resources already
+// committed to containers and those remaining, so that schedulers can place
+// work without tracking this state themselves.
+type CommittedCapacity struct {
+	garden.Capacity
+
+	CommittedMemoryInBytes uint64 `json:"committed_memory_in_bytes"`
+	CommittedDiskInBytes   uint64 `json:"committed_disk_in_bytes"`
+	ContainersInUse        uint64 `json:"containers_in_use"`
+	AvailableSubnets       uint64 `json:"available_subnets"`
+	AvailablePorts         uint64 `json:"available_ports"`
+
+	// Pools is the capacity of each network pool, keyed by name
+	Pools map[string]PoolCapacity `json:"pools,omitempty"`
+}
+
+func (g *Gardener) CommittedCapacity() (CommittedCapacity, error) {
+	capacity, err := g.Capacity()
+	if err != nil {
+		return CommittedCapacity{}, err
+	}
+
+	handles, err := g.Containerizer.Handles()
+	if err != nil {
+		return CommittedCapacity{}, err
+	}
+
+	g.admission.mu.Lock()
+	defer g.admission.mu.Unlock()
+
+	memory, err := g.committed(CommittedMemoryKey)
+	if err != nil {
+		return CommittedCapacity{}, err
+	}
+
+	disk, err := g.committed(CommittedDiskKey)
+	if err != nil {
+		return CommittedCapacity{}, err
+	}
+
+	return CommittedCapacity{
+		Capacity:               capacity,
+		CommittedMemoryInBytes: memory,
+		CommittedDiskInBytes:   disk,
+		ContainersInUse:        uint64(len(handles)),
+		AvailableSubnets:       g.Networker.AvailableSubnets(),
+		AvailablePorts:         g.Networker.AvailablePorts(),
+		Pools:                  g.Networker.PoolCapacities(),
+	}, nil
+}
+
 func (g *Gardener) Containers(props garden.Properties) ([]garden.Container, error) {
 	log := g.Logger.Session("list-containers")
 
@@ -394,40 +773,47 @@ func (g *Gardener) Containers(props garden.Properties) ([]garden.Container, erro
 }
 
 func (g *Gardener) BulkInfo(handles []string) (map[string]garden.ContainerInfoEntry, error) {
-	result := make(map[string]garden.ContainerInfoEntry)
-	for _, handle := range handles {
-		container := g.lookup(handle)
+	results := g.bulk(handles, func(handle string) bulkResult {
+		info, err := g.lookup(handle).Info()
+		return bulkResult{info: info, err: err}
+	})
 
+	infos := make(map[string]garden.ContainerInfoEntry)
+	for handle, result := range results {
 		var infoErr *garden.Error = nil
-		info, err := container.Info()
-		if err != nil {
-			infoErr = garden.NewError(err.Error())
+		if result.err != nil {
+			infoErr = garden.NewError(result.err.Error())
 		}
-		result[handle] = garden.ContainerInfoEntry{
-			Info: info,
+
+		infos[handle] = garden.ContainerInfoEntry{
+			Info: result.info,
 			Err:  infoErr,
 		}
 	}
 
-	return result, nil
+	return infos, nil
 }
 
 func (g *Gardener) BulkMetrics(handles []string) (map[string]garden.ContainerMetricsEntry, error) {
-	result := make(map[string]garden.ContainerMetricsEntry)
-	for _, handle := range handles {
+	results := g.bulk(handles, func(handle string) bulkResult {
+		metrics, err := g.lookup(handle).Metrics()
+		return bulkResult{metrics: metrics, err: err}
+	})
+
+	metrics := make(map[string]garden.ContainerMetricsEntry)
+	for handle, result := range results {
 		var e *garden.Error
-		m, err := g.lookup(handle).Metrics()
-		if err != nil {
-			e = garden.NewError(err.Error())
+		if result.err != nil {
+			e = garden.NewError(result.err.Error())
 		}
 
-		result[handle] = garden.ContainerMetricsEntry{
+		metrics[handle] = garden.ContainerMetricsEntry{
 			Err:     e,
-			Metrics: m,
+			Metrics: result.metrics,
 		}
 	}
 
-	return result, nil
+	return metrics, nil
 }
 
 func (g *Gardener) checkDuplicateHandle(handle string) error {
@@ -465,12 +851,36 @@ func (g *Gardener) Start() error {
 		}
 	}
 
+	g.rollbackInterruptedCreates(log)
+
 	handles, err := g.Containerizer.Handles()
 	if err != nil {
 		return err
 	}
 
-	for _, handle := range g.Restorer.Restore(log, handles) {
+	destroyed := make(map[string]bool)
+	for _, handle := range g.Reconciler.Reconcile(log, handles) {
+		destroyLog := log.Session("destroy-unreconciled-container", lager.Data{"handle": handle})
+		destroyLog.Info("start")
+
+		if err := g.destroy(destroyLog, handle); err != nil {
+			destroyLog.Error("failed", err)
+			continue
+		}
+
+		destroyed[handle] = true
+		destroyLog.Info("destroyed")
+	}
+
+	var remaining []string
+	for _, handle := range handles {
+		if !destroyed[handle] {
+			remaining = append(remaining, handle)
+		}
+	}
+
+	cleanedUp := make(map[string]bool)
+	for _, handle := range g.Restorer.Restore(log, remaining) {
 		destroyLog := log.Session("clean-up-container", lager.Data{"handle": handle})
 		destroyLog.Info("start")
 
@@ -479,8 +889,69 @@ func (g *Gardener) Start() error {
 			continue
 		}
 
+		cleanedUp[handle] = true
 		destroyLog.Info("cleaned-up")
 	}
 
+	for _, handle := range remaining {
+		if !cleanedUp[handle] {
+			g.recordCommittedMemory(log, handle)
+		}
+	}
+
 	return nil
 }
+
+// recordCommittedMemory commits the memory limit of a container which was
+// created before committed memory was recorded, so that it is counted by the
+// memory overcommit check
+func (g *Gardener) recordCommittedMemory(log lager.Logger, handle string) {
+	if _, ok := g.PropertyManager.Get(handle, CommittedMemoryKey); ok {
+		return
+	}
+
+	actualSpec, err := g.Containerizer.Info(log, handle)
+	if err != nil {
+		log.Error("record-committed-memory-failed", err, lager.Data{"handle": handle})
+		return
+	}
+
+	if limit := actualSpec.Limits.Memory.LimitInBytes; limit > 0 {
+		g.PropertyManager.Set(handle, CommittedMemoryKey, strconv.FormatUint(limit, 10))
+	}
+}
+
+// rollbackInterruptedCreates undoes the steps of any create which was in
+// progress when guardian last stopped. Journals which can not be rolled back
+// are kept so that the next start tries again.
+func (g *Gardener) rollbackInterruptedCreates(log lager.Logger) {
+	handles, err := g.Journal.Handles()
+	if err != nil {
+		log.Error("list-journals-failed", err)
+		return
+	}
+
+	for _, handle := range handles {
+		rollbackLog := log.Session("rollback-interrupted-create", lager.Data{"handle": handle})
+
+		steps, err := g.Journal.Steps(handle)
+		if err != nil {
+			rollbackLog.Error("read-journal-failed", err)
+			continue
+		}
+
+		rollbackLog.Info("start", lager.Data{"steps": steps})
+
+		if err := g.rollback(rollbackLog, handle, steps); err != nil {
+			rollbackLog.Error("failed", err)
+			continue
+		}
+
+		if err := g.Journal.Finish(handle); err != nil {
+			rollbackLog.Error("finish-journal-failed", err)
+			continue
+		}
+
+		rollbackLog.Info("rolled-back")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strings"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/garden"
@@ -13,6 +15,7 @@ import (
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
 )
@@ -26,6 +29,8 @@ var _ = Describe("Gardener", func() {
 		sysinfoProvider *fakes.FakeSysInfoProvider
 		propertyManager *fakes.FakePropertyManager
 		restorer        *fakes.FakeRestorer
+		journal         *fakes.FakeJournal
+		reconciler      *fakes.FakeReconciler
 
 		logger lager.Logger
 
@@ -41,6 +46,8 @@ var _ = Describe("Gardener", func() {
 		sysinfoProvider = new(fakes.FakeSysInfoProvider)
 		propertyManager = new(fakes.FakePropertyManager)
 		restorer = new(fakes.FakeRestorer)
+		journal = new(fakes.FakeJournal)
+		reconciler = new(fakes.FakeReconciler)
 
 		propertyManager.GetReturns("", true)
 		containerizer.HandlesReturns([]string{"some-handle"}, nil)
@@ -54,11 +61,29 @@ var _ = Describe("Gardener", func() {
 			Logger:          logger,
 			PropertyManager: propertyManager,
 			Restorer:        restorer,
+			Journal:         journal,
+			Reconciler:      reconciler,
 		}
 	})
 
 	Describe("creating a container", func() {
-		ItDestroysEverything := func(rootfsPath string) {
+		ItRollsBack := func(rootfsPath string, steps ...string) {
+			started := func(step string) bool {
+				for _, s := range steps {
+					if s == step {
+						return true
+					}
+				}
+				return false
+			}
+
+			expectedCalls := func(step string) int {
+				if started(step) {
+					return 1
+				}
+				return 0
+			}
+
 			BeforeEach(func() {
 				_, err := gdnr.Create(garden.ContainerSpec{
 					RootFSPath: rootfsPath,
@@ -67,22 +92,35 @@ var _ = Describe("Gardener", func() {
 				Expect(err).To(HaveOccurred())
 			})
 
-			It("should clean up the networking configuration", func() {
-				Expect(networker.DestroyCallCount()).To(Equal(1))
-				_, handle := networker.DestroyArgsForCall(0)
-				Expect(handle).To(Equal("poor-banana"))
+			It("records the steps it started in the journal", func() {
+				Expect(journal.RecordCallCount()).To(Equal(len(steps)))
+				for i, step := range steps {
+					handle, recorded := journal.RecordArgsForCall(i)
+					Expect(handle).To(Equal("poor-banana"))
+					Expect(recorded).To(Equal(step))
+				}
 			})
 
-			It("should clean up any created volumes", func() {
-				Expect(volumeCreator.DestroyCallCount()).To(Equal(1))
-				_, handle := volumeCreator.DestroyArgsForCall(0)
-				Expect(handle).To(Equal("poor-banana"))
+			It("cleans up the networking configuration only if it was started", func() {
+				Expect(networker.DestroyCallCount()).To(Equal(expectedCalls(gardener.NetworkStep)))
 			})
 
-			It("should destroy any container state", func() {
-				Expect(containerizer.DestroyCallCount()).To(Equal(1))
-				_, handle := containerizer.DestroyArgsForCall(0)
-				Expect(handle).To(Equal("poor-banana"))
+			It("cleans up the volume only if it was started", func() {
+				Expect(volumeCreator.DestroyCallCount()).To(Equal(expectedCalls(gardener.VolumeStep)))
+			})
+
+			It("destroys the container state only if it was started", func() {
+				Expect(containerizer.DestroyCallCount()).To(Equal(expectedCalls(gardener.ContainerStep)))
+			})
+
+			It("destroys the properties", func() {
+				Expect(propertyManager.DestroyKeySpaceCallCount()).To(Equal(1))
+				Expect(propertyManager.DestroyKeySpaceArgsForCall(0)).To(Equal("poor-banana"))
+			})
+
+			It("finishes the journal", func() {
+				Expect(journal.FinishCallCount()).To(Equal(1))
+				Expect(journal.FinishArgsForCall(0)).To(Equal("poor-banana"))
 			})
 		}
 
@@ -131,7 +169,7 @@ var _ = Describe("Gardener", func() {
 				Expect(err).To(HaveOccurred())
 			})
 
-			ItDestroysEverything("://banana")
+			ItRollsBack("://banana", gardener.PropertiesStep)
 		})
 
 		Context("when the rootfs path is raw", func() {
@@ -205,7 +243,7 @@ var _ = Describe("Gardener", func() {
 				Expect(containerizer.CreateCallCount()).To(Equal(0))
 			})
 
-			ItDestroysEverything("")
+			ItRollsBack("", gardener.PropertiesStep, gardener.VolumeStep)
 		})
 
 		It("asks the containerizer to create a container", func() {
@@ -226,6 +264,127 @@ var _ = Describe("Gardener", func() {
 			Expect(spec.Hostname).To(Equal("bob"))
 		})
 
+		It("passes the requested seccomp profile to the containerizer", func() {
+			_, err := gdnr.Create(garden.ContainerSpec{
+				Properties: garden.Properties{
+					gardener.SeccompProfileKey: "relaxed",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, spec := containerizer.CreateArgsForCall(0)
+			Expect(spec.SeccompProfile).To(Equal("relaxed"))
+		})
+
+		It("passes the requested capabilities to the containerizer", func() {
+			_, err := gdnr.Create(garden.ContainerSpec{
+				Properties: garden.Properties{
+					gardener.CapAddKey:  "CAP_NET_ADMIN, CAP_SYS_PTRACE",
+					gardener.CapDropKey: "CAP_NET_RAW",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, spec := containerizer.CreateArgsForCall(0)
+			Expect(spec.CapAdd).To(Equal([]string{"CAP_NET_ADMIN", "CAP_SYS_PTRACE"}))
+			Expect(spec.CapDrop).To(Equal([]string{"CAP_NET_RAW"}))
+		})
+
+		It("passes the read-only rootfs and tmpfs mount options to the containerizer", func() {
+			_, err := gdnr.Create(garden.ContainerSpec{
+				Properties: garden.Properties{
+					gardener.ReadOnlyRootFSKey: "true",
+					gardener.TmpfsMountsKey:    "/tmp:1048576,/run",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, spec := containerizer.CreateArgsForCall(0)
+			Expect(spec.ReadOnlyRootFS).To(BeTrue())
+			Expect(spec.TmpfsMounts).To(Equal([]gardener.TmpfsMount{
+				{Path: "/tmp", SizeInBytes: 1048576},
+				{Path: "/run"},
+			}))
+		})
+
+		It("passes the requested masked and read-only paths to the containerizer", func() {
+			_, err := gdnr.Create(garden.ContainerSpec{
+				Properties: garden.Properties{
+					gardener.MaskedPathsKey:   "/proc/foo,/sys/bar",
+					gardener.ReadonlyPathsKey: "/proc/baz",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, spec := containerizer.CreateArgsForCall(0)
+			Expect(spec.MaskedPaths).To(Equal([]string{"/proc/foo", "/sys/bar"}))
+			Expect(spec.ReadonlyPaths).To(Equal([]string{"/proc/baz"}))
+		})
+
+		It("passes the requested apparmor profile and no_new_privileges options to the containerizer", func() {
+			_, err := gdnr.Create(garden.ContainerSpec{
+				Properties: garden.Properties{
+					gardener.ApparmorProfileKey: "strict",
+					gardener.NoNewPrivilegesKey: "true",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, spec := containerizer.CreateArgsForCall(0)
+			Expect(spec.ApparmorProfile).To(Equal("strict"))
+			Expect(spec.NoNewPrivileges).To(BeTrue())
+		})
+
+		It("passes the requested runtime to the containerizer", func() {
+			_, err := gdnr.Create(garden.ContainerSpec{
+				Properties: garden.Properties{
+					gardener.RuntimeKey: "runsc",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, spec := containerizer.CreateArgsForCall(0)
+			Expect(spec.Runtime).To(Equal("runsc"))
+		})
+
+		Context("when the tmpfs mounts property is invalid", func() {
+			It("returns an error without creating the container", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{
+					Properties: garden.Properties{
+						gardener.TmpfsMountsKey: "tmp:banana",
+					},
+				})
+				Expect(err).To(MatchError(ContainSubstring("invalid tmpfs mount 'tmp:banana'")))
+				Expect(containerizer.CreateCallCount()).To(Equal(0))
+			})
+		})
+
+		DescribeTable("tmpfs mount paths which are not allowed",
+			func(path, expectedErr string) {
+				_, err := gdnr.Create(garden.ContainerSpec{
+					Properties: garden.Properties{
+						gardener.TmpfsMountsKey: path,
+					},
+				})
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+				Expect(containerizer.CreateCallCount()).To(Equal(0))
+			},
+			Entry("the root directory", "//", "path may not be '/'"),
+			Entry("a path escaping the rootfs", "/tmp/../../etc", "path may not contain '..'"),
+		)
+
+		It("cleans the tmpfs mount paths", func() {
+			_, err := gdnr.Create(garden.ContainerSpec{
+				Properties: garden.Properties{
+					gardener.TmpfsMountsKey: "/tmp//scratch/",
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, spec := containerizer.CreateArgsForCall(0)
+			Expect(spec.TmpfsMounts).To(Equal([]gardener.TmpfsMount{{Path: "/tmp/scratch"}}))
+		})
+
 		Context("when the containerizer fails to create the container", func() {
 			BeforeEach(func() {
 				containerizer.CreateReturns(errors.New("failed to create the banana"))
@@ -238,7 +397,7 @@ var _ = Describe("Gardener", func() {
 				Expect(err).To(HaveOccurred())
 			})
 
-			ItDestroysEverything("")
+			ItRollsBack("", gardener.PropertiesStep, gardener.VolumeStep, gardener.ContainerStep)
 
 			It("logs the underlying error", func() {
 				_, err := gdnr.Create(garden.ContainerSpec{Handle: "bob"})
@@ -293,11 +452,238 @@ var _ = Describe("Gardener", func() {
 		})
 
 		Context("when networker fails to configure network", func() {
+			BeforeEach(func() {
+				networker.NetworkReturns(errors.New("network-failed"))
+			})
+
 			It("errors", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{Handle: "bob"})
+				Expect(err).To(MatchError("network-failed"))
+			})
+
+			ItRollsBack("", gardener.PropertiesStep, gardener.VolumeStep, gardener.ContainerStep, gardener.NetworkStep)
+		})
+
+		Context("when the journal can not be started", func() {
+			BeforeEach(func() {
+				journal.BeginReturns(errors.New("no-journal"))
+			})
+
+			It("returns the error without creating anything", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{Handle: "bob"})
+				Expect(err).To(MatchError("no-journal"))
+				Expect(volumeCreator.CreateCallCount()).To(Equal(0))
+				Expect(containerizer.CreateCallCount()).To(Equal(0))
+				Expect(propertyManager.DestroyKeySpaceCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when a step can not be recorded", func() {
+			BeforeEach(func() {
+				journal.RecordStub = func(_, step string) error {
+					if step == gardener.ContainerStep {
+						return errors.New("disk-full")
+					}
+					return nil
+				}
+			})
+
+			It("does not start the step", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{Handle: "bob"})
+				Expect(err).To(MatchError("disk-full"))
+				Expect(containerizer.CreateCallCount()).To(Equal(0))
+				Expect(containerizer.DestroyCallCount()).To(Equal(0))
+				Expect(volumeCreator.DestroyCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when rolling back fails", func() {
+			BeforeEach(func() {
 				networker.NetworkReturns(errors.New("network-failed"))
+				containerizer.DestroyReturns(errors.New("container-destroy-failed"))
+			})
 
+			It("still undoes the remaining steps", func() {
 				_, err := gdnr.Create(garden.ContainerSpec{Handle: "bob"})
 				Expect(err).To(MatchError("network-failed"))
+				Expect(networker.DestroyCallCount()).To(Equal(1))
+				Expect(volumeCreator.DestroyCallCount()).To(Equal(1))
+				Expect(propertyManager.DestroyKeySpaceCallCount()).To(Equal(1))
+			})
+
+			It("keeps the journal so the next start can retry", func() {
+				gdnr.Create(garden.ContainerSpec{Handle: "bob"})
+				Expect(journal.FinishCallCount()).To(Equal(0))
+			})
+		})
+
+		It("begins and finishes a journal for the container", func() {
+			_, err := gdnr.Create(garden.ContainerSpec{Handle: "bob"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(journal.BeginCallCount()).To(Equal(1))
+			Expect(journal.BeginArgsForCall(0)).To(Equal("bob"))
+			Expect(journal.FinishCallCount()).To(Equal(1))
+			Expect(journal.FinishArgsForCall(0)).To(Equal("bob"))
+		})
+
+		It("does not allow internal properties to be set on creation", func() {
+			_, err := gdnr.Create(garden.ContainerSpec{
+				Properties: garden.Properties{gardener.CommittedMemoryKey: "0"},
+			})
+			Expect(err).To(MatchError("property is reserved: " + gardener.CommittedMemoryKey))
+			Expect(containerizer.CreateCallCount()).To(Equal(0))
+		})
+
+		Context("when a memory overcommit ratio is configured", func() {
+			BeforeEach(func() {
+				gdnr.MemoryOvercommitRatio = 1.5
+				sysinfoProvider.TotalMemoryReturns(1000, nil)
+
+				propertyManager.GetStub = func(handle, name string) (string, bool) {
+					if handle == "some-handle" && name == gardener.CommittedMemoryKey {
+						return "1000", true
+					}
+
+					return "", false
+				}
+			})
+
+			It("creates containers whose memory limit fits", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{
+					Handle: "small",
+					Limits: garden.Limits{Memory: garden.MemoryLimits{LimitInBytes: 500}},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				handle, name, value := propertyManager.SetArgsForCall(0)
+				Expect(handle).To(Equal("small"))
+				Expect(name).To(Equal(gardener.CommittedMemoryKey))
+				Expect(value).To(Equal("500"))
+			})
+
+			It("rejects containers whose memory limit would exceed the overcommitted total", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{
+					Handle: "big",
+					Limits: garden.Limits{Memory: garden.MemoryLimits{LimitInBytes: 501}},
+				})
+				Expect(err).To(MatchError("insufficient memory: requested 501 bytes, 1000 of 1500 bytes already committed"))
+				Expect(volumeCreator.CreateCallCount()).To(Equal(0))
+				Expect(containerizer.CreateCallCount()).To(Equal(0))
+			})
+
+			It("counts the memory of containers which are still being created", func() {
+				createStarted := make(chan struct{})
+				finishCreate := make(chan struct{})
+				containerizer.CreateStub = func(_ lager.Logger, spec gardener.DesiredContainerSpec) error {
+					if spec.Handle == "first" {
+						close(createStarted)
+						<-finishCreate
+					}
+					return nil
+				}
+
+				propertyManager.GetStub = func(handle, name string) (string, bool) {
+					if handle == "first" && name == gardener.CommittedMemoryKey {
+						return "1000", true
+					}
+
+					return "", false
+				}
+
+				go gdnr.Create(garden.ContainerSpec{
+					Handle: "first",
+					Limits: garden.Limits{Memory: garden.MemoryLimits{LimitInBytes: 1000}},
+				})
+				Eventually(createStarted).Should(BeClosed())
+
+				_, err := gdnr.Create(garden.ContainerSpec{
+					Handle: "second",
+					Limits: garden.Limits{Memory: garden.MemoryLimits{LimitInBytes: 1000}},
+				})
+				Expect(err).To(MatchError(ContainSubstring("insufficient memory")))
+
+				close(finishCreate)
+			})
+		})
+
+		Context("when the number of concurrent creates is limited", func() {
+			var (
+				createStarted chan struct{}
+				finishCreate  chan struct{}
+				firstCreated  chan error
+			)
+
+			BeforeEach(func() {
+				gdnr.MaxConcurrentCreates = 1
+
+				createStarted = make(chan struct{})
+				finishCreate = make(chan struct{})
+				firstCreated = make(chan error, 1)
+				containerizer.CreateStub = func(_ lager.Logger, spec gardener.DesiredContainerSpec) error {
+					if spec.Handle == "first" {
+						close(createStarted)
+						<-finishCreate
+					}
+					return nil
+				}
+			})
+
+			JustBeforeEach(func() {
+				go func() {
+					_, err := gdnr.Create(garden.ContainerSpec{Handle: "first"})
+					firstCreated <- err
+				}()
+				Eventually(createStarted).Should(BeClosed())
+			})
+
+			Context("and the queue is full", func() {
+				BeforeEach(func() {
+					gdnr.CreateQueueSize = 0
+				})
+
+				It("rejects further creates", func() {
+					_, err := gdnr.Create(garden.ContainerSpec{Handle: "second"})
+					Expect(err).To(Equal(gardener.ErrCreateQueueFull))
+
+					close(finishCreate)
+					Eventually(firstCreated).Should(Receive(BeNil()))
+				})
+			})
+
+			Context("and there is room in the queue", func() {
+				BeforeEach(func() {
+					gdnr.CreateQueueSize = 1
+				})
+
+				It("creates the queued container once a slot is free", func() {
+					secondCreated := make(chan error)
+					go func() {
+						_, err := gdnr.Create(garden.ContainerSpec{Handle: "second"})
+						secondCreated <- err
+					}()
+
+					Consistently(containerizer.CreateCallCount).Should(Equal(1))
+
+					close(finishCreate)
+					Eventually(secondCreated).Should(Receive(BeNil()))
+					Expect(containerizer.CreateCallCount()).To(Equal(2))
+				})
+
+				Context("and the queue timeout passes", func() {
+					BeforeEach(func() {
+						gdnr.CreateQueueTimeout = 50 * time.Millisecond
+					})
+
+					It("fails the queued create", func() {
+						_, err := gdnr.Create(garden.ContainerSpec{Handle: "second"})
+						Expect(err).To(MatchError("timed out after 50ms waiting to create container"))
+
+						close(finishCreate)
+						Eventually(firstCreated).Should(Receive(BeNil()))
+						Expect(containerizer.CreateCallCount()).To(Equal(1))
+					})
+				})
 			})
 		})
 
@@ -366,6 +752,27 @@ var _ = Describe("Gardener", func() {
 			})
 		})
 
+		DescribeTable("when passed an invalid handle",
+			func(handle string, reason string) {
+				_, err := gdnr.Create(garden.ContainerSpec{Handle: handle})
+				Expect(err).To(Equal(gardener.InvalidHandleError{Handle: handle, Reason: reason}))
+
+				Expect(volumeCreator.CreateCallCount()).To(Equal(0))
+				Expect(containerizer.CreateCallCount()).To(Equal(0))
+				Expect(containerizer.DestroyCallCount()).To(Equal(0))
+			},
+			Entry("containing a slash", "../../etc", "may only contain letters, digits, '_', '.' and '-'"),
+			Entry("containing whitespace", "some handle", "may only contain letters, digits, '_', '.' and '-'"),
+			Entry("dot", ".", "must not be '.' or '..'"),
+			Entry("dot dot", "..", "must not be '.' or '..'"),
+			Entry("too long", strings.Repeat("a", 129), "must be at most 128 characters"),
+		)
+
+		It("accepts a handle of the maximum length", func() {
+			_, err := gdnr.Create(garden.ContainerSpec{Handle: strings.Repeat("a", 128)})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		Context("when passed a handle that already exists", func() {
 			var (
 				containerSpec garden.ContainerSpec
@@ -617,10 +1024,307 @@ var _ = Describe("Gardener", func() {
 			Expect(handle).To(Equal("container2"))
 		})
 
+		It("reconciles the containers before restoring them", func() {
+			reconciler.ReconcileStub = func(_ lager.Logger, handles []string) []string {
+				Expect(restorer.RestoreCallCount()).To(Equal(0))
+				return nil
+			}
+
+			Expect(gdnr.Start()).To(Succeed())
+			Expect(reconciler.ReconcileCallCount()).To(Equal(1))
+			_, handles := reconciler.ReconcileArgsForCall(0)
+			Expect(handles).To(Equal([]string{"container1", "container2"}))
+		})
+
+		Context("when the reconciler says to destroy a container", func() {
+			BeforeEach(func() {
+				reconciler.ReconcileReturns([]string{"container1"})
+			})
+
+			It("destroys it", func() {
+				Expect(gdnr.Start()).To(Succeed())
+				Expect(containerizer.DestroyCallCount()).To(Equal(1))
+				_, handle := containerizer.DestroyArgsForCall(0)
+				Expect(handle).To(Equal("container1"))
+			})
+
+			It("does not restore it", func() {
+				Expect(gdnr.Start()).To(Succeed())
+				_, handles := restorer.RestoreArgsForCall(0)
+				Expect(handles).To(Equal([]string{"container2"}))
+			})
+
+			Context("and destroying it fails", func() {
+				BeforeEach(func() {
+					containerizer.DestroyReturns(errors.New("busy"))
+				})
+
+				It("still tries to restore it", func() {
+					Expect(gdnr.Start()).To(Succeed())
+					_, handles := restorer.RestoreArgsForCall(0)
+					Expect(handles).To(Equal([]string{"container1", "container2"}))
+				})
+			})
+		})
+
+		It("records the committed memory of containers which predate it", func() {
+			propertyManager.GetStub = func(handle, name string) (string, bool) {
+				return "1", handle == "container2"
+			}
+			containerizer.InfoReturns(gardener.ActualContainerSpec{
+				Limits: garden.Limits{Memory: garden.MemoryLimits{LimitInBytes: 4096}},
+			}, nil)
+
+			Expect(gdnr.Start()).To(Succeed())
+
+			Expect(propertyManager.SetCallCount()).To(Equal(1))
+			handle, name, value := propertyManager.SetArgsForCall(0)
+			Expect(handle).To(Equal("container1"))
+			Expect(name).To(Equal(gardener.CommittedMemoryKey))
+			Expect(value).To(Equal("4096"))
+		})
+
 		It("should return the error when it failes to get a list of handles", func() {
 			containerizer.HandlesReturns([]string{}, errors.New("banana"))
 			Expect(gdnr.Start()).To(MatchError("banana"))
 		})
+
+		Context("when creates were interrupted", func() {
+			BeforeEach(func() {
+				journal.HandlesReturns([]string{"half-made", "barely-begun"}, nil)
+				journal.StepsStub = func(handle string) ([]string, error) {
+					if handle == "half-made" {
+						return []string{gardener.PropertiesStep, gardener.VolumeStep, gardener.ContainerStep}, nil
+					}
+					return []string{gardener.PropertiesStep}, nil
+				}
+			})
+
+			It("rolls back only the steps which were started", func() {
+				Expect(gdnr.Start()).To(Succeed())
+
+				Expect(containerizer.DestroyCallCount()).To(Equal(1))
+				_, handle := containerizer.DestroyArgsForCall(0)
+				Expect(handle).To(Equal("half-made"))
+
+				Expect(volumeCreator.DestroyCallCount()).To(Equal(1))
+				Expect(networker.DestroyCallCount()).To(Equal(0))
+				Expect(propertyManager.DestroyKeySpaceCallCount()).To(Equal(2))
+			})
+
+			It("finishes the journals", func() {
+				Expect(gdnr.Start()).To(Succeed())
+				Expect(journal.FinishCallCount()).To(Equal(2))
+			})
+
+			It("rolls back before restoring the containers", func() {
+				restorer.RestoreStub = func(_ lager.Logger, handles []string) []string {
+					Expect(journal.FinishCallCount()).To(Equal(2))
+					return nil
+				}
+				Expect(gdnr.Start()).To(Succeed())
+			})
+
+			Context("when a rollback fails", func() {
+				BeforeEach(func() {
+					volumeCreator.DestroyReturns(errors.New("busy"))
+				})
+
+				It("keeps that journal and carries on", func() {
+					Expect(gdnr.Start()).To(Succeed())
+					Expect(journal.FinishCallCount()).To(Equal(1))
+					Expect(journal.FinishArgsForCall(0)).To(Equal("barely-begun"))
+				})
+			})
+		})
+	})
+
+	Describe("stopping gardener", func() {
+		var (
+			stopper   *fakes.FakeStopper
+			persister *fakes.FakePersister
+		)
+
+		BeforeEach(func() {
+			stopper = new(fakes.FakeStopper)
+			persister = new(fakes.FakePersister)
+			gdnr.Stoppers = []gardener.Stopper{stopper}
+			gdnr.Persisters = []gardener.Persister{persister}
+		})
+
+		It("runs the stoppers and then the persisters", func() {
+			persister.PersistStub = func() error {
+				Expect(stopper.StopCallCount()).To(Equal(1))
+				return nil
+			}
+
+			gdnr.Stop()
+			Expect(persister.PersistCallCount()).To(Equal(1))
+		})
+
+		It("only stops once", func() {
+			gdnr.Stop()
+			gdnr.Stop()
+			Expect(stopper.StopCallCount()).To(Equal(1))
+			Expect(persister.PersistCallCount()).To(Equal(1))
+		})
+
+		It("still runs the later persisters when one fails", func() {
+			other := new(fakes.FakePersister)
+			persister.PersistReturns(errors.New("disk-full"))
+			gdnr.Persisters = append(gdnr.Persisters, other)
+
+			gdnr.Stop()
+			Expect(other.PersistCallCount()).To(Equal(1))
+		})
+
+		It("rejects new creates and destroys", func() {
+			gdnr.Stop()
+
+			_, err := gdnr.Create(garden.ContainerSpec{})
+			Expect(err).To(Equal(gardener.ErrShuttingDown))
+			Expect(gdnr.Destroy("some-handle")).To(Equal(gardener.ErrShuttingDown))
+			Expect(containerizer.CreateCallCount()).To(Equal(0))
+			Expect(containerizer.DestroyCallCount()).To(Equal(0))
+		})
+
+		It("rejects changes to existing containers", func() {
+			container, err := gdnr.Lookup("some-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			gdnr.Stop()
+
+			_, _, err = container.NetIn(1, 2)
+			Expect(err).To(Equal(gardener.ErrShuttingDown))
+			Expect(container.NetOut(garden.NetOutRule{})).To(Equal(gardener.ErrShuttingDown))
+			Expect(container.SetProperty("foo", "bar")).To(Equal(gardener.ErrShuttingDown))
+			Expect(networker.NetInCallCount()).To(Equal(0))
+			Expect(networker.NetOutCallCount()).To(Equal(0))
+			Expect(propertyManager.SetCallCount()).To(Equal(0))
+		})
+
+		Context("when a net in is in flight", func() {
+			var (
+				netInStarted chan struct{}
+				finishNetIn  chan struct{}
+			)
+
+			BeforeEach(func() {
+				netInStarted = make(chan struct{})
+				finishNetIn = make(chan struct{})
+				networker.NetInStub = func(_ lager.Logger, _ string, _, _ uint32, _ string) (uint32, uint32, error) {
+					close(netInStarted)
+					<-finishNetIn
+					return 0, 0, nil
+				}
+
+				container, err := gdnr.Lookup("some-handle")
+				Expect(err).NotTo(HaveOccurred())
+
+				go container.NetIn(1, 2)
+				Eventually(netInStarted).Should(BeClosed())
+			})
+
+			It("waits for it to finish before persisting", func() {
+				stopped := make(chan struct{})
+				go func() {
+					gdnr.Stop()
+					close(stopped)
+				}()
+
+				Consistently(stopped).ShouldNot(BeClosed())
+				Expect(persister.PersistCallCount()).To(Equal(0))
+
+				close(finishNetIn)
+				Eventually(stopped).Should(BeClosed())
+				Expect(persister.PersistCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when a create is in flight", func() {
+			var (
+				createStarted chan struct{}
+				finishCreate  chan struct{}
+				createErr     chan error
+			)
+
+			BeforeEach(func() {
+				createStarted = make(chan struct{})
+				finishCreate = make(chan struct{})
+				createErr = make(chan error, 1)
+				containerizer.CreateStub = func(_ lager.Logger, _ gardener.DesiredContainerSpec) error {
+					close(createStarted)
+					<-finishCreate
+					return nil
+				}
+
+				go func() {
+					_, err := gdnr.Create(garden.ContainerSpec{
+						Handle:     "in-flight",
+						GraceTime:  time.Minute,
+						Properties: garden.Properties{"foo": "bar"},
+					})
+					createErr <- err
+				}()
+				Eventually(createStarted).Should(BeClosed())
+			})
+
+			It("lets it finish, including setting its properties", func() {
+				stopped := make(chan struct{})
+				go func() {
+					gdnr.Stop()
+					close(stopped)
+				}()
+
+				Consistently(stopped).ShouldNot(BeClosed())
+
+				close(finishCreate)
+				Eventually(createErr).Should(Receive(BeNil()))
+				Eventually(stopped).Should(BeClosed())
+
+				stored := make(map[string]string)
+				for i := 0; i < propertyManager.SetCallCount(); i++ {
+					handle, name, value := propertyManager.SetArgsForCall(i)
+					if handle == "in-flight" {
+						stored[name] = value
+					}
+				}
+				Expect(stored).To(HaveKeyWithValue("foo", "bar"))
+				Expect(stored).To(HaveKeyWithValue(gardener.GraceTimeKey, fmt.Sprintf("%d", time.Minute)))
+				Expect(stored).To(HaveKeyWithValue("garden.state", "created"))
+			})
+
+			It("waits for it to finish before stopping", func() {
+				stopped := make(chan struct{})
+				go func() {
+					gdnr.Stop()
+					close(stopped)
+				}()
+
+				Consistently(stopped).ShouldNot(BeClosed())
+				Expect(stopper.StopCallCount()).To(Equal(0))
+
+				close(finishCreate)
+				Eventually(stopped).Should(BeClosed())
+				Expect(stopper.StopCallCount()).To(Equal(1))
+			})
+
+			Context("and it does not finish within the stop timeout", func() {
+				BeforeEach(func() {
+					gdnr.StopTimeout = 50 * time.Millisecond
+				})
+
+				AfterEach(func() {
+					close(finishCreate)
+				})
+
+				It("stops anyway", func() {
+					gdnr.Stop()
+					Expect(stopper.StopCallCount()).To(Equal(1))
+					Expect(persister.PersistCallCount()).To(Equal(1))
+				})
+			})
+		})
 	})
 
 	Describe("listing containers", func() {
@@ -681,11 +1385,12 @@ var _ = Describe("Gardener", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(networker.NetInCallCount()).To(Equal(1))
 
-			actualLogger, actualHandle, actualExtPort, actualContainerPort := networker.NetInArgsForCall(0)
+			actualLogger, actualHandle, actualExtPort, actualContainerPort, actualProtocol := networker.NetInArgsForCall(0)
 			Expect(actualLogger).To(Equal(logger))
 			Expect(actualHandle).To(Equal(container.Handle()))
 			Expect(actualExtPort).To(Equal(externalPort))
 			Expect(actualContainerPort).To(Equal(contianerPort))
+			Expect(actualProtocol).To(BeEmpty())
 		})
 
 		Context("when networker returns an error", func() {
@@ -699,6 +1404,129 @@ var _ = Describe("Gardener", func() {
 		})
 	})
 
+	Describe("NetInWithProtocol and PortMappings", func() {
+		var container gardener.PortMapper
+
+		BeforeEach(func() {
+			c, err := gdnr.Lookup("banana")
+			Expect(err).NotTo(HaveOccurred())
+
+			var ok bool
+			container, ok = c.(gardener.PortMapper)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("asks the networker to forward the ports for the protocol", func() {
+			networker.NetInReturns(8888, 8080, nil)
+
+			hostPort, containerPort, err := container.NetInWithProtocol(8888, 8080, "udp")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hostPort).To(BeEquivalentTo(8888))
+			Expect(containerPort).To(BeEquivalentTo(8080))
+
+			_, actualHandle, _, _, actualProtocol := networker.NetInArgsForCall(0)
+			Expect(actualHandle).To(Equal("banana"))
+			Expect(actualProtocol).To(Equal("udp"))
+		})
+
+		It("lists the port mappings with their protocols", func() {
+			propertyManager.GetReturns(`[{"HostPort":8888,"ContainerPort":8080},{"HostPort":9999,"ContainerPort":53,"Protocol":"tcp+udp"}]`, true)
+
+			Expect(container.PortMappings()).To(Equal([]gardener.PortMapping{
+				{HostPort: 8888, ContainerPort: 8080},
+				{HostPort: 9999, ContainerPort: 53, Protocol: "tcp+udp"},
+			}))
+
+			handle, name := propertyManager.GetArgsForCall(0)
+			Expect(handle).To(Equal("banana"))
+			Expect(name).To(Equal(gardener.MappedPortsKey))
+		})
+
+		Context("when there are no port mappings", func() {
+			It("returns an empty list", func() {
+				Expect(container.PortMappings()).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("RemoveNetIn", func() {
+		var container gardener.PortMappingRemover
+
+		BeforeEach(func() {
+			c, err := gdnr.Lookup("banana")
+			Expect(err).NotTo(HaveOccurred())
+
+			var ok bool
+			container, ok = c.(gardener.PortMappingRemover)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("asks the networker to remove the port mapping", func() {
+			Expect(container.RemoveNetIn(8888, 8080, "udp")).To(Succeed())
+			Expect(networker.RemoveNetInCallCount()).To(Equal(1))
+
+			actualLogger, actualHandle, actualExtPort, actualContainerPort, actualProtocol := networker.RemoveNetInArgsForCall(0)
+			Expect(actualLogger).To(Equal(logger))
+			Expect(actualHandle).To(Equal("banana"))
+			Expect(actualExtPort).To(BeEquivalentTo(8888))
+			Expect(actualContainerPort).To(BeEquivalentTo(8080))
+			Expect(actualProtocol).To(Equal("udp"))
+		})
+
+		Context("when networker returns an error", func() {
+			It("returns the error", func() {
+				networker.RemoveNetInReturns(fmt.Errorf("banana republic"))
+				Expect(container.RemoveNetIn(8888, 8080, "")).To(MatchError("banana republic"))
+			})
+		})
+	})
+
+	Describe("BulkNetOut, NetOutRules and RevokeNetOut", func() {
+		var container gardener.NetOutRuleManager
+
+		BeforeEach(func() {
+			c, err := gdnr.Lookup("banana")
+			Expect(err).NotTo(HaveOccurred())
+
+			var ok bool
+			container, ok = c.(gardener.NetOutRuleManager)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("asks the networker for the container's netout rules", func() {
+			rules := []gardener.NetOutRule{{ID: "1", Rule: garden.NetOutRule{Protocol: garden.ProtocolTCP}}}
+			networker.NetOutRulesReturns(rules, nil)
+
+			Expect(container.NetOutRules()).To(Equal(rules))
+			_, handle := networker.NetOutRulesArgsForCall(0)
+			Expect(handle).To(Equal("banana"))
+		})
+
+		It("asks the networker to apply the netout rules in bulk", func() {
+			rules := []garden.NetOutRule{{Protocol: garden.ProtocolTCP}, {Protocol: garden.ProtocolUDP}}
+			Expect(container.BulkNetOut(rules)).To(Succeed())
+
+			_, handle, actualRules := networker.BulkNetOutArgsForCall(0)
+			Expect(handle).To(Equal("banana"))
+			Expect(actualRules).To(Equal(rules))
+		})
+
+		It("asks the networker to revoke the netout rule", func() {
+			Expect(container.RevokeNetOut("1")).To(Succeed())
+
+			_, handle, id := networker.RevokeNetOutArgsForCall(0)
+			Expect(handle).To(Equal("banana"))
+			Expect(id).To(Equal("1"))
+		})
+
+		Context("when networker returns an error", func() {
+			It("returns the error", func() {
+				networker.RevokeNetOutReturns(fmt.Errorf("banana republic"))
+				Expect(container.RevokeNetOut("1")).To(MatchError("banana republic"))
+			})
+		})
+	})
+
 	Describe("NetOut", func() {
 		var (
 			container garden.Container
@@ -801,6 +1629,76 @@ var _ = Describe("Gardener", func() {
 			Expect(handleToDestroy).To(Equal("some-handle"))
 		})
 
+		Context("when an operation is in progress on the container", func() {
+			var (
+				runStarted chan struct{}
+				finishRun  chan struct{}
+				runErr     chan error
+			)
+
+			BeforeEach(func() {
+				runStarted = make(chan struct{})
+				finishRun = make(chan struct{})
+				runErr = make(chan error, 1)
+
+				containerizer.RunStub = func(_ lager.Logger, handle string, _ garden.ProcessSpec, _ garden.ProcessIO) (garden.Process, error) {
+					close(runStarted)
+					<-finishRun
+					return nil, nil
+				}
+
+				container, err := gdnr.Lookup("some-handle")
+				Expect(err).NotTo(HaveOccurred())
+
+				go func() {
+					_, err := container.Run(garden.ProcessSpec{}, garden.ProcessIO{})
+					runErr <- err
+				}()
+				Eventually(runStarted).Should(BeClosed())
+			})
+
+			It("waits for the operation to finish before destroying", func() {
+				destroyed := make(chan error)
+				go func() { destroyed <- gdnr.Destroy("some-handle") }()
+
+				Consistently(containerizer.DestroyCallCount).Should(Equal(0))
+
+				close(finishRun)
+				Eventually(destroyed).Should(Receive(BeNil()))
+				Expect(runErr).To(Receive(BeNil()))
+				Expect(containerizer.DestroyCallCount()).To(Equal(1))
+			})
+
+			It("fails operations requested once the destroy has started", func() {
+				go gdnr.Destroy("some-handle")
+
+				container, err := gdnr.Lookup("some-handle")
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(func() error {
+					return container.StreamIn(garden.StreamInSpec{})
+				}).Should(MatchError("container is being destroyed"))
+
+				Expect(gdnr.Destroy("some-handle")).To(MatchError("container is being destroyed"))
+				Expect(container.SetProperty("foo", "bar")).To(MatchError("container is being destroyed"))
+				_, _, err = container.NetIn(1, 2)
+				Expect(err).To(MatchError("container is being destroyed"))
+
+				close(finishRun)
+				Eventually(containerizer.DestroyCallCount).Should(Equal(1))
+				Expect(containerizer.StreamInCallCount()).To(Equal(0))
+			})
+
+			It("allows operations once the destroy has finished", func() {
+				close(finishRun)
+				Expect(gdnr.Destroy("some-handle")).To(Succeed())
+
+				container, err := gdnr.Lookup("some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(container.Stop(false)).To(Succeed())
+			})
+		})
+
 		It("should destroy the key space of the property manager", func() {
 			gdnr.Destroy("some-handle")
 
@@ -936,6 +1834,89 @@ var _ = Describe("Gardener", func() {
 		})
 	})
 
+	Describe("getting committed capacity", func() {
+		BeforeEach(func() {
+			sysinfoProvider.TotalMemoryReturns(999, nil)
+			sysinfoProvider.TotalDiskReturns(888, nil)
+			networker.CapacityReturns(1000)
+			networker.AvailableSubnetsReturns(998)
+			networker.AvailablePortsReturns(4000)
+			networker.PoolCapacitiesReturns(map[string]gardener.PoolCapacity{
+				"default": {Capacity: 1000, Available: 998},
+				"dmz":     {Capacity: 8, Available: 8},
+			})
+
+			containerizer.HandlesReturns([]string{"container-1", "container-2"}, nil)
+			propertyManager.GetStub = func(handle, name string) (string, bool) {
+				switch {
+				case handle == "container-1" && name == gardener.CommittedMemoryKey:
+					return "100", true
+				case handle == "container-2" && name == gardener.CommittedMemoryKey:
+					return "200", true
+				case handle == "container-2" && name == gardener.CommittedDiskKey:
+					return "300", true
+				}
+
+				return "", false
+			}
+		})
+
+		It("returns the capacity along with the committed and available resources", func() {
+			capacity, err := gdnr.CommittedCapacity()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(capacity).To(Equal(gardener.CommittedCapacity{
+				Capacity: garden.Capacity{
+					MemoryInBytes: 999,
+					DiskInBytes:   888,
+					MaxContainers: 1000,
+				},
+				CommittedMemoryInBytes: 300,
+				CommittedDiskInBytes:   300,
+				ContainersInUse:        2,
+				AvailableSubnets:       998,
+				AvailablePorts:         4000,
+				Pools: map[string]gardener.PoolCapacity{
+					"default": {Capacity: 1000, Available: 998},
+					"dmz":     {Capacity: 8, Available: 8},
+				},
+			}))
+		})
+
+		It("records the limits of new containers", func() {
+			_, err := gdnr.Create(garden.ContainerSpec{
+				Handle: "container-3",
+				Limits: garden.Limits{
+					Memory: garden.MemoryLimits{LimitInBytes: 10},
+					Disk:   garden.DiskLimits{ByteHard: 20},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			props := map[string]string{}
+			for i := 0; i < propertyManager.SetCallCount(); i++ {
+				handle, name, value := propertyManager.SetArgsForCall(i)
+				if handle == "container-3" {
+					props[name] = value
+				}
+			}
+
+			Expect(props).To(HaveKeyWithValue(gardener.CommittedMemoryKey, "10"))
+			Expect(props).To(HaveKeyWithValue(gardener.CommittedDiskKey, "20"))
+		})
+
+		Context("when listing the containers fails", func() {
+			BeforeEach(func() {
+				containerizer.HandlesReturns(nil, errors.New("boom"))
+			})
+
+			It("returns the error", func() {
+				_, err := gdnr.CommittedCapacity()
+				Expect(err).To(MatchError("boom"))
+			})
+		})
+	})
+
 	Describe("Properties", func() {
 		var container garden.Container
 
@@ -976,6 +1957,14 @@ var _ = Describe("Gardener", func() {
 			Expect(handle).To(Equal("some-handle"))
 			Expect(name).To(Equal("name"))
 		})
+
+		It("does not allow internal properties to be set or removed", func() {
+			Expect(container.SetProperty(gardener.CommittedMemoryKey, "0")).To(MatchError("property is reserved: " + gardener.CommittedMemoryKey))
+			Expect(container.RemoveProperty("kawasaki.subnet")).To(MatchError("property is reserved: kawasaki.subnet"))
+
+			Expect(propertyManager.SetCallCount()).To(Equal(0))
+			Expect(propertyManager.RemoveCallCount()).To(Equal(0))
+		})
 	})
 
 	Describe("Info", func() {
@@ -1189,6 +2178,114 @@ var _ = Describe("Gardener", func() {
 				Expect(infos["some-handle-1"].Err).To(MatchError(ContainSubstring("no property found")))
 			})
 		})
+
+		Context("when the concurrency is bounded", func() {
+			var (
+				mu                    sync.Mutex
+				inFlight, maxInFlight int
+				started               chan string
+				release               chan struct{}
+			)
+
+			BeforeEach(func() {
+				inFlight, maxInFlight = 0, 0
+				started = make(chan string, 5)
+				release = make(chan struct{})
+				gdnr.BulkConcurrency = 2
+
+				containerizer.InfoStub = func(_ lager.Logger, handle string) (gardener.ActualContainerSpec, error) {
+					mu.Lock()
+					inFlight++
+					if inFlight > maxInFlight {
+						maxInFlight = inFlight
+					}
+					mu.Unlock()
+
+					started <- handle
+					<-release
+
+					mu.Lock()
+					inFlight--
+					mu.Unlock()
+
+					return gardener.ActualContainerSpec{}, nil
+				}
+			})
+
+			It("queries the containers in parallel up to the limit", func() {
+				done := make(chan map[string]garden.ContainerInfoEntry)
+				go func() {
+					defer GinkgoRecover()
+					infos, err := gdnr.BulkInfo([]string{"a", "b", "c", "d", "e"})
+					Expect(err).NotTo(HaveOccurred())
+					done <- infos
+				}()
+
+				Eventually(started).Should(Receive())
+				Eventually(started).Should(Receive())
+				Consistently(started).ShouldNot(Receive())
+
+				close(release)
+
+				var infos map[string]garden.ContainerInfoEntry
+				Eventually(done).Should(Receive(&infos))
+				Expect(infos).To(HaveLen(5))
+
+				mu.Lock()
+				defer mu.Unlock()
+				Expect(maxInFlight).To(Equal(2))
+			})
+		})
+
+		Context("when a container takes longer than the timeout", func() {
+			var unblock chan struct{}
+
+			BeforeEach(func() {
+				unblock = make(chan struct{})
+				gdnr.BulkConcurrency = 2
+				gdnr.BulkTimeout = 50 * time.Millisecond
+
+				containerizer.InfoStub = func(_ lager.Logger, handle string) (gardener.ActualContainerSpec, error) {
+					if strings.HasPrefix(handle, "wedged") {
+						<-unblock
+					}
+
+					return gardener.ActualContainerSpec{}, nil
+				}
+			})
+
+			AfterEach(func() {
+				close(unblock)
+			})
+
+			It("returns an error entry for that container only", func() {
+				infos, err := gdnr.BulkInfo([]string{"wedged", "some-handle-1"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(infos["wedged"].Err).To(MatchError("timed out after 50ms"))
+				Expect(infos["some-handle-1"].Err).NotTo(HaveOccurred())
+			})
+
+			Context("when as many containers as the concurrency are wedged", func() {
+				It("returns within the timeout, with an error entry for the containers not queried", func() {
+					done := make(chan map[string]garden.ContainerInfoEntry)
+					go func() {
+						defer GinkgoRecover()
+						infos, err := gdnr.BulkInfo([]string{"wedged-1", "wedged-2", "some-handle-1"})
+						Expect(err).NotTo(HaveOccurred())
+						done <- infos
+					}()
+
+					var infos map[string]garden.ContainerInfoEntry
+					Eventually(done, "100ms").Should(Receive(&infos))
+
+					Expect(infos).To(HaveLen(3))
+					Expect(infos["wedged-1"].Err).To(MatchError("timed out after 50ms"))
+					Expect(infos["wedged-2"].Err).To(MatchError("timed out after 50ms"))
+					Expect(infos["some-handle-1"].Err).To(MatchError("timed out after 50ms"))
+				})
+			})
+		})
 	})
 
 	Describe("Metrics", func() {
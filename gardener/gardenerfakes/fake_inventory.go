@@ -0,0 +1,133 @@
+// This file was generated by counterfeiter
+package gardenerfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/lager"
+)
+
+type FakeInventory struct {
+	CheckStub        func(log lager.Logger, handles []string) (missing, orphans []string, err error)
+	checkMutex       sync.RWMutex
+	checkArgsForCall []struct {
+		log     lager.Logger
+		handles []string
+	}
+	checkReturns struct {
+		result1 []string
+		result2 []string
+		result3 error
+	}
+	RemoveOrphanStub        func(log lager.Logger, orphan string) error
+	removeOrphanMutex       sync.RWMutex
+	removeOrphanArgsForCall []struct {
+		log    lager.Logger
+		orphan string
+	}
+	removeOrphanReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeInventory) Check(log lager.Logger, handles []string) (missing, orphans []string, err error) {
+	var handlesCopy []string
+	if handles != nil {
+		handlesCopy = make([]string, len(handles))
+		copy(handlesCopy, handles)
+	}
+	fake.checkMutex.Lock()
+	fake.checkArgsForCall = append(fake.checkArgsForCall, struct {
+		log     lager.Logger
+		handles []string
+	}{log, handlesCopy})
+	fake.recordInvocation("Check", []interface{}{log, handlesCopy})
+	fake.checkMutex.Unlock()
+	if fake.CheckStub != nil {
+		return fake.CheckStub(log, handles)
+	} else {
+		return fake.checkReturns.result1, fake.checkReturns.result2, fake.checkReturns.result3
+	}
+}
+
+func (fake *FakeInventory) CheckCallCount() int {
+	fake.checkMutex.RLock()
+	defer fake.checkMutex.RUnlock()
+	return len(fake.checkArgsForCall)
+}
+
+func (fake *FakeInventory) CheckArgsForCall(i int) (lager.Logger, []string) {
+	fake.checkMutex.RLock()
+	defer fake.checkMutex.RUnlock()
+	return fake.checkArgsForCall[i].log, fake.checkArgsForCall[i].handles
+}
+
+func (fake *FakeInventory) CheckReturns(result1 []string, result2 []string, result3 error) {
+	fake.CheckStub = nil
+	fake.checkReturns = struct {
+		result1 []string
+		result2 []string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeInventory) RemoveOrphan(log lager.Logger, orphan string) error {
+	fake.removeOrphanMutex.Lock()
+	fake.removeOrphanArgsForCall = append(fake.removeOrphanArgsForCall, struct {
+		log    lager.Logger
+		orphan string
+	}{log, orphan})
+	fake.recordInvocation("RemoveOrphan", []interface{}{log, orphan})
+	fake.removeOrphanMutex.Unlock()
+	if fake.RemoveOrphanStub != nil {
+		return fake.RemoveOrphanStub(log, orphan)
+	} else {
+		return fake.removeOrphanReturns.result1
+	}
+}
+
+func (fake *FakeInventory) RemoveOrphanCallCount() int {
+	fake.removeOrphanMutex.RLock()
+	defer fake.removeOrphanMutex.RUnlock()
+	return len(fake.removeOrphanArgsForCall)
+}
+
+func (fake *FakeInventory) RemoveOrphanArgsForCall(i int) (lager.Logger, string) {
+	fake.removeOrphanMutex.RLock()
+	defer fake.removeOrphanMutex.RUnlock()
+	return fake.removeOrphanArgsForCall[i].log, fake.removeOrphanArgsForCall[i].orphan
+}
+
+func (fake *FakeInventory) RemoveOrphanReturns(result1 error) {
+	fake.RemoveOrphanStub = nil
+	fake.removeOrphanReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeInventory) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.checkMutex.RLock()
+	defer fake.checkMutex.RUnlock()
+	fake.removeOrphanMutex.RLock()
+	defer fake.removeOrphanMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeInventory) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ gardener.Inventory = new(FakeInventory)
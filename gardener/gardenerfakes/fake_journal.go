@@ -0,0 +1,244 @@
+// This file was generated by counterfeiter
+package gardenerfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/gardener"
+)
+
+type FakeJournal struct {
+	BeginStub        func(handle string) error
+	beginMutex       sync.RWMutex
+	beginArgsForCall []struct {
+		handle string
+	}
+	beginReturns struct {
+		result1 error
+	}
+	RecordStub        func(handle, step string) error
+	recordMutex       sync.RWMutex
+	recordArgsForCall []struct {
+		handle string
+		step   string
+	}
+	recordReturns struct {
+		result1 error
+	}
+	StepsStub        func(handle string) ([]string, error)
+	stepsMutex       sync.RWMutex
+	stepsArgsForCall []struct {
+		handle string
+	}
+	stepsReturns struct {
+		result1 []string
+		result2 error
+	}
+	FinishStub        func(handle string) error
+	finishMutex       sync.RWMutex
+	finishArgsForCall []struct {
+		handle string
+	}
+	finishReturns struct {
+		result1 error
+	}
+	HandlesStub        func() ([]string, error)
+	handlesMutex       sync.RWMutex
+	handlesArgsForCall []struct{}
+	handlesReturns     struct {
+		result1 []string
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeJournal) Begin(handle string) error {
+	fake.beginMutex.Lock()
+	fake.beginArgsForCall = append(fake.beginArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Begin", []interface{}{handle})
+	fake.beginMutex.Unlock()
+	if fake.BeginStub != nil {
+		return fake.BeginStub(handle)
+	} else {
+		return fake.beginReturns.result1
+	}
+}
+
+func (fake *FakeJournal) BeginCallCount() int {
+	fake.beginMutex.RLock()
+	defer fake.beginMutex.RUnlock()
+	return len(fake.beginArgsForCall)
+}
+
+func (fake *FakeJournal) BeginArgsForCall(i int) string {
+	fake.beginMutex.RLock()
+	defer fake.beginMutex.RUnlock()
+	return fake.beginArgsForCall[i].handle
+}
+
+func (fake *FakeJournal) BeginReturns(result1 error) {
+	fake.BeginStub = nil
+	fake.beginReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeJournal) Record(handle string, step string) error {
+	fake.recordMutex.Lock()
+	fake.recordArgsForCall = append(fake.recordArgsForCall, struct {
+		handle string
+		step   string
+	}{handle, step})
+	fake.recordInvocation("Record", []interface{}{handle, step})
+	fake.recordMutex.Unlock()
+	if fake.RecordStub != nil {
+		return fake.RecordStub(handle, step)
+	} else {
+		return fake.recordReturns.result1
+	}
+}
+
+func (fake *FakeJournal) RecordCallCount() int {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return len(fake.recordArgsForCall)
+}
+
+func (fake *FakeJournal) RecordArgsForCall(i int) (string, string) {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return fake.recordArgsForCall[i].handle, fake.recordArgsForCall[i].step
+}
+
+func (fake *FakeJournal) RecordReturns(result1 error) {
+	fake.RecordStub = nil
+	fake.recordReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeJournal) Steps(handle string) ([]string, error) {
+	fake.stepsMutex.Lock()
+	fake.stepsArgsForCall = append(fake.stepsArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Steps", []interface{}{handle})
+	fake.stepsMutex.Unlock()
+	if fake.StepsStub != nil {
+		return fake.StepsStub(handle)
+	} else {
+		return fake.stepsReturns.result1, fake.stepsReturns.result2
+	}
+}
+
+func (fake *FakeJournal) StepsCallCount() int {
+	fake.stepsMutex.RLock()
+	defer fake.stepsMutex.RUnlock()
+	return len(fake.stepsArgsForCall)
+}
+
+func (fake *FakeJournal) StepsArgsForCall(i int) string {
+	fake.stepsMutex.RLock()
+	defer fake.stepsMutex.RUnlock()
+	return fake.stepsArgsForCall[i].handle
+}
+
+func (fake *FakeJournal) StepsReturns(result1 []string, result2 error) {
+	fake.StepsStub = nil
+	fake.stepsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeJournal) Finish(handle string) error {
+	fake.finishMutex.Lock()
+	fake.finishArgsForCall = append(fake.finishArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Finish", []interface{}{handle})
+	fake.finishMutex.Unlock()
+	if fake.FinishStub != nil {
+		return fake.FinishStub(handle)
+	} else {
+		return fake.finishReturns.result1
+	}
+}
+
+func (fake *FakeJournal) FinishCallCount() int {
+	fake.finishMutex.RLock()
+	defer fake.finishMutex.RUnlock()
+	return len(fake.finishArgsForCall)
+}
+
+func (fake *FakeJournal) FinishArgsForCall(i int) string {
+	fake.finishMutex.RLock()
+	defer fake.finishMutex.RUnlock()
+	return fake.finishArgsForCall[i].handle
+}
+
+func (fake *FakeJournal) FinishReturns(result1 error) {
+	fake.FinishStub = nil
+	fake.finishReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeJournal) Handles() ([]string, error) {
+	fake.handlesMutex.Lock()
+	fake.handlesArgsForCall = append(fake.handlesArgsForCall, struct{}{})
+	fake.recordInvocation("Handles", []interface{}{})
+	fake.handlesMutex.Unlock()
+	if fake.HandlesStub != nil {
+		return fake.HandlesStub()
+	} else {
+		return fake.handlesReturns.result1, fake.handlesReturns.result2
+	}
+}
+
+func (fake *FakeJournal) HandlesCallCount() int {
+	fake.handlesMutex.RLock()
+	defer fake.handlesMutex.RUnlock()
+	return len(fake.handlesArgsForCall)
+}
+
+func (fake *FakeJournal) HandlesReturns(result1 []string, result2 error) {
+	fake.HandlesStub = nil
+	fake.handlesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeJournal) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.beginMutex.RLock()
+	defer fake.beginMutex.RUnlock()
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	fake.stepsMutex.RLock()
+	defer fake.stepsMutex.RUnlock()
+	fake.finishMutex.RLock()
+	defer fake.finishMutex.RUnlock()
+	fake.handlesMutex.RLock()
+	defer fake.handlesMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeJournal) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ gardener.Journal = new(FakeJournal)
@@ -0,0 +1,66 @@
+// This file was generated by counterfeiter
+package gardenerfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/gardener"
+)
+
+type FakePersister struct {
+	PersistStub        func() error
+	persistMutex       sync.RWMutex
+	persistArgsForCall []struct{}
+	persistReturns     struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakePersister) Persist() error {
+	fake.persistMutex.Lock()
+	fake.persistArgsForCall = append(fake.persistArgsForCall, struct{}{})
+	fake.recordInvocation("Persist", []interface{}{})
+	fake.persistMutex.Unlock()
+	if fake.PersistStub != nil {
+		return fake.PersistStub()
+	} else {
+		return fake.persistReturns.result1
+	}
+}
+
+func (fake *FakePersister) PersistCallCount() int {
+	fake.persistMutex.RLock()
+	defer fake.persistMutex.RUnlock()
+	return len(fake.persistArgsForCall)
+}
+
+func (fake *FakePersister) PersistReturns(result1 error) {
+	fake.PersistStub = nil
+	fake.persistReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakePersister) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.persistMutex.RLock()
+	defer fake.persistMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakePersister) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ gardener.Persister = new(FakePersister)
@@ -0,0 +1,84 @@
+// This file was generated by counterfeiter
+package gardenerfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/lager"
+)
+
+type FakeReconciler struct {
+	ReconcileStub        func(log lager.Logger, handles []string) []string
+	reconcileMutex       sync.RWMutex
+	reconcileArgsForCall []struct {
+		log     lager.Logger
+		handles []string
+	}
+	reconcileReturns struct {
+		result1 []string
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeReconciler) Reconcile(log lager.Logger, handles []string) []string {
+	var handlesCopy []string
+	if handles != nil {
+		handlesCopy = make([]string, len(handles))
+		copy(handlesCopy, handles)
+	}
+	fake.reconcileMutex.Lock()
+	fake.reconcileArgsForCall = append(fake.reconcileArgsForCall, struct {
+		log     lager.Logger
+		handles []string
+	}{log, handlesCopy})
+	fake.recordInvocation("Reconcile", []interface{}{log, handlesCopy})
+	fake.reconcileMutex.Unlock()
+	if fake.ReconcileStub != nil {
+		return fake.ReconcileStub(log, handles)
+	} else {
+		return fake.reconcileReturns.result1
+	}
+}
+
+func (fake *FakeReconciler) ReconcileCallCount() int {
+	fake.reconcileMutex.RLock()
+	defer fake.reconcileMutex.RUnlock()
+	return len(fake.reconcileArgsForCall)
+}
+
+func (fake *FakeReconciler) ReconcileArgsForCall(i int) (lager.Logger, []string) {
+	fake.reconcileMutex.RLock()
+	defer fake.reconcileMutex.RUnlock()
+	return fake.reconcileArgsForCall[i].log, fake.reconcileArgsForCall[i].handles
+}
+
+func (fake *FakeReconciler) ReconcileReturns(result1 []string) {
+	fake.ReconcileStub = nil
+	fake.reconcileReturns = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeReconciler) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.reconcileMutex.RLock()
+	defer fake.reconcileMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeReconciler) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ gardener.Reconciler = new(FakeReconciler)
@@ -0,0 +1,41 @@
+package gardener
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// MaxHandleLength is the longest handle a container may be created with
+const MaxHandleLength = 128
+
+var validHandle = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// InvalidHandleError is returned when a container is created with a handle
+// which can not safely be used as a depot directory name and runtime ID.
+type InvalidHandleError struct {
+	Handle string
+	Reason string
+}
+
+func (e InvalidHandleError) Error() string {
+	return fmt.Sprintf("invalid handle '%s': %s", e.Handle, e.Reason)
+}
+
+// ValidateHandle checks that a client-supplied handle only contains
+// alphanumerics, '_', '.' and '-', is not '.' or '..' and is no longer than
+// MaxHandleLength.
+func ValidateHandle(handle string) error {
+	if len(handle) > MaxHandleLength {
+		return InvalidHandleError{Handle: handle, Reason: fmt.Sprintf("must be at most %d characters", MaxHandleLength)}
+	}
+
+	if !validHandle.MatchString(handle) {
+		return InvalidHandleError{Handle: handle, Reason: "may only contain letters, digits, '_', '.' and '-'"}
+	}
+
+	if handle == "." || handle == ".." {
+		return InvalidHandleError{Handle: handle, Reason: "must not be '.' or '..'"}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package gardener
+
+import (
+	"errors"
+	"sync"
+)
+
+var ErrContainerBeingDestroyed = errors.New("container is being destroyed")
+
+// handleLocks serializes operations on each container against its
+// destruction. Any number of operations may hold a handle's lock at once, but
+// destroying the container waits for them to finish and excludes new ones.
+// Operations which are still waiting for the lock when a destroy starts fail
+// with ErrContainerBeingDestroyed rather than running against a container
+// which is going away.
+type handleLocks struct {
+	mu    sync.Mutex
+	locks map[string]*handleLock
+}
+
+type handleLock struct {
+	sync.RWMutex
+
+	// users counts holders and waiters so the entry can be dropped when idle
+	users int
+	// destroying is true from the start of a destroy until it releases
+	destroying bool
+	// destroys counts completed destroys, so that waiters can tell one
+	// happened while they were blocked
+	destroys int
+}
+
+func (l *handleLocks) acquire(handle string) (release func(), err error) {
+	l.mu.Lock()
+	lock := l.get(handle)
+	if lock.destroying {
+		l.mu.Unlock()
+		return nil, ErrContainerBeingDestroyed
+	}
+
+	destroys := lock.destroys
+	lock.users++
+	l.mu.Unlock()
+
+	lock.RLock()
+
+	l.mu.Lock()
+	destroyed := lock.destroys != destroys
+	l.mu.Unlock()
+
+	if destroyed {
+		lock.RUnlock()
+		l.done(handle, lock)
+		return nil, ErrContainerBeingDestroyed
+	}
+
+	return func() {
+		lock.RUnlock()
+		l.done(handle, lock)
+	}, nil
+}
+
+func (l *handleLocks) acquireForDestroy(handle string) (release func(), err error) {
+	l.mu.Lock()
+	lock := l.get(handle)
+	if lock.destroying {
+		l.mu.Unlock()
+		return nil, ErrContainerBeingDestroyed
+	}
+
+	lock.destroying = true
+	lock.users++
+	l.mu.Unlock()
+
+	lock.Lock()
+
+	return func() {
+		l.mu.Lock()
+		lock.destroying = false
+		lock.destroys++
+		l.mu.Unlock()
+
+		lock.Unlock()
+		l.done(handle, lock)
+	}, nil
+}
+
+func (l *handleLocks) get(handle string) *handleLock {
+	if l.locks == nil {
+		l.locks = make(map[string]*handleLock)
+	}
+
+	lock, ok := l.locks[handle]
+	if !ok {
+		lock = &handleLock{}
+		l.locks[handle] = lock
+	}
+
+	return lock
+}
+
+func (l *handleLocks) done(handle string, lock *handleLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock.users--
+	if lock.users == 0 {
+		delete(l.locks, handle)
+	}
+}
@@ -0,0 +1,19 @@
+package gardener_test
+
+import (
+	"code.cloudfoundry.org/guardian/gardener"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NoopJournal", func() {
+	It("never has any interrupted creates", func() {
+		journal := &gardener.NoopJournal{}
+		Expect(journal.Begin("banana")).To(Succeed())
+		Expect(journal.Record("banana", gardener.VolumeStep)).To(Succeed())
+
+		handles, err := journal.Handles()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handles).To(BeEmpty())
+	})
+})
@@ -0,0 +1,130 @@
+package gardener
+
+import (
+	"fmt"
+	"sort"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// ReconcilePolicy says what the Reconciler does with the mismatches it finds
+type ReconcilePolicy string
+
+const (
+	// ReconcileReport only logs mismatches
+	ReconcileReport ReconcilePolicy = "report"
+
+	// ReconcileAdopt removes orphaned resources which belong to no container
+	// in the depot, but keeps containers in the depot which are missing from
+	// another inventory
+	ReconcileAdopt ReconcilePolicy = "adopt"
+
+	// ReconcileDestroy removes orphaned resources and destroys containers in
+	// the depot which are missing from another inventory
+	ReconcileDestroy ReconcilePolicy = "destroy"
+)
+
+func ParseReconcilePolicy(name string) (ReconcilePolicy, error) {
+	switch policy := ReconcilePolicy(name); policy {
+	case ReconcileReport, ReconcileAdopt, ReconcileDestroy:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown reconcile policy: %s", name)
+	}
+}
+
+// Inventory is somewhere other than the depot which keeps state for
+// containers, e.g. the property key spaces or the runtime's container list
+type Inventory interface {
+	// Check compares the inventory with the handles in the depot. It returns
+	// the handles which are missing from the inventory, and the resources in
+	// the inventory which belong to none of the handles.
+	Check(log lager.Logger, handles []string) (missing, orphans []string, err error)
+
+	// RemoveOrphan removes a resource returned as an orphan by Check
+	RemoveOrphan(log lager.Logger, orphan string) error
+}
+
+type Reconciler interface {
+	// Reconcile checks the inventories against the handles in the depot,
+	// returning the handles which should be destroyed
+	Reconcile(log lager.Logger, handles []string) []string
+}
+
+type reconciler struct {
+	policy      ReconcilePolicy
+	inventories map[string]Inventory
+}
+
+func NewReconciler(policy ReconcilePolicy, inventories map[string]Inventory) Reconciler {
+	return &reconciler{
+		policy:      policy,
+		inventories: inventories,
+	}
+}
+
+// inventoryReport is logged for each inventory once it has been reconciled
+type inventoryReport struct {
+	Missing        []string `json:"missing"`
+	Orphans        []string `json:"orphans"`
+	RemovedOrphans int      `json:"removed_orphans"`
+	Error          string   `json:"error,omitempty"`
+}
+
+func (r *reconciler) Reconcile(logger lager.Logger, handles []string) []string {
+	log := logger.Session("reconcile", lager.Data{"policy": r.policy})
+
+	log.Info("start")
+
+	var names []string
+	for name := range r.inventories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := make(map[string]inventoryReport)
+	toDestroy := make(map[string]bool)
+
+	for _, name := range names {
+		inventoryLog := log.Session(name)
+
+		missing, orphans, err := r.inventories[name].Check(inventoryLog, handles)
+		if err != nil {
+			inventoryLog.Error("check-failed", err)
+			report[name] = inventoryReport{Error: err.Error()}
+			continue
+		}
+
+		result := inventoryReport{Missing: missing, Orphans: orphans}
+
+		if r.policy != ReconcileReport {
+			for _, orphan := range orphans {
+				if err := r.inventories[name].RemoveOrphan(inventoryLog, orphan); err != nil {
+					inventoryLog.Error("remove-orphan-failed", err, lager.Data{"orphan": orphan})
+					continue
+				}
+
+				result.RemovedOrphans++
+			}
+		}
+
+		if r.policy == ReconcileDestroy {
+			for _, handle := range missing {
+				toDestroy[handle] = true
+			}
+		}
+
+		report[name] = result
+	}
+
+	destroy := []string{}
+	for _, handle := range handles {
+		if toDestroy[handle] {
+			destroy = append(destroy, handle)
+		}
+	}
+
+	log.Info("finished", lager.Data{"report": report, "destroying": destroy})
+
+	return destroy
+}
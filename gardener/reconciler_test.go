@@ -0,0 +1,137 @@
+package gardener_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	fakes "code.cloudfoundry.org/guardian/gardener/gardenerfakes"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Reconciler", func() {
+	var (
+		runtime    *fakes.FakeInventory
+		props      *fakes.FakeInventory
+		policy     gardener.ReconcilePolicy
+		logger     *lagertest.TestLogger
+		reconciler gardener.Reconciler
+
+		toDestroy []string
+	)
+
+	BeforeEach(func() {
+		runtime = new(fakes.FakeInventory)
+		props = new(fakes.FakeInventory)
+		logger = lagertest.NewTestLogger("test")
+
+		runtime.CheckReturns([]string{"not-running"}, []string{"runtime-orphan"}, nil)
+		props.CheckReturns(nil, []string{"props-orphan"}, nil)
+	})
+
+	JustBeforeEach(func() {
+		reconciler = gardener.NewReconciler(policy, map[string]gardener.Inventory{
+			"runtime":    runtime,
+			"properties": props,
+		})
+
+		toDestroy = reconciler.Reconcile(logger, []string{"not-running", "fine"})
+	})
+
+	It("checks every inventory against the handles", func() {
+		Expect(runtime.CheckCallCount()).To(Equal(1))
+		_, handles := runtime.CheckArgsForCall(0)
+		Expect(handles).To(Equal([]string{"not-running", "fine"}))
+
+		Expect(props.CheckCallCount()).To(Equal(1))
+	})
+
+	Context("when the policy is to report", func() {
+		BeforeEach(func() {
+			policy = gardener.ReconcileReport
+		})
+
+		It("does not remove anything", func() {
+			Expect(runtime.RemoveOrphanCallCount()).To(Equal(0))
+			Expect(props.RemoveOrphanCallCount()).To(Equal(0))
+			Expect(toDestroy).To(BeEmpty())
+		})
+
+		It("logs the mismatches", func() {
+			Expect(logger).To(gbytes.Say("runtime-orphan"))
+		})
+	})
+
+	Context("when the policy is to adopt", func() {
+		BeforeEach(func() {
+			policy = gardener.ReconcileAdopt
+		})
+
+		It("removes the orphans", func() {
+			Expect(runtime.RemoveOrphanCallCount()).To(Equal(1))
+			_, orphan := runtime.RemoveOrphanArgsForCall(0)
+			Expect(orphan).To(Equal("runtime-orphan"))
+
+			Expect(props.RemoveOrphanCallCount()).To(Equal(1))
+			_, orphan = props.RemoveOrphanArgsForCall(0)
+			Expect(orphan).To(Equal("props-orphan"))
+		})
+
+		It("keeps the containers with missing state", func() {
+			Expect(toDestroy).To(BeEmpty())
+		})
+	})
+
+	Context("when the policy is to destroy", func() {
+		BeforeEach(func() {
+			policy = gardener.ReconcileDestroy
+		})
+
+		It("removes the orphans", func() {
+			Expect(runtime.RemoveOrphanCallCount()).To(Equal(1))
+			Expect(props.RemoveOrphanCallCount()).To(Equal(1))
+		})
+
+		It("returns the containers with missing state to be destroyed", func() {
+			Expect(toDestroy).To(Equal([]string{"not-running"}))
+		})
+
+		Context("when removing an orphan fails", func() {
+			BeforeEach(func() {
+				runtime.RemoveOrphanReturns(errors.New("boom"))
+			})
+
+			It("carries on with the other inventories", func() {
+				Expect(props.RemoveOrphanCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when checking an inventory fails", func() {
+			BeforeEach(func() {
+				runtime.CheckReturns([]string{"not-running"}, []string{"runtime-orphan"}, errors.New("boom"))
+			})
+
+			It("skips that inventory", func() {
+				Expect(runtime.RemoveOrphanCallCount()).To(Equal(0))
+				Expect(toDestroy).To(BeEmpty())
+				Expect(props.RemoveOrphanCallCount()).To(Equal(1))
+			})
+		})
+	})
+})
+
+var _ = Describe("ParseReconcilePolicy", func() {
+	It("parses the known policies", func() {
+		Expect(gardener.ParseReconcilePolicy("report")).To(Equal(gardener.ReconcileReport))
+		Expect(gardener.ParseReconcilePolicy("adopt")).To(Equal(gardener.ReconcileAdopt))
+		Expect(gardener.ParseReconcilePolicy("destroy")).To(Equal(gardener.ReconcileDestroy))
+	})
+
+	It("rejects unknown policies", func() {
+		_, err := gardener.ParseReconcilePolicy("banana")
+		Expect(err).To(MatchError("unknown reconcile policy: banana"))
+	})
+})
@@ -0,0 +1,112 @@
+package gardener
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+var ErrShuttingDown = errors.New("guardian is shutting down")
+
+// Stopper stops a background task (e.g. watching for events) on shutdown
+type Stopper interface {
+	Stop()
+}
+
+type StopperFunc func()
+
+func (fn StopperFunc) Stop() {
+	fn()
+}
+
+// Persister saves state (e.g. properties) which must survive a restart
+type Persister interface {
+	Persist() error
+}
+
+type PersisterFunc func() error
+
+func (fn PersisterFunc) Persist() error {
+	return fn()
+}
+
+// shutdown tracks creates, destroys and changes to containers in flight so
+// that Stop can wait for them. Once Stop has been called no new ones are
+// started.
+type shutdown struct {
+	once sync.Once
+
+	mu       sync.Mutex
+	stopping bool
+	inFlight sync.WaitGroup
+}
+
+// begin registers an operation, returning a func to call when it finishes
+func (s *shutdown) begin() (func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopping {
+		return nil, ErrShuttingDown
+	}
+
+	s.inFlight.Add(1)
+	return s.inFlight.Done, nil
+}
+
+// drain stops new operations from starting and waits for those in flight to
+// finish, returning false if they did not finish within the timeout
+func (s *shutdown) drain(timeout time.Duration) bool {
+	s.mu.Lock()
+	s.stopping = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+
+	if timeout <= 0 {
+		<-done
+		return true
+	}
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// Stop rejects new creates, destroys and changes to containers (e.g. NetIn,
+// NetOut, SetProperty), waits up to StopTimeout for those in flight to finish,
+// then runs the Stoppers followed by the Persisters. Only the first call has
+// any effect.
+func (g *Gardener) Stop() {
+	g.shutdown.once.Do(g.stop)
+}
+
+func (g *Gardener) stop() {
+	log := g.Logger.Session("stop")
+
+	log.Info("start")
+	defer log.Info("finished")
+
+	if !g.shutdown.drain(g.StopTimeout) {
+		log.Info("timed-out-waiting-for-operations", lager.Data{"timeout": g.StopTimeout.String()})
+	}
+
+	for _, stopper := range g.Stoppers {
+		stopper.Stop()
+	}
+
+	for _, persister := range g.Persisters {
+		if err := persister.Persist(); err != nil {
+			log.Error("persist-failed", err)
+		}
+	}
+}
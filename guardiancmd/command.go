@@ -1,6 +1,7 @@
 package guardiancmd
 
 import (
+	"expvar"
 	"fmt"
 	"io"
 	"net"
@@ -22,10 +23,13 @@ import (
 	"code.cloudfoundry.org/garden-shed/repository_fetcher"
 	"code.cloudfoundry.org/garden-shed/rootfs_provider"
 	"code.cloudfoundry.org/garden/server"
+	"code.cloudfoundry.org/guardian/api"
 	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/journal"
 	"code.cloudfoundry.org/guardian/kawasaki"
 	"code.cloudfoundry.org/guardian/kawasaki/factory"
 	"code.cloudfoundry.org/guardian/kawasaki/iptables"
+	"code.cloudfoundry.org/guardian/kawasaki/nftables"
 	"code.cloudfoundry.org/guardian/kawasaki/ports"
 	"code.cloudfoundry.org/guardian/kawasaki/subnets"
 	"code.cloudfoundry.org/guardian/logging"
@@ -52,6 +56,7 @@ import (
 	"github.com/pivotal-golang/clock"
 	"github.com/pivotal-golang/localip"
 	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/http_server"
 	"github.com/tedsuo/ifrit/sigmon"
 )
 
@@ -122,6 +127,9 @@ var PrivilegedMaxCaps = []string{
 // plus CAP_SYS_ADMIN.
 var NonRootMaxCaps = append(UnprivilegedMaxCaps, "CAP_SYS_ADMIN")
 
+// The runtime given by --runc-bin, used unless a container requests another
+const defaultRuntimeName = "runc"
+
 var PrivilegedContainerNamespaces = []specs.Namespace{
 	goci.NetworkNamespace, goci.PIDNamespace, goci.UTSNamespace, goci.IPCNamespace, goci.MountNamespace,
 }
@@ -135,20 +143,35 @@ type GuardianCommand struct {
 
 		BindSocket string `long:"bind-socket" default:"/tmp/garden.sock" description:"Bind with Unix on the given socket path."`
 
+		APIBindSocket string `long:"api-bind-socket" default:"/tmp/guardian-api.sock" description:"Bind the guardian extension API, which serves committed capacity, including the usage of each network pool, and the port mapping and net out operations the garden API has no routes for, with Unix on the given socket path."`
+
 		DebugBindIP   IPFlag `long:"debug-bind-ip"                   description:"Bind the debug server on the given IP."`
 		DebugBindPort uint16 `long:"debug-bind-port" default:"17013" description:"Bind the debug server to the given port."`
 
+		StopTimeout time.Duration `long:"stop-timeout" default:"1m" description:"Time to wait on shutdown for in-flight creates and destroys to finish. Zero waits indefinitely."`
+
 		Tag string `long:"tag" description:"Optional 2-character identifier used for namespacing global configuration."`
 	} `group:"Server Configuration"`
 
 	Containers struct {
 		Dir            DirFlag `long:"depot" required:"true" description:"Directory in which to store container data."`
 		PropertiesPath string  `long:"properties-path" description:"Path in which to store properties."`
+		JournalDir     string  `long:"journal-dir"     description:"Directory in which to record the progress of container creates, so that interrupted creates are rolled back on startup. Defaults to a '-journal' directory alongside the depot."`
 
 		DefaultRootFSDir           DirFlag       `long:"default-rootfs"     description:"Default rootfs to use when not specified on container creation."`
 		DefaultGraceTime           time.Duration `long:"default-grace-time" description:"Default time after which idle containers should expire."`
 		DestroyContainersOnStartup bool          `long:"destroy-containers-on-startup" description:"Clean up all the existing containers on startup."`
+		ReconcilePolicy            string        `long:"reconcile-policy" default:"report" choice:"report" choice:"adopt" choice:"destroy" description:"What to do on startup with containers whose state is incomplete and with resources which belong to no container. 'report' only logs them, 'adopt' keeps incomplete containers but removes orphaned resources, 'destroy' removes both."`
+		StateCacheTTL              time.Duration `long:"state-cache-ttl" default:"10s" description:"Time for which container state is cached before asking the runtime again."`
 		ApparmorProfile            string        `long:"apparmor" description:"Apparmor profile to use for unprivileged container processes"`
+		AllowedApparmorProfiles    []string      `long:"apparmor-allowed-profile" description:"Apparmor profile which containers may select using the 'garden.apparmor-profile' property. Can be specified multiple times."`
+		AllowedCapabilities        []string      `long:"allowed-capability" description:"Capability which containers may add using the 'garden.capabilities.add' property. Can be specified multiple times."`
+
+		SeccompProfile       SeccompProfileFlag        `long:"seccomp-profile"       description:"Path to a Docker or OCI format seccomp profile to use for unprivileged containers instead of the built-in default."`
+		NamedSeccompProfiles []NamedSeccompProfileFlag `long:"seccomp-named-profile" description:"Seccomp profile which containers may select using the 'garden.seccomp-profile' property, in the form name:path. Can be specified multiple times."`
+
+		MaskedPaths   []string `long:"masked-path"    description:"Path to mask in unprivileged containers, in addition to the defaults. Can be specified multiple times."`
+		ReadonlyPaths []string `long:"read-only-path" description:"Path to make read-only in unprivileged containers, in addition to the defaults. Can be specified multiple times."`
 	} `group:"Container Lifecycle"`
 
 	Bin struct {
@@ -158,6 +181,14 @@ type GuardianCommand struct {
 		IPTables FileFlag `long:"iptables-bin" default:"/sbin/iptables" description:"path to the the iptables binary"`
 		Init     FileFlag `long:"init-bin"     required:"true" description:"Path execute as pid 1 inside each container."`
 		Runc     string   `long:"runc-bin"     default:"runc" description:"Path to the 'runc' binary."`
+
+		IPTablesRestore FileFlag `long:"iptables-restore-bin" default:"/sbin/iptables-restore" description:"Path to the 'iptables-restore' binary, used to apply NetOut rules in bulk."`
+		NFT             string   `long:"nft-bin"              default:"nft"                    description:"Path to the 'nft' binary, used when --firewall-backend is nftables."`
+
+		IP6Tables        string `long:"ip6tables-bin"         default:"/sbin/ip6tables"         description:"Path to the 'ip6tables' binary, used when --network-pool-ipv6 is set."`
+		IP6TablesRestore string `long:"ip6tables-restore-bin" default:"/sbin/ip6tables-restore" description:"Path to the 'ip6tables-restore' binary, used when --network-pool-ipv6 is set."`
+
+		Runtimes []RuntimeFlag `long:"runtime" description:"Additional OCI runtime which containers may select using the 'garden.runtime' property, in the form name:flavour:path where flavour is one of runc, crun or runsc. Can be specified multiple times."`
 	} `group:"Binary Tools"`
 
 	Graph struct {
@@ -172,13 +203,19 @@ type GuardianCommand struct {
 	} `group:"Docker Image Fetching"`
 
 	Network struct {
-		Pool CIDRFlag `long:"network-pool" default:"10.254.0.0/22" description:"Network range to use for dynamically allocated container subnets."`
+		Pool     CIDRFlag `long:"network-pool" default:"10.254.0.0/22" description:"Network range to use for dynamically allocated container subnets."`
+		IPv6Pool CIDRFlag `long:"network-pool-ipv6"                     description:"IPv6 network range from which each container is additionally given a /64 subnet. Containers are IPv4-only if not specified."`
+
+		SubnetPrefix int `long:"network-pool-subnet-prefix" default:"30" description:"Prefix length of the subnets dynamically allocated from the network pools. Containers share a subnet, and its bridge, until it has no IPs left."`
+
+		NamedPools []NetworkPoolFlag `long:"network-pool-named" description:"Additional network pool which containers select with a '<name>:' prefix on their network spec or the 'garden.network.pool' property, in the form name:cidr[:option,...]. The options are mtu=<mtu>, and allow=<cidr> and deny=<cidr> for networks allowed and denied to the pool's containers in addition to the global ones. Can be specified multiple times."`
 
 		AllowHostAccess bool       `long:"allow-host-access" description:"Allow network access to the host machine."`
 		DenyNetworks    []CIDRFlag `long:"deny-network"      description:"Network ranges to which traffic from containers will be denied. Can be specified multiple times."`
-		AllowNetworks   []CIDRFlag `long:"allow-network"     description:"Network ranges to which traffic from containers will be allowed. Can be specified multiple times."`
+		AllowNetworks   []CIDRFlag `long:"allow-network"     description:"Network ranges to which traffic from containers will be allowed, even if they fall within a denied network. Can be specified multiple times."`
 
-		DNSServers []IPFlag `long:"dns-server" description:"DNS server IP address to use instead of automatically determined servers. Can be specified multiple times."`
+		DNSServers     []IPFlag `long:"dns-server"      description:"DNS server IP address to use instead of automatically determined servers. Can be specified multiple times."`
+		IPv6DNSServers []IPFlag `long:"dns-server-ipv6" description:"IPv6 DNS server address to add to the resolv.conf of containers given an IPv6 subnet. Can be specified multiple times."`
 
 		ExternalIP             IPFlag `long:"external-ip"                     description:"IP address to use to reach container's mapped ports. Autodetected if not specified."`
 		PortPoolStart          uint32 `long:"port-pool-start" default:"60000" description:"Start of the ephemeral port range used for mapped container ports."`
@@ -187,12 +224,22 @@ type GuardianCommand struct {
 
 		Mtu int `long:"mtu" default:"1500" description:"MTU size for container network interfaces."`
 
+		FirewallBackend string `long:"firewall-backend" default:"iptables" choice:"iptables" choice:"nftables" description:"Firewall used for container networking."`
+
 		Plugin          FileFlag `long:"network-plugin"           description:"Path to network plugin binary."`
 		PluginExtraArgs []string `long:"network-plugin-extra-arg" description:"Extra argument to pass to the network plugin. Can be specified multiple times."`
 	} `group:"Container Networking"`
 
 	Limits struct {
 		MaxContainers uint64 `long:"max-containers" default:"0" description:"Maximum number of containers that can be created."`
+
+		BulkConcurrency int           `long:"bulk-concurrency" default:"16"  description:"Maximum number of containers queried at once by bulk info and metrics requests."`
+		BulkTimeout     time.Duration `long:"bulk-timeout"     default:"10s" description:"Time after which containers without a result are reported as failed in bulk info and metrics requests."`
+
+		MaxConcurrentCreates  int           `long:"max-concurrent-creates"  default:"0"   description:"Maximum number of containers created at once, or 0 for no limit."`
+		CreateQueueSize       int           `long:"create-queue-size"       default:"100" description:"Maximum number of creates waiting when --max-concurrent-creates are in progress. Further creates are rejected."`
+		CreateQueueTimeout    time.Duration `long:"create-queue-timeout"    default:"1m"  description:"Time after which a create waiting in the queue fails."`
+		MemoryOvercommitRatio float64       `long:"memory-overcommit-ratio" default:"0"   description:"Multiple of the total memory which the memory limits of all containers may add up to, or 0 to disable the check."`
 	} `group:"Limits"`
 
 	Metrics struct {
@@ -251,27 +298,98 @@ func (cmd *GuardianCommand) Run(signals <-chan os.Signal, ready chan<- struct{})
 		return fmt.Errorf("invalid pool range: %s", err)
 	}
 
-	networker, iptablesStarter, err := cmd.wireNetworker(logger, propManager, portPool)
+	networker, networkStarters, networkInventory, err := cmd.wireNetworker(logger, propManager, portPool)
 	if err != nil {
 		logger.Error("failed-to-wire-networker", err)
 		return err
 	}
 
+	runtimes, err := cmd.probeRuntimes(logger)
+	if err != nil {
+		logger.Error("failed-to-probe-runtimes", err)
+		return err
+	}
+
 	restorer := gardener.NewRestorer(networker)
 	if cmd.Containers.DestroyContainersOnStartup {
 		restorer = &gardener.NoopRestorer{}
 	}
 
+	for _, cap := range cmd.Containers.AllowedCapabilities {
+		if !containsString(PrivilegedMaxCaps, cap) {
+			err := fmt.Errorf("unknown capability: %s", cap)
+			logger.Error("invalid-allowed-capabilities", err)
+			return err
+		}
+	}
+
+	seccompProfiles, err := cmd.namedSeccompProfiles()
+	if err != nil {
+		logger.Error("invalid-seccomp-profiles", err)
+		return err
+	}
+
+	createJournal, err := cmd.wireJournal(cmd.Containers.JournalDir, cmd.Containers.Dir.Path())
+	if err != nil {
+		logger.Error("failed-to-create-journal", err)
+		return err
+	}
+
+	containerizer, runtimeInventory := cmd.wireContainerizer(logger, cmd.Containers.Dir.Path(), cmd.Bin.Dadoo.Path(), runtimes, cmd.Bin.NSTar.Path(), cmd.Bin.Tar.Path(), cmd.Containers.DefaultRootFSDir.Path(), cmd.Containers.ApparmorProfile, seccompProfiles, propManager)
+
+	cmd.initializeDropsonde(logger)
+
+	metricsProvider := cmd.wireMetricsProvider(logger, cmd.Containers.Dir.Path(), cmd.Graph.Dir.Path())
+
+	metronNotifier := cmd.wireMetronNotifier(logger, metricsProvider)
+
+	reconcilePolicy, err := gardener.ParseReconcilePolicy(cmd.Containers.ReconcilePolicy)
+	if err != nil {
+		logger.Error("failed-to-parse-reconcile-policy", err)
+		return err
+	}
+
+	reconciler := gardener.NewReconciler(reconcilePolicy, map[string]gardener.Inventory{
+		"runtime":    runtimeInventory,
+		"properties": &properties.Inventory{Manager: propManager},
+		"network":    networkInventory,
+	})
+
 	backend := &gardener.Gardener{
 		UidGenerator:    cmd.wireUidGenerator(),
-		Starters:        []gardener.Starter{cmd.wireRunDMCStarter(logger), iptablesStarter},
+		Starters:        append([]gardener.Starter{cmd.wireRunDMCStarter(logger)}, networkStarters...),
 		SysInfoProvider: sysinfo.NewProvider(cmd.Containers.Dir.Path()),
 		Networker:       networker,
 		VolumeCreator:   cmd.wireVolumeCreator(logger, cmd.Graph.Dir.Path(), cmd.Docker.InsecureRegistries, cmd.Graph.PersistentImages),
-		Containerizer:   cmd.wireContainerizer(logger, cmd.Containers.Dir.Path(), cmd.Bin.Dadoo.Path(), cmd.Bin.Runc, cmd.Bin.NSTar.Path(), cmd.Bin.Tar.Path(), cmd.Containers.DefaultRootFSDir.Path(), cmd.Containers.ApparmorProfile, propManager),
+		Containerizer:   containerizer,
 		PropertyManager: propManager,
 		MaxContainers:   cmd.Limits.MaxContainers,
 		Restorer:        restorer,
+		Reconciler:      reconciler,
+		Journal:         createJournal,
+		BulkConcurrency: cmd.Limits.BulkConcurrency,
+		BulkTimeout:     cmd.Limits.BulkTimeout,
+
+		MaxConcurrentCreates:  cmd.Limits.MaxConcurrentCreates,
+		CreateQueueSize:       cmd.Limits.CreateQueueSize,
+		CreateQueueTimeout:    cmd.Limits.CreateQueueTimeout,
+		MemoryOvercommitRatio: cmd.Limits.MemoryOvercommitRatio,
+
+		StopTimeout: cmd.Server.StopTimeout,
+		Stoppers: []gardener.Stopper{
+			gardener.StopperFunc(func() { containerizer.StopWatchingEvents(logger) }),
+			metronNotifier,
+		},
+		Persisters: []gardener.Persister{
+			// the subnet pools are rebuilt on startup from the network config
+			// which the networker stores in the properties
+			gardener.PersisterFunc(func() error {
+				return cmd.saveProperties(logger, cmd.Containers.PropertiesPath, propManager)
+			}),
+			gardener.PersisterFunc(func() error {
+				return cmd.savePortPoolState(logger, cmd.Network.PortPoolPropertiesPath, portPool)
+			}),
+		},
 
 		Logger: logger,
 	}
@@ -287,14 +405,24 @@ func (cmd *GuardianCommand) Run(signals <-chan os.Signal, ready chan<- struct{})
 
 	gardenServer := server.New(listenNetwork, listenAddr, cmd.Containers.DefaultGraceTime, backend, logger.Session("api"))
 
-	cmd.initializeDropsonde(logger)
-
-	metricsProvider := cmd.wireMetricsProvider(logger, cmd.Containers.Dir.Path(), cmd.Graph.Dir.Path())
-
-	metronNotifier := cmd.wireMetronNotifier(logger, metricsProvider)
 	metronNotifier.Start()
 
+	if err := cmd.startAPIServer(logger.Session("extension-api"), backend); err != nil {
+		logger.Error("failed-to-start-api-server", err)
+		return err
+	}
+
 	if cmd.Server.DebugBindIP != nil {
+		expvar.Publish("capacity", expvar.Func(func() interface{} {
+			capacity, err := backend.CommittedCapacity()
+			if err != nil {
+				logger.Error("failed-to-get-capacity", err)
+				return nil
+			}
+
+			return capacity
+		}))
+
 		addr := fmt.Sprintf("%s:%d", cmd.Server.DebugBindIP.IP(), cmd.Server.DebugBindPort)
 		metrics.StartDebugServer(addr, reconfigurableSink, metricsProvider)
 	}
@@ -315,13 +443,28 @@ func (cmd *GuardianCommand) Run(signals <-chan os.Signal, ready chan<- struct{})
 	<-signals
 
 	gardenServer.Stop()
+	backend.Stop()
 
-	cmd.saveProperties(logger, cmd.Containers.PropertiesPath, propManager)
+	return nil
+}
 
-	portPoolState = portPool.RefreshState()
-	ports.SaveState(cmd.Network.PortPoolPropertiesPath, portPoolState)
+func (cmd *GuardianCommand) startAPIServer(logger lager.Logger, backend api.Backend) error {
+	handler, err := api.New(backend, logger)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	if err := os.Remove(cmd.Server.APIBindSocket); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	p := ifrit.Invoke(http_server.NewUnixServer(cmd.Server.APIBindSocket, handler))
+	select {
+	case <-p.Ready():
+		return nil
+	case err := <-p.Wait():
+		return err
+	}
 }
 
 func (cmd *GuardianCommand) loadProperties(logger lager.Logger, propertiesPath string) (*properties.Manager, error) {
@@ -334,13 +477,37 @@ func (cmd *GuardianCommand) loadProperties(logger lager.Logger, propertiesPath s
 	return propManager, nil
 }
 
-func (cmd *GuardianCommand) saveProperties(logger lager.Logger, propertiesPath string, propManager *properties.Manager) {
+func (cmd *GuardianCommand) wireJournal(dir, depotPath string) (gardener.Journal, error) {
+	if dir == "" {
+		// not inside the depot, where it would be listed as a container
+		dir = filepath.Clean(depotPath) + "-journal"
+	}
+
+	return journal.New(dir)
+}
+
+func (cmd *GuardianCommand) saveProperties(logger lager.Logger, propertiesPath string, propManager *properties.Manager) error {
 	if propertiesPath != "" {
 		err := properties.Save(propertiesPath, propManager)
 		if err != nil {
 			logger.Error("failed-to-save-properties", err, lager.Data{"propertiesPath": propertiesPath})
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cmd *GuardianCommand) savePortPoolState(logger lager.Logger, portPoolPropertiesPath string, portPool *ports.PortPool) error {
+	if portPoolPropertiesPath != "" {
+		err := ports.SaveState(portPoolPropertiesPath, portPool.RefreshState())
+		if err != nil {
+			logger.Error("failed-to-save-port-pool-properties", err, lager.Data{"portPoolPropertiesPath": portPoolPropertiesPath})
+			return err
 		}
 	}
+
+	return nil
 }
 
 func (cmd *GuardianCommand) wireUidGenerator() gardener.UidGeneratorFunc {
@@ -358,10 +525,15 @@ func (cmd *GuardianCommand) wireRunDMCStarter(logger lager.Logger) gardener.Star
 	return rundmc.NewStarter(logger, mustOpen("/proc/cgroups"), mustOpen("/proc/self/cgroup"), cgroupsMountpoint, linux_command_runner.New())
 }
 
-func (cmd *GuardianCommand) wireNetworker(log lager.Logger, propManager kawasaki.ConfigStore, portPool *ports.PortPool) (gardener.Networker, gardener.Starter, error) {
+func (cmd *GuardianCommand) wireNetworker(log lager.Logger, propManager kawasaki.ConfigStore, portPool *ports.PortPool) (gardener.Networker, []gardener.Starter, gardener.Inventory, error) {
 	interfacePrefix := fmt.Sprintf("w%s", cmd.Server.Tag)
 	chainPrefix := fmt.Sprintf("w-%s-", cmd.Server.Tag)
 
+	var allowNetworksList []string
+	for _, network := range cmd.Network.AllowNetworks {
+		allowNetworksList = append(allowNetworksList, network.String())
+	}
+
 	var denyNetworksList []string
 	for _, network := range cmd.Network.DenyNetworks {
 		denyNetworksList = append(denyNetworksList, network.String())
@@ -369,7 +541,7 @@ func (cmd *GuardianCommand) wireNetworker(log lager.Logger, propManager kawasaki
 
 	externalIP, err := defaultExternalIP(cmd.Network.ExternalIP)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	dnsServers := make([]net.IP, len(cmd.Network.DNSServers))
@@ -377,22 +549,56 @@ func (cmd *GuardianCommand) wireNetworker(log lager.Logger, propManager kawasaki
 		dnsServers[i] = ip.IP()
 	}
 
-	iptRunner := &logging.Runner{CommandRunner: linux_command_runner.New(), Logger: log.Session("iptables-runner")}
-	ipTables := iptables.New(cmd.Bin.IPTables.Path(), iptRunner, chainPrefix)
-	ipTablesStarter := iptables.NewStarter(ipTables, cmd.Network.AllowHostAccess, interfacePrefix, denyNetworksList)
+	ipv6DNSServers := make([]net.IP, len(cmd.Network.IPv6DNSServers))
+	for i, ip := range cmd.Network.IPv6DNSServers {
+		ipv6DNSServers[i] = ip.IP()
+	}
+
+	if cmd.Network.SubnetPrefix < 1 || cmd.Network.SubnetPrefix > subnets.DefaultSubnetPrefix {
+		return nil, nil, nil, fmt.Errorf("invalid --network-pool-subnet-prefix %d, must be between 1 and %d", cmd.Network.SubnetPrefix, subnets.DefaultSubnetPrefix)
+	}
+
+	namedPools, poolNetworks, err := cmd.namedNetworkPools()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ipTables, chainCreator, ipTablesStarter := cmd.wireFirewall(log, chainPrefix, interfacePrefix, allowNetworksList, denyNetworksList, poolNetworks)
+	starters := []gardener.Starter{ipTablesStarter}
+
+	var ipv6SubnetPool subnets.Pool
+	var ipv6ChainCreator kawasaki.InstanceChainCreator
+	var ipv6FirewallOpener kawasaki.FirewallOpener
+	var ip6Tables iptables.IPTables
+	if cmd.Network.IPv6Pool.CIDR() != nil {
+		if cmd.Network.FirewallBackend == "nftables" {
+			return nil, nil, nil, fmt.Errorf("--network-pool-ipv6 is only supported by the iptables firewall backend")
+		}
+
+		ip6tRunner := &logging.Runner{CommandRunner: linux_command_runner.New(), Logger: log.Session("ip6tables-runner")}
+		ip6tController := iptables.NewIPv6(cmd.Bin.IP6Tables, cmd.Bin.IP6TablesRestore, ip6tRunner, chainPrefix)
+		ip6Tables = ip6tController
+		ipv6ChainCreator = iptables.NewInstanceChainCreator(ip6tController)
+		ipv6FirewallOpener = iptables.NewIPv6FirewallOpener(ip6tController)
+		ipv6SubnetPool = subnets.NewPool(cmd.Network.IPv6Pool.CIDR())
+		starters = append(starters, iptables.NewStarter(log.Session("ip6tables-starter"), ip6tController, cmd.Network.AllowHostAccess, interfacePrefix, allowNetworksList, denyNetworksList, nil, "/proc"))
+	}
 
 	idGenerator := kawasaki.NewSequentialIDGenerator(time.Now().UnixNano())
 
 	kawasakiNetworker := kawasaki.New(
 		cmd.Bin.IPTables.Path(),
-		kawasaki.SpecParserFunc(kawasaki.ParseSpec),
-		subnets.NewPool(cmd.Network.Pool.CIDR()),
-		kawasaki.NewConfigCreator(idGenerator, interfacePrefix, chainPrefix, externalIP, dnsServers, cmd.Network.Mtu),
+		kawasaki.ParseSpecWithSubnetPrefix(cmd.Network.SubnetPrefix),
+		subnets.NewPoolWithSubnetPrefix(cmd.Network.Pool.CIDR(), cmd.Network.SubnetPrefix),
+		ipv6SubnetPool,
+		namedPools,
+		kawasaki.NewConfigCreator(idGenerator, interfacePrefix, chainPrefix, externalIP, dnsServers, ipv6DNSServers, cmd.Network.Mtu),
 		propManager,
-		factory.NewDefaultConfigurer(ipTables),
+		factory.NewDefaultConfigurer(chainCreator, ipv6ChainCreator),
 		portPool,
 		iptables.NewPortForwarder(ipTables),
 		iptables.NewFirewallOpener(ipTables),
+		ipv6FirewallOpener,
 	)
 
 	networkers := []kawasaki.Networker{kawasakiNetworker}
@@ -409,7 +615,57 @@ func (cmd *GuardianCommand) wireNetworker(log lager.Logger, propManager kawasaki
 		Networkers: networkers,
 	}
 
-	return networker, ipTablesStarter, nil
+	return networker, starters, factory.NewDefaultInventory(propManager, ipTables, chainCreator, ip6Tables, ipv6ChainCreator, interfacePrefix), nil
+}
+
+// namedNetworkPools returns the pools given by --network-pool-named, along
+// with the networks allowed and denied to each of them. The pools may not
+// overlap each other or the default pool.
+func (cmd *GuardianCommand) namedNetworkPools() ([]kawasaki.NetworkPool, []iptables.PoolNetworks, error) {
+	var (
+		pools        []kawasaki.NetworkPool
+		poolNetworks []iptables.PoolNetworks
+	)
+
+	cidrs := map[string]*net.IPNet{kawasaki.DefaultNetworkPool: cmd.Network.Pool.CIDR()}
+	for _, flag := range cmd.Network.NamedPools {
+		if _, ok := cidrs[flag.Name()]; ok {
+			return nil, nil, fmt.Errorf("network pool '%s' specified more than once", flag.Name())
+		}
+
+		for name, cidr := range cidrs {
+			if cidr.Contains(flag.CIDR().IP) || flag.CIDR().Contains(cidr.IP) {
+				return nil, nil, fmt.Errorf("network pool '%s' overlaps network pool '%s'", flag.Name(), name)
+			}
+		}
+		cidrs[flag.Name()] = flag.CIDR()
+
+		pools = append(pools, kawasaki.NetworkPool{
+			Name: flag.Name(),
+			Pool: subnets.NewPoolWithSubnetPrefix(flag.CIDR(), cmd.Network.SubnetPrefix),
+			Mtu:  flag.Mtu(),
+		})
+
+		poolNetworks = append(poolNetworks, iptables.PoolNetworks{
+			Subnet:        flag.CIDR().String(),
+			AllowNetworks: flag.AllowNetworks(),
+			DenyNetworks:  flag.DenyNetworks(),
+		})
+	}
+
+	return pools, poolNetworks, nil
+}
+
+func (cmd *GuardianCommand) wireFirewall(log lager.Logger, chainPrefix, interfacePrefix string, allowNetworks, denyNetworks []string, poolNetworks []iptables.PoolNetworks) (iptables.IPTables, kawasaki.InstanceChainCreator, gardener.Starter) {
+	if cmd.Network.FirewallBackend == "nftables" {
+		nftRunner := &logging.Runner{CommandRunner: linux_command_runner.New(), Logger: log.Session("nftables-runner")}
+		nft := nftables.New(cmd.Bin.NFT, nftRunner, chainPrefix)
+		return nft, nftables.NewInstanceChainCreator(nft), nftables.NewStarter(log.Session("nftables-starter"), nft, cmd.Network.AllowHostAccess, interfacePrefix, allowNetworks, denyNetworks, poolNetworks, "/proc")
+	}
+
+	iptRunner := &logging.Runner{CommandRunner: linux_command_runner.New(), Logger: log.Session("iptables-runner")}
+	ipTables := iptables.New(cmd.Bin.IPTables.Path(), cmd.Bin.IPTablesRestore.Path(), iptRunner, chainPrefix)
+	return ipTables, iptables.NewInstanceChainCreator(ipTables), iptables.NewStarter(log.Session("iptables-starter"), ipTables, cmd.Network.AllowHostAccess, interfacePrefix, allowNetworks, denyNetworks, poolNetworks, "/proc")
 }
 
 func (cmd *GuardianCommand) wireVolumeCreator(logger lager.Logger, graphRoot string, insecureRegistries, persistentImages []string) gardener.VolumeCreator {
@@ -533,7 +789,7 @@ func (cmd *GuardianCommand) wireVolumeCreator(logger lager.Logger, graphRoot str
 		ovenCleaner)
 }
 
-func (cmd *GuardianCommand) wireContainerizer(log lager.Logger, depotPath, dadooPath, runcPath, nstarPath, tarPath, defaultRootFSPath, appArmorProfile string, properties gardener.PropertyManager) *rundmc.Containerizer {
+func (cmd *GuardianCommand) wireContainerizer(log lager.Logger, depotPath, dadooPath string, runtimes map[string]goci.RuntimeBinary, nstarPath, tarPath, defaultRootFSPath, appArmorProfile string, seccompProfiles map[string]*goci.SeccompProfile, properties gardener.PropertyManager) (*rundmc.Containerizer, gardener.Inventory) {
 	depot := depot.New(depotPath)
 
 	commandRunner := linux_command_runner.New()
@@ -548,20 +804,44 @@ func (cmd *GuardianCommand) wireContainerizer(log lager.Logger, depotPath, dadoo
 		SleepInterval: time.Millisecond * 100,
 	}
 
-	runcrunner := runrunc.New(
-		commandRunner,
-		runrunc.NewLogRunner(commandRunner, runrunc.LogDir(os.TempDir()).GenerateLogFile),
-		goci.RuncBinary(runcPath),
-		dadooPath,
-		runcPath,
-		runrunc.NewExecPreparer(&goci.BndlLoader{}, runrunc.LookupFunc(runrunc.LookupUser), chrootMkdir, NonRootMaxCaps),
-		dadoo.NewExecRunner(
+	runtimeMux := &rundmc.RuntimeMux{
+		Runtimes: map[string]rundmc.OCIRuntime{},
+		Stoppers: map[string]rundmc.Stopper{},
+		Default:  defaultRuntimeName,
+		Depot:    depot,
+		Loader:   &goci.BndlLoader{},
+	}
+
+	stopRetrier := retrier.New(retrier.ConstantBackoff(10, 1*time.Second), nil)
+
+	var runtimeNames []string
+	for name, runtime := range runtimes {
+		runtimeNames = append(runtimeNames, name)
+
+		// processes in a gVisor sandbox are not host processes, so runsc
+		// containers can not be stopped by signalling their cgroup
+		switch runtime.Flavour {
+		case goci.RuncFlavour:
+			runtimeMux.Stoppers[name] = stopper.New(stopper.NewRuncStateCgroupPathResolver(runtime.StateDir()), nil, stopRetrier)
+		case goci.CrunFlavour:
+			runtimeMux.Stoppers[name] = stopper.New(stopper.NewCrunStatusCgroupPathResolver(runtime.StateDir(), "/sys/fs/cgroup"), nil, stopRetrier)
+		}
+
+		runtimeMux.Runtimes[name] = runrunc.New(
+			commandRunner,
+			runrunc.NewLogRunner(commandRunner, runrunc.LogDir(os.TempDir()).GenerateLogFile),
+			runtime,
 			dadooPath,
-			runcPath,
-			cmd.wireUidGenerator(),
-			pidFileReader,
-			linux_command_runner.New()),
-	)
+			runtime.Path,
+			runrunc.NewExecPreparer(&goci.BndlLoader{}, runrunc.LookupFunc(runrunc.LookupUser), chrootMkdir, NonRootMaxCaps),
+			dadoo.NewExecRunner(
+				dadooPath,
+				runtime,
+				cmd.wireUidGenerator(),
+				pidFileReader,
+				linux_command_runner.New()),
+		)
+	}
 
 	mounts := []specs.Mount{
 		{Type: "sysfs", Source: "sysfs", Destination: "/sys", Options: []string{"nosuid", "noexec", "nodev", "ro"}},
@@ -624,9 +904,11 @@ func (cmd *GuardianCommand) wireContainerizer(log lager.Logger, depotPath, dadoo
 		WithUIDMappings(idMappings...).
 		WithGIDMappings(idMappings...).
 		WithMounts(unprivilegedMounts...).
-		WithMaskedPaths(defaultMaskedPaths())
+		WithMaskedPaths(append(defaultMaskedPaths(), cmd.Containers.MaskedPaths...)).
+		WithReadonlyPaths(append(defaultReadonlyPaths(), cmd.Containers.ReadonlyPaths...))
+
+	unprivilegedBundle = unprivilegedBundle.WithSeccomp(seccomp)
 
-	unprivilegedBundle.Spec.Linux.Seccomp = seccomp
 	if appArmorProfile != "" {
 		unprivilegedBundle.Spec.Process.ApparmorProfile = appArmorProfile
 	}
@@ -646,10 +928,30 @@ func (cmd *GuardianCommand) wireContainerizer(log lager.Logger, depotPath, dadoo
 				ContainerRootGID: idMappings.Map(0),
 				MkdirChown:       chrootMkdir,
 			},
+			bundlerules.ReadOnlyRootFS{
+				DepotDir: depotPath,
+			},
+			bundlerules.RestrictedPaths{},
 			bundlerules.Limits{},
 			bundlerules.BindMounts{},
 			bundlerules.Env{},
 			bundlerules.Hostname{},
+			bundlerules.Apparmor{
+				AllowedProfiles: cmd.Containers.AllowedApparmorProfiles,
+			},
+			bundlerules.NoNewPrivileges{},
+			bundlerules.Capabilities{
+				ValidCaps:      PrivilegedMaxCaps,
+				AllowedAddCaps: cmd.Containers.AllowedCapabilities,
+			},
+			bundlerules.Seccomp{
+				Arch:     goci.SeccompArch(),
+				Default:  cmd.Containers.SeccompProfile.Profile(),
+				Profiles: seccompProfiles,
+			},
+			bundlerules.Runtime{
+				Runtimes: runtimeNames,
+			},
 		},
 	}
 
@@ -662,8 +964,8 @@ func (cmd *GuardianCommand) wireContainerizer(log lager.Logger, depotPath, dadoo
 	stateStore := rundmc.NewStateStore(properties)
 
 	nstar := rundmc.NewNstarRunner(nstarPath, tarPath, linux_command_runner.New())
-	stopper := stopper.New(stopper.NewRuncStateCgroupPathResolver("/run/runc"), nil, retrier.New(retrier.ConstantBackoff(10, 1*time.Second), nil))
-	return rundmc.New(depot, template, runcrunner, &goci.BndlLoader{}, nstar, stopper, eventStore, stateStore)
+	stateCache := rundmc.NewStateCache(runtimeMux, clock.NewClock(), cmd.Containers.StateCacheTTL)
+	return rundmc.New(depot, template, stateCache, &goci.BndlLoader{}, nstar, runtimeMux, eventStore, stateStore), rundmc.NewRuntimeInventory(runtimeMux, depotPath)
 }
 
 func (cmd *GuardianCommand) wireMetricsProvider(log lager.Logger, depotPath, graphRoot string) metrics.Metrics {
@@ -701,12 +1003,86 @@ func defaultExternalIP(ip IPFlag) (net.IP, error) {
 	return net.ParseIP(localIP), nil
 }
 
+// probeRuntimes determines the features supported by the default runc runtime
+// and by each additional runtime given on the command line
+func (cmd *GuardianCommand) probeRuntimes(log lager.Logger) (map[string]goci.RuntimeBinary, error) {
+	runtimes := map[string]goci.RuntimeBinary{
+		defaultRuntimeName: {Path: cmd.Bin.Runc, Flavour: goci.RuncFlavour},
+	}
+
+	for _, runtime := range cmd.Bin.Runtimes {
+		if _, ok := runtimes[runtime.Name()]; ok {
+			return nil, fmt.Errorf("runtime '%s' specified more than once", runtime.Name())
+		}
+
+		runtimes[runtime.Name()] = goci.RuntimeBinary{Path: runtime.Path(), Flavour: runtime.Flavour()}
+	}
+
+	for name, runtime := range runtimes {
+		features, err := goci.ProbeRuntimeFeatures(runtime.Path, runtime.Flavour)
+		if err != nil {
+			return nil, fmt.Errorf("probing runtime '%s': %s", name, err)
+		}
+
+		runtime.Features = features
+		runtimes[name] = runtime
+
+		log.Info("runtime-features", lager.Data{
+			"name":     name,
+			"flavour":  runtime.Flavour,
+			"path":     runtime.Path,
+			"features": features,
+		})
+	}
+
+	return runtimes, nil
+}
+
+func (cmd *GuardianCommand) namedSeccompProfiles() (map[string]*goci.SeccompProfile, error) {
+	profiles := map[string]*goci.SeccompProfile{}
+	for _, namedProfile := range cmd.Containers.NamedSeccompProfiles {
+		if _, ok := profiles[namedProfile.Name()]; ok {
+			return nil, fmt.Errorf("seccomp profile '%s' specified more than once", namedProfile.Name())
+		}
+
+		profiles[namedProfile.Name()] = namedProfile.Profile()
+	}
+
+	return profiles, nil
+}
+
+func containsString(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
+
 func defaultMaskedPaths() []string {
 	return []string{
+		"/proc/acpi",
 		"/proc/kcore",
+		"/proc/keys",
 		"/proc/latency_stats",
+		"/proc/timer_list",
 		"/proc/timer_stats",
 		"/proc/sched_debug",
+		"/proc/scsi",
+		"/sys/firmware",
+	}
+}
+
+func defaultReadonlyPaths() []string {
+	return []string{
+		"/proc/asound",
+		"/proc/bus",
+		"/proc/fs",
+		"/proc/irq",
+		"/proc/sys",
+		"/proc/sysrq-trigger",
 	}
 }
 
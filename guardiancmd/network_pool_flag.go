@@ -0,0 +1,123 @@
+package guardiancmd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+)
+
+// NetworkPoolFlag is a named network pool, in the form
+// name:cidr[:option,...] where the options are mtu=<mtu>, allow=<cidr> and
+// deny=<cidr>. The allow and deny options may be given more than once.
+type NetworkPoolFlag struct {
+	name          string
+	cidr          *net.IPNet
+	mtu           int
+	allowNetworks []string
+	denyNetworks  []string
+}
+
+func (f *NetworkPoolFlag) UnmarshalFlag(value string) error {
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) < 2 || parts[0] == "" {
+		return fmt.Errorf("invalid network pool '%s', expected name:cidr[:option,...]", value)
+	}
+
+	if parts[0] == kawasaki.DefaultNetworkPool {
+		return fmt.Errorf("invalid network pool '%s', the name '%s' is reserved", value, kawasaki.DefaultNetworkPool)
+	}
+
+	_, cidr, err := net.ParseCIDR(parts[1])
+	if err != nil {
+		return err
+	}
+
+	pool := NetworkPoolFlag{name: parts[0], cidr: cidr}
+	if len(parts) == 3 {
+		for _, option := range strings.Split(parts[2], ",") {
+			if err := pool.setOption(option); err != nil {
+				return fmt.Errorf("invalid network pool '%s': %s", value, err)
+			}
+		}
+	}
+
+	*f = pool
+	return nil
+}
+
+func (f *NetworkPoolFlag) setOption(option string) error {
+	kv := strings.SplitN(option, "=", 2)
+	if len(kv) != 2 {
+		return fmt.Errorf("option '%s' is not of the form key=value", option)
+	}
+
+	switch kv[0] {
+	case "mtu":
+		mtu, err := strconv.Atoi(kv[1])
+		if err != nil || mtu <= 0 {
+			return fmt.Errorf("invalid mtu '%s'", kv[1])
+		}
+		f.mtu = mtu
+	case "allow", "deny":
+		_, network, err := net.ParseCIDR(kv[1])
+		if err != nil {
+			return err
+		}
+
+		if kv[0] == "allow" {
+			f.allowNetworks = append(f.allowNetworks, network.String())
+		} else {
+			f.denyNetworks = append(f.denyNetworks, network.String())
+		}
+	default:
+		return fmt.Errorf("unknown option '%s'", kv[0])
+	}
+
+	return nil
+}
+
+func (f NetworkPoolFlag) String() string {
+	if f.cidr == nil {
+		return ""
+	}
+
+	var options []string
+	if f.mtu != 0 {
+		options = append(options, fmt.Sprintf("mtu=%d", f.mtu))
+	}
+	for _, network := range f.allowNetworks {
+		options = append(options, "allow="+network)
+	}
+	for _, network := range f.denyNetworks {
+		options = append(options, "deny="+network)
+	}
+
+	if len(options) == 0 {
+		return fmt.Sprintf("%s:%s", f.name, f.cidr)
+	}
+
+	return fmt.Sprintf("%s:%s:%s", f.name, f.cidr, strings.Join(options, ","))
+}
+
+func (f NetworkPoolFlag) Name() string {
+	return f.name
+}
+
+func (f NetworkPoolFlag) CIDR() *net.IPNet {
+	return f.cidr
+}
+
+func (f NetworkPoolFlag) Mtu() int {
+	return f.mtu
+}
+
+func (f NetworkPoolFlag) AllowNetworks() []string {
+	return f.allowNetworks
+}
+
+func (f NetworkPoolFlag) DenyNetworks() []string {
+	return f.denyNetworks
+}
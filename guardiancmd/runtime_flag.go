@@ -0,0 +1,48 @@
+package guardiancmd
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+type RuntimeFlag struct {
+	name    string
+	flavour goci.RuntimeFlavour
+	path    string
+}
+
+func (f *RuntimeFlag) UnmarshalFlag(value string) error {
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+		return fmt.Errorf("invalid runtime '%s', expected name:flavour:path", value)
+	}
+
+	flavour, err := goci.ParseRuntimeFlavour(parts[1])
+	if err != nil {
+		return err
+	}
+
+	f.name = parts[0]
+	f.flavour = flavour
+	f.path = parts[2]
+
+	return nil
+}
+
+func (f RuntimeFlag) String() string {
+	return fmt.Sprintf("%s:%s:%s", f.name, f.flavour, f.path)
+}
+
+func (f RuntimeFlag) Name() string {
+	return f.name
+}
+
+func (f RuntimeFlag) Flavour() goci.RuntimeFlavour {
+	return f.flavour
+}
+
+func (f RuntimeFlag) Path() string {
+	return f.path
+}
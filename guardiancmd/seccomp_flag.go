@@ -0,0 +1,56 @@
+package guardiancmd
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+type SeccompProfileFlag struct {
+	path    string
+	profile *goci.SeccompProfile
+}
+
+func (f *SeccompProfileFlag) UnmarshalFlag(value string) error {
+	profile, err := goci.LoadSeccompProfile(value)
+	if err != nil {
+		return err
+	}
+
+	f.path = value
+	f.profile = profile
+
+	return nil
+}
+
+func (f SeccompProfileFlag) String() string {
+	return f.path
+}
+
+func (f SeccompProfileFlag) Profile() *goci.SeccompProfile {
+	return f.profile
+}
+
+type NamedSeccompProfileFlag struct {
+	SeccompProfileFlag
+	name string
+}
+
+func (f *NamedSeccompProfileFlag) UnmarshalFlag(value string) error {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("invalid named seccomp profile '%s', expected name:path", value)
+	}
+
+	f.name = parts[0]
+	return f.SeccompProfileFlag.UnmarshalFlag(parts[1])
+}
+
+func (f NamedSeccompProfileFlag) String() string {
+	return f.name + ":" + f.path
+}
+
+func (f NamedSeccompProfileFlag) Name() string {
+	return f.name
+}
@@ -0,0 +1,110 @@
+// The journal package records the steps of an in-progress container create on
+// disk, so that a create interrupted by a crash can be rolled back when
+// guardian next starts.
+package journal
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Journal keeps one file per handle in a directory, listing the create steps
+// which have been started for that container, one per line.
+type Journal struct {
+	dir string
+}
+
+func New(dir string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &Journal{dir: dir}, nil
+}
+
+// Begin starts a new, empty journal for the handle, discarding any existing one.
+func (j *Journal) Begin(handle string) error {
+	f, err := os.OpenFile(j.path(handle), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Record durably appends a step to the handle's journal.
+func (j *Journal) Record(handle, step string) error {
+	f, err := os.OpenFile(j.path(handle), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.WriteString(step + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Steps returns the steps recorded in the handle's journal, in order.
+func (j *Journal) Steps(handle string) ([]string, error) {
+	f, err := os.Open(j.path(handle))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var steps []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if step := scanner.Text(); step != "" {
+			steps = append(steps, step)
+		}
+	}
+
+	return steps, scanner.Err()
+}
+
+// Finish removes the handle's journal. It is not an error if there is none.
+func (j *Journal) Finish(handle string) error {
+	if err := os.Remove(j.path(handle)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Handles returns the handles which have a journal, i.e. whose create was
+// started but neither finished nor rolled back.
+func (j *Journal) Handles() ([]string, error) {
+	infos, err := ioutil.ReadDir(j.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var handles []string
+	for _, info := range infos {
+		if !info.IsDir() {
+			handles = append(handles, info.Name())
+		}
+	}
+
+	return handles, nil
+}
+
+func (j *Journal) path(handle string) string {
+	return filepath.Join(j.dir, handle)
+}
@@ -0,0 +1,92 @@
+package journal_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/guardian/journal"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Journal", func() {
+	var (
+		dir string
+		j   *journal.Journal
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "journal")
+		Expect(err).NotTo(HaveOccurred())
+
+		j, err = journal.New(filepath.Join(dir, "create"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("creates the journal directory", func() {
+		Expect(filepath.Join(dir, "create")).To(BeADirectory())
+	})
+
+	It("records the steps of each handle in order", func() {
+		Expect(j.Begin("banana")).To(Succeed())
+		Expect(j.Record("banana", "volume")).To(Succeed())
+		Expect(j.Record("banana", "container")).To(Succeed())
+
+		Expect(j.Begin("apple")).To(Succeed())
+		Expect(j.Record("apple", "properties")).To(Succeed())
+
+		Expect(j.Steps("banana")).To(Equal([]string{"volume", "container"}))
+		Expect(j.Steps("apple")).To(Equal([]string{"properties"}))
+	})
+
+	It("discards existing steps when a journal is begun again", func() {
+		Expect(j.Begin("banana")).To(Succeed())
+		Expect(j.Record("banana", "volume")).To(Succeed())
+
+		Expect(j.Begin("banana")).To(Succeed())
+		Expect(j.Steps("banana")).To(BeEmpty())
+	})
+
+	It("lists the handles with a journal", func() {
+		Expect(j.Begin("banana")).To(Succeed())
+		Expect(j.Begin("apple")).To(Succeed())
+
+		Expect(j.Handles()).To(ConsistOf("banana", "apple"))
+	})
+
+	It("persists the journal across instances", func() {
+		Expect(j.Begin("banana")).To(Succeed())
+		Expect(j.Record("banana", "volume")).To(Succeed())
+
+		reopened, err := journal.New(filepath.Join(dir, "create"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reopened.Handles()).To(ConsistOf("banana"))
+		Expect(reopened.Steps("banana")).To(Equal([]string{"volume"}))
+	})
+
+	Describe("Finish", func() {
+		It("removes the journal", func() {
+			Expect(j.Begin("banana")).To(Succeed())
+			Expect(j.Finish("banana")).To(Succeed())
+
+			Expect(j.Handles()).To(BeEmpty())
+		})
+
+		It("succeeds when there is no journal", func() {
+			Expect(j.Finish("banana")).To(Succeed())
+		})
+	})
+
+	Context("when recording to a journal which was not begun", func() {
+		It("returns an error", func() {
+			Expect(j.Record("banana", "volume")).NotTo(Succeed())
+		})
+	})
+})
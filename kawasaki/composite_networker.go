@@ -4,6 +4,7 @@ import (
 	"math"
 
 	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
 	"code.cloudfoundry.org/lager"
 )
 
@@ -20,6 +21,25 @@ func (c *CompositeNetworker) Capacity() (m uint64) {
 	return m
 }
 
+func (c *CompositeNetworker) AvailableSubnets() (m uint64) {
+	m = math.MaxUint64
+	for _, networker := range c.Networkers {
+		m = min(networker.AvailableSubnets(), m)
+	}
+
+	return m
+}
+
+// AvailablePorts is that of the first networker, which handles NetIn
+func (c *CompositeNetworker) AvailablePorts() uint64 {
+	return c.Networkers[0].AvailablePorts()
+}
+
+// PoolCapacities are those of the first networker, which allocates subnets
+func (c *CompositeNetworker) PoolCapacities() map[string]gardener.PoolCapacity {
+	return c.Networkers[0].PoolCapacities()
+}
+
 func (c *CompositeNetworker) Destroy(log lager.Logger, handle string) error {
 	for _, networker := range c.Networkers {
 		if err := networker.Destroy(log, handle); err != nil {
@@ -29,14 +49,30 @@ func (c *CompositeNetworker) Destroy(log lager.Logger, handle string) error {
 	return nil
 }
 
-func (c *CompositeNetworker) NetIn(log lager.Logger, handle string, externalPort, containerPort uint32) (uint32, uint32, error) {
-	return c.Networkers[0].NetIn(log, handle, externalPort, containerPort)
+func (c *CompositeNetworker) NetIn(log lager.Logger, handle string, externalPort, containerPort uint32, protocol string) (uint32, uint32, error) {
+	return c.Networkers[0].NetIn(log, handle, externalPort, containerPort, protocol)
+}
+
+func (c *CompositeNetworker) RemoveNetIn(log lager.Logger, handle string, externalPort, containerPort uint32, protocol string) error {
+	return c.Networkers[0].RemoveNetIn(log, handle, externalPort, containerPort, protocol)
 }
 
 func (c *CompositeNetworker) NetOut(log lager.Logger, handle string, rule garden.NetOutRule) error {
 	return c.Networkers[0].NetOut(log, handle, rule)
 }
 
+func (c *CompositeNetworker) BulkNetOut(log lager.Logger, handle string, rules []garden.NetOutRule) error {
+	return c.Networkers[0].BulkNetOut(log, handle, rules)
+}
+
+func (c *CompositeNetworker) NetOutRules(log lager.Logger, handle string) ([]gardener.NetOutRule, error) {
+	return c.Networkers[0].NetOutRules(log, handle)
+}
+
+func (c *CompositeNetworker) RevokeNetOut(log lager.Logger, handle string, id string) error {
+	return c.Networkers[0].RevokeNetOut(log, handle, id)
+}
+
 func (c *CompositeNetworker) Restore(log lager.Logger, handle string) error {
 	for _, networker := range c.Networkers {
 		if err := networker.Restore(log, handle); err != nil {
@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
 	"code.cloudfoundry.org/guardian/kawasaki"
 	fakes "code.cloudfoundry.org/guardian/kawasaki/kawasakifakes"
 	. "github.com/onsi/ginkgo"
@@ -52,11 +53,35 @@ var _ = Describe("CompositeNetworker", func() {
 		})
 	})
 
+	Describe("AvailableSubnets", func() {
+		It("returns the min of all networkers' available subnets", func() {
+			fakeNetworkers[0].AvailableSubnetsReturns(3)
+			fakeNetworkers[1].AvailableSubnetsReturns(2)
+			fakeNetworkers[2].AvailableSubnetsReturns(5)
+			Expect(compositeNetworker.AvailableSubnets()).To(BeNumerically("==", 2))
+		})
+	})
+
+	Describe("AvailablePorts", func() {
+		It("delegates to the first networker", func() {
+			fakeNetworkers[0].AvailablePortsReturns(10)
+			fakeNetworkers[1].AvailablePortsReturns(20)
+			Expect(compositeNetworker.AvailablePorts()).To(BeNumerically("==", 10))
+		})
+	})
+
+	Describe("PoolCapacities", func() {
+		It("delegates to the first networker", func() {
+			fakeNetworkers[0].PoolCapacitiesReturns(map[string]gardener.PoolCapacity{"dmz": {Capacity: 8, Available: 3}})
+			Expect(compositeNetworker.PoolCapacities()).To(Equal(map[string]gardener.PoolCapacity{"dmz": {Capacity: 8, Available: 3}}))
+		})
+	})
+
 	Describe("NetIn", func() {
 		It("delegates to the first networker", func() {
 			fakeNetworkers[0].NetInReturns(1, 2, nil)
 
-			hostPort, containerPort, err := compositeNetworker.NetIn(nil, "some-handle", 1, 2)
+			hostPort, containerPort, err := compositeNetworker.NetIn(nil, "some-handle", 1, 2, "udp")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(hostPort).To(BeEquivalentTo(1))
 			Expect(containerPort).To(BeEquivalentTo(2))
@@ -65,10 +90,27 @@ var _ = Describe("CompositeNetworker", func() {
 			Expect(fakeNetworkers[1].NetInCallCount()).To(Equal(0))
 			Expect(fakeNetworkers[2].NetInCallCount()).To(Equal(0))
 
-			_, handle, p1, p2 := fakeNetworkers[0].NetInArgsForCall(0)
+			_, handle, p1, p2, protocol := fakeNetworkers[0].NetInArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(p1).To(BeEquivalentTo(1))
+			Expect(p2).To(BeEquivalentTo(2))
+			Expect(protocol).To(Equal("udp"))
+		})
+	})
+
+	Describe("RemoveNetIn", func() {
+		It("delegates to the first networker", func() {
+			Expect(compositeNetworker.RemoveNetIn(nil, "some-handle", 1, 2, "udp")).To(Succeed())
+
+			Expect(fakeNetworkers[0].RemoveNetInCallCount()).To(Equal(1))
+			Expect(fakeNetworkers[1].RemoveNetInCallCount()).To(Equal(0))
+			Expect(fakeNetworkers[2].RemoveNetInCallCount()).To(Equal(0))
+
+			_, handle, p1, p2, protocol := fakeNetworkers[0].RemoveNetInArgsForCall(0)
 			Expect(handle).To(Equal("some-handle"))
 			Expect(p1).To(BeEquivalentTo(1))
 			Expect(p2).To(BeEquivalentTo(2))
+			Expect(protocol).To(Equal("udp"))
 		})
 	})
 
@@ -111,6 +153,45 @@ var _ = Describe("CompositeNetworker", func() {
 		})
 	})
 
+	Describe("BulkNetOut", func() {
+		It("delegates to the first networker", func() {
+			rules := []garden.NetOutRule{{Protocol: garden.ProtocolTCP}}
+			Expect(compositeNetworker.BulkNetOut(nil, "some-handle", rules)).To(Succeed())
+
+			Expect(fakeNetworkers[0].BulkNetOutCallCount()).To(Equal(1))
+			Expect(fakeNetworkers[1].BulkNetOutCallCount()).To(Equal(0))
+			Expect(fakeNetworkers[2].BulkNetOutCallCount()).To(Equal(0))
+
+			_, handle, actualRules := fakeNetworkers[0].BulkNetOutArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(actualRules).To(Equal(rules))
+		})
+	})
+
+	Describe("NetOutRules", func() {
+		It("delegates to the first networker", func() {
+			fakeNetworkers[0].NetOutRulesReturns([]gardener.NetOutRule{{ID: "1"}}, nil)
+
+			Expect(compositeNetworker.NetOutRules(nil, "some-handle")).To(Equal([]gardener.NetOutRule{{ID: "1"}}))
+			Expect(fakeNetworkers[1].NetOutRulesCallCount()).To(Equal(0))
+			Expect(fakeNetworkers[2].NetOutRulesCallCount()).To(Equal(0))
+		})
+	})
+
+	Describe("RevokeNetOut", func() {
+		It("delegates to the first networker", func() {
+			Expect(compositeNetworker.RevokeNetOut(nil, "some-handle", "1")).To(Succeed())
+
+			Expect(fakeNetworkers[0].RevokeNetOutCallCount()).To(Equal(1))
+			Expect(fakeNetworkers[1].RevokeNetOutCallCount()).To(Equal(0))
+			Expect(fakeNetworkers[2].RevokeNetOutCallCount()).To(Equal(0))
+
+			_, handle, id := fakeNetworkers[0].RevokeNetOutArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(id).To(Equal("1"))
+		})
+	})
+
 	Describe("Restore", func() {
 		shouldDelegateTo := func(fakeNetworker []*fakes.FakeNetworker) {
 			for _, fakeNetworker := range fakeNetworkers {
@@ -33,6 +33,16 @@ type NetworkConfig struct {
 	Subnet          *net.IPNet
 	Mtu             int
 	DNSServers      []net.IP
+
+	// NetworkPool is the name of the pool the subnet was allocated from, or
+	// empty for the default pool
+	NetworkPool string
+
+	// The IPv6 addresses are only set when the networker has an IPv6 pool
+	BridgeIPv6     net.IP
+	ContainerIPv6  net.IP
+	SubnetIPv6     *net.IPNet
+	IPv6DNSServers []net.IP
 }
 
 type Creator struct {
@@ -41,10 +51,11 @@ type Creator struct {
 	chainPrefix     string
 	externalIP      net.IP
 	dnsServers      []net.IP
+	ipv6DNSServers  []net.IP
 	mtu             int
 }
 
-func NewConfigCreator(idGenerator IDGenerator, interfacePrefix, chainPrefix string, externalIP net.IP, dnsServers []net.IP, mtu int) *Creator {
+func NewConfigCreator(idGenerator IDGenerator, interfacePrefix, chainPrefix string, externalIP net.IP, dnsServers, ipv6DNSServers []net.IP, mtu int) *Creator {
 	if len(interfacePrefix) > maxInterfacePrefixLen {
 		panic("interface prefix is too long")
 	}
@@ -59,6 +70,7 @@ func NewConfigCreator(idGenerator IDGenerator, interfacePrefix, chainPrefix stri
 		chainPrefix:     chainPrefix,
 		externalIP:      externalIP,
 		dnsServers:      dnsServers,
+		ipv6DNSServers:  ipv6DNSServers,
 		mtu:             mtu,
 	}
 }
@@ -80,5 +92,6 @@ func (c *Creator) Create(log lager.Logger, handle string, subnet *net.IPNet, ip
 		Subnet:          subnet,
 		Mtu:             c.mtu,
 		DNSServers:      c.dnsServers,
+		IPv6DNSServers:  c.ipv6DNSServers,
 	}, nil
 }
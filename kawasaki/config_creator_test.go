@@ -13,14 +13,15 @@ import (
 
 var _ = Describe("ConfigCreator", func() {
 	var (
-		creator     *kawasaki.Creator
-		subnet      *net.IPNet
-		ip          net.IP
-		externalIP  net.IP
-		dnsServers  []net.IP
-		logger      lager.Logger
-		idGenerator *fakes.FakeIDGenerator
-		mtu         int
+		creator        *kawasaki.Creator
+		subnet         *net.IPNet
+		ip             net.IP
+		externalIP     net.IP
+		dnsServers     []net.IP
+		ipv6DNSServers []net.IP
+		logger         lager.Logger
+		idGenerator    *fakes.FakeIDGenerator
+		mtu            int
 	)
 
 	BeforeEach(func() {
@@ -33,24 +34,27 @@ var _ = Describe("ConfigCreator", func() {
 			net.ParseIP("8.8.8.8"),
 			net.ParseIP("8.8.4.4"),
 		}
+		ipv6DNSServers = []net.IP{
+			net.ParseIP("2001:4860:4860::8888"),
+		}
 
 		logger = lagertest.NewTestLogger("test")
 		idGenerator = &fakes.FakeIDGenerator{}
 
 		mtu = 1234
 
-		creator = kawasaki.NewConfigCreator(idGenerator, "w1", "0123456789abcdef", externalIP, dnsServers, mtu)
+		creator = kawasaki.NewConfigCreator(idGenerator, "w1", "0123456789abcdef", externalIP, dnsServers, ipv6DNSServers, mtu)
 	})
 
 	It("panics if the interface prefix is longer than 2 characters", func() {
 		Expect(func() {
-			kawasaki.NewConfigCreator(idGenerator, "too-long", "wc", externalIP, dnsServers, mtu)
+			kawasaki.NewConfigCreator(idGenerator, "too-long", "wc", externalIP, dnsServers, ipv6DNSServers, mtu)
 		}).To(Panic())
 	})
 
 	It("panics if the chain prefix is longer than 16 characters", func() {
 		Expect(func() {
-			kawasaki.NewConfigCreator(idGenerator, "w1", "0123456789abcdefg", externalIP, dnsServers, mtu)
+			kawasaki.NewConfigCreator(idGenerator, "w1", "0123456789abcdefg", externalIP, dnsServers, ipv6DNSServers, mtu)
 		}).To(Panic())
 	})
 
@@ -141,4 +145,11 @@ var _ = Describe("ConfigCreator", func() {
 
 		Expect(config.DNSServers).To(Equal(dnsServers))
 	})
+
+	It("Assigns the IPv6 DNS servers", func() {
+		config, err := creator.Create(logger, "banana", subnet, ip)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(config.IPv6DNSServers).To(Equal(ipv6DNSServers))
+	})
 })
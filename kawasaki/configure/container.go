@@ -24,6 +24,7 @@ import (
 func init() {
 	reexec.Register("configure-container-netns", func() {
 		var netNsPath, containerIntf, containerIPStr, bridgeIPStr, subnetStr string
+		var containerIPv6Str, bridgeIPv6Str, subnetIPv6Str string
 		var mtu int
 
 		flag.StringVar(&netNsPath, "netNsPath", "", "netNsPath")
@@ -31,6 +32,9 @@ func init() {
 		flag.StringVar(&containerIPStr, "containerIP", "", "containerIP")
 		flag.StringVar(&bridgeIPStr, "bridgeIP", "", "bridgeIP")
 		flag.StringVar(&subnetStr, "subnet", "", "subnet")
+		flag.StringVar(&containerIPv6Str, "containerIPv6", "", "containerIPv6")
+		flag.StringVar(&bridgeIPv6Str, "bridgeIPv6", "", "bridgeIPv6")
+		flag.StringVar(&subnetIPv6Str, "subnetIPv6", "", "subnetIPv6")
 		flag.IntVar(&mtu, "mtu", 0, "mtu")
 		flag.Parse()
 
@@ -77,6 +81,23 @@ func init() {
 				panic(err)
 			}
 
+			if containerIPv6Str == "" {
+				return nil
+			}
+
+			_, subnetIPv6Net, err := net.ParseCIDR(subnetIPv6Str)
+			if err != nil {
+				panic(err)
+			}
+
+			if err := link.AddIP(intf, net.ParseIP(containerIPv6Str), subnetIPv6Net); err != nil {
+				panic(err)
+			}
+
+			if err := link.AddDefaultGW(intf, net.ParseIP(bridgeIPv6Str)); err != nil {
+				panic(err)
+			}
+
 			return nil
 		}); err != nil {
 			fmt.Fprintf(os.Stderr, err.Error())
@@ -101,14 +122,24 @@ func (c *Container) Apply(log lager.Logger, cfg kawasaki.NetworkConfig, pid int)
 		"netNsPath":     netns.Name(),
 	})
 
-	cmd := reexec.Command("configure-container-netns",
+	args := []string{
 		"-netNsPath", netns.Name(),
 		"-containerIntf", cfg.ContainerIntf,
 		"-containerIP", cfg.ContainerIP.String(),
 		"-bridgeIP", cfg.BridgeIP.String(),
 		"-subnet", cfg.Subnet.String(),
 		"-mtu", strconv.FormatInt(int64(cfg.Mtu), 10),
-	)
+	}
+
+	if cfg.ContainerIPv6 != nil {
+		args = append(args,
+			"-containerIPv6", cfg.ContainerIPv6.String(),
+			"-bridgeIPv6", cfg.BridgeIPv6.String(),
+			"-subnetIPv6", cfg.SubnetIPv6.String(),
+		)
+	}
+
+	cmd := reexec.Command(append([]string{"configure-container-netns"}, args...)...)
 
 	errBuf := bytes.NewBuffer([]byte{})
 	cmd.Stderr = errBuf
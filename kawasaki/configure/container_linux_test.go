@@ -109,6 +109,29 @@ var _ = Describe("Container", func() {
 		Expect(linkMTU(netNsName, linkName)).To(Equal(networkConfig.Mtu))
 	})
 
+	Context("when the container has an IPv6 address", func() {
+		BeforeEach(func() {
+			containerIPv6, subnetIPv6, err := net.ParseCIDR("2001:db8::20/64")
+			Expect(err).NotTo(HaveOccurred())
+
+			networkConfig.ContainerIPv6 = containerIPv6
+			networkConfig.BridgeIPv6 = net.ParseIP("2001:db8::1")
+			networkConfig.SubnetIPv6 = subnetIPv6
+		})
+
+		It("sets the container IPv6 address", func() {
+			Expect(configurer.Apply(logger, networkConfig, 42)).To(Succeed())
+
+			Expect(linkIPv6Addresses(netNsName, linkName)).To(ContainElement("2001:db8::20/64"))
+		})
+
+		It("sets the IPv6 default gateway", func() {
+			Expect(configurer.Apply(logger, networkConfig, 42)).To(Succeed())
+
+			Expect(linkIPv6DefaultGW(netNsName, linkName)).To(Equal("2001:db8::1"))
+		})
+	})
+
 	Context("when the netns file disappears", func() {
 		BeforeEach(func() {
 			var err error
@@ -213,3 +236,39 @@ func linkDefaultGW(netNsName, linkName string) string {
 
 	return ret[1]
 }
+
+func linkIPv6Addresses(netNsName, linkName string) []string {
+	cmd := exec.Command("ip", "netns", "exec", netNsName, "ip", "-6", "addr", "show", "dev", linkName)
+
+	buffer := gbytes.NewBuffer()
+	sess, err := gexec.Start(cmd, buffer, GinkgoWriter)
+	Expect(err).NotTo(HaveOccurred())
+	Eventually(sess).Should(gexec.Exit(0))
+
+	re, err := regexp.Compile(`inet6 ([0-9a-f:]+/[0-9]+)`)
+	Expect(err).NotTo(HaveOccurred())
+
+	var addresses []string
+	for _, match := range re.FindAllStringSubmatch(string(buffer.Contents()), -1) {
+		addresses = append(addresses, match[1])
+	}
+
+	return addresses
+}
+
+func linkIPv6DefaultGW(netNsName, linkName string) string {
+	cmd := exec.Command("ip", "netns", "exec", netNsName, "ip", "-6", "route", "list", "dev", linkName)
+
+	buffer := gbytes.NewBuffer()
+	sess, err := gexec.Start(cmd, buffer, GinkgoWriter)
+	Expect(err).NotTo(HaveOccurred())
+	Eventually(sess).Should(gexec.Exit(0))
+
+	re, err := regexp.Compile(`default via ([0-9a-f:]+)`)
+	Expect(err).NotTo(HaveOccurred())
+
+	ret := re.FindStringSubmatch(string(buffer.Contents()))
+	Expect(ret).NotTo(BeEmpty())
+
+	return ret[1]
+}
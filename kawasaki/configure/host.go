@@ -15,6 +15,8 @@ type Host struct {
 	}
 
 	Link interface {
+		AddIP(intf *net.Interface, ip net.IP, subnet *net.IPNet) error
+		RemoveIP(intf *net.Interface, ip net.IP, subnet *net.IPNet) error
 		SetUp(intf *net.Interface) error
 		SetMTU(intf *net.Interface, mtu int) error
 		SetNs(intf *net.Interface, fd int) error
@@ -56,6 +58,12 @@ func (c *Host) Apply(logger lager.Logger, config kawasaki.NetworkConfig, pid int
 		return err
 	}
 
+	if config.BridgeIPv6 != nil {
+		if err = c.configureBridgeIPv6(cLog, bridge, config.BridgeIPv6, config.SubnetIPv6); err != nil {
+			return err
+		}
+	}
+
 	if host, container, err = c.configureVethPair(cLog, config.HostIntf, config.ContainerIntf); err != nil {
 		return err
 	}
@@ -82,6 +90,29 @@ func (c *Host) Destroy(config kawasaki.NetworkConfig) error {
 	return c.Bridge.Destroy(config.BridgeName)
 }
 
+// DestroyIPv6 removes the gateway address of a container's IPv6 subnet from
+// a bridge which is still shared by other containers.
+func (c *Host) DestroyIPv6(config kawasaki.NetworkConfig) error {
+	if config.BridgeIPv6 == nil {
+		return nil
+	}
+
+	bridge, bridgeExists, err := c.Link.InterfaceByName(config.BridgeName)
+	if err != nil {
+		return err
+	}
+
+	if !bridgeExists {
+		return nil
+	}
+
+	if err := c.Link.RemoveIP(bridge, config.BridgeIPv6, config.SubnetIPv6); err != nil {
+		return &ConfigureLinkError{err, "bridge", bridge, config.BridgeIPv6, config.SubnetIPv6}
+	}
+
+	return nil
+}
+
 func (c *Host) configureBridgeIntf(log lager.Logger, name string, ip net.IP, subnet *net.IPNet) (*net.Interface, error) {
 	log = log.Session("bridge-interface")
 
@@ -104,6 +135,21 @@ func (c *Host) configureBridgeIntf(log lager.Logger, name string, ip net.IP, sub
 	return bridge, nil
 }
 
+// configureBridgeIPv6 adds the gateway address of a container's IPv6 subnet
+// to the bridge. Each container has its own IPv6 subnet, so a bridge shared
+// by containers has one address per container.
+func (c *Host) configureBridgeIPv6(log lager.Logger, bridge *net.Interface, ip net.IP, subnet *net.IPNet) error {
+	log = log.Session("bridge-ipv6", lager.Data{"ip": ip, "subnet": subnet})
+
+	log.Debug("add-ip")
+	if err := c.Link.AddIP(bridge, ip, subnet); err != nil {
+		log.Error("add-ip", err)
+		return &ConfigureLinkError{err, "bridge", bridge, ip, subnet}
+	}
+
+	return nil
+}
+
 func (c *Host) configureVethPair(log lager.Logger, hostName, containerName string) (*net.Interface, *net.Interface, error) {
 	log = log.Session("veth")
 
@@ -226,6 +226,46 @@ var _ = Describe("Host", func() {
 						})
 					})
 				})
+
+				Context("when the container has an IPv6 subnet", func() {
+					var subnetIPv6 *net.IPNet
+
+					BeforeEach(func() {
+						var err error
+						_, subnetIPv6, err = net.ParseCIDR("fd00::/64")
+						Expect(err).NotTo(HaveOccurred())
+
+						config.BridgeName = "bridge"
+						config.BridgeIPv6 = net.ParseIP("fd00::1")
+						config.SubnetIPv6 = subnetIPv6
+					})
+
+					It("adds the IPv6 gateway address to the bridge", func() {
+						Expect(configurer.Apply(logger, config, 42)).To(Succeed())
+
+						Expect(linkConfigurer.AddIPCalledWith).To(ConsistOf(fakedevices.InterfaceIPAndSubnet{
+							Interface: existingBridge,
+							IP:        net.ParseIP("fd00::1"),
+							Subnet:    subnetIPv6,
+						}))
+					})
+
+					Context("when adding the address fails", func() {
+						It("returns a wrapped error", func() {
+							cause := errors.New("no ipv6 here")
+							linkConfigurer.AddIPReturns["bridge"] = cause
+
+							err := configurer.Apply(logger, config, 42)
+							Expect(err).To(MatchError(&configure.ConfigureLinkError{
+								Cause:          cause,
+								Role:           "bridge",
+								Interface:      existingBridge,
+								IntendedIP:     net.ParseIP("fd00::1"),
+								IntendedSubnet: subnetIPv6,
+							}))
+						})
+					})
+				})
 			})
 		})
 	})
@@ -248,4 +288,83 @@ var _ = Describe("Host", func() {
 			})
 		})
 	})
+
+	Describe("DestroyIPv6", func() {
+		var (
+			bridge     *net.Interface
+			subnetIPv6 *net.IPNet
+		)
+
+		BeforeEach(func() {
+			var err error
+			_, subnetIPv6, err = net.ParseCIDR("fd00::/64")
+			Expect(err).NotTo(HaveOccurred())
+
+			config.BridgeName = "bridge"
+			config.BridgeIPv6 = net.ParseIP("fd00::1")
+			config.SubnetIPv6 = subnetIPv6
+
+			bridge = &net.Interface{Name: "bridge"}
+			linkConfigurer.InterfaceByNameFunc = func(name string) (*net.Interface, bool, error) {
+				if name == "bridge" {
+					return bridge, true, nil
+				}
+
+				return nil, false, nil
+			}
+		})
+
+		It("removes the IPv6 gateway address from the bridge", func() {
+			Expect(configurer.DestroyIPv6(config)).To(Succeed())
+
+			Expect(linkConfigurer.RemoveIPCalledWith).To(ConsistOf(fakedevices.InterfaceIPAndSubnet{
+				Interface: bridge,
+				IP:        net.ParseIP("fd00::1"),
+				Subnet:    subnetIPv6,
+			}))
+		})
+
+		It("does not destroy the bridge", func() {
+			Expect(configurer.DestroyIPv6(config)).To(Succeed())
+			Expect(bridger.DestroyCalledWith).To(BeEmpty())
+		})
+
+		Context("when the container has no IPv6 subnet", func() {
+			BeforeEach(func() {
+				config.BridgeIPv6 = nil
+				config.SubnetIPv6 = nil
+			})
+
+			It("does nothing", func() {
+				Expect(configurer.DestroyIPv6(config)).To(Succeed())
+				Expect(linkConfigurer.RemoveIPCalledWith).To(BeEmpty())
+			})
+		})
+
+		Context("when the bridge no longer exists", func() {
+			BeforeEach(func() {
+				config.BridgeName = "gone"
+			})
+
+			It("does nothing", func() {
+				Expect(configurer.DestroyIPv6(config)).To(Succeed())
+				Expect(linkConfigurer.RemoveIPCalledWith).To(BeEmpty())
+			})
+		})
+
+		Context("when removing the address fails", func() {
+			It("returns a wrapped error", func() {
+				cause := errors.New("still there")
+				linkConfigurer.RemoveIPReturns = cause
+
+				Expect(configurer.DestroyIPv6(config)).To(MatchError(&configure.ConfigureLinkError{
+					Cause:          cause,
+					Role:           "bridge",
+					Interface:      bridge,
+					IntendedIP:     net.ParseIP("fd00::1"),
+					IntendedSubnet: subnetIPv6,
+				}))
+			})
+		})
+	})
 })
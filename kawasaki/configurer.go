@@ -14,17 +14,19 @@ type NetnsExecer interface {
 }
 
 type configurer struct {
-	dnsResolvConfigurer  DnsResolvConfigurer
-	hostConfigurer       HostConfigurer
-	containerConfigurer  ContainerConfigurer
-	instanceChainCreator InstanceChainCreator
-	fileOpener           netns.Opener
+	dnsResolvConfigurer      DnsResolvConfigurer
+	hostConfigurer           HostConfigurer
+	containerConfigurer      ContainerConfigurer
+	instanceChainCreator     InstanceChainCreator
+	ipv6InstanceChainCreator InstanceChainCreator
+	fileOpener               netns.Opener
 }
 
 //go:generate counterfeiter . HostConfigurer
 type HostConfigurer interface {
 	Apply(logger lager.Logger, cfg NetworkConfig, pid int) error
 	Destroy(cfg NetworkConfig) error
+	DestroyIPv6(cfg NetworkConfig) error
 }
 
 //go:generate counterfeiter . InstanceChainCreator
@@ -43,12 +45,16 @@ type DnsResolvConfigurer interface {
 	Configure(log lager.Logger, cfg NetworkConfig, pid int) error
 }
 
-func NewConfigurer(resolvConfigurer DnsResolvConfigurer, hostConfigurer HostConfigurer, containerConfigurer ContainerConfigurer, instanceChainCreator InstanceChainCreator) *configurer {
+// NewConfigurer returns a Configurer. The ipv6InstanceChainCreator creates
+// the ip6tables chains of containers with IPv6 addresses, and may be nil if
+// IPv6 is not enabled.
+func NewConfigurer(resolvConfigurer DnsResolvConfigurer, hostConfigurer HostConfigurer, containerConfigurer ContainerConfigurer, instanceChainCreator, ipv6InstanceChainCreator InstanceChainCreator) *configurer {
 	return &configurer{
-		dnsResolvConfigurer:  resolvConfigurer,
-		hostConfigurer:       hostConfigurer,
-		containerConfigurer:  containerConfigurer,
-		instanceChainCreator: instanceChainCreator,
+		dnsResolvConfigurer:      resolvConfigurer,
+		hostConfigurer:           hostConfigurer,
+		containerConfigurer:      containerConfigurer,
+		instanceChainCreator:     instanceChainCreator,
+		ipv6InstanceChainCreator: ipv6InstanceChainCreator,
 	}
 }
 
@@ -65,6 +71,12 @@ func (c *configurer) Apply(log lager.Logger, cfg NetworkConfig, pid int) error {
 		return err
 	}
 
+	if c.hasIPv6(cfg) {
+		if err := c.ipv6InstanceChainCreator.Create(log, cfg.ContainerHandle, cfg.IPTableInstance, cfg.BridgeName, cfg.ContainerIPv6, cfg.SubnetIPv6); err != nil {
+			return err
+		}
+	}
+
 	return c.containerConfigurer.Apply(log, cfg, pid)
 }
 
@@ -72,6 +84,24 @@ func (c *configurer) DestroyBridge(log lager.Logger, cfg NetworkConfig) error {
 	return c.hostConfigurer.Destroy(cfg)
 }
 
+// DestroyBridgeIPv6 removes the IPv6 gateway address of a container from a
+// bridge which is kept because other containers still use it.
+func (c *configurer) DestroyBridgeIPv6(log lager.Logger, cfg NetworkConfig) error {
+	return c.hostConfigurer.DestroyIPv6(cfg)
+}
+
 func (c *configurer) DestroyIPTablesRules(log lager.Logger, cfg NetworkConfig) error {
-	return c.instanceChainCreator.Destroy(log, cfg.IPTableInstance)
+	if err := c.instanceChainCreator.Destroy(log, cfg.IPTableInstance); err != nil {
+		return err
+	}
+
+	if c.hasIPv6(cfg) {
+		return c.ipv6InstanceChainCreator.Destroy(log, cfg.IPTableInstance)
+	}
+
+	return nil
+}
+
+func (c *configurer) hasIPv6(cfg NetworkConfig) bool {
+	return cfg.SubnetIPv6 != nil && c.ipv6InstanceChainCreator != nil
 }
@@ -22,6 +22,7 @@ var _ = Describe("Configurer", func() {
 		fakeHostConfigurer       *fakes.FakeHostConfigurer
 		fakeContainerConfigurer  *fakes.FakeContainerConfigurer
 		fakeInstanceChainCreator *fakes.FakeInstanceChainCreator
+		fakeIPv6ChainCreator     *fakes.FakeInstanceChainCreator
 
 		dummyFileOpener netns.Opener
 
@@ -38,6 +39,7 @@ var _ = Describe("Configurer", func() {
 		fakeHostConfigurer = new(fakes.FakeHostConfigurer)
 		fakeContainerConfigurer = new(fakes.FakeContainerConfigurer)
 		fakeInstanceChainCreator = new(fakes.FakeInstanceChainCreator)
+		fakeIPv6ChainCreator = new(fakes.FakeInstanceChainCreator)
 
 		var err error
 		netnsFD, err = ioutil.TempFile("", "")
@@ -47,7 +49,7 @@ var _ = Describe("Configurer", func() {
 			return netnsFD, nil
 		}
 
-		configurer = kawasaki.NewConfigurer(fakeDnsResolvConfigurer, fakeHostConfigurer, fakeContainerConfigurer, fakeInstanceChainCreator)
+		configurer = kawasaki.NewConfigurer(fakeDnsResolvConfigurer, fakeHostConfigurer, fakeContainerConfigurer, fakeInstanceChainCreator, fakeIPv6ChainCreator)
 
 		logger = lagertest.NewTestLogger("test")
 	})
@@ -133,6 +135,59 @@ var _ = Describe("Configurer", func() {
 			})
 		})
 
+		It("does not create ip6tables chains for a container without IPv6 addresses", func() {
+			Expect(configurer.Apply(logger, kawasaki.NetworkConfig{}, 42)).To(Succeed())
+			Expect(fakeIPv6ChainCreator.CreateCallCount()).To(Equal(0))
+		})
+
+		Context("when the container has IPv6 addresses", func() {
+			var cfg kawasaki.NetworkConfig
+
+			BeforeEach(func() {
+				_, subnetIPv6, err := net.ParseCIDR("fd00::/64")
+				Expect(err).NotTo(HaveOccurred())
+
+				cfg = kawasaki.NetworkConfig{
+					IPTableInstance: "instance",
+					BridgeName:      "the-bridge-name",
+					ContainerHandle: "some-handle",
+					ContainerIPv6:   net.ParseIP("fd00::2"),
+					SubnetIPv6:      subnetIPv6,
+				}
+			})
+
+			It("creates the ip6tables chains", func() {
+				Expect(configurer.Apply(logger, cfg, 42)).To(Succeed())
+
+				Expect(fakeIPv6ChainCreator.CreateCallCount()).To(Equal(1))
+				_, handle, instanceChain, bridgeName, ip, subnet := fakeIPv6ChainCreator.CreateArgsForCall(0)
+				Expect(handle).To(Equal("some-handle"))
+				Expect(instanceChain).To(Equal("instance"))
+				Expect(bridgeName).To(Equal("the-bridge-name"))
+				Expect(ip).To(Equal(cfg.ContainerIPv6))
+				Expect(subnet).To(Equal(cfg.SubnetIPv6))
+			})
+
+			Context("when creating the ip6tables chains fails", func() {
+				BeforeEach(func() {
+					fakeIPv6ChainCreator.CreateReturns(errors.New("no ip6tables"))
+				})
+
+				It("returns the error without configuring the container", func() {
+					Expect(configurer.Apply(logger, cfg, 42)).To(MatchError("no ip6tables"))
+					Expect(fakeContainerConfigurer.ApplyCallCount()).To(Equal(0))
+				})
+			})
+
+			It("tears down the ip6tables chains", func() {
+				Expect(configurer.DestroyIPTablesRules(logger, cfg)).To(Succeed())
+
+				Expect(fakeIPv6ChainCreator.DestroyCallCount()).To(Equal(1))
+				_, instance := fakeIPv6ChainCreator.DestroyArgsForCall(0)
+				Expect(instance).To(Equal("instance"))
+			})
+		})
+
 		It("applies the configuration in the container", func() {
 			cfg := kawasaki.NetworkConfig{
 				ContainerIntf: "banana",
@@ -178,6 +233,28 @@ var _ = Describe("Configurer", func() {
 		})
 	})
 
+	Describe("DestroyBridgeIPv6", func() {
+		It("should remove the IPv6 gateway address from the bridge", func() {
+			cfg := kawasaki.NetworkConfig{
+				BridgeName: "banana-bridge",
+			}
+			Expect(configurer.DestroyBridgeIPv6(logger, cfg)).To(Succeed())
+
+			Expect(fakeHostConfigurer.DestroyIPv6CallCount()).To(Equal(1))
+			Expect(fakeHostConfigurer.DestroyIPv6ArgsForCall(0)).To(Equal(cfg))
+			Expect(fakeHostConfigurer.DestroyCallCount()).To(Equal(0))
+		})
+
+		Context("when it fails to remove the address", func() {
+			It("should return the error", func() {
+				fakeHostConfigurer.DestroyIPv6Returns(errors.New("spiderman-error"))
+
+				err := configurer.DestroyBridgeIPv6(logger, kawasaki.NetworkConfig{})
+				Expect(err).To(MatchError(ContainSubstring("spiderman-error")))
+			})
+		})
+	})
+
 	Describe("DestroyIPTablesRules", func() {
 		It("should tear down the IP tables chains", func() {
 			cfg := kawasaki.NetworkConfig{
@@ -29,6 +29,7 @@ type InterfaceIPAndSubnet struct {
 
 type FakeLink struct {
 	AddIPCalledWith        []InterfaceIPAndSubnet
+	RemoveIPCalledWith     []InterfaceIPAndSubnet
 	SetUpCalledWith        []*net.Interface
 	AddDefaultGWCalledWith struct {
 		Interface *net.Interface
@@ -49,6 +50,7 @@ type FakeLink struct {
 	InterfaceByNameFunc func(string) (*net.Interface, bool, error)
 
 	AddIPReturns        map[string]error
+	RemoveIPReturns     error
 	AddDefaultGWReturns error
 	SetMTUReturns       error
 	SetNsReturns        error
@@ -60,6 +62,11 @@ func (f *FakeLink) AddIP(intf *net.Interface, ip net.IP, subnet *net.IPNet) erro
 	return f.AddIPReturns[intf.Name]
 }
 
+func (f *FakeLink) RemoveIP(intf *net.Interface, ip net.IP, subnet *net.IPNet) error {
+	f.RemoveIPCalledWith = append(f.RemoveIPCalledWith, InterfaceIPAndSubnet{intf, ip, subnet})
+	return f.RemoveIPReturns
+}
+
 func (f *FakeLink) AddDefaultGW(intf *net.Interface, ip net.IP) error {
 	f.AddDefaultGWCalledWith.Interface = intf
 	f.AddDefaultGWCalledWith.IP = ip
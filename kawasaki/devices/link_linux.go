@@ -28,6 +28,19 @@ func (Link) AddIP(intf *net.Interface, ip net.IP, subnet *net.IPNet) error {
 	return errF(netlink.AddrAdd(link, addr))
 }
 
+func (Link) RemoveIP(intf *net.Interface, ip net.IP, subnet *net.IPNet) error {
+	netlinkMu.Lock()
+	defer netlinkMu.Unlock()
+
+	link, err := netlink.LinkByName(intf.Name)
+	if err != nil {
+		return errF(err)
+	}
+
+	addr := &netlink.Addr{IPNet: &net.IPNet{IP: ip, Mask: subnet.Mask}}
+	return errF(netlink.AddrDel(link, addr))
+}
+
 func (Link) AddDefaultGW(intf *net.Interface, ip net.IP) error {
 	netlinkMu.Lock()
 	defer netlinkMu.Unlock()
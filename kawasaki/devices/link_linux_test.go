@@ -56,6 +56,27 @@ var _ = Describe("Link Management", func() {
 		})
 	})
 
+	Describe("RemoveIP", func() {
+		Context("when the interface does not exist", func() {
+			It("returns the error", func() {
+				ip, subnet, _ := net.ParseCIDR("1.2.3.4/5")
+				Expect(l.RemoveIP(&net.Interface{Name: "something"}, ip, subnet)).To(MatchError("devices: Link not found"))
+			})
+		})
+
+		Context("when the interface has the IP", func() {
+			It("removes the IP", func() {
+				ip, subnet, _ := net.ParseCIDR("1.2.3.4/5")
+				Expect(l.AddIP(intf, ip, subnet)).To(Succeed())
+				Expect(l.RemoveIP(intf, ip, subnet)).To(Succeed())
+
+				addrs, err := intf.Addrs()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(addrs).To(BeEmpty())
+			})
+		})
+	})
+
 	Describe("AddDefaultGW", func() {
 		Context("when the interface does not exist", func() {
 			It("returns the error", func() {
@@ -11,7 +11,7 @@ import (
 	"code.cloudfoundry.org/guardian/kawasaki/netns"
 )
 
-func NewDefaultConfigurer(ipt *iptables.IPTablesController) kawasaki.Configurer {
+func NewDefaultConfigurer(chainCreator, ipv6ChainCreator kawasaki.InstanceChainCreator) kawasaki.Configurer {
 	resolvConfigurer := &kawasaki.ResolvConfigurer{
 		HostsFileCompiler:  &dns.HostsFileCompiler{},
 		ResolvFileCompiler: &dns.ResolvFileCompiler{},
@@ -34,6 +34,20 @@ func NewDefaultConfigurer(ipt *iptables.IPTablesController) kawasaki.Configurer
 		resolvConfigurer,
 		hostConfigurer,
 		containerConfigurer,
-		iptables.NewInstanceChainCreator(ipt),
+		chainCreator,
+		ipv6ChainCreator,
+	)
+}
+
+func NewDefaultInventory(configStore kawasaki.ConfigStore, ipt iptables.IPTables, chainCreator kawasaki.InstanceChainCreator, ip6t iptables.IPTables, ipv6ChainCreator kawasaki.InstanceChainCreator, interfacePrefix string) *kawasaki.Inventory {
+	return kawasaki.NewInventory(
+		configStore,
+		ipt,
+		chainCreator,
+		ip6t,
+		ipv6ChainCreator,
+		&devices.Link{},
+		&devices.Bridge{},
+		interfacePrefix,
 	)
 }
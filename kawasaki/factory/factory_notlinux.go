@@ -7,6 +7,10 @@ import (
 	"code.cloudfoundry.org/guardian/kawasaki/iptables"
 )
 
-func NewDefaultConfigurer(ipt *iptables.IPTablesController) kawasaki.Configurer {
+func NewDefaultConfigurer(chainCreator, ipv6ChainCreator kawasaki.InstanceChainCreator) kawasaki.Configurer {
+	panic("not supported on this platform")
+}
+
+func NewDefaultInventory(configStore kawasaki.ConfigStore, ipt iptables.IPTables, chainCreator kawasaki.InstanceChainCreator, ip6t iptables.IPTables, ipv6ChainCreator kawasaki.InstanceChainCreator, interfacePrefix string) *kawasaki.Inventory {
 	panic("not supported on this platform")
 }
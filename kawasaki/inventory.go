@@ -0,0 +1,140 @@
+package kawasaki
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter . InstanceChainLister
+
+type InstanceChainLister interface {
+	InstanceChains() ([]string, error)
+}
+
+//go:generate counterfeiter . LinkLister
+
+type LinkLister interface {
+	List() ([]string, error)
+}
+
+//go:generate counterfeiter . BridgeDestroyer
+
+type BridgeDestroyer interface {
+	Destroy(bridge string) error
+}
+
+const (
+	orphanChainPrefix     = "iptables-instance:"
+	orphanIPv6ChainPrefix = "ip6tables-instance:"
+	orphanBridgePrefix    = "bridge:"
+)
+
+// Inventory finds iptables and ip6tables instance chains and bridges which
+// belong to no container, by comparing them with the network config of the
+// containers in the depot. Host veths need no checking as they go away with
+// the container's network namespace.
+type Inventory struct {
+	configStore  ConfigStore
+	chains       InstanceChainLister
+	chainCreator InstanceChainCreator
+	links        LinkLister
+	bridges      BridgeDestroyer
+	bridgePrefix string
+
+	ipv6Chains       InstanceChainLister
+	ipv6ChainCreator InstanceChainCreator
+}
+
+// NewInventory returns an Inventory. The ipv6Chains and ipv6ChainCreator list
+// and destroy the ip6tables instance chains, and may be nil if IPv6 is not
+// enabled.
+func NewInventory(
+	configStore ConfigStore,
+	chains InstanceChainLister,
+	chainCreator InstanceChainCreator,
+	ipv6Chains InstanceChainLister,
+	ipv6ChainCreator InstanceChainCreator,
+	links LinkLister,
+	bridges BridgeDestroyer,
+	interfacePrefix string,
+) *Inventory {
+	return &Inventory{
+		configStore:      configStore,
+		chains:           chains,
+		chainCreator:     chainCreator,
+		ipv6Chains:       ipv6Chains,
+		ipv6ChainCreator: ipv6ChainCreator,
+		links:            links,
+		bridges:          bridges,
+		bridgePrefix:     interfacePrefix + "brdg-",
+	}
+}
+
+// Check returns the instance chains and bridges used by none of the handles
+// as orphans, prefixed with their kind. Containers may not have been
+// networked by kawasaki, so none are ever reported missing.
+func (i *Inventory) Check(log lager.Logger, handles []string) (missing, orphans []string, err error) {
+	ownedChains := make(map[string]bool)
+	ownedBridges := make(map[string]bool)
+	for _, handle := range handles {
+		if instance, ok := i.configStore.Get(handle, iptableInstanceKey); ok {
+			ownedChains[instance] = true
+		}
+
+		if bridge, ok := i.configStore.Get(handle, bridgeIntfKey); ok {
+			ownedBridges[bridge] = true
+		}
+	}
+
+	instances, err := i.chains.InstanceChains()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, instance := range instances {
+		if !ownedChains[instance] {
+			orphans = append(orphans, orphanChainPrefix+instance)
+		}
+	}
+
+	if i.ipv6Chains != nil {
+		ipv6Instances, err := i.ipv6Chains.InstanceChains()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, instance := range ipv6Instances {
+			if !ownedChains[instance] {
+				orphans = append(orphans, orphanIPv6ChainPrefix+instance)
+			}
+		}
+	}
+
+	links, err := i.links.List()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, link := range links {
+		if strings.HasPrefix(link, i.bridgePrefix) && !ownedBridges[link] {
+			orphans = append(orphans, orphanBridgePrefix+link)
+		}
+	}
+
+	return nil, orphans, nil
+}
+
+func (i *Inventory) RemoveOrphan(log lager.Logger, orphan string) error {
+	switch {
+	case strings.HasPrefix(orphan, orphanChainPrefix):
+		return i.chainCreator.Destroy(log, strings.TrimPrefix(orphan, orphanChainPrefix))
+	case strings.HasPrefix(orphan, orphanIPv6ChainPrefix) && i.ipv6ChainCreator != nil:
+		return i.ipv6ChainCreator.Destroy(log, strings.TrimPrefix(orphan, orphanIPv6ChainPrefix))
+	case strings.HasPrefix(orphan, orphanBridgePrefix):
+		return i.bridges.Destroy(strings.TrimPrefix(orphan, orphanBridgePrefix))
+	default:
+		return fmt.Errorf("unknown network resource: %s", orphan)
+	}
+}
@@ -0,0 +1,139 @@
+package kawasaki_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	fakes "code.cloudfoundry.org/guardian/kawasaki/kawasakifakes"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inventory", func() {
+	var (
+		configStore  *fakes.FakeConfigStore
+		chainLister  *fakes.FakeInstanceChainLister
+		chainCreator *fakes.FakeInstanceChainCreator
+		linkLister   *fakes.FakeLinkLister
+		bridges      *fakes.FakeBridgeDestroyer
+		logger       *lagertest.TestLogger
+
+		inventory *kawasaki.Inventory
+	)
+
+	BeforeEach(func() {
+		configStore = new(fakes.FakeConfigStore)
+		chainLister = new(fakes.FakeInstanceChainLister)
+		chainCreator = new(fakes.FakeInstanceChainCreator)
+		linkLister = new(fakes.FakeLinkLister)
+		bridges = new(fakes.FakeBridgeDestroyer)
+		logger = lagertest.NewTestLogger("test")
+
+		configStore.GetStub = func(handle, name string) (string, bool) {
+			if handle != "container" {
+				return "", false
+			}
+
+			switch name {
+			case "kawasaki.iptable-inst":
+				return "instance-1", true
+			case "kawasaki.bridge-interface":
+				return "wbrdg-0aff0000", true
+			}
+
+			return "", false
+		}
+
+		chainLister.InstanceChainsReturns([]string{"instance-1", "instance-2"}, nil)
+		linkLister.ListReturns([]string{"lo", "eth0", "wbrdg-0aff0000", "wbrdg-0aff0004", "w123-0"}, nil)
+
+		inventory = kawasaki.NewInventory(configStore, chainLister, chainCreator, nil, nil, linkLister, bridges, "w")
+	})
+
+	Describe("Check", func() {
+		It("reports the instance chains and bridges no container uses as orphans", func() {
+			missing, orphans, err := inventory.Check(logger, []string{"container", "not-networked"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(missing).To(BeEmpty())
+			Expect(orphans).To(ConsistOf("iptables-instance:instance-2", "bridge:wbrdg-0aff0004"))
+		})
+
+		Context("when listing the instance chains fails", func() {
+			It("returns the error", func() {
+				chainLister.InstanceChainsReturns(nil, errors.New("boom"))
+
+				_, _, err := inventory.Check(logger, []string{"container"})
+				Expect(err).To(MatchError("boom"))
+			})
+		})
+
+		Context("when IPv6 is enabled", func() {
+			var ipv6ChainLister *fakes.FakeInstanceChainLister
+
+			BeforeEach(func() {
+				ipv6ChainLister = new(fakes.FakeInstanceChainLister)
+				ipv6ChainLister.InstanceChainsReturns([]string{"instance-1", "instance-3"}, nil)
+
+				inventory = kawasaki.NewInventory(configStore, chainLister, chainCreator, ipv6ChainLister, new(fakes.FakeInstanceChainCreator), linkLister, bridges, "w")
+			})
+
+			It("also reports the ip6tables instance chains no container uses as orphans", func() {
+				_, orphans, err := inventory.Check(logger, []string{"container"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(orphans).To(ConsistOf("iptables-instance:instance-2", "ip6tables-instance:instance-3", "bridge:wbrdg-0aff0004"))
+			})
+
+			Context("when listing the ip6tables instance chains fails", func() {
+				It("returns the error", func() {
+					ipv6ChainLister.InstanceChainsReturns(nil, errors.New("boom"))
+
+					_, _, err := inventory.Check(logger, []string{"container"})
+					Expect(err).To(MatchError("boom"))
+				})
+			})
+		})
+
+		Context("when listing the links fails", func() {
+			It("returns the error", func() {
+				linkLister.ListReturns(nil, errors.New("boom"))
+
+				_, _, err := inventory.Check(logger, []string{"container"})
+				Expect(err).To(MatchError("boom"))
+			})
+		})
+	})
+
+	Describe("RemoveOrphan", func() {
+		It("destroys orphaned instance chains", func() {
+			Expect(inventory.RemoveOrphan(logger, "iptables-instance:instance-2")).To(Succeed())
+
+			Expect(chainCreator.DestroyCallCount()).To(Equal(1))
+			_, instance := chainCreator.DestroyArgsForCall(0)
+			Expect(instance).To(Equal("instance-2"))
+		})
+
+		It("destroys orphaned bridges", func() {
+			Expect(inventory.RemoveOrphan(logger, "bridge:wbrdg-0aff0004")).To(Succeed())
+
+			Expect(bridges.DestroyCallCount()).To(Equal(1))
+			Expect(bridges.DestroyArgsForCall(0)).To(Equal("wbrdg-0aff0004"))
+		})
+
+		It("destroys orphaned ip6tables instance chains", func() {
+			ipv6ChainCreator := new(fakes.FakeInstanceChainCreator)
+			inventory = kawasaki.NewInventory(configStore, chainLister, chainCreator, new(fakes.FakeInstanceChainLister), ipv6ChainCreator, linkLister, bridges, "w")
+
+			Expect(inventory.RemoveOrphan(logger, "ip6tables-instance:instance-3")).To(Succeed())
+
+			Expect(chainCreator.DestroyCallCount()).To(Equal(0))
+			Expect(ipv6ChainCreator.DestroyCallCount()).To(Equal(1))
+			_, instance := ipv6ChainCreator.DestroyArgsForCall(0)
+			Expect(instance).To(Equal("instance-3"))
+		})
+
+		It("rejects unknown resources", func() {
+			Expect(inventory.RemoveOrphan(logger, "banana")).To(MatchError("unknown network resource: banana"))
+		})
+	})
+})
@@ -10,6 +10,7 @@ import (
 
 type FirewallOpener struct {
 	iptables IPTables
+	ipv6     bool
 }
 
 func NewFirewallOpener(iptables IPTables) *FirewallOpener {
@@ -18,29 +19,118 @@ func NewFirewallOpener(iptables IPTables) *FirewallOpener {
 	}
 }
 
+// NewIPv6FirewallOpener returns a FirewallOpener for rules to IPv6 networks,
+// which are applied with ip6tables
+func NewIPv6FirewallOpener(ip6tables IPTables) *FirewallOpener {
+	return &FirewallOpener{
+		iptables: ip6tables,
+		ipv6:     true,
+	}
+}
+
 func (f *FirewallOpener) Open(logger lager.Logger, instance string, r garden.NetOutRule) error {
 	chain := f.iptables.InstanceChain(instance)
 
 	logger = logger.Session("prepend-filter-rule", lager.Data{"rule": r, "instance": instance, "chain": chain})
 	logger.Debug("started")
 
+	filters, err := filterRules(r, f.ipv6)
+	if err != nil {
+		return err
+	}
+
+	for _, filter := range filters {
+		if err := f.iptables.PrependRule(chain, filter); err != nil {
+			return err
+		}
+	}
+
+	logger.Debug("ending")
+	return nil
+}
+
+// BulkOpen applies all of the rules at once. If any rule is invalid, or
+// iptables rejects any of the resulting entries, none of them are applied.
+func (f *FirewallOpener) BulkOpen(logger lager.Logger, instance string, rules []garden.NetOutRule) error {
+	chain := f.iptables.InstanceChain(instance)
+
+	logger = logger.Session("bulk-prepend-filter-rules", lager.Data{"rules": len(rules), "instance": instance, "chain": chain})
+	logger.Debug("started")
+
+	var filters []Rule
+	for _, r := range rules {
+		ruleFilters, err := filterRules(r, f.ipv6)
+		if err != nil {
+			return err
+		}
+
+		for _, filter := range ruleFilters {
+			filters = append(filters, filter)
+		}
+	}
+
+	if err := f.iptables.BulkPrependRules(chain, filters); err != nil {
+		return err
+	}
+
+	logger.Debug("ending")
+	return nil
+}
+
+// Close deletes the iptables entries previously added by Open for the same
+// rule. If any entry cannot be deleted, those already deleted are added back,
+// so that the rule is either closed entirely or still fully open.
+func (f *FirewallOpener) Close(logger lager.Logger, instance string, r garden.NetOutRule) error {
+	chain := f.iptables.InstanceChain(instance)
+
+	logger = logger.Session("delete-filter-rule", lager.Data{"rule": r, "instance": instance, "chain": chain})
+	logger.Debug("started")
+
+	filters, err := filterRules(r, f.ipv6)
+	if err != nil {
+		return err
+	}
+
+	for i, filter := range filters {
+		if err := f.iptables.DeleteRule(chain, filter); err != nil {
+			f.reopen(logger, chain, filters[:i])
+			return err
+		}
+	}
+
+	logger.Debug("ending")
+	return nil
+}
+
+func (f *FirewallOpener) reopen(logger lager.Logger, chain string, filters []SingleFilterRule) {
+	for _, filter := range filters {
+		if err := f.iptables.PrependRule(chain, filter); err != nil {
+			logger.Error("reopen-failed", err, lager.Data{"filter": filter})
+		}
+	}
+}
+
+func filterRules(r garden.NetOutRule, ipv6 bool) ([]SingleFilterRule, error) {
 	if len(r.Ports) > 0 && !allowsPort(r.Protocol) {
-		return fmt.Errorf("Ports cannot be specified for Protocol %s", strings.ToUpper(protocols[r.Protocol]))
+		return nil, fmt.Errorf("Ports cannot be specified for Protocol %s", strings.ToUpper(protocols[r.Protocol]))
 	}
 
 	if _, ok := protocols[r.Protocol]; !ok {
-		return fmt.Errorf("invalid protocol: %d", r.Protocol)
+		return nil, fmt.Errorf("invalid protocol: %d", r.Protocol)
 	}
 
-	filter := SingleFilterRule{
-		Protocol: r.Protocol,
-		ICMPs:    r.ICMPs,
-		Log:      r.Log,
-	}
+	var filters []SingleFilterRule
 
 	// It should still loop once even if there are no networks or ports.
 	for i := 0; i < len(r.Ports) || i == 0; i++ {
 		for j := 0; j < len(r.Networks) || j == 0; j++ {
+			filter := SingleFilterRule{
+				Protocol: r.Protocol,
+				ICMPs:    r.ICMPs,
+				Log:      r.Log,
+				IPv6:     ipv6,
+			}
+
 			// Preserve nils unless there are ports specified
 			if len(r.Ports) > 0 {
 				filter.Ports = &r.Ports[i]
@@ -51,14 +141,11 @@ func (f *FirewallOpener) Open(logger lager.Logger, instance string, r garden.Net
 				filter.Networks = &r.Networks[j]
 			}
 
-			if err := f.iptables.PrependRule(chain, filter); err != nil {
-				return err
-			}
+			filters = append(filters, filter)
 		}
 	}
 
-	logger.Debug("ending")
-	return nil
+	return filters, nil
 }
 
 func allowsPort(p garden.Protocol) bool {
@@ -212,4 +212,171 @@ var _ = Describe("FirewallOpenner", func() {
 			),
 		)
 	})
+
+	Describe("an IPv6 FirewallOpener", func() {
+		BeforeEach(func() {
+			opener = iptables.NewIPv6FirewallOpener(fakeIPTablesController)
+		})
+
+		It("marks the rules it applies as IPv6", func() {
+			Expect(opener.Open(logger, "foo-bar-baz", garden.NetOutRule{
+				Protocol: garden.ProtocolICMP,
+			})).To(Succeed())
+
+			Expect(fakeIPTablesController.PrependRuleCallCount()).To(Equal(1))
+			_, rule := fakeIPTablesController.PrependRuleArgsForCall(0)
+			Expect(rule).To(Equal(iptables.SingleFilterRule{
+				Protocol: garden.ProtocolICMP,
+				IPv6:     true,
+			}))
+		})
+
+		It("marks the rules it deletes as IPv6", func() {
+			Expect(opener.Close(logger, "foo-bar-baz", garden.NetOutRule{})).To(Succeed())
+
+			Expect(fakeIPTablesController.DeleteRuleCallCount()).To(Equal(1))
+			_, rule := fakeIPTablesController.DeleteRuleArgsForCall(0)
+			Expect(rule).To(Equal(iptables.SingleFilterRule{IPv6: true}))
+		})
+	})
+
+	Describe("BulkOpen", func() {
+		It("prepends every filter rule in a single call", func() {
+			Expect(opener.BulkOpen(logger, "foo-bar-baz", []garden.NetOutRule{
+				{
+					Protocol: garden.ProtocolTCP,
+					Networks: []garden.IPRange{
+						garden.IPRangeFromIP(net.ParseIP("1.2.3.4")),
+						garden.IPRangeFromIP(net.ParseIP("2.2.3.4")),
+					},
+				},
+				{
+					Protocol: garden.ProtocolUDP,
+					Ports:    []garden.PortRange{garden.PortRangeFromPort(53)},
+				},
+			})).To(Succeed())
+
+			Expect(fakeIPTablesController.PrependRuleCallCount()).To(Equal(0))
+			Expect(fakeIPTablesController.BulkPrependRulesCallCount()).To(Equal(1))
+
+			chainName, rules := fakeIPTablesController.BulkPrependRulesArgsForCall(0)
+			Expect(chainName).To(Equal("prefix-foo-bar-baz"))
+			Expect(rules).To(Equal([]iptables.Rule{
+				iptables.SingleFilterRule{
+					Protocol: garden.ProtocolTCP,
+					Networks: &garden.IPRange{Start: net.ParseIP("1.2.3.4"), End: net.ParseIP("1.2.3.4")},
+				},
+				iptables.SingleFilterRule{
+					Protocol: garden.ProtocolTCP,
+					Networks: &garden.IPRange{Start: net.ParseIP("2.2.3.4"), End: net.ParseIP("2.2.3.4")},
+				},
+				iptables.SingleFilterRule{
+					Protocol: garden.ProtocolUDP,
+					Ports:    &garden.PortRange{Start: 53, End: 53},
+				},
+			}))
+		})
+
+		Context("when any of the rules is invalid", func() {
+			It("returns an error without applying anything", func() {
+				Expect(opener.BulkOpen(logger, "foo-bar-baz", []garden.NetOutRule{
+					{Protocol: garden.ProtocolTCP},
+					{Protocol: garden.Protocol(52)},
+				})).To(MatchError("invalid protocol: 52"))
+
+				Expect(fakeIPTablesController.BulkPrependRulesCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when applying the rules fails", func() {
+			BeforeEach(func() {
+				fakeIPTablesController.BulkPrependRulesReturns(errors.New("i-lost-my-banana"))
+			})
+
+			It("returns the error", func() {
+				Expect(opener.BulkOpen(logger, "foo-bar-baz", []garden.NetOutRule{{}})).To(MatchError("i-lost-my-banana"))
+			})
+		})
+	})
+
+	Describe("Close", func() {
+		It("deletes each filter rule from the instance chain", func() {
+			Expect(opener.Close(logger, "foo-bar-baz", garden.NetOutRule{
+				Protocol: garden.ProtocolTCP,
+				Networks: []garden.IPRange{
+					garden.IPRangeFromIP(net.ParseIP("1.2.3.4")),
+					garden.IPRangeFromIP(net.ParseIP("2.2.3.4")),
+				},
+				Ports: []garden.PortRange{garden.PortRangeFromPort(22)},
+			})).To(Succeed())
+
+			Expect(fakeIPTablesController.PrependRuleCallCount()).To(Equal(0))
+			Expect(fakeIPTablesController.DeleteRuleCallCount()).To(Equal(2))
+
+			chainName, rule := fakeIPTablesController.DeleteRuleArgsForCall(0)
+			Expect(chainName).To(Equal("prefix-foo-bar-baz"))
+			Expect(rule).To(Equal(iptables.SingleFilterRule{
+				Protocol: garden.ProtocolTCP,
+				Networks: &garden.IPRange{Start: net.ParseIP("1.2.3.4"), End: net.ParseIP("1.2.3.4")},
+				Ports:    &garden.PortRange{Start: 22, End: 22},
+			}))
+
+			_, rule = fakeIPTablesController.DeleteRuleArgsForCall(1)
+			Expect(rule).To(Equal(iptables.SingleFilterRule{
+				Protocol: garden.ProtocolTCP,
+				Networks: &garden.IPRange{Start: net.ParseIP("2.2.3.4"), End: net.ParseIP("2.2.3.4")},
+				Ports:    &garden.PortRange{Start: 22, End: 22},
+			}))
+		})
+
+		Context("when the rule is invalid", func() {
+			It("returns an error without deleting anything", func() {
+				Expect(opener.Close(logger, "foo-bar-baz", garden.NetOutRule{
+					Protocol: garden.Protocol(52),
+				})).To(MatchError("invalid protocol: 52"))
+
+				Expect(fakeIPTablesController.DeleteRuleCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when deleting the rule fails", func() {
+			BeforeEach(func() {
+				fakeIPTablesController.DeleteRuleReturns(errors.New("i-lost-my-banana"))
+			})
+
+			It("returns the error", func() {
+				Expect(opener.Close(logger, "foo-bar-baz", garden.NetOutRule{})).To(MatchError("i-lost-my-banana"))
+			})
+		})
+
+		Context("when deleting a later filter rule fails", func() {
+			BeforeEach(func() {
+				fakeIPTablesController.DeleteRuleStub = func(_ string, rule iptables.Rule) error {
+					if rule.(iptables.SingleFilterRule).Networks.Start.Equal(net.ParseIP("2.2.3.4")) {
+						return errors.New("i-lost-my-banana")
+					}
+
+					return nil
+				}
+			})
+
+			It("adds the deleted filter rules back and returns the error", func() {
+				Expect(opener.Close(logger, "foo-bar-baz", garden.NetOutRule{
+					Protocol: garden.ProtocolTCP,
+					Networks: []garden.IPRange{
+						garden.IPRangeFromIP(net.ParseIP("1.2.3.4")),
+						garden.IPRangeFromIP(net.ParseIP("2.2.3.4")),
+					},
+				})).To(MatchError("i-lost-my-banana"))
+
+				Expect(fakeIPTablesController.PrependRuleCallCount()).To(Equal(1))
+				chainName, rule := fakeIPTablesController.PrependRuleArgsForCall(0)
+				Expect(chainName).To(Equal("prefix-foo-bar-baz"))
+				Expect(rule).To(Equal(iptables.SingleFilterRule{
+					Protocol: garden.ProtocolTCP,
+					Networks: &garden.IPRange{Start: net.ParseIP("1.2.3.4"), End: net.ParseIP("1.2.3.4")},
+				}))
+			})
+		})
+	})
 })
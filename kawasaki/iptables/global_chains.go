@@ -1,247 +1,262 @@
 package iptables
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
-	"os"
+	"net"
 	"os/exec"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
 )
 
-const SetupScript = `
-	set -o xtrace
-	set -o nounset
-	set -o errexit
-	shopt -s nullglob
-
-	filter_input_chain="${GARDEN_IPTABLES_FILTER_INPUT_CHAIN}"
-	filter_forward_chain="${GARDEN_IPTABLES_FILTER_FORWARD_CHAIN}"
-	filter_default_chain="${GARDEN_IPTABLES_FILTER_DEFAULT_CHAIN}"
-	filter_instance_prefix="${GARDEN_IPTABLES_FILTER_INSTANCE_PREFIX}"
-	nat_prerouting_chain="${GARDEN_IPTABLES_NAT_PREROUTING_CHAIN}"
-	nat_postrouting_chain="${GARDEN_IPTABLES_NAT_POSTROUTING_CHAIN}"
-	nat_instance_prefix="${GARDEN_IPTABLES_NAT_INSTANCE_PREFIX}"
-	iptables_bin="${GARDEN_IPTABLES_BIN}"
-
-	function teardown_deprecated_rules() {
-		# Remove jump to garden-dispatch from INPUT
-		${iptables_bin} -w -S INPUT 2> /dev/null |
-		grep " -j garden-dispatch" |
-		sed -e "s/-A/-D/" -e "s/\s\+\$//" |
-		xargs --no-run-if-empty --max-lines=1 ${iptables_bin} -w
+// deprecatedDispatchChain is the chain older versions of garden jumped to
+// from INPUT and FORWARD
+const deprecatedDispatchChain = "garden-dispatch"
+
+// Starter sets up the global chains, unless they already exist, and resets
+// the allow and deny networks in the default chain. Allowed networks are
+// accepted before any are denied, so they act as exceptions to the deny
+// networks. Setup tears down any chains left behind by a previous run first,
+// so each of its steps is safe to repeat. An ip6tables Starter only applies
+// the IPv6 allow and deny networks, and an iptables one the IPv4 ones.
+//
+// The networks of each pool apply only to traffic from its subnet, and come
+// before the global ones.
+type Starter struct {
+	logger          lager.Logger
+	iptables        *IPTablesController
+	allowHostAccess bool
+	nicPrefix       string
+	procDir         string
+
+	allowNetworks []string
+	denyNetworks  []string
+	poolNetworks  []PoolNetworks
+}
+
+// PoolNetworks are the networks allowed and denied to the containers of a
+// network pool
+type PoolNetworks struct {
+	Subnet        string
+	AllowNetworks []string
+	DenyNetworks  []string
+}
+
+func NewStarter(logger lager.Logger, iptables *IPTablesController, allowHostAccess bool, nicPrefix string, allowNetworks, denyNetworks []string, poolNetworks []PoolNetworks, procDir string) *Starter {
+	return &Starter{
+		logger:          logger,
+		iptables:        iptables,
+		allowHostAccess: allowHostAccess,
+		nicPrefix:       nicPrefix,
+		procDir:         procDir,
+
+		allowNetworks: allowNetworks,
+		denyNetworks:  denyNetworks,
+		poolNetworks:  poolNetworks,
+	}
+}
 
-		# Remove jump to garden-dispatch from FORWARD
-		${iptables_bin} -w -S FORWARD 2> /dev/null |
-		grep " -j garden-dispatch" |
-		sed -e "s/-A/-D/" -e "s/\s\+\$//" |
-		xargs --no-run-if-empty --max-lines=1 ${iptables_bin} -w
-
-		# Prune garden-dispatch
-		${iptables_bin} -w -F garden-dispatch 2> /dev/null || true
-
-		# Delete garden-dispatch
-		${iptables_bin} -w -X garden-dispatch 2> /dev/null || true
-	}
-
-	function teardown_filter() {
-		teardown_deprecated_rules
-
-		# Prune garden-forward chain
-		${iptables_bin} -w -S ${filter_forward_chain} 2> /dev/null |
-		grep "\-g ${filter_instance_prefix}" |
-		sed -e "s/-A/-D/" -e "s/\s\+\$//" |
-		xargs --no-run-if-empty --max-lines=1 ${iptables_bin} -w
+func (s Starter) Start() error {
+	if !s.chainExists(s.iptables.inputChain) {
+		if err := s.setup(s.logger.Session("setup-global-chains")); err != nil {
+			return fmt.Errorf("setting up default chains: %s", err)
+		}
+	}
 
-		# Prune per-instance chains
-		${iptables_bin} -w -S 2> /dev/null |
-		grep "^-A ${filter_instance_prefix}" |
-		sed -e "s/-A/-D/" -e "s/\s\+\$//" |
-		xargs --no-run-if-empty --max-lines=1 ${iptables_bin} -w
+	if err := s.resetDenyNetworks(); err != nil {
+		return err
+	}
 
-		# Delete per-instance chains
-		${iptables_bin} -w -S 2> /dev/null |
-		grep "^-N ${filter_instance_prefix}" |
-		sed -e "s/-N/-X/" -e "s/\s\+\$//" |
-		xargs --no-run-if-empty --max-lines=1 ${iptables_bin} -w || true
+	for _, pool := range s.poolNetworks {
+		if len(s.networks([]string{pool.Subnet})) == 0 {
+			continue
+		}
 
-		# Remove jump to garden-forward from FORWARD
-		${iptables_bin} -w -S FORWARD 2> /dev/null |
-		grep " -j ${filter_forward_chain}" |
-		sed -e "s/-A/-D/" -e "s/\s\+\$//" |
-		xargs --no-run-if-empty --max-lines=1 ${iptables_bin} -w || true
+		for _, n := range s.networks(pool.AllowNetworks) {
+			if err := s.iptables.AppendRule(s.iptables.defaultChain, sourceRule(pool.Subnet, acceptRule(n))); err != nil {
+				return err
+			}
+		}
 
-		${iptables_bin} -w -F ${filter_forward_chain} 2> /dev/null || true
-		${iptables_bin} -w -F ${filter_default_chain} 2> /dev/null || true
+		for _, n := range s.networks(pool.DenyNetworks) {
+			if err := s.iptables.AppendRule(s.iptables.defaultChain, sourceRule(pool.Subnet, rejectRule(n))); err != nil {
+				return err
+			}
+		}
+	}
 
-		# Remove jump to filter input chain from INPUT
-		${iptables_bin} -w -S INPUT 2> /dev/null |
-		grep " -j ${filter_input_chain}" |
-		sed -e "s/-A/-D/" -e "s/\s\+\$//" |
-		xargs --no-run-if-empty --max-lines=1 ${iptables_bin} -w || true
+	for _, n := range s.networks(s.allowNetworks) {
+		if err := s.iptables.AppendRule(s.iptables.defaultChain, acceptRule(n)); err != nil {
+			return err
+		}
+	}
 
-		# Empty and delete filter input chain
-		${iptables_bin} -w -F ${filter_input_chain} 2> /dev/null || true
-		${iptables_bin} -w -X ${filter_input_chain} 2> /dev/null || true
+	for _, n := range s.networks(s.denyNetworks) {
+		if err := s.iptables.AppendRule(s.iptables.defaultChain, rejectRule(n)); err != nil {
+			return err
+		}
 	}
 
-	function setup_filter() {
-		teardown_filter
-
-		# Determine interface device to the outside
-		default_interface=$(ip route show | grep default | cut -d' ' -f5 | head -1)
-
-		# Create, or empty existing, filter input chain
-		${iptables_bin} -w -N ${filter_input_chain} 2> /dev/null || ${iptables_bin} -w -F ${filter_input_chain}
-
-		# Accept inbound packets if default interface is matched by filter prefix
-		${iptables_bin} -w -I ${filter_input_chain} -i $default_interface --jump ACCEPT
-
-		# Put connection tracking rule in filter input chain
-		# to accept packets related to previously established connections
-		${iptables_bin} -w -A ${filter_input_chain} -m conntrack --ctstate ESTABLISHED,RELATED --jump ACCEPT
-
-		if [ "${GARDEN_IPTABLES_ALLOW_HOST_ACCESS}" != "true" ]; then
-		${iptables_bin} -w -A ${filter_input_chain} --jump REJECT --reject-with icmp-host-prohibited
-		else
-		${iptables_bin} -w -A ${filter_input_chain} --jump ACCEPT
-		fi
-
-		# Forward input traffic via ${filter_input_chain}
-		${iptables_bin} -w -A INPUT -i ${GARDEN_NETWORK_INTERFACE_PREFIX}+ --jump ${filter_input_chain}
-
-		# Create or flush forward chain
-		${iptables_bin} -w -N ${filter_forward_chain} 2> /dev/null || ${iptables_bin} -w -F ${filter_forward_chain}
-		${iptables_bin} -w -A ${filter_forward_chain} -j DROP
-
-		# Create or flush default chain
-		${iptables_bin} -w -N ${filter_default_chain} 2> /dev/null || ${iptables_bin} -w -F ${filter_default_chain}
-
-		# Always allow established connections to containers
-		${iptables_bin} -w -A ${filter_default_chain} -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT
-
-		# Forward outbound traffic via ${filter_forward_chain}
-		${iptables_bin} -w -A FORWARD -i ${GARDEN_NETWORK_INTERFACE_PREFIX}+ --jump ${filter_forward_chain}
-
-		# Forward inbound traffic immediately
-		${iptables_bin} -w -I ${filter_forward_chain} -i $default_interface --jump ACCEPT
-	}
-
-	function teardown_nat() {
-		# Prune prerouting chain
-		${iptables_bin} -w -t nat -S ${nat_prerouting_chain} 2> /dev/null |
-		grep "\-j ${nat_instance_prefix}" |
-		sed -e "s/-A/-D/" -e "s/\s\+\$//" |
-		xargs --no-run-if-empty --max-lines=1 ${iptables_bin} -w -t nat
+	return nil
+}
 
-		# Prune per-instance chains
-		${iptables_bin} -w -t nat -S 2> /dev/null |
-		grep "^-A ${nat_instance_prefix}" |
-		sed -e "s/-A/-D/" -e "s/\s\+\$//" |
-		xargs --no-run-if-empty --max-lines=1 ${iptables_bin} -w -t nat
+func (s Starter) setup(log lager.Logger) error {
+	defaultInterface, err := DefaultInterface(s.procDir)
+	if err != nil {
+		return err
+	}
 
-		# Delete per-instance chains
-		${iptables_bin} -w -t nat -S 2> /dev/null |
-		grep "^-N ${nat_instance_prefix}" |
-		sed -e "s/-N/-X/" -e "s/\s\+\$//" |
-		xargs --no-run-if-empty --max-lines=1 ${iptables_bin} -w -t nat || true
-
-		# Flush prerouting chain
-		${iptables_bin} -w -t nat -F ${nat_prerouting_chain} 2> /dev/null || true
-
-		# Flush postrouting chain
-		${iptables_bin} -w -t nat -F ${nat_postrouting_chain} 2> /dev/null || true
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"teardown-deprecated-chains", s.teardownDeprecated},
+		{"teardown-filter-chains", s.teardownFilter},
+		{"setup-filter-chains", func() error { return s.setupFilter(defaultInterface) }},
+		{"teardown-nat-chains", s.teardownNat},
+		{"setup-nat-chains", s.setupNat},
+		{"enable-ip-forward", s.enableForwarding},
 	}
 
-	function setup_nat() {
-		teardown_nat
+	log.Info("started", lager.Data{"default-interface": defaultInterface})
+	defer log.Info("finished")
+
+	for _, step := range steps {
+		stepLog := log.Session(step.name)
+		stepLog.Debug("started")
 
-		# Create prerouting chain
-		${iptables_bin} -w -t nat -N ${nat_prerouting_chain} 2> /dev/null || true
+		if err := step.run(); err != nil {
+			stepLog.Error("failed", err)
+			return fmt.Errorf("%s: %s", step.name, err)
+		}
 
-		# Bind chain to PREROUTING
-		(${iptables_bin} -w -t nat -S PREROUTING | grep -q "\-j ${nat_prerouting_chain}\b") ||
-		${iptables_bin} -w -t nat -A PREROUTING \
-		--jump ${nat_prerouting_chain}
+		stepLog.Debug("finished")
+	}
 
-		# Bind chain to OUTPUT (for traffic originating from same host)
-		(${iptables_bin} -w -t nat -S OUTPUT | grep -q "\-j ${nat_prerouting_chain}\b") ||
-		${iptables_bin} -w -t nat -A OUTPUT \
-		--out-interface "lo" \
-		--jump ${nat_prerouting_chain}
+	return nil
+}
 
-		# Create postrouting chain
-		${iptables_bin} -w -t nat -N ${nat_postrouting_chain} 2> /dev/null || true
+func (s Starter) teardownDeprecated() error {
+	ipt := s.iptables
+	dispatch := isChain(deprecatedDispatchChain)
 
-		# Bind chain to POSTROUTING
-		(${iptables_bin} -w -t nat -S POSTROUTING | grep -q "\-j ${nat_postrouting_chain}\b") ||
-		${iptables_bin} -w -t nat -A POSTROUTING \
-		--jump ${nat_postrouting_chain}
+	if err := ipt.deleteReferences("filter", "INPUT", dispatch); err != nil {
+		return err
 	}
 
-	case "${ACTION}" in
-	setup)
-	setup_filter
-	setup_nat
+	if err := ipt.deleteReferences("filter", "FORWARD", dispatch); err != nil {
+		return err
+	}
 
-	# Enable forwarding
-	echo 1 > /proc/sys/net/ipv4/ip_forward
-	;;
-	teardown)
-	teardown_filter
-	teardown_nat
-	;;
-	*)
-	echo "Unknown command: ${1}" 1>&2
-	exit 1
-	;;
-	esac
-`
+	return ipt.removeChains("filter", dispatch)
+}
 
-type Starter struct {
-	iptables        *IPTablesController
-	allowHostAccess bool
-	nicPrefix       string
+func (s Starter) teardownFilter() error {
+	ipt := s.iptables
+
+	if err := ipt.deleteReferences("filter", "INPUT", isChain(ipt.inputChain)); err != nil {
+		return err
+	}
+
+	if err := ipt.deleteReferences("filter", "FORWARD", isChain(ipt.forwardChain)); err != nil {
+		return err
+	}
+
+	// the forward chain refers to the instance chains, so must be emptied
+	// before they can be deleted
+	if err := ipt.flushChains("filter", isChain(ipt.forwardChain, ipt.defaultChain)); err != nil {
+		return err
+	}
 
-	denyNetworks []string
+	return ipt.removeChains("filter", func(chain string) bool {
+		return chain == ipt.inputChain || strings.HasPrefix(chain, ipt.instanceChainPrefix)
+	})
 }
 
-func NewStarter(iptables *IPTablesController, allowHostAccess bool, nicPrefix string, denyNetworks []string) *Starter {
-	return &Starter{
-		iptables:        iptables,
-		allowHostAccess: allowHostAccess,
-		nicPrefix:       nicPrefix,
+func (s Starter) setupFilter(defaultInterface string) error {
+	ipt := s.iptables
+
+	hostAccess := []string{"--jump", "REJECT", "--reject-with", "icmp-host-prohibited"}
+	if ipt.ipv6 {
+		hostAccess = []string{"--jump", "REJECT", "--reject-with", "icmp6-adm-prohibited"}
+	}
+	if s.allowHostAccess {
+		hostAccess = []string{"--jump", "ACCEPT"}
+	}
+
+	if err := ipt.ensureChain("filter", ipt.inputChain); err != nil {
+		return err
+	}
 
-		denyNetworks: denyNetworks,
+	if err := ipt.ensureChain("filter", ipt.forwardChain); err != nil {
+		return err
 	}
+
+	if err := ipt.ensureChain("filter", ipt.defaultChain); err != nil {
+		return err
+	}
+
+	return ipt.appendRules("filter", [][]string{
+		// Accept inbound packets from the outside, and those related to
+		// previously established connections
+		{ipt.inputChain, "--in-interface", defaultInterface, "--jump", "ACCEPT"},
+		{ipt.inputChain, "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "--jump", "ACCEPT"},
+		append([]string{ipt.inputChain}, hostAccess...),
+		{"INPUT", "--in-interface", s.nicPrefix + "+", "--jump", ipt.inputChain},
+
+		// Forward inbound traffic immediately, and outbound traffic via the
+		// instance chains, which are inserted before the final drop
+		{ipt.forwardChain, "--in-interface", defaultInterface, "--jump", "ACCEPT"},
+		{ipt.forwardChain, "--jump", "DROP"},
+		{"FORWARD", "--in-interface", s.nicPrefix + "+", "--jump", ipt.forwardChain},
+
+		// Always allow established connections to containers
+		{ipt.defaultChain, "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "--jump", "ACCEPT"},
+	})
 }
 
-func (s Starter) Start() error {
-	if !s.chainExists(s.iptables.inputChain) {
-		cmd := exec.Command("bash", "-c", SetupScript)
-		cmd.Env = []string{
-			fmt.Sprintf("PATH=%s", os.Getenv("PATH")),
-			"ACTION=setup",
-			fmt.Sprintf("GARDEN_IPTABLES_BIN=%s", s.iptables.binPath),
-			fmt.Sprintf("GARDEN_IPTABLES_FILTER_INPUT_CHAIN=%s", s.iptables.inputChain),
-			fmt.Sprintf("GARDEN_IPTABLES_FILTER_FORWARD_CHAIN=%s", s.iptables.forwardChain),
-			fmt.Sprintf("GARDEN_IPTABLES_FILTER_DEFAULT_CHAIN=%s", s.iptables.defaultChain),
-			fmt.Sprintf("GARDEN_IPTABLES_FILTER_INSTANCE_PREFIX=%s", s.iptables.instanceChainPrefix),
-			fmt.Sprintf("GARDEN_IPTABLES_NAT_PREROUTING_CHAIN=%s", s.iptables.preroutingChain),
-			fmt.Sprintf("GARDEN_IPTABLES_NAT_POSTROUTING_CHAIN=%s", s.iptables.postroutingChain),
-			fmt.Sprintf("GARDEN_IPTABLES_NAT_INSTANCE_PREFIX=%s", s.iptables.instanceChainPrefix),
-			fmt.Sprintf("GARDEN_NETWORK_INTERFACE_PREFIX=%s", s.nicPrefix),
-			fmt.Sprintf("GARDEN_IPTABLES_ALLOW_HOST_ACCESS=%t", s.allowHostAccess),
-		}
+func (s Starter) teardownNat() error {
+	ipt := s.iptables
+	instanceChain := func(chain string) bool {
+		return strings.HasPrefix(chain, ipt.instanceChainPrefix)
+	}
 
-		if err := s.iptables.run("setup-global-chains", cmd); err != nil {
-			return fmt.Errorf("setting up default chains: %s", err)
-		}
+	if err := ipt.deleteReferences("nat", ipt.preroutingChain, instanceChain); err != nil {
+		return err
 	}
 
-	if err := s.resetDenyNetworks(); err != nil {
+	if err := ipt.removeChains("nat", instanceChain); err != nil {
 		return err
 	}
 
-	for _, n := range s.denyNetworks {
-		if err := s.iptables.appendRule(s.iptables.defaultChain, rejectRule(n)); err != nil {
+	return ipt.flushChains("nat", isChain(ipt.preroutingChain, ipt.postroutingChain))
+}
+
+func (s Starter) setupNat() error {
+	ipt := s.iptables
+
+	if err := ipt.ensureChain("nat", ipt.preroutingChain); err != nil {
+		return err
+	}
+
+	if err := ipt.ensureChain("nat", ipt.postroutingChain); err != nil {
+		return err
+	}
+
+	// the base chains are not torn down, so only bind to them once
+	for _, rule := range [][]string{
+		{"PREROUTING", "--jump", ipt.preroutingChain},
+		// for traffic originating from the same host
+		{"OUTPUT", "--out-interface", "lo", "--jump", ipt.preroutingChain},
+		{"POSTROUTING", "--jump", ipt.postroutingChain},
+	} {
+		if ipt.ruleExists("nat", rule) {
+			continue
+		}
+
+		if err := ipt.appendRules("nat", [][]string{rule}); err != nil {
 			return err
 		}
 	}
@@ -249,24 +264,245 @@ func (s Starter) Start() error {
 	return nil
 }
 
+func (s Starter) enableForwarding() error {
+	if s.iptables.ipv6 {
+		return EnableIPv6Forward(s.procDir)
+	}
+
+	return EnableIPForward(s.procDir)
+}
+
+// networks returns the networks of the family the Starter manages
+func (s Starter) networks(cidrs []string) []string {
+	var networks []string
+	for _, cidr := range cidrs {
+		ip, _, err := net.ParseCIDR(cidr)
+		if err == nil && (ip.To4() == nil) != s.iptables.ipv6 {
+			continue
+		}
+
+		networks = append(networks, cidr)
+	}
+
+	return networks
+}
+
 func (s Starter) chainExists(chainName string) bool {
 	cmd := exec.Command(s.iptables.binPath, "-w", "-L", chainName)
-	cmd.Env = append(cmd.Env, fmt.Sprintf("PATH=%s", os.Getenv("PATH")))
 	return s.iptables.run("checking-chain-exists", cmd) == nil
 }
 
 func (s Starter) resetDenyNetworks() error {
 	cmd := exec.Command(s.iptables.binPath, "-w", "-F", s.iptables.defaultChain)
-	cmd.Env = append(cmd.Env, fmt.Sprintf("PATH=%s", os.Getenv("PATH")))
 	if err := s.iptables.run("flushing-default-chain", cmd); err != nil {
 		return err
 	}
 
 	cmd = exec.Command(s.iptables.binPath, "-w", "-A", s.iptables.defaultChain, "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "--jump", "ACCEPT")
-	cmd.Env = append(cmd.Env, fmt.Sprintf("PATH=%s", os.Getenv("PATH")))
 	if err := s.iptables.run("appending-default-chain", cmd); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func isChain(names ...string) func(chain string) bool {
+	return func(chain string) bool {
+		for _, name := range names {
+			if chain == name {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// chains returns the names of the user defined chains in the table
+func (iptables *IPTablesController) chains(table string) ([]string, error) {
+	specs, err := iptables.specs(table)
+	if err != nil {
+		return nil, err
+	}
+
+	var chains []string
+	for _, spec := range specs {
+		if len(spec) == 2 && spec[0] == "-N" {
+			chains = append(chains, spec[1])
+		}
+	}
+
+	return chains, nil
+}
+
+// specs returns the rule specifications listed by iptables -S, split into
+// arguments, for the table or, if given, only the chain
+func (iptables *IPTablesController) specs(table string, chain ...string) ([][]string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command(iptables.binPath, append([]string{"--wait", "--table", table, "-S"}, chain...)...)
+	cmd.Stdout = &stdout
+	if err := iptables.run("list-rules", cmd); err != nil {
+		return nil, err
+	}
+
+	var specs [][]string
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		if spec := splitSpec(scanner.Text()); len(spec) > 0 {
+			specs = append(specs, spec)
+		}
+	}
+
+	return specs, scanner.Err()
+}
+
+// deleteReferences deletes the rules in targetChain which jump or go to a
+// chain matching the predicate. A targetChain which does not exist has no
+// references, so is not an error.
+func (iptables *IPTablesController) deleteReferences(table, targetChain string, references func(chain string) bool) error {
+	specs, err := iptables.specs(table, targetChain)
+	if err != nil {
+		return nil
+	}
+
+	for _, spec := range specs {
+		if len(spec) < 2 || spec[0] != "-A" || !references(specTarget(spec)) {
+			continue
+		}
+
+		args := append([]string{"--wait", "--table", table, "-D"}, spec[1:]...)
+		if err := iptables.run("delete-referenced-chains", exec.Command(iptables.binPath, args...)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flushChains flushes the existing chains in the table matching the predicate
+func (iptables *IPTablesController) flushChains(table string, matches func(chain string) bool) error {
+	chains, err := iptables.chains(table)
+	if err != nil {
+		return err
+	}
+
+	for _, chain := range chains {
+		if !matches(chain) {
+			continue
+		}
+
+		if err := iptables.run("flush-chain", exec.Command(iptables.binPath, "--wait", "--table", table, "-F", chain)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeChains flushes and then deletes the existing chains in the table
+// matching the predicate. They are all flushed before any are deleted, since
+// they may refer to each other.
+func (iptables *IPTablesController) removeChains(table string, matches func(chain string) bool) error {
+	if err := iptables.flushChains(table, matches); err != nil {
+		return err
+	}
+
+	chains, err := iptables.chains(table)
+	if err != nil {
+		return err
+	}
+
+	for _, chain := range chains {
+		if !matches(chain) {
+			continue
+		}
+
+		if err := iptables.run("delete-chain", exec.Command(iptables.binPath, "--wait", "--table", table, "-X", chain)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ensureChain creates the chain, or flushes it if it already exists
+func (iptables *IPTablesController) ensureChain(table, chain string) error {
+	chains, err := iptables.chains(table)
+	if err != nil {
+		return err
+	}
+
+	if isChain(chains...)(chain) {
+		return iptables.run("flush-chain", exec.Command(iptables.binPath, "--wait", "--table", table, "-F", chain))
+	}
+
+	return iptables.run("create-chain", exec.Command(iptables.binPath, "--wait", "--table", table, "-N", chain))
+}
+
+// appendRules appends each rule, given as a chain followed by its flags
+func (iptables *IPTablesController) appendRules(table string, rules [][]string) error {
+	for _, rule := range rules {
+		args := append([]string{"--wait", "--table", table, "-A"}, rule...)
+		if err := iptables.run("append", exec.Command(iptables.binPath, args...)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ruleExists checks for a rule, given as a chain followed by its flags
+func (iptables *IPTablesController) ruleExists(table string, rule []string) bool {
+	args := append([]string{"--wait", "--table", table, "-C"}, rule...)
+	return iptables.run("check-rule", exec.Command(iptables.binPath, args...)) == nil
+}
+
+// specTarget returns the chain a rule specification jumps or goes to, if any
+func specTarget(spec []string) string {
+	for i := 0; i < len(spec)-1; i++ {
+		switch spec[i] {
+		case "-j", "--jump", "-g", "--goto":
+			return spec[i+1]
+		}
+	}
+
+	return ""
+}
+
+// splitSpec splits a line of iptables -S output into arguments. iptables
+// quotes arguments containing spaces, such as log prefixes and comments.
+func splitSpec(line string) []string {
+	var (
+		args    []string
+		current bytes.Buffer
+		quoted  bool
+		started bool
+	)
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && quoted && i+1 < len(line):
+			i++
+			current.WriteByte(line[i])
+		case c == '"':
+			quoted = !quoted
+			started = true
+		case (c == ' ' || c == '\t') && !quoted:
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteByte(c)
+			started = true
+		}
+	}
+
+	if started {
+		args = append(args, current.String())
+	}
+
+	return args
+}
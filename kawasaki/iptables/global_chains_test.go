@@ -3,10 +3,13 @@ package iptables_test
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	"code.cloudfoundry.org/guardian/kawasaki/iptables"
+	"code.cloudfoundry.org/lager/lagertest"
 	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
 	. "github.com/cloudfoundry/gunk/command_runner/fake_command_runner/matchers"
 	. "github.com/onsi/ginkgo"
@@ -15,51 +18,97 @@ import (
 
 var _ = Describe("Setup", func() {
 	var (
-		fakeRunner   *fake_command_runner.FakeCommandRunner
-		denyNetworks []string
-		starter      *iptables.Starter
+		fakeRunner    *fake_command_runner.FakeCommandRunner
+		allowNetworks []string
+		denyNetworks  []string
+		poolNetworks  []iptables.PoolNetworks
+		procDir       string
+		controller    *iptables.IPTablesController
+		starter       *iptables.Starter
 	)
 
 	BeforeEach(func() {
+		var err error
+
 		fakeRunner = fake_command_runner.New()
+		allowNetworks = nil
+		denyNetworks = nil
+		poolNetworks = nil
+		controller = iptables.New("/sbin/iptables", "/sbin/iptables-restore", fakeRunner, "prefix-")
+
+		procDir, err = ioutil.TempDir("", "proc")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(procDir, "net"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(procDir, "sys", "net", "ipv4"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(procDir, "sys", "net", "ipv6", "conf", "all"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(procDir, "net", "route"), []byte(
+			"Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n"+
+				"eth1\t0000000A\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0\n"+
+				"eth0\t00000000\t0100000A\t0003\t0\t0\t0\t00000000\t0\t0\t0\n",
+		), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(procDir)).To(Succeed())
 	})
 
 	JustBeforeEach(func() {
 		starter = iptables.NewStarter(
-			iptables.New("/sbin/iptables", fakeRunner, "prefix-"),
+			lagertest.NewTestLogger("test"),
+			controller,
 			true,
 			"the-nic-prefix",
+			allowNetworks,
 			denyNetworks,
+			poolNetworks,
+			procDir,
 		)
 	})
 
+	iptablesSpec := func(table string, args ...string) fake_command_runner.CommandSpec {
+		return fake_command_runner.CommandSpec{
+			Path: "/sbin/iptables",
+			Args: append([]string{"--wait", "--table", table}, args...),
+		}
+	}
+
+	listReturns := func(table, chain, rules string) {
+		args := []string{"-S"}
+		if chain != "" {
+			args = append(args, chain)
+		}
+
+		fakeRunner.WhenRunning(iptablesSpec(table, args...), func(cmd *exec.Cmd) error {
+			_, err := cmd.Stdout.Write([]byte(rules))
+			return err
+		})
+	}
+
 	itSetsUpGlobalChains := func() {
-		Expect(fakeRunner).To(HaveExecutedSerially(fake_command_runner.CommandSpec{
-			Path: "bash",
-			Args: []string{"-c", iptables.SetupScript},
-			Env: []string{
-				fmt.Sprintf("PATH=%s", os.Getenv("PATH")),
-				"ACTION=setup",
-
-				"GARDEN_IPTABLES_BIN=/sbin/iptables",
-				"GARDEN_IPTABLES_FILTER_INPUT_CHAIN=prefix-input",
-				"GARDEN_IPTABLES_FILTER_FORWARD_CHAIN=prefix-forward",
-				"GARDEN_IPTABLES_FILTER_DEFAULT_CHAIN=prefix-default",
-				"GARDEN_IPTABLES_FILTER_INSTANCE_PREFIX=prefix-instance-",
-				"GARDEN_IPTABLES_NAT_PREROUTING_CHAIN=prefix-prerouting",
-				"GARDEN_IPTABLES_NAT_POSTROUTING_CHAIN=prefix-postrouting",
-				"GARDEN_IPTABLES_NAT_INSTANCE_PREFIX=prefix-instance-",
-				"GARDEN_NETWORK_INTERFACE_PREFIX=the-nic-prefix",
-				"GARDEN_IPTABLES_ALLOW_HOST_ACCESS=true",
-			},
-		}))
+		Expect(fakeRunner).To(HaveExecutedSerially(
+			iptablesSpec("filter", "-N", "prefix-input"),
+			iptablesSpec("filter", "-N", "prefix-forward"),
+			iptablesSpec("filter", "-N", "prefix-default"),
+			iptablesSpec("filter", "-A", "prefix-input", "--in-interface", "eth0", "--jump", "ACCEPT"),
+			iptablesSpec("filter", "-A", "prefix-input", "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "--jump", "ACCEPT"),
+			iptablesSpec("filter", "-A", "prefix-input", "--jump", "ACCEPT"),
+			iptablesSpec("filter", "-A", "INPUT", "--in-interface", "the-nic-prefix+", "--jump", "prefix-input"),
+			iptablesSpec("filter", "-A", "prefix-forward", "--in-interface", "eth0", "--jump", "ACCEPT"),
+			iptablesSpec("filter", "-A", "prefix-forward", "--jump", "DROP"),
+			iptablesSpec("filter", "-A", "FORWARD", "--in-interface", "the-nic-prefix+", "--jump", "prefix-forward"),
+			iptablesSpec("filter", "-A", "prefix-default", "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "--jump", "ACCEPT"),
+			iptablesSpec("nat", "-N", "prefix-prerouting"),
+			iptablesSpec("nat", "-N", "prefix-postrouting"),
+		))
+
+		forwarding, err := ioutil.ReadFile(filepath.Join(procDir, "sys", "net", "ipv4", "ip_forward"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(forwarding)).To(Equal("1"))
 	}
 
 	itDoesNotSetUpGlobalChains := func() {
-		Expect(fakeRunner).NotTo(HaveExecutedSerially(fake_command_runner.CommandSpec{
-			Path: "bash",
-			Args: []string{"-c", iptables.SetupScript},
-		}))
+		Expect(fakeRunner).NotTo(HaveExecutedSerially(iptablesSpec("filter", "-N", "prefix-input")))
+		Expect(filepath.Join(procDir, "sys", "net", "ipv4", "ip_forward")).NotTo(BeAnExistingFile())
 	}
 
 	itRejectsNetwork := func(network string) {
@@ -94,25 +143,118 @@ var _ = Describe("Setup", func() {
 				})
 			})
 
-			It("runs the setup script, passing the environment variables", func() {
+			It("sets up the global chains and enables ip forwarding", func() {
 				Expect(starter.Start()).To(Succeed())
 
 				itSetsUpGlobalChains()
 			})
 
-			Context("when running the setup script fails", func() {
+			Context("when the base chains are not yet bound to the nat chains", func() {
 				BeforeEach(func() {
-					fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
-						Path: "bash",
-						Args: []string{"-c", iptables.SetupScript},
-					}, func(cmd *exec.Cmd) error {
+					for _, rule := range [][]string{
+						{"PREROUTING", "--jump", "prefix-prerouting"},
+						{"OUTPUT", "--out-interface", "lo", "--jump", "prefix-prerouting"},
+						{"POSTROUTING", "--jump", "prefix-postrouting"},
+					} {
+						fakeRunner.WhenRunning(iptablesSpec("nat", append([]string{"-C"}, rule...)...), func(*exec.Cmd) error {
+							return errors.New("exit status 1")
+						})
+					}
+				})
+
+				It("binds them", func() {
+					Expect(starter.Start()).To(Succeed())
+
+					Expect(fakeRunner).To(HaveExecutedSerially(
+						iptablesSpec("nat", "-A", "PREROUTING", "--jump", "prefix-prerouting"),
+						iptablesSpec("nat", "-A", "OUTPUT", "--out-interface", "lo", "--jump", "prefix-prerouting"),
+						iptablesSpec("nat", "-A", "POSTROUTING", "--jump", "prefix-postrouting"),
+					))
+				})
+			})
+
+			Context("when the base chains are already bound to the nat chains", func() {
+				It("does not bind them again", func() {
+					Expect(starter.Start()).To(Succeed())
+
+					Expect(fakeRunner).NotTo(HaveExecutedSerially(iptablesSpec("nat", "-A", "PREROUTING", "--jump", "prefix-prerouting")))
+					Expect(fakeRunner).NotTo(HaveExecutedSerially(iptablesSpec("nat", "-A", "POSTROUTING", "--jump", "prefix-postrouting")))
+				})
+			})
+
+			Context("when chains were left behind by a previous run", func() {
+				BeforeEach(func() {
+					listReturns("filter", "", "-P INPUT ACCEPT\n"+
+						"-N DOCKER\n"+
+						"-N garden-dispatch\n"+
+						"-N prefix-forward\n"+
+						"-N prefix-instance-abc\n"+
+						"-N prefix-instance-abc-log\n"+
+						"-N prefix-input\n"+
+						"-A prefix-instance-abc-log -m conntrack --ctstate NEW -j LOG --log-prefix \"some handle\"\n")
+					listReturns("filter", "INPUT", "-P INPUT ACCEPT\n"+
+						"-A INPUT -j garden-dispatch\n"+
+						"-A INPUT -i the-nic-prefix+ -j prefix-input\n"+
+						"-A INPUT -i docker0 -j DOCKER\n")
+					listReturns("nat", "prefix-prerouting", "-N prefix-prerouting\n"+
+						"-A prefix-prerouting -j prefix-instance-abc\n")
+				})
+
+				It("tears down only the chains with the prefix before setting up", func() {
+					Expect(starter.Start()).To(Succeed())
+
+					Expect(fakeRunner).To(HaveExecutedSerially(
+						iptablesSpec("filter", "-D", "INPUT", "-j", "garden-dispatch"),
+						iptablesSpec("filter", "-F", "garden-dispatch"),
+						iptablesSpec("filter", "-X", "garden-dispatch"),
+						iptablesSpec("filter", "-D", "INPUT", "-i", "the-nic-prefix+", "-j", "prefix-input"),
+						iptablesSpec("filter", "-F", "prefix-forward"),
+						iptablesSpec("filter", "-F", "prefix-instance-abc"),
+						iptablesSpec("filter", "-F", "prefix-instance-abc-log"),
+						iptablesSpec("filter", "-F", "prefix-input"),
+						iptablesSpec("filter", "-X", "prefix-instance-abc"),
+						iptablesSpec("filter", "-X", "prefix-instance-abc-log"),
+						iptablesSpec("filter", "-X", "prefix-input"),
+						iptablesSpec("nat", "-D", "prefix-prerouting", "-j", "prefix-instance-abc"),
+					))
+
+					Expect(fakeRunner).NotTo(HaveExecutedSerially(iptablesSpec("filter", "-D", "INPUT", "-i", "docker0", "-j", "DOCKER")))
+					Expect(fakeRunner).NotTo(HaveExecutedSerially(iptablesSpec("filter", "-X", "DOCKER")))
+				})
+			})
+
+			Context("when there is no default route", func() {
+				BeforeEach(func() {
+					Expect(ioutil.WriteFile(filepath.Join(procDir, "net", "route"), []byte(
+						"Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n"+
+							"eth1\t0000000A\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0\n",
+					), 0644)).To(Succeed())
+				})
+
+				It("returns an error without changing any chains", func() {
+					Expect(starter.Start()).To(MatchError(ContainSubstring("no default route found")))
+					Expect(fakeRunner.ExecutedCommands()).To(HaveLen(1))
+				})
+			})
+
+			Context("when a setup step fails", func() {
+				BeforeEach(func() {
+					fakeRunner.WhenRunning(iptablesSpec("filter", "-N", "prefix-input"), func(cmd *exec.Cmd) error {
 						cmd.Stderr.Write([]byte("oh no!"))
 						return fmt.Errorf("exit status something")
 					})
 				})
 
-				It("returns the error", func() {
-					Expect(starter.Start()).To(MatchError(ContainSubstring("oh no!")))
+				It("returns the error, naming the step", func() {
+					err := starter.Start()
+					Expect(err).To(MatchError(ContainSubstring("setup-filter-chains")))
+					Expect(err).To(MatchError(ContainSubstring("oh no!")))
+				})
+
+				It("does not run the later steps", func() {
+					starter.Start()
+
+					Expect(fakeRunner).NotTo(HaveExecutedSerially(iptablesSpec("nat", "-N", "prefix-prerouting")))
 				})
 			})
 
@@ -146,7 +288,10 @@ var _ = Describe("Setup", func() {
 					It("does not try to apply the rest of the deny rules", func() {
 						starter.Start()
 
-						Expect(fakeRunner.ExecutedCommands()).To(HaveLen(5))
+						Expect(fakeRunner).NotTo(HaveExecutedSerially(fake_command_runner.CommandSpec{
+							Path: "/sbin/iptables",
+							Args: []string{"-w", "-A", "prefix-default", "--destination", "5.6.7.8/33", "--jump", "REJECT"},
+						}))
 					})
 				})
 			})
@@ -162,7 +307,7 @@ var _ = Describe("Setup", func() {
 				})
 			})
 
-			It("does not run the setup script", func() {
+			It("does not set up the global chains", func() {
 				Expect(starter.Start()).To(Succeed())
 
 				itDoesNotSetUpGlobalChains()
@@ -189,6 +334,69 @@ var _ = Describe("Setup", func() {
 					itRejectsNetwork("8.7.6.5/33")
 				})
 
+				Context("and some networks are allowed", func() {
+					BeforeEach(func() {
+						allowNetworks = []string{"4.3.2.128/25"}
+					})
+
+					It("accepts the allowed networks before rejecting the denied ones", func() {
+						Expect(starter.Start()).To(Succeed())
+
+						Expect(fakeRunner).To(HaveExecutedSerially(
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-F", "prefix-default"},
+							},
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-A", "prefix-default", "--destination", "4.3.2.128/25", "--jump", "ACCEPT"},
+							},
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-A", "prefix-default", "--destination", "4.3.2.1/11", "--jump", "REJECT"},
+							},
+						))
+					})
+				})
+
+				Context("and a network pool has its own networks", func() {
+					BeforeEach(func() {
+						allowNetworks = []string{"4.3.2.128/25"}
+						poolNetworks = []iptables.PoolNetworks{{
+							Subnet:        "10.10.0.0/22",
+							AllowNetworks: []string{"4.3.2.0/28"},
+							DenyNetworks:  []string{"9.9.0.0/16"},
+						}}
+					})
+
+					It("applies them to traffic from the pool ahead of the global networks", func() {
+						Expect(starter.Start()).To(Succeed())
+
+						Expect(fakeRunner).To(HaveExecutedSerially(
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-F", "prefix-default"},
+							},
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-A", "prefix-default", "--source", "10.10.0.0/22", "--destination", "4.3.2.0/28", "--jump", "ACCEPT"},
+							},
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-A", "prefix-default", "--source", "10.10.0.0/22", "--destination", "9.9.0.0/16", "--jump", "REJECT"},
+							},
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-A", "prefix-default", "--destination", "4.3.2.128/25", "--jump", "ACCEPT"},
+							},
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-A", "prefix-default", "--destination", "4.3.2.1/11", "--jump", "REJECT"},
+							},
+						))
+					})
+				})
+
 				Context("when resetting deny netoworks fail", func() {
 					Context("when flushing the chain fails", func() {
 						BeforeEach(func() {
@@ -227,4 +435,70 @@ var _ = Describe("Setup", func() {
 			})
 		})
 	})
+
+	Describe("an ip6tables starter", func() {
+		ip6tablesSpec := func(table string, args ...string) fake_command_runner.CommandSpec {
+			return fake_command_runner.CommandSpec{
+				Path: "/sbin/ip6tables",
+				Args: append([]string{"--wait", "--table", table}, args...),
+			}
+		}
+
+		BeforeEach(func() {
+			controller = iptables.NewIPv6("/sbin/ip6tables", "/sbin/ip6tables-restore", fakeRunner, "prefix-")
+			allowNetworks = []string{"10.0.0.0/24", "fd00:1::/64"}
+			denyNetworks = []string{"10.0.0.0/8", "fd00::/16"}
+
+			fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+				Path: "/sbin/ip6tables",
+				Args: []string{"-w", "-L", "prefix-input"},
+			}, func(_ *exec.Cmd) error {
+				return errors.New("exit status 1")
+			})
+		})
+
+		It("enables ipv6 forwarding rather than ipv4 forwarding", func() {
+			Expect(starter.Start()).To(Succeed())
+
+			forwarding, err := ioutil.ReadFile(filepath.Join(procDir, "sys", "net", "ipv6", "conf", "all", "forwarding"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(forwarding)).To(Equal("1"))
+			Expect(filepath.Join(procDir, "sys", "net", "ipv4", "ip_forward")).NotTo(BeAnExistingFile())
+		})
+
+		Context("when host access is not allowed", func() {
+			JustBeforeEach(func() {
+				starter = iptables.NewStarter(lagertest.NewTestLogger("test"), controller, false, "the-nic-prefix", allowNetworks, denyNetworks, poolNetworks, procDir)
+			})
+
+			It("rejects host access with an icmpv6 error", func() {
+				Expect(starter.Start()).To(Succeed())
+
+				Expect(fakeRunner).To(HaveExecutedSerially(
+					ip6tablesSpec("filter", "-A", "prefix-input", "--jump", "REJECT", "--reject-with", "icmp6-adm-prohibited"),
+				))
+			})
+		})
+
+		It("only applies the ipv6 allow and deny networks", func() {
+			Expect(starter.Start()).To(Succeed())
+
+			Expect(fakeRunner).To(HaveExecutedSerially(
+				fake_command_runner.CommandSpec{
+					Path: "/sbin/ip6tables",
+					Args: []string{"-w", "-A", "prefix-default", "--destination", "fd00:1::/64", "--jump", "ACCEPT"},
+				},
+				fake_command_runner.CommandSpec{
+					Path: "/sbin/ip6tables",
+					Args: []string{"-w", "-A", "prefix-default", "--destination", "fd00::/16", "--jump", "REJECT"},
+				},
+			))
+
+			for _, network := range []string{"10.0.0.0/24", "10.0.0.0/8"} {
+				for _, cmd := range fakeRunner.ExecutedCommands() {
+					Expect(cmd.Args).NotTo(ContainElement(network))
+				}
+			}
+		})
+	})
 })
@@ -40,7 +40,7 @@ var _ = Describe("Create", func() {
 		Expect(err).NotTo(HaveOccurred())
 
 		creator = iptables.NewInstanceChainCreator(
-			iptables.New("/sbin/iptables", fakeRunner, "prefix-"),
+			iptables.New("/sbin/iptables", "/sbin/iptables-restore", fakeRunner, "prefix-"),
 		)
 	})
 
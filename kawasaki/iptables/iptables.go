@@ -1,9 +1,11 @@
 package iptables
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"os/exec"
+	"strings"
 
 	"code.cloudfoundry.org/garden"
 	"github.com/cloudfoundry/gunk/command_runner"
@@ -27,24 +29,32 @@ type IPTables interface {
 	DeleteChain(table, chain string) error
 	FlushChain(table, chain string) error
 	DeleteChainReferences(table, targetChain, referencedChain string) error
+	AppendRule(chain string, rule Rule) error
 	PrependRule(chain string, rule Rule) error
+	BulkPrependRules(chain string, rules []Rule) error
+	DeleteRule(chain string, rule Rule) error
 	InstanceChain(instanceId string) string
+	InstanceChains() ([]string, error)
 }
 
 type IPTablesController struct {
 	runner                                                                                         command_runner.CommandRunner
-	binPath                                                                                        string
+	binPath, restoreBinPath                                                                        string
 	preroutingChain, postroutingChain, inputChain, forwardChain, defaultChain, instanceChainPrefix string
+
+	// ipv6 is set when the binaries are ip6tables and ip6tables-restore
+	ipv6 bool
 }
 
 type Chains struct {
 	Prerouting, Postrouting, Input, Forward, Default string
 }
 
-func New(binPath string, runner command_runner.CommandRunner, chainPrefix string) *IPTablesController {
+func New(binPath, restoreBinPath string, runner command_runner.CommandRunner, chainPrefix string) *IPTablesController {
 	return &IPTablesController{
-		runner:  runner,
-		binPath: binPath,
+		runner:         runner,
+		binPath:        binPath,
+		restoreBinPath: restoreBinPath,
 
 		preroutingChain:     chainPrefix + "prerouting",
 		postroutingChain:    chainPrefix + "postrouting",
@@ -55,6 +65,14 @@ func New(binPath string, runner command_runner.CommandRunner, chainPrefix string
 	}
 }
 
+// NewIPv6 returns a controller for ip6tables, which manages chains named
+// exactly as the IPv4 ones
+func NewIPv6(binPath, restoreBinPath string, runner command_runner.CommandRunner, chainPrefix string) *IPTablesController {
+	iptables := New(binPath, restoreBinPath, runner, chainPrefix)
+	iptables.ipv6 = true
+	return iptables
+}
+
 func (iptables *IPTablesController) CreateChain(table, chain string) error {
 	return iptables.run("create-instance-chains", exec.Command(iptables.binPath, "--wait", "--table", table, "-N", chain))
 }
@@ -83,14 +101,71 @@ func (iptables *IPTablesController) DeleteChainReferences(table, targetChain, re
 	return iptables.run("delete-referenced-chains", exec.Command("sh", "-c", shellCmd))
 }
 
+func (iptables *IPTablesController) AppendRule(chain string, rule Rule) error {
+	return iptables.run("append", exec.Command(iptables.binPath, append([]string{"-w", "-A", chain}, rule.Flags(chain)...)...))
+}
+
 func (iptables *IPTablesController) PrependRule(chain string, rule Rule) error {
 	return iptables.run("prepend", exec.Command(iptables.binPath, append([]string{"-w", "-I", chain, "1"}, rule.Flags(chain)...)...))
 }
 
+// BulkPrependRules prepends all of the rules to the chain in the filter table
+// with a single iptables-restore, so either all of them are applied or none are
+func (iptables *IPTablesController) BulkPrependRules(chain string, rules []Rule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var input bytes.Buffer
+	input.WriteString("*filter\n")
+	for _, rule := range rules {
+		input.WriteString(strings.Join(append([]string{"-I", chain, "1"}, rule.Flags(chain)...), " "))
+		input.WriteString("\n")
+	}
+	input.WriteString("COMMIT\n")
+
+	cmd := exec.Command(iptables.restoreBinPath, "-w", "--noflush")
+	cmd.Stdin = &input
+	return iptables.run("bulk-prepend", cmd)
+}
+
+func (iptables *IPTablesController) DeleteRule(chain string, rule Rule) error {
+	return iptables.run("delete", exec.Command(iptables.binPath, append([]string{"-w", "-D", chain}, rule.Flags(chain)...)...))
+}
+
 func (iptables *IPTablesController) InstanceChain(instanceId string) string {
 	return iptables.instanceChainPrefix + instanceId
 }
 
+// InstanceChains returns the instance ids of the instance chains in the filter table
+func (iptables *IPTablesController) InstanceChains() ([]string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command(iptables.binPath, "--wait", "-S")
+	cmd.Stdout = &stdout
+	if err := iptables.run("list-instance-chains", cmd); err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[0] != "-N" || !strings.HasPrefix(fields[1], iptables.instanceChainPrefix) {
+			continue
+		}
+
+		// each instance also has a logging chain, named <instance chain>-log
+		id := strings.TrimPrefix(fields[1], iptables.instanceChainPrefix)
+		if strings.HasSuffix(id, "-log") {
+			continue
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, scanner.Err()
+}
+
 func (iptables *IPTablesController) run(action string, cmd *exec.Cmd) error {
 	var buff bytes.Buffer
 	cmd.Stderr = &buff
@@ -101,7 +176,3 @@ func (iptables *IPTablesController) run(action string, cmd *exec.Cmd) error {
 
 	return nil
 }
-
-func (iptables *IPTablesController) appendRule(chain string, rule Rule) error {
-	return iptables.run("append", exec.Command(iptables.binPath, append([]string{"-w", "-A", chain}, rule.Flags(chain)...)...))
-}
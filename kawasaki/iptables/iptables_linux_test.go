@@ -7,6 +7,7 @@ import (
 	"code.cloudfoundry.org/guardian/kawasaki/iptables"
 	fakes "code.cloudfoundry.org/guardian/kawasaki/iptables/iptablesfakes"
 	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
+	. "github.com/cloudfoundry/gunk/command_runner/fake_command_runner/matchers"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
@@ -32,7 +33,7 @@ var _ = Describe("IPTables controller", func() {
 		)
 
 		prefix = fmt.Sprintf("g-%d", GinkgoParallelNode())
-		iptablesController = iptables.New("/sbin/iptables", fakeRunner, prefix)
+		iptablesController = iptables.New("/sbin/iptables", "/sbin/iptables-restore", fakeRunner, prefix)
 	})
 
 	AfterEach(func() {
@@ -69,6 +70,26 @@ var _ = Describe("IPTables controller", func() {
 		})
 	})
 
+	Describe("AppendRule", func() {
+		It("appends the rule", func() {
+			fakeTCPRule := new(fakes.FakeRule)
+			fakeTCPRule.FlagsReturns([]string{"--protocol", "tcp"})
+			fakeUDPRule := new(fakes.FakeRule)
+			fakeUDPRule.FlagsReturns([]string{"--protocol", "udp"})
+
+			Expect(iptablesController.CreateChain("filter", "test-chain")).To(Succeed())
+
+			Expect(iptablesController.AppendRule("test-chain", fakeTCPRule)).To(Succeed())
+			Expect(iptablesController.AppendRule("test-chain", fakeUDPRule)).To(Succeed())
+
+			buff := gbytes.NewBuffer()
+			sess, err := gexec.Start(wrapCmdInNs(netnsName, exec.Command("iptables", "-S", "test-chain")), buff, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(sess).Should(gexec.Exit(0))
+			Expect(buff).To(gbytes.Say("-A test-chain -p tcp\n-A test-chain -p udp"))
+		})
+	})
+
 	Describe("PrependRule", func() {
 		It("prepends the rule", func() {
 			fakeTCPRule := new(fakes.FakeRule)
@@ -96,6 +117,83 @@ var _ = Describe("IPTables controller", func() {
 		})
 	})
 
+	Describe("BulkPrependRules", func() {
+		It("prepends the rules in order", func() {
+			fakeTCPRule := new(fakes.FakeRule)
+			fakeTCPRule.FlagsReturns([]string{"--protocol", "tcp"})
+			fakeUDPRule := new(fakes.FakeRule)
+			fakeUDPRule.FlagsReturns([]string{"--protocol", "udp"})
+
+			Expect(iptablesController.CreateChain("filter", "test-chain")).To(Succeed())
+			Expect(iptablesController.BulkPrependRules("test-chain", []iptables.Rule{fakeTCPRule, fakeUDPRule})).To(Succeed())
+
+			buff := gbytes.NewBuffer()
+			sess, err := gexec.Start(wrapCmdInNs(netnsName, exec.Command("iptables", "-S", "test-chain")), buff, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(sess).Should(gexec.Exit(0))
+			Expect(buff).To(gbytes.Say("-A test-chain -p udp\n-A test-chain -p tcp"))
+		})
+
+		It("waits for the xtables lock rather than failing when it is held", func() {
+			fakeTCPRule := new(fakes.FakeRule)
+			fakeTCPRule.FlagsReturns([]string{"--protocol", "tcp"})
+
+			recordingRunner := fake_command_runner.New()
+			controller := iptables.New("/sbin/iptables", "/sbin/iptables-restore", recordingRunner, prefix)
+			Expect(controller.BulkPrependRules("test-chain", []iptables.Rule{fakeTCPRule})).To(Succeed())
+
+			Expect(recordingRunner).To(HaveExecutedSerially(fake_command_runner.CommandSpec{
+				Path: "/sbin/iptables-restore",
+				Args: []string{"-w", "--noflush"},
+			}))
+		})
+
+		It("applies none of the rules when one of them is invalid", func() {
+			fakeTCPRule := new(fakes.FakeRule)
+			fakeTCPRule.FlagsReturns([]string{"--protocol", "tcp"})
+			fakeBadRule := new(fakes.FakeRule)
+			fakeBadRule.FlagsReturns([]string{"--protocol", "banana"})
+
+			Expect(iptablesController.CreateChain("filter", "test-chain")).To(Succeed())
+			Expect(iptablesController.BulkPrependRules("test-chain", []iptables.Rule{fakeTCPRule, fakeBadRule})).NotTo(Succeed())
+
+			buff := gbytes.NewBuffer()
+			sess, err := gexec.Start(wrapCmdInNs(netnsName, exec.Command("iptables", "-S", "test-chain")), buff, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(sess).Should(gexec.Exit(0))
+			Expect(buff).NotTo(gbytes.Say("-p tcp"))
+		})
+	})
+
+	Describe("DeleteRule", func() {
+		It("deletes the rule", func() {
+			fakeTCPRule := new(fakes.FakeRule)
+			fakeTCPRule.FlagsReturns([]string{"--protocol", "tcp"})
+			fakeUDPRule := new(fakes.FakeRule)
+			fakeUDPRule.FlagsReturns([]string{"--protocol", "udp"})
+
+			Expect(iptablesController.CreateChain("filter", "test-chain")).To(Succeed())
+			Expect(iptablesController.PrependRule("test-chain", fakeTCPRule)).To(Succeed())
+			Expect(iptablesController.PrependRule("test-chain", fakeUDPRule)).To(Succeed())
+
+			Expect(iptablesController.DeleteRule("test-chain", fakeTCPRule)).To(Succeed())
+
+			buff := gbytes.NewBuffer()
+			sess, err := gexec.Start(wrapCmdInNs(netnsName, exec.Command("iptables", "-S", "test-chain")), buff, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(sess).Should(gexec.Exit(0))
+			Expect(buff).NotTo(gbytes.Say("-p tcp"))
+		})
+
+		It("returns an error when the rule does not exist", func() {
+			fakeRule := new(fakes.FakeRule)
+			fakeRule.FlagsReturns([]string{"--protocol", "tcp"})
+
+			Expect(iptablesController.CreateChain("filter", "test-chain")).To(Succeed())
+			Expect(iptablesController.DeleteRule("test-chain", fakeRule)).NotTo(Succeed())
+		})
+	})
+
 	Describe("DeleteChain", func() {
 		BeforeEach(func() {
 			Expect(iptablesController.CreateChain("filter", "test-chain")).To(Succeed())
@@ -178,6 +276,18 @@ var _ = Describe("IPTables controller", func() {
 		})
 	})
 
+	Describe("InstanceChains", func() {
+		BeforeEach(func() {
+			Expect(iptablesController.CreateChain("filter", iptablesController.InstanceChain("abc"))).To(Succeed())
+			Expect(iptablesController.CreateChain("filter", iptablesController.InstanceChain("abc")+"-log")).To(Succeed())
+			Expect(iptablesController.CreateChain("filter", "test-chain")).To(Succeed())
+		})
+
+		It("returns the instance ids of the instance chains", func() {
+			Expect(iptablesController.InstanceChains()).To(Equal([]string{"abc"}))
+		})
+	})
+
 	Describe("DeleteChainReferences", func() {
 		var table string
 
@@ -45,6 +45,15 @@ type FakeIPTables struct {
 	deleteChainReferencesReturns struct {
 		result1 error
 	}
+	AppendRuleStub        func(chain string, rule iptables.Rule) error
+	appendRuleMutex       sync.RWMutex
+	appendRuleArgsForCall []struct {
+		chain string
+		rule  iptables.Rule
+	}
+	appendRuleReturns struct {
+		result1 error
+	}
 	PrependRuleStub        func(chain string, rule iptables.Rule) error
 	prependRuleMutex       sync.RWMutex
 	prependRuleArgsForCall []struct {
@@ -54,6 +63,24 @@ type FakeIPTables struct {
 	prependRuleReturns struct {
 		result1 error
 	}
+	BulkPrependRulesStub        func(chain string, rules []iptables.Rule) error
+	bulkPrependRulesMutex       sync.RWMutex
+	bulkPrependRulesArgsForCall []struct {
+		chain string
+		rules []iptables.Rule
+	}
+	bulkPrependRulesReturns struct {
+		result1 error
+	}
+	DeleteRuleStub        func(chain string, rule iptables.Rule) error
+	deleteRuleMutex       sync.RWMutex
+	deleteRuleArgsForCall []struct {
+		chain string
+		rule  iptables.Rule
+	}
+	deleteRuleReturns struct {
+		result1 error
+	}
 	InstanceChainStub        func(instanceId string) string
 	instanceChainMutex       sync.RWMutex
 	instanceChainArgsForCall []struct {
@@ -62,6 +89,13 @@ type FakeIPTables struct {
 	instanceChainReturns struct {
 		result1 string
 	}
+	InstanceChainsStub        func() ([]string, error)
+	instanceChainsMutex       sync.RWMutex
+	instanceChainsArgsForCall []struct{}
+	instanceChainsReturns     struct {
+		result1 []string
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -203,6 +237,40 @@ func (fake *FakeIPTables) DeleteChainReferencesReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIPTables) AppendRule(chain string, rule iptables.Rule) error {
+	fake.appendRuleMutex.Lock()
+	fake.appendRuleArgsForCall = append(fake.appendRuleArgsForCall, struct {
+		chain string
+		rule  iptables.Rule
+	}{chain, rule})
+	fake.recordInvocation("AppendRule", []interface{}{chain, rule})
+	fake.appendRuleMutex.Unlock()
+	if fake.AppendRuleStub != nil {
+		return fake.AppendRuleStub(chain, rule)
+	} else {
+		return fake.appendRuleReturns.result1
+	}
+}
+
+func (fake *FakeIPTables) AppendRuleCallCount() int {
+	fake.appendRuleMutex.RLock()
+	defer fake.appendRuleMutex.RUnlock()
+	return len(fake.appendRuleArgsForCall)
+}
+
+func (fake *FakeIPTables) AppendRuleArgsForCall(i int) (string, iptables.Rule) {
+	fake.appendRuleMutex.RLock()
+	defer fake.appendRuleMutex.RUnlock()
+	return fake.appendRuleArgsForCall[i].chain, fake.appendRuleArgsForCall[i].rule
+}
+
+func (fake *FakeIPTables) AppendRuleReturns(result1 error) {
+	fake.AppendRuleStub = nil
+	fake.appendRuleReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIPTables) PrependRule(chain string, rule iptables.Rule) error {
 	fake.prependRuleMutex.Lock()
 	fake.prependRuleArgsForCall = append(fake.prependRuleArgsForCall, struct {
@@ -237,6 +305,79 @@ func (fake *FakeIPTables) PrependRuleReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIPTables) BulkPrependRules(chain string, rules []iptables.Rule) error {
+	var rulesCopy []iptables.Rule
+	if rules != nil {
+		rulesCopy = make([]iptables.Rule, len(rules))
+		copy(rulesCopy, rules)
+	}
+	fake.bulkPrependRulesMutex.Lock()
+	fake.bulkPrependRulesArgsForCall = append(fake.bulkPrependRulesArgsForCall, struct {
+		chain string
+		rules []iptables.Rule
+	}{chain, rulesCopy})
+	fake.recordInvocation("BulkPrependRules", []interface{}{chain, rulesCopy})
+	fake.bulkPrependRulesMutex.Unlock()
+	if fake.BulkPrependRulesStub != nil {
+		return fake.BulkPrependRulesStub(chain, rules)
+	} else {
+		return fake.bulkPrependRulesReturns.result1
+	}
+}
+
+func (fake *FakeIPTables) BulkPrependRulesCallCount() int {
+	fake.bulkPrependRulesMutex.RLock()
+	defer fake.bulkPrependRulesMutex.RUnlock()
+	return len(fake.bulkPrependRulesArgsForCall)
+}
+
+func (fake *FakeIPTables) BulkPrependRulesArgsForCall(i int) (string, []iptables.Rule) {
+	fake.bulkPrependRulesMutex.RLock()
+	defer fake.bulkPrependRulesMutex.RUnlock()
+	return fake.bulkPrependRulesArgsForCall[i].chain, fake.bulkPrependRulesArgsForCall[i].rules
+}
+
+func (fake *FakeIPTables) BulkPrependRulesReturns(result1 error) {
+	fake.BulkPrependRulesStub = nil
+	fake.bulkPrependRulesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIPTables) DeleteRule(chain string, rule iptables.Rule) error {
+	fake.deleteRuleMutex.Lock()
+	fake.deleteRuleArgsForCall = append(fake.deleteRuleArgsForCall, struct {
+		chain string
+		rule  iptables.Rule
+	}{chain, rule})
+	fake.recordInvocation("DeleteRule", []interface{}{chain, rule})
+	fake.deleteRuleMutex.Unlock()
+	if fake.DeleteRuleStub != nil {
+		return fake.DeleteRuleStub(chain, rule)
+	} else {
+		return fake.deleteRuleReturns.result1
+	}
+}
+
+func (fake *FakeIPTables) DeleteRuleCallCount() int {
+	fake.deleteRuleMutex.RLock()
+	defer fake.deleteRuleMutex.RUnlock()
+	return len(fake.deleteRuleArgsForCall)
+}
+
+func (fake *FakeIPTables) DeleteRuleArgsForCall(i int) (string, iptables.Rule) {
+	fake.deleteRuleMutex.RLock()
+	defer fake.deleteRuleMutex.RUnlock()
+	return fake.deleteRuleArgsForCall[i].chain, fake.deleteRuleArgsForCall[i].rule
+}
+
+func (fake *FakeIPTables) DeleteRuleReturns(result1 error) {
+	fake.DeleteRuleStub = nil
+	fake.deleteRuleReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIPTables) InstanceChain(instanceId string) string {
 	fake.instanceChainMutex.Lock()
 	fake.instanceChainArgsForCall = append(fake.instanceChainArgsForCall, struct {
@@ -270,6 +411,32 @@ func (fake *FakeIPTables) InstanceChainReturns(result1 string) {
 	}{result1}
 }
 
+func (fake *FakeIPTables) InstanceChains() ([]string, error) {
+	fake.instanceChainsMutex.Lock()
+	fake.instanceChainsArgsForCall = append(fake.instanceChainsArgsForCall, struct{}{})
+	fake.recordInvocation("InstanceChains", []interface{}{})
+	fake.instanceChainsMutex.Unlock()
+	if fake.InstanceChainsStub != nil {
+		return fake.InstanceChainsStub()
+	} else {
+		return fake.instanceChainsReturns.result1, fake.instanceChainsReturns.result2
+	}
+}
+
+func (fake *FakeIPTables) InstanceChainsCallCount() int {
+	fake.instanceChainsMutex.RLock()
+	defer fake.instanceChainsMutex.RUnlock()
+	return len(fake.instanceChainsArgsForCall)
+}
+
+func (fake *FakeIPTables) InstanceChainsReturns(result1 []string, result2 error) {
+	fake.InstanceChainsStub = nil
+	fake.instanceChainsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIPTables) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -281,10 +448,18 @@ func (fake *FakeIPTables) Invocations() map[string][][]interface{} {
 	defer fake.flushChainMutex.RUnlock()
 	fake.deleteChainReferencesMutex.RLock()
 	defer fake.deleteChainReferencesMutex.RUnlock()
+	fake.appendRuleMutex.RLock()
+	defer fake.appendRuleMutex.RUnlock()
 	fake.prependRuleMutex.RLock()
 	defer fake.prependRuleMutex.RUnlock()
+	fake.bulkPrependRulesMutex.RLock()
+	defer fake.bulkPrependRulesMutex.RUnlock()
+	fake.deleteRuleMutex.RLock()
+	defer fake.deleteRuleMutex.RUnlock()
 	fake.instanceChainMutex.RLock()
 	defer fake.instanceChainMutex.RUnlock()
+	fake.instanceChainsMutex.RLock()
+	defer fake.instanceChainsMutex.RUnlock()
 	return fake.invocations
 }
 
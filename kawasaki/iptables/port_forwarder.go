@@ -3,23 +3,54 @@ package iptables
 import "code.cloudfoundry.org/guardian/kawasaki"
 
 type PortForwarder struct {
-	iptables *IPTablesController
+	iptables IPTables
 }
 
-func NewPortForwarder(iptables *IPTablesController) *PortForwarder {
+func NewPortForwarder(iptables IPTables) *PortForwarder {
 	return &PortForwarder{
 		iptables: iptables,
 	}
 }
 
+// Forward adds a NAT rule for each protocol of the spec. If one can not be
+// added those already added are deleted, so that a mapping is never left
+// half forwarded.
 func (p *PortForwarder) Forward(spec kawasaki.PortForwarderSpec) error {
-	return p.iptables.appendRule(
-		p.iptables.InstanceChain(spec.InstanceID),
-		natRule(
-			spec.ExternalIP.String(),
-			spec.FromPort,
-			spec.ContainerIP.String(),
-			spec.ToPort,
-		),
+	chain := p.iptables.InstanceChain(spec.InstanceID)
+
+	var forwarded []string
+	for _, protocol := range spec.Protocol.Protocols() {
+		if err := p.iptables.AppendRule(chain, forwardRule(protocol, spec)); err != nil {
+			for _, forwardedProtocol := range forwarded {
+				p.iptables.DeleteRule(chain, forwardRule(forwardedProtocol, spec))
+			}
+
+			return err
+		}
+
+		forwarded = append(forwarded, protocol)
+	}
+
+	return nil
+}
+
+func (p *PortForwarder) Unforward(spec kawasaki.PortForwarderSpec) error {
+	for _, protocol := range spec.Protocol.Protocols() {
+		err := p.iptables.DeleteRule(p.iptables.InstanceChain(spec.InstanceID), forwardRule(protocol, spec))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func forwardRule(protocol string, spec kawasaki.PortForwarderSpec) Rule {
+	return natRule(
+		protocol,
+		spec.ExternalIP.String(),
+		spec.FromPort,
+		spec.ContainerIP.String(),
+		spec.ToPort,
 	)
 }
@@ -1,7 +1,9 @@
 package iptables_test
 
 import (
+	"errors"
 	"net"
+	"os/exec"
 
 	"code.cloudfoundry.org/guardian/kawasaki"
 	"code.cloudfoundry.org/guardian/kawasaki/iptables"
@@ -21,7 +23,7 @@ var _ = Describe("PortForwarder", func() {
 	BeforeEach(func() {
 		fakeRunner = fake_command_runner.New()
 		forwarder = iptables.NewPortForwarder(
-			iptables.New("/sbin/iptables", fakeRunner, "prefix-"),
+			iptables.New("/sbin/iptables", "/sbin/iptables-restore", fakeRunner, "prefix-"),
 		)
 	})
 
@@ -50,4 +52,125 @@ var _ = Describe("PortForwarder", func() {
 			},
 		))
 	})
+
+	It("adds a NAT rule for each protocol to forward", func() {
+		Expect(forwarder.Forward(kawasaki.PortForwarderSpec{
+			InstanceID:  "some-instance",
+			ExternalIP:  net.ParseIP("5.6.7.8"),
+			ContainerIP: net.ParseIP("1.2.3.4"),
+			FromPort:    53,
+			ToPort:      53,
+			Protocol:    kawasaki.NetInTCPUDP,
+		})).To(Succeed())
+
+		ruleFor := func(protocol string) fake_command_runner.CommandSpec {
+			return fake_command_runner.CommandSpec{
+				Path: "/sbin/iptables",
+				Args: []string{
+					"-w",
+					"-A", "prefix-instance-some-instance",
+					"--table", "nat",
+					"--protocol", protocol,
+					"--destination", "5.6.7.8",
+					"--destination-port", "53",
+					"--jump", "DNAT",
+					"--to-destination", "1.2.3.4:53",
+				},
+			}
+		}
+
+		Expect(fakeRunner).To(HaveExecutedSerially(ruleFor("tcp"), ruleFor("udp")))
+	})
+
+	Context("when a later protocol's rule can not be added", func() {
+		ruleFor := func(action, protocol string) fake_command_runner.CommandSpec {
+			return fake_command_runner.CommandSpec{
+				Path: "/sbin/iptables",
+				Args: []string{
+					"-w",
+					action, "prefix-instance-some-instance",
+					"--table", "nat",
+					"--protocol", protocol,
+					"--destination", "5.6.7.8",
+					"--destination-port", "53",
+					"--jump", "DNAT",
+					"--to-destination", "1.2.3.4:53",
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			fakeRunner.WhenRunning(ruleFor("-A", "udp"), func(*exec.Cmd) error {
+				return errors.New("exit status 1")
+			})
+		})
+
+		It("deletes the rules already added and returns an error", func() {
+			Expect(forwarder.Forward(kawasaki.PortForwarderSpec{
+				InstanceID:  "some-instance",
+				ExternalIP:  net.ParseIP("5.6.7.8"),
+				ContainerIP: net.ParseIP("1.2.3.4"),
+				FromPort:    53,
+				ToPort:      53,
+				Protocol:    kawasaki.NetInTCPUDP,
+			})).NotTo(Succeed())
+
+			Expect(fakeRunner).To(HaveExecutedSerially(
+				ruleFor("-A", "tcp"),
+				ruleFor("-A", "udp"),
+				ruleFor("-D", "tcp"),
+			))
+		})
+	})
+
+	Describe("Unforward", func() {
+		It("deletes the NAT rule for each protocol forwarded", func() {
+			Expect(forwarder.Unforward(kawasaki.PortForwarderSpec{
+				InstanceID:  "some-instance",
+				ExternalIP:  net.ParseIP("5.6.7.8"),
+				ContainerIP: net.ParseIP("1.2.3.4"),
+				FromPort:    53,
+				ToPort:      54,
+				Protocol:    kawasaki.NetInTCPUDP,
+			})).To(Succeed())
+
+			ruleFor := func(protocol string) fake_command_runner.CommandSpec {
+				return fake_command_runner.CommandSpec{
+					Path: "/sbin/iptables",
+					Args: []string{
+						"-w",
+						"-D", "prefix-instance-some-instance",
+						"--table", "nat",
+						"--protocol", protocol,
+						"--destination", "5.6.7.8",
+						"--destination-port", "53",
+						"--jump", "DNAT",
+						"--to-destination", "1.2.3.4:54",
+					},
+				}
+			}
+
+			Expect(fakeRunner).To(HaveExecutedSerially(ruleFor("tcp"), ruleFor("udp")))
+		})
+
+		Context("when iptables fails to delete the rule", func() {
+			BeforeEach(func() {
+				fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+					Path: "/sbin/iptables",
+				}, func(*exec.Cmd) error {
+					return errors.New("exit status 1")
+				})
+			})
+
+			It("returns an error", func() {
+				Expect(forwarder.Unforward(kawasaki.PortForwarderSpec{
+					InstanceID:  "some-instance",
+					ExternalIP:  net.ParseIP("5.6.7.8"),
+					ContainerIP: net.ParseIP("1.2.3.4"),
+					FromPort:    22,
+					ToPort:      33,
+				})).To(MatchError(ContainSubstring("/sbin/iptables delete")))
+			})
+		})
+	})
 })
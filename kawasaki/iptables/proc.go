@@ -0,0 +1,49 @@
+package iptables
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultInterface returns the interface of the first default route in the
+// routing table at <procDir>/net/route
+func DefaultInterface(procDir string) (string, error) {
+	routeTable := filepath.Join(procDir, "net", "route")
+	f, err := os.Open(routeTable)
+	if err != nil {
+		return "", fmt.Errorf("reading routing table: %s", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Iface Destination Gateway Flags RefCnt Use Metric Mask ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+
+		return fields[0], nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("reading routing table: %s", err)
+	}
+
+	return "", fmt.Errorf("no default route found in %s", routeTable)
+}
+
+// EnableIPForward turns on IPv4 forwarding through <procDir>/sys
+func EnableIPForward(procDir string) error {
+	return ioutil.WriteFile(filepath.Join(procDir, "sys", "net", "ipv4", "ip_forward"), []byte("1"), 0644)
+}
+
+// EnableIPv6Forward turns on IPv6 forwarding on all interfaces through
+// <procDir>/sys
+func EnableIPv6Forward(procDir string) error {
+	return ioutil.WriteFile(filepath.Join(procDir, "sys", "net", "ipv6", "conf", "all", "forwarding"), []byte("1"), 0644)
+}
@@ -12,10 +12,10 @@ func (flags iptablesFlags) Flags(chain string) []string {
 	return flags
 }
 
-func natRule(destination string, destinationPort uint32, containerIP string, containerPort uint32) Rule {
+func natRule(protocol, destination string, destinationPort uint32, containerIP string, containerPort uint32) Rule {
 	return iptablesFlags([]string{
 		"--table", "nat",
-		"--protocol", "tcp",
+		"--protocol", protocol,
 		"--destination", destination,
 		"--destination-port", fmt.Sprintf("%d", destinationPort),
 		"--jump", "DNAT",
@@ -23,6 +23,13 @@ func natRule(destination string, destinationPort uint32, containerIP string, con
 	})
 }
 
+func acceptRule(destination string) Rule {
+	return iptablesFlags([]string{
+		"--destination", destination,
+		"--jump", "ACCEPT",
+	})
+}
+
 func rejectRule(destination string) Rule {
 	return iptablesFlags([]string{
 		"--destination", destination,
@@ -30,16 +37,29 @@ func rejectRule(destination string) Rule {
 	})
 }
 
+func sourceRule(source string, rule Rule) Rule {
+	return iptablesFlags(append([]string{"--source", source}, rule.Flags("")...))
+}
+
 type SingleFilterRule struct {
 	Protocol garden.Protocol
 	Networks *garden.IPRange
 	Ports    *garden.PortRange
 	ICMPs    *garden.ICMPControl
 	Log      bool
+
+	// IPv6 is set for rules applied with ip6tables, which matches ICMP as
+	// icmpv6
+	IPv6 bool
 }
 
 func (r SingleFilterRule) Flags(chain string) (params []string) {
-	params = append(params, "--protocol", protocols[r.Protocol])
+	protocol, icmpTypeFlag := protocols[r.Protocol], "--icmp-type"
+	if r.IPv6 && r.Protocol == garden.ProtocolICMP {
+		protocol, icmpTypeFlag = "icmpv6", "--icmpv6-type"
+	}
+
+	params = append(params, "--protocol", protocol)
 
 	network := r.Networks
 	if network != nil {
@@ -67,7 +87,7 @@ func (r SingleFilterRule) Flags(chain string) (params []string) {
 			icmpType = fmt.Sprintf("%d/%d", r.ICMPs.Type, *r.ICMPs.Code)
 		}
 
-		params = append(params, "--icmp-type", icmpType)
+		params = append(params, icmpTypeFlag, icmpType)
 	}
 
 	if r.Log {
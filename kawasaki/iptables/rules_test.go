@@ -126,6 +126,26 @@ var _ = Describe("Rules", func() {
 					}))
 				})
 			})
+
+			Context("when the rule is for ip6tables", func() {
+				It("matches icmpv6 and its type", func() {
+					code := garden.ICMPCode(0)
+					rule := iptables.SingleFilterRule{
+						Protocol: garden.ProtocolICMP,
+						ICMPs: &garden.ICMPControl{
+							Type: 128,
+							Code: &code,
+						},
+						IPv6: true,
+					}
+
+					Expect(rule.Flags("banana-chain")).To(Equal([]string{
+						"--protocol", "icmpv6",
+						"--icmpv6-type", "128/0",
+						"--jump", "RETURN",
+					}))
+				})
+			})
 		})
 
 		It("goes to the log chain when logging is enabled", func() {
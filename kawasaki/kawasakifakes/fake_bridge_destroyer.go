@@ -0,0 +1,76 @@
+// This file was generated by counterfeiter
+package kawasakifakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+)
+
+type FakeBridgeDestroyer struct {
+	DestroyStub        func(bridge string) error
+	destroyMutex       sync.RWMutex
+	destroyArgsForCall []struct {
+		bridge string
+	}
+	destroyReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeBridgeDestroyer) Destroy(bridge string) error {
+	fake.destroyMutex.Lock()
+	fake.destroyArgsForCall = append(fake.destroyArgsForCall, struct {
+		bridge string
+	}{bridge})
+	fake.recordInvocation("Destroy", []interface{}{bridge})
+	fake.destroyMutex.Unlock()
+	if fake.DestroyStub != nil {
+		return fake.DestroyStub(bridge)
+	} else {
+		return fake.destroyReturns.result1
+	}
+}
+
+func (fake *FakeBridgeDestroyer) DestroyCallCount() int {
+	fake.destroyMutex.RLock()
+	defer fake.destroyMutex.RUnlock()
+	return len(fake.destroyArgsForCall)
+}
+
+func (fake *FakeBridgeDestroyer) DestroyArgsForCall(i int) string {
+	fake.destroyMutex.RLock()
+	defer fake.destroyMutex.RUnlock()
+	return fake.destroyArgsForCall[i].bridge
+}
+
+func (fake *FakeBridgeDestroyer) DestroyReturns(result1 error) {
+	fake.DestroyStub = nil
+	fake.destroyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeBridgeDestroyer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.destroyMutex.RLock()
+	defer fake.destroyMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeBridgeDestroyer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ kawasaki.BridgeDestroyer = new(FakeBridgeDestroyer)
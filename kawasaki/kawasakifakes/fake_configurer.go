@@ -28,6 +28,15 @@ type FakeConfigurer struct {
 	destroyBridgeReturns struct {
 		result1 error
 	}
+	DestroyBridgeIPv6Stub        func(log lager.Logger, cfg kawasaki.NetworkConfig) error
+	destroyBridgeIPv6Mutex       sync.RWMutex
+	destroyBridgeIPv6ArgsForCall []struct {
+		log lager.Logger
+		cfg kawasaki.NetworkConfig
+	}
+	destroyBridgeIPv6Returns struct {
+		result1 error
+	}
 	DestroyIPTablesRulesStub        func(log lager.Logger, cfg kawasaki.NetworkConfig) error
 	destroyIPTablesRulesMutex       sync.RWMutex
 	destroyIPTablesRulesArgsForCall []struct {
@@ -110,6 +119,40 @@ func (fake *FakeConfigurer) DestroyBridgeReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeConfigurer) DestroyBridgeIPv6(log lager.Logger, cfg kawasaki.NetworkConfig) error {
+	fake.destroyBridgeIPv6Mutex.Lock()
+	fake.destroyBridgeIPv6ArgsForCall = append(fake.destroyBridgeIPv6ArgsForCall, struct {
+		log lager.Logger
+		cfg kawasaki.NetworkConfig
+	}{log, cfg})
+	fake.recordInvocation("DestroyBridgeIPv6", []interface{}{log, cfg})
+	fake.destroyBridgeIPv6Mutex.Unlock()
+	if fake.DestroyBridgeIPv6Stub != nil {
+		return fake.DestroyBridgeIPv6Stub(log, cfg)
+	} else {
+		return fake.destroyBridgeIPv6Returns.result1
+	}
+}
+
+func (fake *FakeConfigurer) DestroyBridgeIPv6CallCount() int {
+	fake.destroyBridgeIPv6Mutex.RLock()
+	defer fake.destroyBridgeIPv6Mutex.RUnlock()
+	return len(fake.destroyBridgeIPv6ArgsForCall)
+}
+
+func (fake *FakeConfigurer) DestroyBridgeIPv6ArgsForCall(i int) (lager.Logger, kawasaki.NetworkConfig) {
+	fake.destroyBridgeIPv6Mutex.RLock()
+	defer fake.destroyBridgeIPv6Mutex.RUnlock()
+	return fake.destroyBridgeIPv6ArgsForCall[i].log, fake.destroyBridgeIPv6ArgsForCall[i].cfg
+}
+
+func (fake *FakeConfigurer) DestroyBridgeIPv6Returns(result1 error) {
+	fake.DestroyBridgeIPv6Stub = nil
+	fake.destroyBridgeIPv6Returns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeConfigurer) DestroyIPTablesRules(log lager.Logger, cfg kawasaki.NetworkConfig) error {
 	fake.destroyIPTablesRulesMutex.Lock()
 	fake.destroyIPTablesRulesArgsForCall = append(fake.destroyIPTablesRulesArgsForCall, struct {
@@ -151,6 +194,8 @@ func (fake *FakeConfigurer) Invocations() map[string][][]interface{} {
 	defer fake.applyMutex.RUnlock()
 	fake.destroyBridgeMutex.RLock()
 	defer fake.destroyBridgeMutex.RUnlock()
+	fake.destroyBridgeIPv6Mutex.RLock()
+	defer fake.destroyBridgeIPv6Mutex.RUnlock()
 	fake.destroyIPTablesRulesMutex.RLock()
 	defer fake.destroyIPTablesRulesMutex.RUnlock()
 	return fake.invocations
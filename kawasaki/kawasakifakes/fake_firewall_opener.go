@@ -20,6 +20,26 @@ type FakeFirewallOpener struct {
 	openReturns struct {
 		result1 error
 	}
+	BulkOpenStub        func(log lager.Logger, instance string, rules []garden.NetOutRule) error
+	bulkOpenMutex       sync.RWMutex
+	bulkOpenArgsForCall []struct {
+		log      lager.Logger
+		instance string
+		rules    []garden.NetOutRule
+	}
+	bulkOpenReturns struct {
+		result1 error
+	}
+	CloseStub        func(log lager.Logger, instance string, rule garden.NetOutRule) error
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct {
+		log      lager.Logger
+		instance string
+		rule     garden.NetOutRule
+	}
+	closeReturns struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -59,11 +79,90 @@ func (fake *FakeFirewallOpener) OpenReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeFirewallOpener) BulkOpen(log lager.Logger, instance string, rules []garden.NetOutRule) error {
+	var rulesCopy []garden.NetOutRule
+	if rules != nil {
+		rulesCopy = make([]garden.NetOutRule, len(rules))
+		copy(rulesCopy, rules)
+	}
+	fake.bulkOpenMutex.Lock()
+	fake.bulkOpenArgsForCall = append(fake.bulkOpenArgsForCall, struct {
+		log      lager.Logger
+		instance string
+		rules    []garden.NetOutRule
+	}{log, instance, rulesCopy})
+	fake.recordInvocation("BulkOpen", []interface{}{log, instance, rulesCopy})
+	fake.bulkOpenMutex.Unlock()
+	if fake.BulkOpenStub != nil {
+		return fake.BulkOpenStub(log, instance, rules)
+	} else {
+		return fake.bulkOpenReturns.result1
+	}
+}
+
+func (fake *FakeFirewallOpener) BulkOpenCallCount() int {
+	fake.bulkOpenMutex.RLock()
+	defer fake.bulkOpenMutex.RUnlock()
+	return len(fake.bulkOpenArgsForCall)
+}
+
+func (fake *FakeFirewallOpener) BulkOpenArgsForCall(i int) (lager.Logger, string, []garden.NetOutRule) {
+	fake.bulkOpenMutex.RLock()
+	defer fake.bulkOpenMutex.RUnlock()
+	return fake.bulkOpenArgsForCall[i].log, fake.bulkOpenArgsForCall[i].instance, fake.bulkOpenArgsForCall[i].rules
+}
+
+func (fake *FakeFirewallOpener) BulkOpenReturns(result1 error) {
+	fake.BulkOpenStub = nil
+	fake.bulkOpenReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFirewallOpener) Close(log lager.Logger, instance string, rule garden.NetOutRule) error {
+	fake.closeMutex.Lock()
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
+		log      lager.Logger
+		instance string
+		rule     garden.NetOutRule
+	}{log, instance, rule})
+	fake.recordInvocation("Close", []interface{}{log, instance, rule})
+	fake.closeMutex.Unlock()
+	if fake.CloseStub != nil {
+		return fake.CloseStub(log, instance, rule)
+	} else {
+		return fake.closeReturns.result1
+	}
+}
+
+func (fake *FakeFirewallOpener) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *FakeFirewallOpener) CloseArgsForCall(i int) (lager.Logger, string, garden.NetOutRule) {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return fake.closeArgsForCall[i].log, fake.closeArgsForCall[i].instance, fake.closeArgsForCall[i].rule
+}
+
+func (fake *FakeFirewallOpener) CloseReturns(result1 error) {
+	fake.CloseStub = nil
+	fake.closeReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeFirewallOpener) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.openMutex.RLock()
 	defer fake.openMutex.RUnlock()
+	fake.bulkOpenMutex.RLock()
+	defer fake.bulkOpenMutex.RUnlock()
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -27,6 +27,14 @@ type FakeHostConfigurer struct {
 	destroyReturns struct {
 		result1 error
 	}
+	DestroyIPv6Stub        func(cfg kawasaki.NetworkConfig) error
+	destroyIPv6Mutex       sync.RWMutex
+	destroyIPv6ArgsForCall []struct {
+		cfg kawasaki.NetworkConfig
+	}
+	destroyIPv6Returns struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -99,6 +107,39 @@ func (fake *FakeHostConfigurer) DestroyReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeHostConfigurer) DestroyIPv6(cfg kawasaki.NetworkConfig) error {
+	fake.destroyIPv6Mutex.Lock()
+	fake.destroyIPv6ArgsForCall = append(fake.destroyIPv6ArgsForCall, struct {
+		cfg kawasaki.NetworkConfig
+	}{cfg})
+	fake.recordInvocation("DestroyIPv6", []interface{}{cfg})
+	fake.destroyIPv6Mutex.Unlock()
+	if fake.DestroyIPv6Stub != nil {
+		return fake.DestroyIPv6Stub(cfg)
+	} else {
+		return fake.destroyIPv6Returns.result1
+	}
+}
+
+func (fake *FakeHostConfigurer) DestroyIPv6CallCount() int {
+	fake.destroyIPv6Mutex.RLock()
+	defer fake.destroyIPv6Mutex.RUnlock()
+	return len(fake.destroyIPv6ArgsForCall)
+}
+
+func (fake *FakeHostConfigurer) DestroyIPv6ArgsForCall(i int) kawasaki.NetworkConfig {
+	fake.destroyIPv6Mutex.RLock()
+	defer fake.destroyIPv6Mutex.RUnlock()
+	return fake.destroyIPv6ArgsForCall[i].cfg
+}
+
+func (fake *FakeHostConfigurer) DestroyIPv6Returns(result1 error) {
+	fake.DestroyIPv6Stub = nil
+	fake.destroyIPv6Returns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeHostConfigurer) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -106,6 +147,8 @@ func (fake *FakeHostConfigurer) Invocations() map[string][][]interface{} {
 	defer fake.applyMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
+	fake.destroyIPv6Mutex.RLock()
+	defer fake.destroyIPv6Mutex.RUnlock()
 	return fake.invocations
 }
 
@@ -0,0 +1,68 @@
+// This file was generated by counterfeiter
+package kawasakifakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+)
+
+type FakeInstanceChainLister struct {
+	InstanceChainsStub        func() ([]string, error)
+	instanceChainsMutex       sync.RWMutex
+	instanceChainsArgsForCall []struct{}
+	instanceChainsReturns     struct {
+		result1 []string
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeInstanceChainLister) InstanceChains() ([]string, error) {
+	fake.instanceChainsMutex.Lock()
+	fake.instanceChainsArgsForCall = append(fake.instanceChainsArgsForCall, struct{}{})
+	fake.recordInvocation("InstanceChains", []interface{}{})
+	fake.instanceChainsMutex.Unlock()
+	if fake.InstanceChainsStub != nil {
+		return fake.InstanceChainsStub()
+	} else {
+		return fake.instanceChainsReturns.result1, fake.instanceChainsReturns.result2
+	}
+}
+
+func (fake *FakeInstanceChainLister) InstanceChainsCallCount() int {
+	fake.instanceChainsMutex.RLock()
+	defer fake.instanceChainsMutex.RUnlock()
+	return len(fake.instanceChainsArgsForCall)
+}
+
+func (fake *FakeInstanceChainLister) InstanceChainsReturns(result1 []string, result2 error) {
+	fake.InstanceChainsStub = nil
+	fake.instanceChainsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInstanceChainLister) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.instanceChainsMutex.RLock()
+	defer fake.instanceChainsMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeInstanceChainLister) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ kawasaki.InstanceChainLister = new(FakeInstanceChainLister)
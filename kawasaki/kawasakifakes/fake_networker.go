@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
 	"code.cloudfoundry.org/guardian/kawasaki"
 	"code.cloudfoundry.org/lager"
 )
@@ -16,6 +17,24 @@ type FakeNetworker struct {
 	capacityReturns     struct {
 		result1 uint64
 	}
+	AvailableSubnetsStub        func() uint64
+	availableSubnetsMutex       sync.RWMutex
+	availableSubnetsArgsForCall []struct{}
+	availableSubnetsReturns     struct {
+		result1 uint64
+	}
+	AvailablePortsStub        func() uint64
+	availablePortsMutex       sync.RWMutex
+	availablePortsArgsForCall []struct{}
+	availablePortsReturns     struct {
+		result1 uint64
+	}
+	PoolCapacitiesStub        func() map[string]gardener.PoolCapacity
+	poolCapacitiesMutex       sync.RWMutex
+	poolCapacitiesArgsForCall []struct{}
+	poolCapacitiesReturns     struct {
+		result1 map[string]gardener.PoolCapacity
+	}
 	NetworkStub        func(log lager.Logger, spec garden.ContainerSpec, pid int) error
 	networkMutex       sync.RWMutex
 	networkArgsForCall []struct {
@@ -35,19 +54,32 @@ type FakeNetworker struct {
 	destroyReturns struct {
 		result1 error
 	}
-	NetInStub        func(log lager.Logger, handle string, externalPort, containerPort uint32) (uint32, uint32, error)
+	NetInStub        func(log lager.Logger, handle string, externalPort, containerPort uint32, protocol string) (uint32, uint32, error)
 	netInMutex       sync.RWMutex
 	netInArgsForCall []struct {
 		log           lager.Logger
 		handle        string
 		externalPort  uint32
 		containerPort uint32
+		protocol      string
 	}
 	netInReturns struct {
 		result1 uint32
 		result2 uint32
 		result3 error
 	}
+	RemoveNetInStub        func(log lager.Logger, handle string, externalPort, containerPort uint32, protocol string) error
+	removeNetInMutex       sync.RWMutex
+	removeNetInArgsForCall []struct {
+		log           lager.Logger
+		handle        string
+		externalPort  uint32
+		containerPort uint32
+		protocol      string
+	}
+	removeNetInReturns struct {
+		result1 error
+	}
 	NetOutStub        func(log lager.Logger, handle string, rule garden.NetOutRule) error
 	netOutMutex       sync.RWMutex
 	netOutArgsForCall []struct {
@@ -58,6 +90,36 @@ type FakeNetworker struct {
 	netOutReturns struct {
 		result1 error
 	}
+	BulkNetOutStub        func(log lager.Logger, handle string, rules []garden.NetOutRule) error
+	bulkNetOutMutex       sync.RWMutex
+	bulkNetOutArgsForCall []struct {
+		log    lager.Logger
+		handle string
+		rules  []garden.NetOutRule
+	}
+	bulkNetOutReturns struct {
+		result1 error
+	}
+	NetOutRulesStub        func(log lager.Logger, handle string) ([]gardener.NetOutRule, error)
+	netOutRulesMutex       sync.RWMutex
+	netOutRulesArgsForCall []struct {
+		log    lager.Logger
+		handle string
+	}
+	netOutRulesReturns struct {
+		result1 []gardener.NetOutRule
+		result2 error
+	}
+	RevokeNetOutStub        func(log lager.Logger, handle string, id string) error
+	revokeNetOutMutex       sync.RWMutex
+	revokeNetOutArgsForCall []struct {
+		log    lager.Logger
+		handle string
+		id     string
+	}
+	revokeNetOutReturns struct {
+		result1 error
+	}
 	RestoreStub        func(log lager.Logger, handle string) error
 	restoreMutex       sync.RWMutex
 	restoreArgsForCall []struct {
@@ -96,6 +158,81 @@ func (fake *FakeNetworker) CapacityReturns(result1 uint64) {
 	}{result1}
 }
 
+func (fake *FakeNetworker) AvailableSubnets() uint64 {
+	fake.availableSubnetsMutex.Lock()
+	fake.availableSubnetsArgsForCall = append(fake.availableSubnetsArgsForCall, struct{}{})
+	fake.recordInvocation("AvailableSubnets", []interface{}{})
+	fake.availableSubnetsMutex.Unlock()
+	if fake.AvailableSubnetsStub != nil {
+		return fake.AvailableSubnetsStub()
+	} else {
+		return fake.availableSubnetsReturns.result1
+	}
+}
+
+func (fake *FakeNetworker) AvailableSubnetsCallCount() int {
+	fake.availableSubnetsMutex.RLock()
+	defer fake.availableSubnetsMutex.RUnlock()
+	return len(fake.availableSubnetsArgsForCall)
+}
+
+func (fake *FakeNetworker) AvailableSubnetsReturns(result1 uint64) {
+	fake.AvailableSubnetsStub = nil
+	fake.availableSubnetsReturns = struct {
+		result1 uint64
+	}{result1}
+}
+
+func (fake *FakeNetworker) AvailablePorts() uint64 {
+	fake.availablePortsMutex.Lock()
+	fake.availablePortsArgsForCall = append(fake.availablePortsArgsForCall, struct{}{})
+	fake.recordInvocation("AvailablePorts", []interface{}{})
+	fake.availablePortsMutex.Unlock()
+	if fake.AvailablePortsStub != nil {
+		return fake.AvailablePortsStub()
+	} else {
+		return fake.availablePortsReturns.result1
+	}
+}
+
+func (fake *FakeNetworker) AvailablePortsCallCount() int {
+	fake.availablePortsMutex.RLock()
+	defer fake.availablePortsMutex.RUnlock()
+	return len(fake.availablePortsArgsForCall)
+}
+
+func (fake *FakeNetworker) AvailablePortsReturns(result1 uint64) {
+	fake.AvailablePortsStub = nil
+	fake.availablePortsReturns = struct {
+		result1 uint64
+	}{result1}
+}
+
+func (fake *FakeNetworker) PoolCapacities() map[string]gardener.PoolCapacity {
+	fake.poolCapacitiesMutex.Lock()
+	fake.poolCapacitiesArgsForCall = append(fake.poolCapacitiesArgsForCall, struct{}{})
+	fake.recordInvocation("PoolCapacities", []interface{}{})
+	fake.poolCapacitiesMutex.Unlock()
+	if fake.PoolCapacitiesStub != nil {
+		return fake.PoolCapacitiesStub()
+	} else {
+		return fake.poolCapacitiesReturns.result1
+	}
+}
+
+func (fake *FakeNetworker) PoolCapacitiesCallCount() int {
+	fake.poolCapacitiesMutex.RLock()
+	defer fake.poolCapacitiesMutex.RUnlock()
+	return len(fake.poolCapacitiesArgsForCall)
+}
+
+func (fake *FakeNetworker) PoolCapacitiesReturns(result1 map[string]gardener.PoolCapacity) {
+	fake.PoolCapacitiesStub = nil
+	fake.poolCapacitiesReturns = struct {
+		result1 map[string]gardener.PoolCapacity
+	}{result1}
+}
+
 func (fake *FakeNetworker) Network(log lager.Logger, spec garden.ContainerSpec, pid int) error {
 	fake.networkMutex.Lock()
 	fake.networkArgsForCall = append(fake.networkArgsForCall, struct {
@@ -165,18 +302,19 @@ func (fake *FakeNetworker) DestroyReturns(result1 error) {
 	}{result1}
 }
 
-func (fake *FakeNetworker) NetIn(log lager.Logger, handle string, externalPort uint32, containerPort uint32) (uint32, uint32, error) {
+func (fake *FakeNetworker) NetIn(log lager.Logger, handle string, externalPort uint32, containerPort uint32, protocol string) (uint32, uint32, error) {
 	fake.netInMutex.Lock()
 	fake.netInArgsForCall = append(fake.netInArgsForCall, struct {
 		log           lager.Logger
 		handle        string
 		externalPort  uint32
 		containerPort uint32
-	}{log, handle, externalPort, containerPort})
-	fake.recordInvocation("NetIn", []interface{}{log, handle, externalPort, containerPort})
+		protocol      string
+	}{log, handle, externalPort, containerPort, protocol})
+	fake.recordInvocation("NetIn", []interface{}{log, handle, externalPort, containerPort, protocol})
 	fake.netInMutex.Unlock()
 	if fake.NetInStub != nil {
-		return fake.NetInStub(log, handle, externalPort, containerPort)
+		return fake.NetInStub(log, handle, externalPort, containerPort, protocol)
 	} else {
 		return fake.netInReturns.result1, fake.netInReturns.result2, fake.netInReturns.result3
 	}
@@ -188,10 +326,10 @@ func (fake *FakeNetworker) NetInCallCount() int {
 	return len(fake.netInArgsForCall)
 }
 
-func (fake *FakeNetworker) NetInArgsForCall(i int) (lager.Logger, string, uint32, uint32) {
+func (fake *FakeNetworker) NetInArgsForCall(i int) (lager.Logger, string, uint32, uint32, string) {
 	fake.netInMutex.RLock()
 	defer fake.netInMutex.RUnlock()
-	return fake.netInArgsForCall[i].log, fake.netInArgsForCall[i].handle, fake.netInArgsForCall[i].externalPort, fake.netInArgsForCall[i].containerPort
+	return fake.netInArgsForCall[i].log, fake.netInArgsForCall[i].handle, fake.netInArgsForCall[i].externalPort, fake.netInArgsForCall[i].containerPort, fake.netInArgsForCall[i].protocol
 }
 
 func (fake *FakeNetworker) NetInReturns(result1 uint32, result2 uint32, result3 error) {
@@ -203,6 +341,43 @@ func (fake *FakeNetworker) NetInReturns(result1 uint32, result2 uint32, result3
 	}{result1, result2, result3}
 }
 
+func (fake *FakeNetworker) RemoveNetIn(log lager.Logger, handle string, externalPort uint32, containerPort uint32, protocol string) error {
+	fake.removeNetInMutex.Lock()
+	fake.removeNetInArgsForCall = append(fake.removeNetInArgsForCall, struct {
+		log           lager.Logger
+		handle        string
+		externalPort  uint32
+		containerPort uint32
+		protocol      string
+	}{log, handle, externalPort, containerPort, protocol})
+	fake.recordInvocation("RemoveNetIn", []interface{}{log, handle, externalPort, containerPort, protocol})
+	fake.removeNetInMutex.Unlock()
+	if fake.RemoveNetInStub != nil {
+		return fake.RemoveNetInStub(log, handle, externalPort, containerPort, protocol)
+	} else {
+		return fake.removeNetInReturns.result1
+	}
+}
+
+func (fake *FakeNetworker) RemoveNetInCallCount() int {
+	fake.removeNetInMutex.RLock()
+	defer fake.removeNetInMutex.RUnlock()
+	return len(fake.removeNetInArgsForCall)
+}
+
+func (fake *FakeNetworker) RemoveNetInArgsForCall(i int) (lager.Logger, string, uint32, uint32, string) {
+	fake.removeNetInMutex.RLock()
+	defer fake.removeNetInMutex.RUnlock()
+	return fake.removeNetInArgsForCall[i].log, fake.removeNetInArgsForCall[i].handle, fake.removeNetInArgsForCall[i].externalPort, fake.removeNetInArgsForCall[i].containerPort, fake.removeNetInArgsForCall[i].protocol
+}
+
+func (fake *FakeNetworker) RemoveNetInReturns(result1 error) {
+	fake.RemoveNetInStub = nil
+	fake.removeNetInReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeNetworker) NetOut(log lager.Logger, handle string, rule garden.NetOutRule) error {
 	fake.netOutMutex.Lock()
 	fake.netOutArgsForCall = append(fake.netOutArgsForCall, struct {
@@ -238,6 +413,116 @@ func (fake *FakeNetworker) NetOutReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeNetworker) BulkNetOut(log lager.Logger, handle string, rules []garden.NetOutRule) error {
+	var rulesCopy []garden.NetOutRule
+	if rules != nil {
+		rulesCopy = make([]garden.NetOutRule, len(rules))
+		copy(rulesCopy, rules)
+	}
+	fake.bulkNetOutMutex.Lock()
+	fake.bulkNetOutArgsForCall = append(fake.bulkNetOutArgsForCall, struct {
+		log    lager.Logger
+		handle string
+		rules  []garden.NetOutRule
+	}{log, handle, rulesCopy})
+	fake.recordInvocation("BulkNetOut", []interface{}{log, handle, rulesCopy})
+	fake.bulkNetOutMutex.Unlock()
+	if fake.BulkNetOutStub != nil {
+		return fake.BulkNetOutStub(log, handle, rules)
+	} else {
+		return fake.bulkNetOutReturns.result1
+	}
+}
+
+func (fake *FakeNetworker) BulkNetOutCallCount() int {
+	fake.bulkNetOutMutex.RLock()
+	defer fake.bulkNetOutMutex.RUnlock()
+	return len(fake.bulkNetOutArgsForCall)
+}
+
+func (fake *FakeNetworker) BulkNetOutArgsForCall(i int) (lager.Logger, string, []garden.NetOutRule) {
+	fake.bulkNetOutMutex.RLock()
+	defer fake.bulkNetOutMutex.RUnlock()
+	return fake.bulkNetOutArgsForCall[i].log, fake.bulkNetOutArgsForCall[i].handle, fake.bulkNetOutArgsForCall[i].rules
+}
+
+func (fake *FakeNetworker) BulkNetOutReturns(result1 error) {
+	fake.BulkNetOutStub = nil
+	fake.bulkNetOutReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworker) NetOutRules(log lager.Logger, handle string) ([]gardener.NetOutRule, error) {
+	fake.netOutRulesMutex.Lock()
+	fake.netOutRulesArgsForCall = append(fake.netOutRulesArgsForCall, struct {
+		log    lager.Logger
+		handle string
+	}{log, handle})
+	fake.recordInvocation("NetOutRules", []interface{}{log, handle})
+	fake.netOutRulesMutex.Unlock()
+	if fake.NetOutRulesStub != nil {
+		return fake.NetOutRulesStub(log, handle)
+	} else {
+		return fake.netOutRulesReturns.result1, fake.netOutRulesReturns.result2
+	}
+}
+
+func (fake *FakeNetworker) NetOutRulesCallCount() int {
+	fake.netOutRulesMutex.RLock()
+	defer fake.netOutRulesMutex.RUnlock()
+	return len(fake.netOutRulesArgsForCall)
+}
+
+func (fake *FakeNetworker) NetOutRulesArgsForCall(i int) (lager.Logger, string) {
+	fake.netOutRulesMutex.RLock()
+	defer fake.netOutRulesMutex.RUnlock()
+	return fake.netOutRulesArgsForCall[i].log, fake.netOutRulesArgsForCall[i].handle
+}
+
+func (fake *FakeNetworker) NetOutRulesReturns(result1 []gardener.NetOutRule, result2 error) {
+	fake.NetOutRulesStub = nil
+	fake.netOutRulesReturns = struct {
+		result1 []gardener.NetOutRule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworker) RevokeNetOut(log lager.Logger, handle string, id string) error {
+	fake.revokeNetOutMutex.Lock()
+	fake.revokeNetOutArgsForCall = append(fake.revokeNetOutArgsForCall, struct {
+		log    lager.Logger
+		handle string
+		id     string
+	}{log, handle, id})
+	fake.recordInvocation("RevokeNetOut", []interface{}{log, handle, id})
+	fake.revokeNetOutMutex.Unlock()
+	if fake.RevokeNetOutStub != nil {
+		return fake.RevokeNetOutStub(log, handle, id)
+	} else {
+		return fake.revokeNetOutReturns.result1
+	}
+}
+
+func (fake *FakeNetworker) RevokeNetOutCallCount() int {
+	fake.revokeNetOutMutex.RLock()
+	defer fake.revokeNetOutMutex.RUnlock()
+	return len(fake.revokeNetOutArgsForCall)
+}
+
+func (fake *FakeNetworker) RevokeNetOutArgsForCall(i int) (lager.Logger, string, string) {
+	fake.revokeNetOutMutex.RLock()
+	defer fake.revokeNetOutMutex.RUnlock()
+	return fake.revokeNetOutArgsForCall[i].log, fake.revokeNetOutArgsForCall[i].handle, fake.revokeNetOutArgsForCall[i].id
+}
+
+func (fake *FakeNetworker) RevokeNetOutReturns(result1 error) {
+	fake.RevokeNetOutStub = nil
+	fake.revokeNetOutReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeNetworker) Restore(log lager.Logger, handle string) error {
 	fake.restoreMutex.Lock()
 	fake.restoreArgsForCall = append(fake.restoreArgsForCall, struct {
@@ -277,14 +562,28 @@ func (fake *FakeNetworker) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.capacityMutex.RLock()
 	defer fake.capacityMutex.RUnlock()
+	fake.availableSubnetsMutex.RLock()
+	defer fake.availableSubnetsMutex.RUnlock()
+	fake.availablePortsMutex.RLock()
+	defer fake.availablePortsMutex.RUnlock()
+	fake.poolCapacitiesMutex.RLock()
+	defer fake.poolCapacitiesMutex.RUnlock()
 	fake.networkMutex.RLock()
 	defer fake.networkMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
 	fake.netInMutex.RLock()
 	defer fake.netInMutex.RUnlock()
+	fake.removeNetInMutex.RLock()
+	defer fake.removeNetInMutex.RUnlock()
 	fake.netOutMutex.RLock()
 	defer fake.netOutMutex.RUnlock()
+	fake.bulkNetOutMutex.RLock()
+	defer fake.bulkNetOutMutex.RUnlock()
+	fake.netOutRulesMutex.RLock()
+	defer fake.netOutRulesMutex.RUnlock()
+	fake.revokeNetOutMutex.RLock()
+	defer fake.revokeNetOutMutex.RUnlock()
 	fake.restoreMutex.RLock()
 	defer fake.restoreMutex.RUnlock()
 	return fake.invocations
@@ -16,6 +16,14 @@ type FakePortForwarder struct {
 	forwardReturns struct {
 		result1 error
 	}
+	UnforwardStub        func(spec kawasaki.PortForwarderSpec) error
+	unforwardMutex       sync.RWMutex
+	unforwardArgsForCall []struct {
+		spec kawasaki.PortForwarderSpec
+	}
+	unforwardReturns struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -53,11 +61,46 @@ func (fake *FakePortForwarder) ForwardReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakePortForwarder) Unforward(spec kawasaki.PortForwarderSpec) error {
+	fake.unforwardMutex.Lock()
+	fake.unforwardArgsForCall = append(fake.unforwardArgsForCall, struct {
+		spec kawasaki.PortForwarderSpec
+	}{spec})
+	fake.recordInvocation("Unforward", []interface{}{spec})
+	fake.unforwardMutex.Unlock()
+	if fake.UnforwardStub != nil {
+		return fake.UnforwardStub(spec)
+	} else {
+		return fake.unforwardReturns.result1
+	}
+}
+
+func (fake *FakePortForwarder) UnforwardCallCount() int {
+	fake.unforwardMutex.RLock()
+	defer fake.unforwardMutex.RUnlock()
+	return len(fake.unforwardArgsForCall)
+}
+
+func (fake *FakePortForwarder) UnforwardArgsForCall(i int) kawasaki.PortForwarderSpec {
+	fake.unforwardMutex.RLock()
+	defer fake.unforwardMutex.RUnlock()
+	return fake.unforwardArgsForCall[i].spec
+}
+
+func (fake *FakePortForwarder) UnforwardReturns(result1 error) {
+	fake.UnforwardStub = nil
+	fake.unforwardReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakePortForwarder) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.forwardMutex.RLock()
 	defer fake.forwardMutex.RUnlock()
+	fake.unforwardMutex.RLock()
+	defer fake.unforwardMutex.RUnlock()
 	return fake.invocations
 }
 
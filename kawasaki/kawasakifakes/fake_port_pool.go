@@ -28,6 +28,12 @@ type FakePortPool struct {
 	removeReturns struct {
 		result1 error
 	}
+	AvailableStub        func() int
+	availableMutex       sync.RWMutex
+	availableArgsForCall []struct{}
+	availableReturns     struct {
+		result1 int
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -115,6 +121,31 @@ func (fake *FakePortPool) RemoveReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakePortPool) Available() int {
+	fake.availableMutex.Lock()
+	fake.availableArgsForCall = append(fake.availableArgsForCall, struct{}{})
+	fake.recordInvocation("Available", []interface{}{})
+	fake.availableMutex.Unlock()
+	if fake.AvailableStub != nil {
+		return fake.AvailableStub()
+	} else {
+		return fake.availableReturns.result1
+	}
+}
+
+func (fake *FakePortPool) AvailableCallCount() int {
+	fake.availableMutex.RLock()
+	defer fake.availableMutex.RUnlock()
+	return len(fake.availableArgsForCall)
+}
+
+func (fake *FakePortPool) AvailableReturns(result1 int) {
+	fake.AvailableStub = nil
+	fake.availableReturns = struct {
+		result1 int
+	}{result1}
+}
+
 func (fake *FakePortPool) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -124,6 +155,8 @@ func (fake *FakePortPool) Invocations() map[string][][]interface{} {
 	defer fake.releaseMutex.RUnlock()
 	fake.removeMutex.RLock()
 	defer fake.removeMutex.RUnlock()
+	fake.availableMutex.RLock()
+	defer fake.availableMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -2,6 +2,7 @@ package kawasaki
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
@@ -15,6 +16,7 @@ import (
 
 // generic gardener properties
 const containerIpKey = gardener.ContainerIPKey
+const containerIPv6Key = gardener.ContainerIPv6Key
 const bridgeIpKey = gardener.BridgeIPKey
 const externalIpKey = gardener.ExternalIPKey
 
@@ -27,6 +29,15 @@ const iptablePrefixKey = "kawasaki.iptable-prefix"
 const iptableInstanceKey = "kawasaki.iptable-inst"
 const mtuKey = "kawasaki.mtu"
 const dnsServerKey = "kawasaki.dns-servers"
+const netOutRulesKey = "kawasaki.netout-rules"
+const bridgeIPv6Key = "kawasaki.bridge-ipv6"
+const subnetIPv6Key = "kawasaki.subnet-ipv6"
+const ipv6DNSServerKey = "kawasaki.ipv6-dns-servers"
+
+// networkPoolKey records the pool a container's subnet was allocated from.
+// The gardener.NetworkPoolKey property only selects the pool on create, as
+// clients may change it afterwards.
+const networkPoolKey = "kawasaki.network-pool"
 
 //go:generate counterfeiter . SpecParser
 
@@ -45,6 +56,7 @@ type ConfigCreator interface {
 type Configurer interface {
 	Apply(log lager.Logger, cfg NetworkConfig, pid int) error
 	DestroyBridge(log lager.Logger, cfg NetworkConfig) error
+	DestroyBridgeIPv6(log lager.Logger, cfg NetworkConfig) error
 	DestroyIPTablesRules(log lager.Logger, cfg NetworkConfig) error
 }
 
@@ -61,12 +73,14 @@ type PortPool interface {
 	Acquire() (uint32, error)
 	Release(uint32)
 	Remove(uint32) error
+	Available() int
 }
 
 //go:generate counterfeiter . PortForwarder
 
 type PortForwarder interface {
 	Forward(spec PortForwarderSpec) error
+	Unforward(spec PortForwarderSpec) error
 }
 
 type PortForwarderSpec struct {
@@ -75,62 +89,156 @@ type PortForwarderSpec struct {
 	ToPort      uint32
 	ContainerIP net.IP
 	ExternalIP  net.IP
+
+	// Protocol to forward, empty for TCP
+	Protocol NetInProtocol
+}
+
+// NetInProtocol is the protocol, or protocols, forwarded by a NetIn mapping
+type NetInProtocol string
+
+const (
+	NetInTCP    NetInProtocol = "tcp"
+	NetInUDP    NetInProtocol = "udp"
+	NetInTCPUDP NetInProtocol = "tcp+udp"
+)
+
+// ParseNetInProtocol parses the protocol of a NetIn mapping, defaulting to
+// TCP when it is empty
+func ParseNetInProtocol(s string) (NetInProtocol, error) {
+	switch protocol := NetInProtocol(s); protocol {
+	case "":
+		return NetInTCP, nil
+	case NetInTCP, NetInUDP, NetInTCPUDP:
+		return protocol, nil
+	default:
+		return "", gardener.InvalidNetInProtocolError{Protocol: s}
+	}
+}
+
+// Protocols returns the iptables names of the protocols to forward
+func (p NetInProtocol) Protocols() []string {
+	switch p {
+	case NetInUDP:
+		return []string{"udp"}
+	case NetInTCPUDP:
+		return []string{"tcp", "udp"}
+	default:
+		return []string{"tcp"}
+	}
+}
+
+// PortMapping is stored under gardener.MappedPortsKey. It extends
+// garden.PortMapping with the protocol, which is omitted for TCP so that
+// mappings stored before protocols were supported read back the same.
+type PortMapping struct {
+	HostPort      uint32
+	ContainerPort uint32
+	Protocol      NetInProtocol `json:",omitempty"`
+}
+
+// protocol returns the protocol of the mapping, which is TCP when omitted
+func (m PortMapping) protocol() NetInProtocol {
+	if m.Protocol == "" {
+		return NetInTCP
+	}
+
+	return m.Protocol
 }
 
 //go:generate counterfeiter . FirewallOpener
 
 type FirewallOpener interface {
 	Open(log lager.Logger, instance string, rule garden.NetOutRule) error
+	BulkOpen(log lager.Logger, instance string, rules []garden.NetOutRule) error
+	Close(log lager.Logger, instance string, rule garden.NetOutRule) error
 }
 
 //go:generate counterfeiter . Networker
 
 type Networker interface {
 	Capacity() uint64
+	AvailableSubnets() uint64
+	AvailablePorts() uint64
+	PoolCapacities() map[string]gardener.PoolCapacity
 	Network(log lager.Logger, spec garden.ContainerSpec, pid int) error
 	Destroy(log lager.Logger, handle string) error
-	NetIn(log lager.Logger, handle string, externalPort, containerPort uint32) (uint32, uint32, error)
+	NetIn(log lager.Logger, handle string, externalPort, containerPort uint32, protocol string) (uint32, uint32, error)
+	RemoveNetIn(log lager.Logger, handle string, externalPort, containerPort uint32, protocol string) error
 	NetOut(log lager.Logger, handle string, rule garden.NetOutRule) error
+	BulkNetOut(log lager.Logger, handle string, rules []garden.NetOutRule) error
+	NetOutRules(log lager.Logger, handle string) ([]gardener.NetOutRule, error)
+	RevokeNetOut(log lager.Logger, handle string, id string) error
 	Restore(log lager.Logger, handle string) error
 }
 
+// DefaultNetworkPool is the name of the pool of containers which do not
+// select one
+const DefaultNetworkPool = "default"
+
+// NetworkPool is a named range of container subnets. Containers select one
+// with a "<name>:" prefix on their network spec or the
+// gardener.NetworkPoolKey property.
+type NetworkPool struct {
+	Name string
+	Pool subnets.Pool
+
+	// Mtu of the containers in the pool, or zero for the default MTU
+	Mtu int
+}
+
 type networker struct {
 	iptablesBin string
 
 	specParser     SpecParser
 	subnetPool     subnets.Pool
+	ipv6SubnetPool subnets.Pool
+	namedPools     map[string]NetworkPool
 	configCreator  ConfigCreator
 	configStore    ConfigStore
 	portForwarder  PortForwarder
 	portPool       PortPool
 	firewallOpener FirewallOpener
 	configurer     Configurer
+
+	ipv6FirewallOpener FirewallOpener
 }
 
 func New(
 	iptablesBin string,
 	specParser SpecParser,
 	subnetPool subnets.Pool,
+	ipv6SubnetPool subnets.Pool,
+	namedPools []NetworkPool,
 	configCreator ConfigCreator,
 	configStore ConfigStore,
 	configurer Configurer,
 	portPool PortPool,
 	portForwarder PortForwarder,
 	firewallOpener FirewallOpener,
+	ipv6FirewallOpener FirewallOpener,
 ) *networker {
+	pools := make(map[string]NetworkPool)
+	for _, pool := range namedPools {
+		pools[pool.Name] = pool
+	}
+
 	return &networker{
 		iptablesBin: iptablesBin,
 
-		specParser:    specParser,
-		subnetPool:    subnetPool,
-		configCreator: configCreator,
-		configStore:   configStore,
-		configurer:    configurer,
+		specParser:     specParser,
+		subnetPool:     subnetPool,
+		ipv6SubnetPool: ipv6SubnetPool,
+		namedPools:     pools,
+		configCreator:  configCreator,
+		configStore:    configStore,
+		configurer:     configurer,
 
 		portForwarder: portForwarder,
 		portPool:      portPool,
 
-		firewallOpener: firewallOpener,
+		firewallOpener:     firewallOpener,
+		ipv6FirewallOpener: ipv6FirewallOpener,
 	}
 }
 
@@ -143,13 +251,25 @@ func (n *networker) Network(log lager.Logger, containerSpec garden.ContainerSpec
 	log.Info("started")
 	defer log.Info("finished")
 
-	subnetReq, ipReq, err := n.specParser.Parse(log, containerSpec.Network)
+	poolName, spec, err := selectedPool(containerSpec)
+	if err != nil {
+		log.Error("select-pool-failed", err)
+		return err
+	}
+
+	pool, err := n.pool(poolName)
+	if err != nil {
+		log.Error("select-pool-failed", err)
+		return err
+	}
+
+	subnetReq, ipReq, err := n.specParser.Parse(log, spec)
 	if err != nil {
 		log.Error("parse-failed", err)
 		return err
 	}
 
-	subnet, ip, err := n.subnetPool.Acquire(log, subnetReq, ipReq)
+	subnet, ip, err := pool.Acquire(log, subnetReq, ipReq)
 	if err != nil {
 		log.Error("acquire-failed", err)
 		return err
@@ -160,6 +280,26 @@ func (n *networker) Network(log lager.Logger, containerSpec garden.ContainerSpec
 		log.Error("create-config-failed", err)
 		return fmt.Errorf("create network config: %s", err)
 	}
+
+	config.NetworkPool = poolName
+	if mtu := n.namedPools[poolName].Mtu; mtu != 0 {
+		config.Mtu = mtu
+	}
+
+	// the IPv6 subnet is always dynamic, since the network spec only
+	// describes the IPv4 one
+	if n.ipv6SubnetPool != nil {
+		subnetIPv6, ipv6, err := n.ipv6SubnetPool.Acquire(log, subnets.DynamicIPv6SubnetSelector, subnets.DynamicIPSelector)
+		if err != nil {
+			log.Error("acquire-ipv6-failed", err)
+			pool.Release(subnet, ip)
+			return err
+		}
+
+		config.SubnetIPv6 = subnetIPv6
+		config.ContainerIPv6 = ipv6
+		config.BridgeIPv6 = subnets.GatewayIP(subnetIPv6)
+	}
 	log.Info("config-create", lager.Data{"config": config})
 
 	if err := save(n.configStore, containerSpec.Handle, config); err != nil {
@@ -172,57 +312,461 @@ func (n *networker) Network(log lager.Logger, containerSpec garden.ContainerSpec
 	return nil
 }
 
-// Capacity returns the number of subnets this network can host
+// Capacity returns the number of containers this network can host, across all
+// of its pools
 func (n *networker) Capacity() uint64 {
-	return uint64(n.subnetPool.Capacity())
+	var capacity uint64
+	for _, pool := range n.PoolCapacities() {
+		capacity += pool.Capacity
+	}
+
+	return capacity
 }
 
-func (n *networker) NetIn(log lager.Logger, handle string, externalPort, containerPort uint32) (uint32, uint32, error) {
+// AvailableSubnets returns the number of container IPs which are not yet
+// allocated, across all of its pools
+func (n *networker) AvailableSubnets() uint64 {
+	var available uint64
+	for _, pool := range n.PoolCapacities() {
+		available += pool.Available
+	}
+
+	return available
+}
+
+// PoolCapacities returns the number of container IPs of each pool, and how
+// many of them are not yet allocated
+func (n *networker) PoolCapacities() map[string]gardener.PoolCapacity {
+	capacities := map[string]gardener.PoolCapacity{
+		DefaultNetworkPool: {
+			Capacity:  uint64(n.subnetPool.Capacity()),
+			Available: uint64(n.subnetPool.Available()),
+		},
+	}
+
+	for name, pool := range n.namedPools {
+		capacities[name] = gardener.PoolCapacity{
+			Capacity:  uint64(pool.Pool.Capacity()),
+			Available: uint64(pool.Pool.Available()),
+		}
+	}
+
+	return capacities
+}
+
+// pool returns the subnet pool with the given name, which is the default
+// pool if the name is empty
+func (n *networker) pool(name string) (subnets.Pool, error) {
+	if name == "" {
+		return n.subnetPool, nil
+	}
+
+	pool, ok := n.namedPools[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown network pool: %s", name)
+	}
+
+	return pool.Pool, nil
+}
+
+// selectedPool returns the name of the pool selected by a container, which is
+// empty for the default pool, along with its network spec without the pool
+// prefix
+func selectedPool(containerSpec garden.ContainerSpec) (string, string, error) {
+	name, spec := SplitPoolSpec(containerSpec.Network)
+
+	if property := containerSpec.Properties[gardener.NetworkPoolKey]; property != "" {
+		if name != "" && name != property {
+			return "", "", fmt.Errorf("network spec selects pool %s but the %s property selects %s", name, gardener.NetworkPoolKey, property)
+		}
+
+		name = property
+	}
+
+	if name == DefaultNetworkPool {
+		name = ""
+	}
+
+	return name, spec, nil
+}
+
+// AvailablePorts returns the number of ports which are not yet mapped
+func (n *networker) AvailablePorts() uint64 {
+	return uint64(n.portPool.Available())
+}
+
+// NetIn forwards the external port to the container port for the given
+// protocol. A port taken from the pool is released again if the mapping can
+// not be added. Ports are only forwarded from the external IPv4 address, to
+// the container's IPv4 address, as the host has no external IPv6 address.
+func (n *networker) NetIn(log lager.Logger, handle string, externalPort, containerPort uint32, protocolName string) (uint32, uint32, error) {
 	cfg, err := load(n.configStore, handle)
 	if err != nil {
 		return 0, 0, err
 	}
 
+	protocol, err := ParseNetInProtocol(protocolName)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	acquired := false
 	if externalPort == 0 {
 		externalPort, err = n.portPool.Acquire()
 		if err != nil {
 			return 0, 0, err
 		}
+		acquired = true
+	}
+
+	releasePort := func() {
+		if acquired {
+			n.portPool.Release(externalPort)
+		}
 	}
 
 	if containerPort == 0 {
 		containerPort = externalPort
 	}
 
-	err = n.portForwarder.Forward(PortForwarderSpec{
+	spec := PortForwarderSpec{
 		InstanceID:  cfg.IPTableInstance,
 		FromPort:    externalPort,
 		ToPort:      containerPort,
 		ContainerIP: cfg.ContainerIP,
 		ExternalIP:  cfg.ExternalIP,
-	})
+		Protocol:    protocol,
+	}
 
-	if err != nil {
+	if err := n.portForwarder.Forward(spec); err != nil {
+		releasePort()
 		return 0, 0, err
 	}
 
-	if err := addPortMapping(log, n.configStore, handle, garden.PortMapping{
+	mapping := PortMapping{
 		HostPort:      externalPort,
 		ContainerPort: containerPort,
-	}); err != nil {
+	}
+	if protocol != NetInTCP {
+		mapping.Protocol = protocol
+	}
+
+	if err := addPortMapping(log, n.configStore, handle, mapping); err != nil {
+		if unforwardErr := n.portForwarder.Unforward(spec); unforwardErr != nil {
+			log.Error("unforward-failed", unforwardErr)
+		}
+		releasePort()
 		return 0, 0, err
 	}
 
 	return externalPort, containerPort, nil
 }
 
+// RemoveNetIn removes a port mapping previously added by NetIn for the given
+// protocol, returning the host port to the pool unless another mapping still
+// uses it
+func (n *networker) RemoveNetIn(log lager.Logger, handle string, externalPort, containerPort uint32, protocolName string) error {
+	log = log.Session("remove-net-in", lager.Data{
+		"handle":        handle,
+		"externalPort":  externalPort,
+		"containerPort": containerPort,
+		"protocol":      protocolName,
+	})
+
+	cfg, err := load(n.configStore, handle)
+	if err != nil {
+		return err
+	}
+
+	protocol, err := ParseNetInProtocol(protocolName)
+	if err != nil {
+		return err
+	}
+
+	var currentMappings portMappingList
+	if currentMappingsJson, ok := n.configStore.Get(handle, gardener.MappedPortsKey); ok {
+		currentMappings, err = portsFromJson(currentMappingsJson)
+		if err != nil {
+			return err
+		}
+	}
+
+	var (
+		found             bool
+		remainingMappings = portMappingList{}
+	)
+	for _, mapping := range currentMappings {
+		if !found && mapping.HostPort == externalPort && mapping.ContainerPort == containerPort && mapping.protocol() == protocol {
+			found = true
+			continue
+		}
+
+		remainingMappings = append(remainingMappings, mapping)
+	}
+
+	if !found {
+		return gardener.PortMappingNotFoundError{HostPort: externalPort, ContainerPort: containerPort, Protocol: string(protocol)}
+	}
+
+	err = n.portForwarder.Unforward(PortForwarderSpec{
+		InstanceID:  cfg.IPTableInstance,
+		FromPort:    externalPort,
+		ToPort:      containerPort,
+		ContainerIP: cfg.ContainerIP,
+		ExternalIP:  cfg.ExternalIP,
+		Protocol:    protocol,
+	})
+	if err != nil {
+		log.Error("unforward-failed", err)
+		return err
+	}
+
+	n.configStore.Set(handle, gardener.MappedPortsKey, remainingMappings.toJson())
+
+	for _, mapping := range remainingMappings {
+		if mapping.HostPort == externalPort {
+			return nil
+		}
+	}
+
+	n.portPool.Release(externalPort)
+	return nil
+}
+
 func (n *networker) NetOut(log lager.Logger, handle string, rule garden.NetOutRule) error {
 	cfg, err := load(n.configStore, handle)
 	if err != nil {
 		return err
 	}
 
-	return n.firewallOpener.Open(log, cfg.IPTableInstance, rule)
+	rules, err := loadNetOutRules(n.configStore, handle)
+	if err != nil {
+		return err
+	}
+
+	ipv4Rules, ipv6Rules, err := n.splitNetOutRules(cfg, []garden.NetOutRule{rule})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range ipv4Rules {
+		if err := n.firewallOpener.Open(log, cfg.IPTableInstance, r); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range ipv6Rules {
+		if err := n.ipv6FirewallOpener.Open(log, cfg.IPTableInstance, r); err != nil {
+			n.rollbackNetOut(log, cfg, ipv4Rules)
+			return err
+		}
+	}
+
+	rules.add(rule)
+	n.configStore.Set(handle, netOutRulesKey, rules.toJson())
+
+	return nil
+}
+
+// BulkNetOut applies all of the rules atomically, so that if any of them
+// cannot be applied none of them are
+func (n *networker) BulkNetOut(log lager.Logger, handle string, newRules []garden.NetOutRule) error {
+	log = log.Session("bulk-net-out", lager.Data{"handle": handle, "rules": len(newRules)})
+
+	cfg, err := load(n.configStore, handle)
+	if err != nil {
+		return err
+	}
+
+	rules, err := loadNetOutRules(n.configStore, handle)
+	if err != nil {
+		return err
+	}
+
+	ipv4Rules, ipv6Rules, err := n.splitNetOutRules(cfg, newRules)
+	if err != nil {
+		return err
+	}
+
+	if err := n.firewallOpener.BulkOpen(log, cfg.IPTableInstance, ipv4Rules); err != nil {
+		log.Error("bulk-open-failed", err)
+		return err
+	}
+
+	if len(ipv6Rules) > 0 {
+		if err := n.ipv6FirewallOpener.BulkOpen(log, cfg.IPTableInstance, ipv6Rules); err != nil {
+			log.Error("bulk-open-ipv6-failed", err)
+			n.rollbackNetOut(log, cfg, ipv4Rules)
+			return err
+		}
+	}
+
+	for _, rule := range newRules {
+		rules.add(rule)
+	}
+	n.configStore.Set(handle, netOutRulesKey, rules.toJson())
+
+	return nil
+}
+
+// NetOutRules returns the rules applied by NetOut which have not been revoked
+func (n *networker) NetOutRules(log lager.Logger, handle string) ([]gardener.NetOutRule, error) {
+	if _, err := load(n.configStore, handle); err != nil {
+		return nil, err
+	}
+
+	rules, err := loadNetOutRules(n.configStore, handle)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules.Rules, nil
+}
+
+// RevokeNetOut deletes the iptables entries of a rule applied by NetOut
+func (n *networker) RevokeNetOut(log lager.Logger, handle string, id string) error {
+	log = log.Session("revoke-net-out", lager.Data{"handle": handle, "id": id})
+
+	cfg, err := load(n.configStore, handle)
+	if err != nil {
+		return err
+	}
+
+	rules, err := loadNetOutRules(n.configStore, handle)
+	if err != nil {
+		return err
+	}
+
+	for i, rule := range rules.Rules {
+		if rule.ID != id {
+			continue
+		}
+
+		ipv4Rules, ipv6Rules, err := n.splitNetOutRules(cfg, []garden.NetOutRule{rule.Rule})
+		if err != nil {
+			return err
+		}
+
+		// the rule stays recorded if it cannot be closed, so any part of it
+		// already closed is opened again to keep it fully enforced
+		for j, r := range ipv4Rules {
+			if err := n.firewallOpener.Close(log, cfg.IPTableInstance, r); err != nil {
+				log.Error("close-failed", err)
+				n.reopenNetOut(log, cfg, ipv4Rules[:j], nil)
+				return err
+			}
+		}
+
+		for j, r := range ipv6Rules {
+			if err := n.ipv6FirewallOpener.Close(log, cfg.IPTableInstance, r); err != nil {
+				log.Error("close-ipv6-failed", err)
+				n.reopenNetOut(log, cfg, ipv4Rules, ipv6Rules[:j])
+				return err
+			}
+		}
+
+		rules.Rules = append(rules.Rules[:i], rules.Rules[i+1:]...)
+		n.configStore.Set(handle, netOutRulesKey, rules.toJson())
+		return nil
+	}
+
+	return fmt.Errorf("netout rule not found: %s", id)
+}
+
+// splitNetOutRules splits rules into those applied with iptables and those
+// applied with ip6tables, according to the address family of their networks.
+// A rule without networks applies to both when the container has IPv6.
+func (n *networker) splitNetOutRules(cfg NetworkConfig, rules []garden.NetOutRule) ([]garden.NetOutRule, []garden.NetOutRule, error) {
+	hasIPv6 := cfg.SubnetIPv6 != nil && n.ipv6FirewallOpener != nil
+
+	var ipv4Rules, ipv6Rules []garden.NetOutRule
+	for _, rule := range rules {
+		if len(rule.Networks) == 0 {
+			ipv4Rules = append(ipv4Rules, rule)
+			if hasIPv6 {
+				ipv6Rules = append(ipv6Rules, rule)
+			}
+			continue
+		}
+
+		var ipv4Networks, ipv6Networks []garden.IPRange
+		for _, network := range rule.Networks {
+			ipv6, err := isIPv6Range(network)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if ipv6 {
+				ipv6Networks = append(ipv6Networks, network)
+			} else {
+				ipv4Networks = append(ipv4Networks, network)
+			}
+		}
+
+		if len(ipv4Networks) > 0 {
+			ipv4Rule := rule
+			ipv4Rule.Networks = ipv4Networks
+			ipv4Rules = append(ipv4Rules, ipv4Rule)
+		}
+
+		if len(ipv6Networks) > 0 {
+			if !hasIPv6 {
+				return nil, nil, errors.New("container has no IPv6 address to allow traffic to IPv6 networks")
+			}
+
+			ipv6Rule := rule
+			ipv6Rule.Networks = ipv6Networks
+			ipv6Rules = append(ipv6Rules, ipv6Rule)
+		}
+	}
+
+	return ipv4Rules, ipv6Rules, nil
+}
+
+func isIPv6Range(r garden.IPRange) (bool, error) {
+	start, end := r.Start, r.End
+	if start == nil {
+		start = end
+	}
+	if end == nil {
+		end = start
+	}
+
+	if start == nil {
+		return false, nil
+	}
+
+	if (start.To4() == nil) != (end.To4() == nil) {
+		return false, fmt.Errorf("network range mixes IPv4 and IPv6 addresses: %s-%s", start, end)
+	}
+
+	return start.To4() == nil, nil
+}
+
+// rollbackNetOut closes the IPv4 part of net out rules whose IPv6 part could
+// not be applied
+func (n *networker) rollbackNetOut(log lager.Logger, cfg NetworkConfig, ipv4Rules []garden.NetOutRule) {
+	for _, r := range ipv4Rules {
+		if err := n.firewallOpener.Close(log, cfg.IPTableInstance, r); err != nil {
+			log.Error("rollback-close-failed", err)
+		}
+	}
+}
+
+// reopenNetOut opens the parts of a net out rule which were closed before
+// closing another part failed
+func (n *networker) reopenNetOut(log lager.Logger, cfg NetworkConfig, ipv4Rules, ipv6Rules []garden.NetOutRule) {
+	for _, r := range ipv4Rules {
+		if err := n.firewallOpener.Open(log, cfg.IPTableInstance, r); err != nil {
+			log.Error("reopen-failed", err)
+		}
+	}
+
+	for _, r := range ipv6Rules {
+		if err := n.ipv6FirewallOpener.Open(log, cfg.IPTableInstance, r); err != nil {
+			log.Error("reopen-ipv6-failed", err)
+		}
+	}
 }
 
 func (n *networker) Destroy(log lager.Logger, handle string) error {
@@ -232,11 +776,16 @@ func (n *networker) Destroy(log lager.Logger, handle string) error {
 		return nil
 	}
 
+	pool, err := n.pool(cfg.NetworkPool)
+	if err != nil {
+		return err
+	}
+
 	if err := n.configurer.DestroyIPTablesRules(log, cfg); err != nil {
 		return err
 	}
 
-	if err := n.subnetPool.Release(cfg.Subnet, cfg.ContainerIP); err != nil && err != subnets.ErrReleasedUnallocatedSubnet {
+	if err := pool.Release(cfg.Subnet, cfg.ContainerIP); err != nil && err != subnets.ErrReleasedUnallocatedSubnet {
 		log.Error("release-failed", err)
 		return err
 	}
@@ -252,11 +801,34 @@ func (n *networker) Destroy(log lager.Logger, handle string) error {
 		}
 	}
 
-	err = n.subnetPool.RunIfFree(cfg.Subnet, func() error {
+	bridgeDestroyed := false
+	err = pool.RunIfFree(cfg.Subnet, func() error {
+		bridgeDestroyed = true
 		return n.configurer.DestroyBridge(log, cfg)
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	if cfg.SubnetIPv6 == nil || n.ipv6SubnetPool == nil {
+		return nil
+	}
+
+	// a bridge kept for other containers still has this container's IPv6
+	// gateway address, which must go before its subnet can be given out again
+	if !bridgeDestroyed {
+		if err := n.configurer.DestroyBridgeIPv6(log, cfg); err != nil {
+			log.Error("destroy-bridge-ipv6-failed", err)
+			return err
+		}
+	}
+
+	if err := n.ipv6SubnetPool.Release(cfg.SubnetIPv6, cfg.ContainerIPv6); err != nil && err != subnets.ErrReleasedUnallocatedSubnet {
+		log.Error("release-ipv6-failed", err)
+		return err
+	}
+
+	return nil
 }
 
 func (n *networker) Restore(log lager.Logger, handle string) error {
@@ -265,11 +837,23 @@ func (n *networker) Restore(log lager.Logger, handle string) error {
 		return fmt.Errorf("loading %s: %v", handle, err)
 	}
 
-	err = n.subnetPool.Remove(networkConfig.Subnet, networkConfig.ContainerIP)
+	pool, err := n.pool(networkConfig.NetworkPool)
+	if err != nil {
+		return fmt.Errorf("restoring %s: %v", handle, err)
+	}
+
+	err = pool.Remove(networkConfig.Subnet, networkConfig.ContainerIP)
 	if err != nil {
 		return fmt.Errorf("subnet pool removing %s: %v", handle, err)
 	}
 
+	if networkConfig.SubnetIPv6 != nil && n.ipv6SubnetPool != nil {
+		err = n.ipv6SubnetPool.Remove(networkConfig.SubnetIPv6, networkConfig.ContainerIPv6)
+		if err != nil {
+			return fmt.Errorf("ipv6 subnet pool removing %s: %v", handle, err)
+		}
+	}
+
 	currentMappingsJson, ok := n.configStore.Get(handle, gardener.MappedPortsKey)
 	if !ok {
 		return nil
@@ -280,7 +864,14 @@ func (n *networker) Restore(log lager.Logger, handle string) error {
 		return fmt.Errorf("unmarshaling port mappings %s: %v", handle, err)
 	}
 
+	// a host port may be mapped once per protocol, but is only taken once
+	removed := make(map[uint32]bool)
 	for _, mapping := range currentMappings {
+		if removed[mapping.HostPort] {
+			continue
+		}
+		removed[mapping.HostPort] = true
+
 		if err = n.portPool.Remove(mapping.HostPort); err != nil {
 			return fmt.Errorf("port pool removing %s: %v", handle, err)
 		}
@@ -289,7 +880,7 @@ func (n *networker) Restore(log lager.Logger, handle string) error {
 	return nil
 }
 
-func addPortMapping(logger lager.Logger, configStore ConfigStore, handle string, newMapping garden.PortMapping) error {
+func addPortMapping(logger lager.Logger, configStore ConfigStore, handle string, newMapping PortMapping) error {
 	var currentMappings portMappingList
 	if currentMappingsJson, ok := configStore.Get(handle, gardener.MappedPortsKey); ok {
 		var err error
@@ -329,16 +920,31 @@ func save(config ConfigStore, handle string, netConfig NetworkConfig) error {
 	config.Set(handle, mtuKey, strconv.Itoa(netConfig.Mtu))
 	config.Set(handle, externalIpKey, netConfig.ExternalIP.String())
 
-	var dnsServers []string
-	for _, dnsServer := range netConfig.DNSServers {
-		dnsServers = append(dnsServers, dnsServer.String())
+	config.Set(handle, dnsServerKey, joinIPs(netConfig.DNSServers))
+
+	if netConfig.NetworkPool != "" {
+		config.Set(handle, networkPoolKey, netConfig.NetworkPool)
 	}
 
-	config.Set(handle, dnsServerKey, strings.Join(dnsServers, ", "))
+	if netConfig.ContainerIPv6 != nil {
+		config.Set(handle, containerIPv6Key, netConfig.ContainerIPv6.String())
+		config.Set(handle, bridgeIPv6Key, netConfig.BridgeIPv6.String())
+		config.Set(handle, subnetIPv6Key, netConfig.SubnetIPv6.String())
+		config.Set(handle, ipv6DNSServerKey, joinIPs(netConfig.IPv6DNSServers))
+	}
 
 	return nil
 }
 
+func joinIPs(ips []net.IP) string {
+	var names []string
+	for _, ip := range ips {
+		names = append(names, ip.String())
+	}
+
+	return strings.Join(names, ", ")
+}
+
 func appendIfNotNil(errors []error, err error) []error {
 	if err != nil {
 		return append(errors, err)
@@ -363,20 +969,12 @@ func load(config ConfigStore, handle string) (NetworkConfig, error) {
 		return NetworkConfig{}, err
 	}
 
-	var dnsServers []net.IP
-	for _, dnsServerName := range strings.Split(vals[10], ",") {
-		dnsServerName = strings.TrimSpace(dnsServerName)
-		if dnsServerName == "" {
-			continue
-		}
-		ip := net.ParseIP(dnsServerName)
-		if ip == nil {
-			return NetworkConfig{}, fmt.Errorf("Failed to parse DNS server IP address %s", dnsServerName)
-		}
-		dnsServers = append(dnsServers, ip)
+	dnsServers, err := splitIPs(vals[10])
+	if err != nil {
+		return NetworkConfig{}, err
 	}
 
-	return NetworkConfig{
+	cfg := NetworkConfig{
 		HostIntf:        vals[0],
 		ContainerIntf:   vals[1],
 		BridgeName:      vals[2],
@@ -388,10 +986,66 @@ func load(config ConfigStore, handle string) (NetworkConfig, error) {
 		IPTableInstance: vals[7],
 		Mtu:             mtu,
 		DNSServers:      dnsServers,
-	}, nil
+	}
+
+	if pool, ok := config.Get(handle, networkPoolKey); ok && pool != DefaultNetworkPool {
+		cfg.NetworkPool = pool
+	}
+
+	if err := loadIPv6(config, handle, &cfg); err != nil {
+		return NetworkConfig{}, err
+	}
+
+	return cfg, nil
 }
 
-type portMappingList []garden.PortMapping
+// loadIPv6 loads the IPv6 addresses of a container, if it has any
+func loadIPv6(config ConfigStore, handle string, cfg *NetworkConfig) error {
+	if _, ok := config.Get(handle, subnetIPv6Key); !ok {
+		return nil
+	}
+
+	vals, err := getAll(config, handle, containerIPv6Key, bridgeIPv6Key, subnetIPv6Key, ipv6DNSServerKey)
+	if err != nil {
+		return err
+	}
+
+	_, subnet, err := net.ParseCIDR(vals[2])
+	if err != nil {
+		return err
+	}
+
+	dnsServers, err := splitIPs(vals[3])
+	if err != nil {
+		return err
+	}
+
+	cfg.ContainerIPv6 = net.ParseIP(vals[0])
+	cfg.BridgeIPv6 = net.ParseIP(vals[1])
+	cfg.SubnetIPv6 = subnet
+	cfg.IPv6DNSServers = dnsServers
+
+	return nil
+}
+
+func splitIPs(s string) ([]net.IP, error) {
+	var ips []net.IP
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		ip := net.ParseIP(name)
+		if ip == nil {
+			return nil, fmt.Errorf("Failed to parse DNS server IP address %s", name)
+		}
+		ips = append(ips, ip)
+	}
+
+	return ips, nil
+}
+
+type portMappingList []PortMapping
 
 func (l portMappingList) toJson() string {
 	b, err := json.Marshal(l)
@@ -402,6 +1056,45 @@ func (l portMappingList) toJson() string {
 	return string(b)
 }
 
+// netOutRuleList is stored under netOutRulesKey. NextID is kept separately
+// from the rules so that the ID of a revoked rule is never handed out again.
+type netOutRuleList struct {
+	NextID int
+	Rules  []gardener.NetOutRule
+}
+
+func (l *netOutRuleList) add(rule garden.NetOutRule) {
+	l.NextID++
+	l.Rules = append(l.Rules, gardener.NetOutRule{
+		ID:   strconv.Itoa(l.NextID),
+		Rule: rule,
+	})
+}
+
+func (l netOutRuleList) toJson() string {
+	b, err := json.Marshal(l)
+	if err != nil {
+		panic(err) // impossible, since netOutRuleList is always encodable
+	}
+
+	return string(b)
+}
+
+func loadNetOutRules(config ConfigStore, handle string) (netOutRuleList, error) {
+	rules := netOutRuleList{Rules: []gardener.NetOutRule{}}
+
+	s, ok := config.Get(handle, netOutRulesKey)
+	if !ok {
+		return rules, nil
+	}
+
+	if err := json.Unmarshal([]byte(s), &rules); err != nil {
+		return netOutRuleList{}, fmt.Errorf("unmarshaling netout rules: %s", err)
+	}
+
+	return rules, nil
+}
+
 func portsFromJson(s string) (portMappingList, error) {
 	var mappings portMappingList
 	if err := json.Unmarshal([]byte(s), &mappings); err != nil {
@@ -21,19 +21,20 @@ import (
 
 var _ = Describe("Networker", func() {
 	var (
-		fakeSpecParser     *fakes.FakeSpecParser
-		fakeSubnetPool     *fake_subnet_pool.FakePool
-		fakeConfigCreator  *fakes.FakeConfigCreator
-		fakeConfigStore    *fakes.FakeConfigStore
-		fakePortForwarder  *fakes.FakePortForwarder
-		fakePortPool       *fakes.FakePortPool
-		fakeFirewallOpener *fakes.FakeFirewallOpener
-		fakeConfigurer     *fakes.FakeConfigurer
-		containerSpec      garden.ContainerSpec
-		networker          kawasaki.Networker
-		logger             lager.Logger
-		networkConfig      kawasaki.NetworkConfig
-		config             map[string]string
+		fakeSpecParser         *fakes.FakeSpecParser
+		fakeSubnetPool         *fake_subnet_pool.FakePool
+		fakeConfigCreator      *fakes.FakeConfigCreator
+		fakeConfigStore        *fakes.FakeConfigStore
+		fakePortForwarder      *fakes.FakePortForwarder
+		fakePortPool           *fakes.FakePortPool
+		fakeFirewallOpener     *fakes.FakeFirewallOpener
+		fakeIPv6FirewallOpener *fakes.FakeFirewallOpener
+		fakeConfigurer         *fakes.FakeConfigurer
+		containerSpec          garden.ContainerSpec
+		networker              kawasaki.Networker
+		logger                 lager.Logger
+		networkConfig          kawasaki.NetworkConfig
+		config                 map[string]string
 	)
 
 	BeforeEach(func() {
@@ -44,6 +45,7 @@ var _ = Describe("Networker", func() {
 		fakePortForwarder = new(fakes.FakePortForwarder)
 		fakePortPool = new(fakes.FakePortPool)
 		fakeFirewallOpener = new(fakes.FakeFirewallOpener)
+		fakeIPv6FirewallOpener = new(fakes.FakeFirewallOpener)
 		fakeConfigurer = new(fakes.FakeConfigurer)
 
 		containerSpec = garden.ContainerSpec{
@@ -56,12 +58,15 @@ var _ = Describe("Networker", func() {
 			"/sbin/iptables",
 			fakeSpecParser,
 			fakeSubnetPool,
+			nil,
+			nil,
 			fakeConfigCreator,
 			fakeConfigStore,
 			fakeConfigurer,
 			fakePortPool,
 			fakePortForwarder,
 			fakeFirewallOpener,
+			fakeIPv6FirewallOpener,
 		)
 
 		ip, subnet, err := net.ParseCIDR("123.123.123.12/24")
@@ -198,6 +203,467 @@ var _ = Describe("Networker", func() {
 		})
 	})
 
+	Describe("IPv6", func() {
+		var (
+			fakeIPv6SubnetPool *fake_subnet_pool.FakePool
+			ipv6               net.IP
+			subnetIPv6         *net.IPNet
+		)
+
+		BeforeEach(func() {
+			var err error
+			ipv6, subnetIPv6, err = net.ParseCIDR("fd00:0:0:1::2/64")
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeIPv6SubnetPool = new(fake_subnet_pool.FakePool)
+			fakeIPv6SubnetPool.AcquireReturns(subnetIPv6, ipv6, nil)
+
+			networker = kawasaki.New(
+				"/sbin/iptables",
+				fakeSpecParser,
+				fakeSubnetPool,
+				fakeIPv6SubnetPool,
+				nil,
+				fakeConfigCreator,
+				fakeConfigStore,
+				fakeConfigurer,
+				fakePortPool,
+				fakePortForwarder,
+				fakeFirewallOpener,
+				fakeIPv6FirewallOpener,
+			)
+		})
+
+		Describe("Network", func() {
+			It("acquires a dynamic IPv6 subnet and IP", func() {
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+				Expect(fakeIPv6SubnetPool.AcquireCallCount()).To(Equal(1))
+				_, sr, ir := fakeIPv6SubnetPool.AcquireArgsForCall(0)
+				Expect(sr).To(Equal(subnets.DynamicIPv6SubnetSelector))
+				Expect(ir).To(Equal(subnets.DynamicIPSelector))
+			})
+
+			It("applies the config with the IPv6 addresses", func() {
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+				_, actualNetConfig, _ := fakeConfigurer.ApplyArgsForCall(0)
+				Expect(actualNetConfig.ContainerIPv6).To(Equal(ipv6))
+				Expect(actualNetConfig.SubnetIPv6).To(Equal(subnetIPv6))
+				Expect(actualNetConfig.BridgeIPv6.String()).To(Equal("fd00:0:0:1::1"))
+			})
+
+			It("stores the IPv6 addresses, including the container IPv6 property", func() {
+				stored := make(map[string]string)
+				fakeConfigStore.SetStub = func(handle, name, value string) {
+					stored[name] = value
+				}
+
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+				Expect(stored[gardener.ContainerIPv6Key]).To(Equal("fd00:0:0:1::2"))
+				Expect(stored["kawasaki.bridge-ipv6"]).To(Equal("fd00:0:0:1::1"))
+				Expect(stored["kawasaki.subnet-ipv6"]).To(Equal("fd00:0:0:1::/64"))
+			})
+
+			Context("when acquiring the IPv6 subnet fails", func() {
+				BeforeEach(func() {
+					fakeIPv6SubnetPool.AcquireReturns(nil, nil, errors.New("no ipv6 for you"))
+				})
+
+				It("returns the error and releases the IPv4 subnet", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError("no ipv6 for you"))
+
+					Expect(fakeSubnetPool.ReleaseCallCount()).To(Equal(1))
+					Expect(fakeConfigurer.ApplyCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when the container has IPv6 addresses", func() {
+			BeforeEach(func() {
+				config[gardener.ContainerIPv6Key] = "fd00:0:0:1::2"
+				config["kawasaki.bridge-ipv6"] = "fd00:0:0:1::1"
+				config["kawasaki.subnet-ipv6"] = "fd00:0:0:1::/64"
+				config["kawasaki.ipv6-dns-servers"] = "2001:4860:4860::8888"
+			})
+
+			It("releases the IPv6 subnet on Destroy", func() {
+				Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+
+				Expect(fakeIPv6SubnetPool.ReleaseCallCount()).To(Equal(1))
+				actualSubnet, actualIP := fakeIPv6SubnetPool.ReleaseArgsForCall(0)
+				Expect(actualSubnet.String()).To(Equal("fd00:0:0:1::/64"))
+				Expect(actualIP.String()).To(Equal("fd00:0:0:1::2"))
+			})
+
+			Describe("NetOut", func() {
+				var (
+					ipv4Network garden.IPRange
+					ipv6Network garden.IPRange
+				)
+
+				BeforeEach(func() {
+					ipv4Network = garden.IPRangeFromIP(net.ParseIP("10.0.0.1"))
+					ipv6Network = garden.IPRangeFromIP(net.ParseIP("2001:db8::1"))
+				})
+
+				It("applies rules to IPv6 networks with the IPv6 FirewallOpener", func() {
+					rule := garden.NetOutRule{
+						Protocol: garden.ProtocolTCP,
+						Networks: []garden.IPRange{ipv4Network, ipv6Network},
+					}
+					Expect(networker.NetOut(logger, "some-handle", rule)).To(Succeed())
+
+					Expect(fakeFirewallOpener.OpenCallCount()).To(Equal(1))
+					_, _, ipv4Rule := fakeFirewallOpener.OpenArgsForCall(0)
+					Expect(ipv4Rule.Networks).To(Equal([]garden.IPRange{ipv4Network}))
+
+					Expect(fakeIPv6FirewallOpener.OpenCallCount()).To(Equal(1))
+					_, _, ipv6Rule := fakeIPv6FirewallOpener.OpenArgsForCall(0)
+					Expect(ipv6Rule.Networks).To(Equal([]garden.IPRange{ipv6Network}))
+				})
+
+				It("applies rules without networks to both address families", func() {
+					rule := garden.NetOutRule{Protocol: garden.ProtocolUDP}
+					Expect(networker.NetOut(logger, "some-handle", rule)).To(Succeed())
+
+					Expect(fakeFirewallOpener.OpenCallCount()).To(Equal(1))
+					Expect(fakeIPv6FirewallOpener.OpenCallCount()).To(Equal(1))
+					_, _, ipv6Rule := fakeIPv6FirewallOpener.OpenArgsForCall(0)
+					Expect(ipv6Rule).To(Equal(rule))
+				})
+
+				It("bulk applies the IPv6 rules with the IPv6 FirewallOpener", func() {
+					rules := []garden.NetOutRule{
+						{Protocol: garden.ProtocolTCP, Networks: []garden.IPRange{ipv4Network}},
+						{Protocol: garden.ProtocolTCP, Networks: []garden.IPRange{ipv6Network}},
+					}
+					Expect(networker.BulkNetOut(logger, "some-handle", rules)).To(Succeed())
+
+					_, _, ipv4Rules := fakeFirewallOpener.BulkOpenArgsForCall(0)
+					Expect(ipv4Rules).To(Equal(rules[:1]))
+					_, _, ipv6Rules := fakeIPv6FirewallOpener.BulkOpenArgsForCall(0)
+					Expect(ipv6Rules).To(Equal(rules[1:]))
+				})
+
+				It("closes the IPv6 part of a revoked rule with the IPv6 FirewallOpener", func() {
+					rule := garden.NetOutRule{Networks: []garden.IPRange{ipv6Network}}
+					Expect(networker.NetOut(logger, "some-handle", rule)).To(Succeed())
+					_, name, value := fakeConfigStore.SetArgsForCall(0)
+					config[name] = value
+
+					Expect(networker.RevokeNetOut(logger, "some-handle", "1")).To(Succeed())
+
+					Expect(fakeFirewallOpener.CloseCallCount()).To(Equal(0))
+					Expect(fakeIPv6FirewallOpener.CloseCallCount()).To(Equal(1))
+					_, _, closed := fakeIPv6FirewallOpener.CloseArgsForCall(0)
+					Expect(closed).To(Equal(rule))
+				})
+
+				Context("when closing the IPv6 part of a revoked rule fails", func() {
+					It("opens the IPv4 part again and keeps the rule", func() {
+						rule := garden.NetOutRule{Networks: []garden.IPRange{ipv4Network, ipv6Network}}
+						Expect(networker.NetOut(logger, "some-handle", rule)).To(Succeed())
+						_, name, value := fakeConfigStore.SetArgsForCall(0)
+						config[name] = value
+
+						fakeIPv6FirewallOpener.CloseReturns(errors.New("no ip6tables"))
+						Expect(networker.RevokeNetOut(logger, "some-handle", "1")).To(MatchError("no ip6tables"))
+
+						Expect(fakeFirewallOpener.CloseCallCount()).To(Equal(1))
+						Expect(fakeFirewallOpener.OpenCallCount()).To(Equal(2))
+						_, _, reopened := fakeFirewallOpener.OpenArgsForCall(1)
+						Expect(reopened.Networks).To(Equal([]garden.IPRange{ipv4Network}))
+						Expect(fakeConfigStore.SetCallCount()).To(Equal(1))
+					})
+				})
+
+				Context("when a network range mixes address families", func() {
+					It("returns an error without applying the rule", func() {
+						rule := garden.NetOutRule{Networks: []garden.IPRange{{
+							Start: net.ParseIP("10.0.0.1"),
+							End:   net.ParseIP("2001:db8::1"),
+						}}}
+						Expect(networker.NetOut(logger, "some-handle", rule)).To(MatchError(ContainSubstring("mixes IPv4 and IPv6")))
+
+						Expect(fakeFirewallOpener.OpenCallCount()).To(Equal(0))
+						Expect(fakeIPv6FirewallOpener.OpenCallCount()).To(Equal(0))
+					})
+				})
+
+				Context("when the IPv6 FirewallOpener fails", func() {
+					BeforeEach(func() {
+						fakeIPv6FirewallOpener.OpenReturns(errors.New("no ip6tables"))
+					})
+
+					It("closes the IPv4 part of the rule again and does not record it", func() {
+						rule := garden.NetOutRule{Networks: []garden.IPRange{ipv4Network, ipv6Network}}
+						Expect(networker.NetOut(logger, "some-handle", rule)).To(MatchError("no ip6tables"))
+
+						Expect(fakeFirewallOpener.CloseCallCount()).To(Equal(1))
+						_, _, closed := fakeFirewallOpener.CloseArgsForCall(0)
+						Expect(closed.Networks).To(Equal([]garden.IPRange{ipv4Network}))
+						Expect(fakeConfigStore.SetCallCount()).To(Equal(0))
+					})
+				})
+			})
+
+			Context("when other containers still use the bridge", func() {
+				It("removes the IPv6 gateway address from the bridge before releasing the IPv6 subnet", func() {
+					fakeIPv6SubnetPool.ReleaseStub = func(_ *net.IPNet, _ net.IP) error {
+						Expect(fakeConfigurer.DestroyBridgeIPv6CallCount()).To(Equal(1))
+						return nil
+					}
+
+					Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+
+					Expect(fakeConfigurer.DestroyBridgeCallCount()).To(Equal(0))
+					Expect(fakeConfigurer.DestroyBridgeIPv6CallCount()).To(Equal(1))
+					_, cfg := fakeConfigurer.DestroyBridgeIPv6ArgsForCall(0)
+					Expect(cfg.BridgeIPv6.String()).To(Equal("fd00:0:0:1::1"))
+					Expect(cfg.SubnetIPv6.String()).To(Equal("fd00:0:0:1::/64"))
+					Expect(fakeIPv6SubnetPool.ReleaseCallCount()).To(Equal(1))
+				})
+
+				Context("when removing the address fails", func() {
+					BeforeEach(func() {
+						fakeConfigurer.DestroyBridgeIPv6Returns(errors.New("address stuck"))
+					})
+
+					It("returns the error without releasing the IPv6 subnet", func() {
+						Expect(networker.Destroy(logger, "some-handle")).To(MatchError("address stuck"))
+						Expect(fakeIPv6SubnetPool.ReleaseCallCount()).To(Equal(0))
+					})
+				})
+			})
+
+			Context("when the bridge is destroyed", func() {
+				BeforeEach(func() {
+					fakeSubnetPool.RunIfFreeStub = func(_ *net.IPNet, cb func() error) error {
+						return cb()
+					}
+				})
+
+				It("does not remove the IPv6 gateway address separately", func() {
+					Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+
+					Expect(fakeConfigurer.DestroyBridgeCallCount()).To(Equal(1))
+					Expect(fakeConfigurer.DestroyBridgeIPv6CallCount()).To(Equal(0))
+					Expect(fakeIPv6SubnetPool.ReleaseCallCount()).To(Equal(1))
+				})
+			})
+
+			It("removes the IPv6 subnet from the pool on Restore", func() {
+				Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+
+				Expect(fakeIPv6SubnetPool.RemoveCallCount()).To(Equal(1))
+				actualSubnet, actualIP := fakeIPv6SubnetPool.RemoveArgsForCall(0)
+				Expect(actualSubnet.String()).To(Equal("fd00:0:0:1::/64"))
+				Expect(actualIP.String()).To(Equal("fd00:0:0:1::2"))
+			})
+
+			Context("when the IPv6 subnet is corrupt", func() {
+				BeforeEach(func() {
+					config["kawasaki.subnet-ipv6"] = "banana"
+				})
+
+				It("fails to load the config", func() {
+					Expect(networker.Restore(logger, "some-handle")).To(MatchError(ContainSubstring("loading some-handle")))
+				})
+			})
+		})
+
+		Context("when the container has no IPv6 addresses", func() {
+			It("does not release an IPv6 subnet on Destroy", func() {
+				Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+				Expect(fakeIPv6SubnetPool.ReleaseCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("network pools", func() {
+		var fakeDMZPool *fake_subnet_pool.FakePool
+
+		BeforeEach(func() {
+			fakeDMZPool = new(fake_subnet_pool.FakePool)
+
+			networker = kawasaki.New(
+				"/sbin/iptables",
+				fakeSpecParser,
+				fakeSubnetPool,
+				nil,
+				[]kawasaki.NetworkPool{{Name: "dmz", Pool: fakeDMZPool, Mtu: 1400}},
+				fakeConfigCreator,
+				fakeConfigStore,
+				fakeConfigurer,
+				fakePortPool,
+				fakePortForwarder,
+				fakeFirewallOpener,
+				fakeIPv6FirewallOpener,
+			)
+		})
+
+		Describe("Network", func() {
+			Context("when the network spec selects a pool", func() {
+				BeforeEach(func() {
+					containerSpec.Network = "dmz:1.2.3.4/30"
+				})
+
+				It("parses the spec without the pool name", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+					_, spec := fakeSpecParser.ParseArgsForCall(0)
+					Expect(spec).To(Equal("1.2.3.4/30"))
+				})
+
+				It("acquires the subnet from the selected pool", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+					Expect(fakeDMZPool.AcquireCallCount()).To(Equal(1))
+					Expect(fakeSubnetPool.AcquireCallCount()).To(Equal(0))
+				})
+
+				It("applies the config with the pool and its MTU", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+					_, cfg, _ := fakeConfigurer.ApplyArgsForCall(0)
+					Expect(cfg.NetworkPool).To(Equal("dmz"))
+					Expect(cfg.Mtu).To(Equal(1400))
+				})
+
+				It("stores the pool", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+					stored := make(map[string]string)
+					for i := 0; i < fakeConfigStore.SetCallCount(); i++ {
+						_, name, value := fakeConfigStore.SetArgsForCall(i)
+						stored[name] = value
+					}
+					Expect(stored).To(HaveKeyWithValue("kawasaki.network-pool", "dmz"))
+					Expect(stored).NotTo(HaveKey(gardener.NetworkPoolKey))
+				})
+
+				Context("when the pool property selects a different pool", func() {
+					BeforeEach(func() {
+						containerSpec.Properties = garden.Properties{gardener.NetworkPoolKey: "build"}
+					})
+
+					It("returns an error without acquiring a subnet", func() {
+						Expect(networker.Network(logger, containerSpec, 42)).To(MatchError(ContainSubstring("selects pool dmz")))
+						Expect(fakeDMZPool.AcquireCallCount()).To(Equal(0))
+						Expect(fakeSubnetPool.AcquireCallCount()).To(Equal(0))
+					})
+				})
+			})
+
+			Context("when the pool property selects a pool", func() {
+				BeforeEach(func() {
+					containerSpec.Properties = garden.Properties{gardener.NetworkPoolKey: "dmz"}
+				})
+
+				It("acquires the subnet from the selected pool", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+					Expect(fakeDMZPool.AcquireCallCount()).To(Equal(1))
+					Expect(fakeSubnetPool.AcquireCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when the default pool is selected by name", func() {
+				BeforeEach(func() {
+					containerSpec.Network = "default:1.2.3.4/30"
+				})
+
+				It("acquires the subnet from the default pool with the default MTU", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+					Expect(fakeSubnetPool.AcquireCallCount()).To(Equal(1))
+					_, cfg, _ := fakeConfigurer.ApplyArgsForCall(0)
+					Expect(cfg.NetworkPool).To(BeEmpty())
+					Expect(cfg.Mtu).To(Equal(1200))
+				})
+			})
+
+			Context("when the selected pool does not exist", func() {
+				BeforeEach(func() {
+					containerSpec.Network = "internal:"
+				})
+
+				It("returns an error", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError("unknown network pool: internal"))
+				})
+			})
+		})
+
+		Context("when the container was allocated a subnet from a pool", func() {
+			BeforeEach(func() {
+				config["kawasaki.network-pool"] = "dmz"
+			})
+
+			It("releases the subnet to the pool on Destroy", func() {
+				Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+
+				Expect(fakeDMZPool.ReleaseCallCount()).To(Equal(1))
+				Expect(fakeSubnetPool.ReleaseCallCount()).To(Equal(0))
+			})
+
+			It("removes the subnet from the pool on Restore", func() {
+				Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+
+				Expect(fakeDMZPool.RemoveCallCount()).To(Equal(1))
+				Expect(fakeSubnetPool.RemoveCallCount()).To(Equal(0))
+			})
+
+			Context("when the pool property has since been changed", func() {
+				BeforeEach(func() {
+					config[gardener.NetworkPoolKey] = "unknown"
+				})
+
+				It("still releases the subnet to the pool it was allocated from on Destroy", func() {
+					Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+
+					Expect(fakeDMZPool.ReleaseCallCount()).To(Equal(1))
+					Expect(fakeSubnetPool.ReleaseCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when only the pool property names a pool", func() {
+			BeforeEach(func() {
+				config[gardener.NetworkPoolKey] = "dmz"
+			})
+
+			It("releases the subnet to the default pool on Destroy", func() {
+				Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+
+				Expect(fakeSubnetPool.ReleaseCallCount()).To(Equal(1))
+				Expect(fakeDMZPool.ReleaseCallCount()).To(Equal(0))
+			})
+		})
+
+		Describe("PoolCapacities", func() {
+			BeforeEach(func() {
+				fakeSubnetPool.CapacityReturns(256)
+				fakeSubnetPool.AvailableReturns(200)
+				fakeDMZPool.CapacityReturns(8)
+				fakeDMZPool.AvailableReturns(3)
+			})
+
+			It("reports the capacity of each pool", func() {
+				Expect(networker.PoolCapacities()).To(Equal(map[string]gardener.PoolCapacity{
+					"default": {Capacity: 256, Available: 200},
+					"dmz":     {Capacity: 8, Available: 3},
+				}))
+			})
+
+			It("sums the pools for Capacity and AvailableSubnets", func() {
+				Expect(networker.Capacity()).To(BeEquivalentTo(264))
+				Expect(networker.AvailableSubnets()).To(BeEquivalentTo(203))
+			})
+		})
+	})
+
 	Describe("Capacity", func() {
 		BeforeEach(func() {
 			fakeSubnetPool.CapacityReturns(9000)
@@ -211,6 +677,20 @@ var _ = Describe("Networker", func() {
 		})
 	})
 
+	Describe("AvailableSubnets", func() {
+		It("delegates to the subnetPool", func() {
+			fakeSubnetPool.AvailableReturns(42)
+			Expect(networker.AvailableSubnets()).To(BeEquivalentTo(42))
+		})
+	})
+
+	Describe("AvailablePorts", func() {
+		It("delegates to the portPool", func() {
+			fakePortPool.AvailableReturns(24)
+			Expect(networker.AvailablePorts()).To(BeEquivalentTo(24))
+		})
+	})
+
 	Describe("Destroy", func() {
 		Context("when the store does not contain the properties for the container", func() {
 			It("should skip destroy, to maintain idempotence", func() {
@@ -340,6 +820,186 @@ var _ = Describe("Networker", func() {
 			Expect(chainArg).To(Equal(networkConfig.IPTableInstance))
 			Expect(ruleArg).To(Equal(rule))
 		})
+
+		It("records the rule in the ConfigStore", func() {
+			rule := garden.NetOutRule{Protocol: garden.ProtocolTCP}
+			Expect(networker.NetOut(logger, "some-handle", rule)).To(Succeed())
+
+			Expect(fakeConfigStore.SetCallCount()).To(Equal(1))
+			actualHandle, actualName, actualValue := fakeConfigStore.SetArgsForCall(0)
+			Expect(actualHandle).To(Equal("some-handle"))
+			Expect(actualName).To(Equal("kawasaki.netout-rules"))
+
+			config[actualName] = actualValue
+			Expect(networker.NetOutRules(logger, "some-handle")).To(Equal([]gardener.NetOutRule{
+				{ID: "1", Rule: rule},
+			}))
+		})
+
+		Context("when the rule is to an IPv6 network and the container has no IPv6 address", func() {
+			It("returns an error without applying the rule", func() {
+				rule := garden.NetOutRule{Networks: []garden.IPRange{garden.IPRangeFromIP(net.ParseIP("2001:db8::1"))}}
+				Expect(networker.NetOut(logger, "some-handle", rule)).To(MatchError(ContainSubstring("no IPv6 address")))
+
+				Expect(fakeFirewallOpener.OpenCallCount()).To(Equal(0))
+				Expect(fakeIPv6FirewallOpener.OpenCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the FirewallOpener fails", func() {
+			It("does not record the rule", func() {
+				fakeFirewallOpener.OpenReturns(errors.New("potato"))
+				Expect(networker.NetOut(logger, "some-handle", garden.NetOutRule{})).NotTo(Succeed())
+
+				Expect(fakeConfigStore.SetCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("BulkNetOut", func() {
+		var rules []garden.NetOutRule
+
+		BeforeEach(func() {
+			rules = []garden.NetOutRule{
+				{Protocol: garden.ProtocolTCP},
+				{Protocol: garden.ProtocolUDP},
+			}
+		})
+
+		It("applies all of the rules with a single call to the FirewallOpener", func() {
+			Expect(networker.BulkNetOut(logger, "some-handle", rules)).To(Succeed())
+
+			Expect(fakeFirewallOpener.OpenCallCount()).To(Equal(0))
+			Expect(fakeFirewallOpener.BulkOpenCallCount()).To(Equal(1))
+			_, instance, actualRules := fakeFirewallOpener.BulkOpenArgsForCall(0)
+			Expect(instance).To(Equal(networkConfig.IPTableInstance))
+			Expect(actualRules).To(Equal(rules))
+		})
+
+		It("records each rule in the ConfigStore", func() {
+			Expect(networker.BulkNetOut(logger, "some-handle", rules)).To(Succeed())
+
+			Expect(fakeConfigStore.SetCallCount()).To(Equal(1))
+			_, name, value := fakeConfigStore.SetArgsForCall(0)
+			config[name] = value
+
+			Expect(networker.NetOutRules(logger, "some-handle")).To(Equal([]gardener.NetOutRule{
+				{ID: "1", Rule: rules[0]},
+				{ID: "2", Rule: rules[1]},
+			}))
+		})
+
+		Context("when the FirewallOpener fails", func() {
+			BeforeEach(func() {
+				fakeFirewallOpener.BulkOpenReturns(errors.New("potato"))
+			})
+
+			It("returns the error without recording any of the rules", func() {
+				Expect(networker.BulkNetOut(logger, "some-handle", rules)).To(MatchError("potato"))
+				Expect(fakeConfigStore.SetCallCount()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("NetOutRules and RevokeNetOut", func() {
+		var (
+			tcpRule, udpRule garden.NetOutRule
+		)
+
+		BeforeEach(func() {
+			fakeConfigStore.SetStub = func(handle, name, value string) {
+				config[name] = value
+			}
+
+			tcpRule = garden.NetOutRule{Protocol: garden.ProtocolTCP, Ports: []garden.PortRange{garden.PortRangeFromPort(80)}}
+			udpRule = garden.NetOutRule{Protocol: garden.ProtocolUDP, Ports: []garden.PortRange{garden.PortRangeFromPort(53)}}
+
+			Expect(networker.NetOut(logger, "some-handle", tcpRule)).To(Succeed())
+			Expect(networker.NetOut(logger, "some-handle", udpRule)).To(Succeed())
+		})
+
+		It("lists the applied rules in order", func() {
+			Expect(networker.NetOutRules(logger, "some-handle")).To(Equal([]gardener.NetOutRule{
+				{ID: "1", Rule: tcpRule},
+				{ID: "2", Rule: udpRule},
+			}))
+		})
+
+		It("returns an empty list when no rules were applied", func() {
+			delete(config, "kawasaki.netout-rules")
+			Expect(networker.NetOutRules(logger, "some-handle")).To(BeEmpty())
+		})
+
+		It("revokes a rule by ID", func() {
+			Expect(networker.RevokeNetOut(logger, "some-handle", "1")).To(Succeed())
+
+			Expect(fakeFirewallOpener.CloseCallCount()).To(Equal(1))
+			_, instance, rule := fakeFirewallOpener.CloseArgsForCall(0)
+			Expect(instance).To(Equal(networkConfig.IPTableInstance))
+			Expect(rule).To(Equal(tcpRule))
+
+			Expect(networker.NetOutRules(logger, "some-handle")).To(Equal([]gardener.NetOutRule{
+				{ID: "2", Rule: udpRule},
+			}))
+		})
+
+		It("does not reuse the ID of a revoked rule", func() {
+			Expect(networker.RevokeNetOut(logger, "some-handle", "2")).To(Succeed())
+			Expect(networker.NetOut(logger, "some-handle", udpRule)).To(Succeed())
+
+			Expect(networker.NetOutRules(logger, "some-handle")).To(Equal([]gardener.NetOutRule{
+				{ID: "1", Rule: tcpRule},
+				{ID: "3", Rule: udpRule},
+			}))
+		})
+
+		It("keeps the rules across a restore", func() {
+			Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+
+			Expect(networker.NetOutRules(logger, "some-handle")).To(HaveLen(2))
+			Expect(networker.RevokeNetOut(logger, "some-handle", "2")).To(Succeed())
+		})
+
+		Context("when the rule does not exist", func() {
+			It("returns an error", func() {
+				Expect(networker.RevokeNetOut(logger, "some-handle", "42")).To(MatchError("netout rule not found: 42"))
+				Expect(fakeFirewallOpener.CloseCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the FirewallOpener fails to close the rule", func() {
+			BeforeEach(func() {
+				fakeFirewallOpener.CloseReturns(errors.New("potato"))
+			})
+
+			It("returns the error and keeps the rule", func() {
+				Expect(networker.RevokeNetOut(logger, "some-handle", "1")).To(MatchError("potato"))
+				Expect(networker.NetOutRules(logger, "some-handle")).To(HaveLen(2))
+			})
+		})
+
+		Context("when the recorded rules are corrupt", func() {
+			BeforeEach(func() {
+				config["kawasaki.netout-rules"] = "{"
+			})
+
+			It("returns an error", func() {
+				_, err := networker.NetOutRules(logger, "some-handle")
+				Expect(err).To(MatchError(ContainSubstring("unmarshaling netout rules")))
+			})
+		})
+
+		Context("when handle does not exist", func() {
+			BeforeEach(func() {
+				fakeConfigStore.GetReturns("", false)
+			})
+
+			It("returns an error", func() {
+				_, err := networker.NetOutRules(logger, "nonexistent")
+				Expect(err).To(MatchError(ContainSubstring("property not found")))
+				Expect(networker.RevokeNetOut(logger, "nonexistent", "1")).To(MatchError(ContainSubstring("property not found")))
+			})
+		})
 	})
 
 	Describe("NetIn", func() {
@@ -357,7 +1017,7 @@ var _ = Describe("Networker", func() {
 		})
 
 		It("calls the PortForwarder with correct parameters", func() {
-			_, _, err := networker.NetIn(logger, handle, externalPort, containerPort)
+			_, _, err := networker.NetIn(logger, handle, externalPort, containerPort, "")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(fakePortForwarder.ForwardCallCount()).To(Equal(1))
 
@@ -375,7 +1035,7 @@ var _ = Describe("Networker", func() {
 			It("acquires a random port from the pool", func() {
 				fakePortPool.AcquireReturns(externalPort, nil)
 
-				actualHostPort, actualContainerPort, err := networker.NetIn(logger, handle, 0, containerPort)
+				actualHostPort, actualContainerPort, err := networker.NetIn(logger, handle, 0, containerPort, "")
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(actualHostPort).To(Equal(externalPort))
@@ -395,7 +1055,7 @@ var _ = Describe("Networker", func() {
 
 			BeforeEach(func() {
 				fakePortPool.AcquireReturns(0, fmt.Errorf("Oh no!"))
-				_, _, err = networker.NetIn(logger, handle, 0, containerPort)
+				_, _, err = networker.NetIn(logger, handle, 0, containerPort, "")
 			})
 
 			It("returns the error", func() {
@@ -413,7 +1073,7 @@ var _ = Describe("Networker", func() {
 
 		Context("when container port is not specified", func() {
 			It("aquires a port from the pool", func() {
-				actualHostPort, actualContainerPort, err := networker.NetIn(logger, handle, externalPort, 0)
+				actualHostPort, actualContainerPort, err := networker.NetIn(logger, handle, externalPort, 0, "")
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(actualHostPort).To(Equal(externalPort))
@@ -422,7 +1082,7 @@ var _ = Describe("Networker", func() {
 		})
 
 		It("stores port mapping in ConfigStore", func() {
-			_, _, err := networker.NetIn(logger, handle, externalPort, containerPort)
+			_, _, err := networker.NetIn(logger, handle, externalPort, containerPort, "")
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(fakeConfigStore.SetCallCount()).To(Equal(1))
@@ -433,13 +1093,47 @@ var _ = Describe("Networker", func() {
 			Expect(actualValue).To(Equal(`[{"HostPort":60000,"ContainerPort":8080},{"HostPort":123,"ContainerPort":456}]`))
 		})
 
+		It("forwards TCP by default", func() {
+			_, _, err := networker.NetIn(logger, handle, externalPort, containerPort, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakePortForwarder.ForwardArgsForCall(0).Protocol).To(Equal(kawasaki.NetInTCP))
+		})
+
+		Context("when a protocol is given", func() {
+			It("forwards that protocol", func() {
+				_, _, err := networker.NetIn(logger, handle, externalPort, containerPort, "tcp+udp")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakePortForwarder.ForwardArgsForCall(0).Protocol).To(Equal(kawasaki.NetInTCPUDP))
+			})
+
+			It("stores the protocol with the port mapping", func() {
+				_, _, err := networker.NetIn(logger, handle, externalPort, containerPort, "tcp+udp")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, actualValue := fakeConfigStore.SetArgsForCall(0)
+				Expect(actualValue).To(Equal(`[{"HostPort":60000,"ContainerPort":8080},{"HostPort":123,"ContainerPort":456,"Protocol":"tcp+udp"}]`))
+			})
+		})
+
+		Context("when the protocol is invalid", func() {
+			It("returns an error without forwarding or acquiring a port", func() {
+				_, _, err := networker.NetIn(logger, handle, 0, containerPort, "sctp")
+				Expect(err).To(MatchError(gardener.InvalidNetInProtocolError{Protocol: "sctp"}))
+
+				Expect(fakePortPool.AcquireCallCount()).To(Equal(0))
+				Expect(fakePortForwarder.ForwardCallCount()).To(Equal(0))
+			})
+		})
+
 		It("stores a list of port mappings in ConfigStore", func() {
-			_, _, err := networker.NetIn(logger, handle, externalPort, containerPort)
+			_, _, err := networker.NetIn(logger, handle, externalPort, containerPort, "")
 			Expect(err).NotTo(HaveOccurred())
 
 			config[gardener.MappedPortsKey] = `[{"HostPort":123,"ContainerPort":456}]`
 
-			_, _, err = networker.NetIn(logger, handle, 654, 987)
+			_, _, err = networker.NetIn(logger, handle, 654, 987, "")
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(fakeConfigStore.SetCallCount()).To(Equal(2))
@@ -453,7 +1147,7 @@ var _ = Describe("Networker", func() {
 
 			BeforeEach(func() {
 				fakePortForwarder.ForwardReturns(fmt.Errorf("Oh no!"))
-				_, _, err = networker.NetIn(logger, handle, 0, 0)
+				_, _, err = networker.NetIn(logger, handle, 0, 0, "")
 			})
 
 			It("returns an error", func() {
@@ -465,13 +1159,158 @@ var _ = Describe("Networker", func() {
 			})
 		})
 
+		Context("when the PortForwarder fails to forward a port taken from the pool", func() {
+			BeforeEach(func() {
+				fakePortPool.AcquireReturns(externalPort, nil)
+				fakePortForwarder.ForwardReturns(fmt.Errorf("Oh no!"))
+			})
+
+			It("releases the port", func() {
+				_, _, err := networker.NetIn(logger, handle, 0, containerPort, "")
+				Expect(err).To(MatchError("Oh no!"))
+
+				Expect(fakePortPool.ReleaseCallCount()).To(Equal(1))
+				Expect(fakePortPool.ReleaseArgsForCall(0)).To(Equal(externalPort))
+			})
+		})
+
+		Context("when the PortForwarder fails to forward a given port", func() {
+			It("does not release it", func() {
+				fakePortForwarder.ForwardReturns(fmt.Errorf("Oh no!"))
+
+				_, _, err := networker.NetIn(logger, handle, externalPort, containerPort, "")
+				Expect(err).To(MatchError("Oh no!"))
+				Expect(fakePortPool.ReleaseCallCount()).To(Equal(0))
+			})
+		})
+
 		Context("when handle does not exist", func() {
 			BeforeEach(func() {
 				fakeConfigStore.GetReturns("", false)
 			})
 
 			It("returns an error", func() {
-				_, _, err := networker.NetIn(logger, "nonexistent", 0, 0)
+				_, _, err := networker.NetIn(logger, "nonexistent", 0, 0, "")
+				Expect(err).To(MatchError(ContainSubstring("property not found")))
+			})
+		})
+	})
+
+	Describe("RemoveNetIn", func() {
+		BeforeEach(func() {
+			config[gardener.MappedPortsKey] = `[{"HostPort":60000,"ContainerPort":8080},{"HostPort":60001,"ContainerPort":53,"Protocol":"udp"}]`
+		})
+
+		It("deletes the forwarding rule for the mapping", func() {
+			Expect(networker.RemoveNetIn(logger, "some-handle", 60001, 53, "udp")).To(Succeed())
+
+			Expect(fakePortForwarder.UnforwardCallCount()).To(Equal(1))
+			Expect(fakePortForwarder.UnforwardArgsForCall(0)).To(Equal(kawasaki.PortForwarderSpec{
+				InstanceID:  networkConfig.IPTableInstance,
+				FromPort:    60001,
+				ToPort:      53,
+				ContainerIP: networkConfig.ContainerIP,
+				ExternalIP:  networkConfig.ExternalIP,
+				Protocol:    kawasaki.NetInUDP,
+			}))
+		})
+
+		It("removes the mapping from the ConfigStore", func() {
+			Expect(networker.RemoveNetIn(logger, "some-handle", 60001, 53, "udp")).To(Succeed())
+
+			Expect(fakeConfigStore.SetCallCount()).To(Equal(1))
+			actualHandle, actualName, actualValue := fakeConfigStore.SetArgsForCall(0)
+			Expect(actualHandle).To(Equal("some-handle"))
+			Expect(actualName).To(Equal(gardener.MappedPortsKey))
+			Expect(actualValue).To(Equal(`[{"HostPort":60000,"ContainerPort":8080}]`))
+		})
+
+		It("returns the host port to the pool", func() {
+			Expect(networker.RemoveNetIn(logger, "some-handle", 60001, 53, "udp")).To(Succeed())
+
+			Expect(fakePortPool.ReleaseCallCount()).To(Equal(1))
+			Expect(fakePortPool.ReleaseArgsForCall(0)).To(BeEquivalentTo(60001))
+		})
+
+		Context("when the host port is still used by another mapping", func() {
+			BeforeEach(func() {
+				config[gardener.MappedPortsKey] = `[{"HostPort":60000,"ContainerPort":8080},{"HostPort":60000,"ContainerPort":8081}]`
+			})
+
+			It("does not return the host port to the pool", func() {
+				Expect(networker.RemoveNetIn(logger, "some-handle", 60000, 8080, "")).To(Succeed())
+
+				Expect(fakePortPool.ReleaseCallCount()).To(Equal(0))
+				_, _, actualValue := fakeConfigStore.SetArgsForCall(0)
+				Expect(actualValue).To(Equal(`[{"HostPort":60000,"ContainerPort":8081}]`))
+			})
+		})
+
+		Context("when the host port is mapped for both tcp and udp", func() {
+			BeforeEach(func() {
+				config[gardener.MappedPortsKey] = `[{"HostPort":60000,"ContainerPort":53},{"HostPort":60000,"ContainerPort":53,"Protocol":"udp"}]`
+			})
+
+			It("removes the mapping of the given protocol", func() {
+				Expect(networker.RemoveNetIn(logger, "some-handle", 60000, 53, "udp")).To(Succeed())
+
+				Expect(fakePortForwarder.UnforwardArgsForCall(0).Protocol).To(Equal(kawasaki.NetInUDP))
+				_, _, actualValue := fakeConfigStore.SetArgsForCall(0)
+				Expect(actualValue).To(Equal(`[{"HostPort":60000,"ContainerPort":53}]`))
+				Expect(fakePortPool.ReleaseCallCount()).To(Equal(0))
+			})
+
+			It("removes the tcp mapping when no protocol is given", func() {
+				Expect(networker.RemoveNetIn(logger, "some-handle", 60000, 53, "")).To(Succeed())
+
+				Expect(fakePortForwarder.UnforwardArgsForCall(0).Protocol).To(Equal(kawasaki.NetInTCP))
+				_, _, actualValue := fakeConfigStore.SetArgsForCall(0)
+				Expect(actualValue).To(Equal(`[{"HostPort":60000,"ContainerPort":53,"Protocol":"udp"}]`))
+			})
+		})
+
+		Context("when the mapping exists for another protocol only", func() {
+			It("returns a not found error", func() {
+				err := networker.RemoveNetIn(logger, "some-handle", 60001, 53, "tcp")
+				Expect(err).To(BeAssignableToTypeOf(gardener.PortMappingNotFoundError{}))
+				Expect(fakePortForwarder.UnforwardCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the mapping does not exist", func() {
+			It("returns an error without changing anything", func() {
+				Expect(networker.RemoveNetIn(logger, "some-handle", 60000, 9999, "")).To(MatchError(gardener.PortMappingNotFoundError{
+					HostPort:      60000,
+					ContainerPort: 9999,
+					Protocol:      "tcp",
+				}))
+
+				Expect(fakePortForwarder.UnforwardCallCount()).To(Equal(0))
+				Expect(fakeConfigStore.SetCallCount()).To(Equal(0))
+				Expect(fakePortPool.ReleaseCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the PortForwarder fails", func() {
+			BeforeEach(func() {
+				fakePortForwarder.UnforwardReturns(errors.New("boom"))
+			})
+
+			It("returns the error and keeps the mapping", func() {
+				Expect(networker.RemoveNetIn(logger, "some-handle", 60000, 8080, "")).To(MatchError("boom"))
+
+				Expect(fakeConfigStore.SetCallCount()).To(Equal(0))
+				Expect(fakePortPool.ReleaseCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when handle does not exist", func() {
+			BeforeEach(func() {
+				fakeConfigStore.GetReturns("", false)
+			})
+
+			It("returns an error", func() {
+				err := networker.RemoveNetIn(logger, "nonexistent", 60000, 8080, "")
 				Expect(err).To(MatchError(ContainSubstring("property not found")))
 			})
 		})
@@ -519,6 +1358,17 @@ var _ = Describe("Networker", func() {
 			})
 		})
 
+		Context("when a host port is mapped for more than one protocol", func() {
+			BeforeEach(func() {
+				config[gardener.MappedPortsKey] = `[{"HostPort":53,"ContainerPort":53},{"HostPort":53,"ContainerPort":53,"Protocol":"udp"}]`
+			})
+
+			It("only removes the port from the pool once", func() {
+				Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+				Expect(fakePortPool.RemoveCallCount()).To(Equal(1))
+			})
+		})
+
 		Context("when the port mapping json can't be marshaled", func() {
 			BeforeEach(func() {
 				config[gardener.MappedPortsKey] = "not-json"
@@ -0,0 +1,195 @@
+package nftables
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"code.cloudfoundry.org/guardian/kawasaki/iptables"
+	"code.cloudfoundry.org/lager"
+)
+
+// baseChains are the chains hooked into netfilter, declared as iptables-nft
+// declares them so that both tools share them
+var baseChains = []struct {
+	table, chain, declaration string
+}{
+	{"filter", "INPUT", "type filter hook input priority 0;"},
+	{"filter", "FORWARD", "type filter hook forward priority 0;"},
+	{"nat", "PREROUTING", "type nat hook prerouting priority -100;"},
+	{"nat", "OUTPUT", "type nat hook output priority -100;"},
+	{"nat", "POSTROUTING", "type nat hook postrouting priority 100;"},
+}
+
+// Starter sets up the global chains, as the iptables backend's Starter
+// does, and resets the allow and deny networks in the default chain,
+// including those of each network pool
+type Starter struct {
+	logger          lager.Logger
+	nft             *NFTablesController
+	allowHostAccess bool
+	nicPrefix       string
+	procDir         string
+
+	allowNetworks []string
+	denyNetworks  []string
+	poolNetworks  []iptables.PoolNetworks
+}
+
+func NewStarter(logger lager.Logger, nft *NFTablesController, allowHostAccess bool, nicPrefix string, allowNetworks, denyNetworks []string, poolNetworks []iptables.PoolNetworks, procDir string) *Starter {
+	return &Starter{
+		logger:          logger,
+		nft:             nft,
+		allowHostAccess: allowHostAccess,
+		nicPrefix:       nicPrefix,
+		procDir:         procDir,
+
+		allowNetworks: allowNetworks,
+		denyNetworks:  denyNetworks,
+		poolNetworks:  poolNetworks,
+	}
+}
+
+func (s Starter) Start() error {
+	if !s.nft.chainExists("filter", s.nft.inputChain) {
+		if err := s.setup(s.logger.Session("setup-global-chains")); err != nil {
+			return fmt.Errorf("setting up default chains: %s", err)
+		}
+	}
+
+	return s.resetDenyNetworks()
+}
+
+func (s Starter) setup(log lager.Logger) error {
+	defaultInterface, err := iptables.DefaultInterface(s.procDir)
+	if err != nil {
+		return err
+	}
+
+	log.Info("started", lager.Data{"default-interface": defaultInterface})
+	defer log.Info("finished")
+
+	s.teardown()
+
+	nft := s.nft
+	var script bytes.Buffer
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&script, format+"\n", args...)
+	}
+
+	line("add table %s filter", family)
+	line("add table %s nat", family)
+	for _, base := range baseChains {
+		line("add chain %s %s %s { %s }", family, base.table, base.chain, base.declaration)
+	}
+
+	// Accept inbound packets from the outside, and those related to
+	// previously established connections
+	line("add chain %s filter %s", family, nft.inputChain)
+	line("add rule %s filter %s iifname %q accept", family, nft.inputChain, defaultInterface)
+	line("add rule %s filter %s ct state established,related accept", family, nft.inputChain)
+	if s.allowHostAccess {
+		line("add rule %s filter %s accept", family, nft.inputChain)
+	} else {
+		line("add rule %s filter %s reject with icmp type host-prohibited", family, nft.inputChain)
+	}
+	line("add rule %s filter INPUT iifname %q jump %s", family, s.nicPrefix+"*", nft.inputChain)
+
+	// Forward inbound traffic immediately, and outbound traffic via the
+	// instance chains, which are inserted before the final drop
+	line("add chain %s filter %s", family, nft.forwardChain)
+	line("add rule %s filter %s iifname %q accept", family, nft.forwardChain, defaultInterface)
+	line("add rule %s filter %s drop", family, nft.forwardChain)
+	line("add rule %s filter FORWARD iifname %q jump %s", family, s.nicPrefix+"*", nft.forwardChain)
+
+	line("add chain %s filter %s", family, nft.defaultChain)
+
+	line("add chain %s nat %s", family, nft.preroutingChain)
+	line("add rule %s nat PREROUTING jump %s", family, nft.preroutingChain)
+	// for traffic originating from the same host
+	line("add rule %s nat OUTPUT oifname \"lo\" jump %s", family, nft.preroutingChain)
+
+	line("add chain %s nat %s", family, nft.postroutingChain)
+	line("add rule %s nat POSTROUTING jump %s", family, nft.postroutingChain)
+
+	if err := nft.apply("setup-global-chains", script.String()); err != nil {
+		log.Error("failed", err)
+		return err
+	}
+
+	return iptables.EnableIPForward(s.procDir)
+}
+
+// teardown removes every chain with the chain prefix, and the rules in the
+// base chains which refer to them. Errors are ignored, since on a fresh host
+// there is nothing to tear down.
+func (s Starter) teardown() {
+	nft := s.nft
+
+	ours := func(chain string) bool {
+		return strings.HasPrefix(chain, nft.chainPrefix)
+	}
+
+	for _, base := range baseChains {
+		nft.deleteReferences(base.table, base.chain, ours)
+	}
+
+	for _, table := range []string{"filter", "nat"} {
+		chains := s.chains(table, nft.chainPrefix)
+		for _, chain := range chains {
+			nft.FlushChain(table, chain)
+		}
+		for _, chain := range chains {
+			nft.DeleteChain(table, chain)
+		}
+	}
+}
+
+func (s Starter) chains(table, prefix string) []string {
+	var stdout bytes.Buffer
+	cmd := exec.Command(s.nft.binPath, "list", "table", family, table)
+	cmd.Stdout = &stdout
+	if err := s.nft.run("list-chains", cmd); err != nil {
+		return nil
+	}
+
+	var chains []string
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "chain" && strings.HasPrefix(fields[1], prefix) {
+			chains = append(chains, fields[1])
+		}
+	}
+
+	return chains
+}
+
+func (s Starter) resetDenyNetworks() error {
+	nft := s.nft
+
+	var script bytes.Buffer
+	fmt.Fprintf(&script, "flush chain %s filter %s\n", family, nft.defaultChain)
+	fmt.Fprintf(&script, "add rule %s filter %s ct state established,related accept\n", family, nft.defaultChain)
+	// the networks of a pool only apply to its subnet, and come before the
+	// global ones
+	for _, pool := range s.poolNetworks {
+		for _, n := range pool.AllowNetworks {
+			fmt.Fprintf(&script, "add rule %s filter %s ip saddr %s ip daddr %s accept\n", family, nft.defaultChain, pool.Subnet, n)
+		}
+		for _, n := range pool.DenyNetworks {
+			fmt.Fprintf(&script, "add rule %s filter %s ip saddr %s ip daddr %s reject\n", family, nft.defaultChain, pool.Subnet, n)
+		}
+	}
+	// allowed networks are exceptions to the denied ones, so come first
+	for _, n := range s.allowNetworks {
+		fmt.Fprintf(&script, "add rule %s filter %s ip daddr %s accept\n", family, nft.defaultChain, n)
+	}
+	for _, n := range s.denyNetworks {
+		fmt.Fprintf(&script, "add rule %s filter %s ip daddr %s reject\n", family, nft.defaultChain, n)
+	}
+
+	return nft.apply("reset-default-chain", script.String())
+}
@@ -0,0 +1,251 @@
+package nftables_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"code.cloudfoundry.org/guardian/kawasaki/iptables"
+	"code.cloudfoundry.org/guardian/kawasaki/nftables"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
+	. "github.com/cloudfoundry/gunk/command_runner/fake_command_runner/matchers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Starter", func() {
+	var (
+		fakeRunner      *fake_command_runner.FakeCommandRunner
+		scripts         *[]string
+		applyErr        error
+		allowHostAccess bool
+		allowNetworks   []string
+		denyNetworks    []string
+		poolNetworks    []iptables.PoolNetworks
+		procDir         string
+		starter         *nftables.Starter
+	)
+
+	writeRoutes := func(routes string) {
+		Expect(ioutil.WriteFile(filepath.Join(procDir, "net", "route"), []byte(
+			"Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n"+routes,
+		), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		procDir, err = ioutil.TempDir("", "proc")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.MkdirAll(filepath.Join(procDir, "net"), 0755)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(procDir, "sys", "net", "ipv4"), 0755)).To(Succeed())
+		writeRoutes("eth1\t0000000A\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0\n" +
+			"eth0\t00000000\t0100000A\t0003\t0\t0\t0\t00000000\t0\t0\t0\n")
+
+		fakeRunner = fake_command_runner.New()
+		applyErr = nil
+		scripts = recordScripts(fakeRunner, &applyErr)
+		allowHostAccess = false
+		allowNetworks = nil
+		denyNetworks = []string{"1.2.3.4/32", "10.0.0.0/8"}
+		poolNetworks = nil
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(procDir)).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		starter = nftables.NewStarter(
+			lagertest.NewTestLogger("test"),
+			nftables.New("/usr/sbin/nft", fakeRunner, "prefix-"),
+			allowHostAccess,
+			"the-nic-prefix",
+			allowNetworks,
+			denyNetworks,
+			poolNetworks,
+			procDir,
+		)
+	})
+
+	resetDenyNetworksScript := "flush chain ip filter prefix-default\n" +
+		"add rule ip filter prefix-default ct state established,related accept\n" +
+		"add rule ip filter prefix-default ip daddr 1.2.3.4/32 reject\n" +
+		"add rule ip filter prefix-default ip daddr 10.0.0.0/8 reject\n"
+
+	Context("when the global chains already exist", func() {
+		It("only resets the deny networks", func() {
+			Expect(starter.Start()).To(Succeed())
+
+			Expect(*scripts).To(Equal([]string{resetDenyNetworksScript}))
+			Expect(filepath.Join(procDir, "sys", "net", "ipv4", "ip_forward")).NotTo(BeAnExistingFile())
+		})
+
+		Context("when some networks are allowed", func() {
+			BeforeEach(func() {
+				allowNetworks = []string{"10.1.0.0/16"}
+			})
+
+			It("accepts them before rejecting the deny networks", func() {
+				Expect(starter.Start()).To(Succeed())
+
+				Expect(*scripts).To(Equal([]string{"flush chain ip filter prefix-default\n" +
+					"add rule ip filter prefix-default ct state established,related accept\n" +
+					"add rule ip filter prefix-default ip daddr 10.1.0.0/16 accept\n" +
+					"add rule ip filter prefix-default ip daddr 1.2.3.4/32 reject\n" +
+					"add rule ip filter prefix-default ip daddr 10.0.0.0/8 reject\n",
+				}))
+			})
+		})
+
+		Context("when a network pool has its own networks", func() {
+			BeforeEach(func() {
+				poolNetworks = []iptables.PoolNetworks{{
+					Subnet:        "10.254.4.0/22",
+					AllowNetworks: []string{"10.2.0.0/16"},
+					DenyNetworks:  []string{"0.0.0.0/0"},
+				}}
+			})
+
+			It("applies them to traffic from the pool before the global networks", func() {
+				Expect(starter.Start()).To(Succeed())
+
+				Expect(*scripts).To(Equal([]string{"flush chain ip filter prefix-default\n" +
+					"add rule ip filter prefix-default ct state established,related accept\n" +
+					"add rule ip filter prefix-default ip saddr 10.254.4.0/22 ip daddr 10.2.0.0/16 accept\n" +
+					"add rule ip filter prefix-default ip saddr 10.254.4.0/22 ip daddr 0.0.0.0/0 reject\n" +
+					"add rule ip filter prefix-default ip daddr 1.2.3.4/32 reject\n" +
+					"add rule ip filter prefix-default ip daddr 10.0.0.0/8 reject\n",
+				}))
+			})
+		})
+	})
+
+	Context("when the global chains do not exist", func() {
+		BeforeEach(func() {
+			fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+				Path: "/usr/sbin/nft",
+				Args: []string{"--handle", "list", "chain", "ip", "filter", "prefix-input"},
+			}, func(*exec.Cmd) error {
+				return errors.New("exit status 1")
+			})
+		})
+
+		It("sets up the global chains in a single transaction, then resets the deny networks", func() {
+			Expect(starter.Start()).To(Succeed())
+
+			Expect(*scripts).To(HaveLen(2))
+			Expect((*scripts)[0]).To(Equal(`add table ip filter
+add table ip nat
+add chain ip filter INPUT { type filter hook input priority 0; }
+add chain ip filter FORWARD { type filter hook forward priority 0; }
+add chain ip nat PREROUTING { type nat hook prerouting priority -100; }
+add chain ip nat OUTPUT { type nat hook output priority -100; }
+add chain ip nat POSTROUTING { type nat hook postrouting priority 100; }
+add chain ip filter prefix-input
+add rule ip filter prefix-input iifname "eth0" accept
+add rule ip filter prefix-input ct state established,related accept
+add rule ip filter prefix-input reject with icmp type host-prohibited
+add rule ip filter INPUT iifname "the-nic-prefix*" jump prefix-input
+add chain ip filter prefix-forward
+add rule ip filter prefix-forward iifname "eth0" accept
+add rule ip filter prefix-forward drop
+add rule ip filter FORWARD iifname "the-nic-prefix*" jump prefix-forward
+add chain ip filter prefix-default
+add chain ip nat prefix-prerouting
+add rule ip nat PREROUTING jump prefix-prerouting
+add rule ip nat OUTPUT oifname "lo" jump prefix-prerouting
+add chain ip nat prefix-postrouting
+add rule ip nat POSTROUTING jump prefix-postrouting
+`))
+			Expect((*scripts)[1]).To(Equal(resetDenyNetworksScript))
+		})
+
+		It("enables ip forwarding", func() {
+			Expect(starter.Start()).To(Succeed())
+
+			forwarding, err := ioutil.ReadFile(filepath.Join(procDir, "sys", "net", "ipv4", "ip_forward"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(forwarding)).To(Equal("1"))
+		})
+
+		Context("when host access is allowed", func() {
+			BeforeEach(func() {
+				allowHostAccess = true
+			})
+
+			It("accepts traffic to the host", func() {
+				Expect(starter.Start()).To(Succeed())
+
+				Expect((*scripts)[0]).To(ContainSubstring("add rule ip filter prefix-input accept\n"))
+				Expect((*scripts)[0]).NotTo(ContainSubstring("host-prohibited"))
+			})
+		})
+
+		Context("when chains were left behind by a previous run", func() {
+			BeforeEach(func() {
+				listChainReturns(fakeRunner, "filter", "INPUT", `table ip filter {
+	chain INPUT { # handle 1
+		type filter hook input priority filter; policy accept;
+		iifname "the-nic-prefix*" jump prefix-input # handle 4
+		iifname "docker0" jump DOCKER # handle 5
+	}
+}
+`)
+
+				fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+					Path: "/usr/sbin/nft",
+					Args: []string{"list", "table", "ip", "filter"},
+				}, func(cmd *exec.Cmd) error {
+					_, err := cmd.Stdout.Write([]byte(`table ip filter {
+	chain INPUT {
+	}
+	chain prefix-forward {
+	}
+	chain prefix-instance-abc {
+	}
+	chain DOCKER {
+	}
+}
+`))
+					return err
+				})
+			})
+
+			It("tears down only the chains with the prefix before setting up", func() {
+				Expect(starter.Start()).To(Succeed())
+
+				Expect((*scripts)[:5]).To(Equal([]string{
+					"delete rule ip filter INPUT handle 4\n",
+					"flush chain ip filter prefix-forward\n",
+					"flush chain ip filter prefix-instance-abc\n",
+					"delete chain ip filter prefix-forward\n",
+					"delete chain ip filter prefix-instance-abc\n",
+				}))
+			})
+		})
+
+		Context("when there is no default route", func() {
+			BeforeEach(func() {
+				writeRoutes("eth1\t0000000A\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0\n")
+			})
+
+			It("returns an error", func() {
+				Expect(starter.Start()).To(MatchError(ContainSubstring("no default route found")))
+			})
+		})
+
+		Context("when setting up the chains fails", func() {
+			BeforeEach(func() {
+				applyErr = errors.New("exit status 1")
+			})
+
+			It("returns an error", func() {
+				Expect(starter.Start()).To(MatchError(ContainSubstring("setting up default chains")))
+			})
+		})
+	})
+})
@@ -0,0 +1,111 @@
+package nftables
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+)
+
+type InstanceChainCreator struct {
+	nft *NFTablesController
+}
+
+func NewInstanceChainCreator(nft *NFTablesController) *InstanceChainCreator {
+	return &InstanceChainCreator{
+		nft: nft,
+	}
+}
+
+// Create creates the nat, filter and logging instance chains in a single nft
+// transaction, so a failure leaves nothing behind
+func (cc *InstanceChainCreator) Create(logger lager.Logger, handle, instanceId, bridgeName string, ip net.IP, network *net.IPNet) error {
+	nft := cc.nft
+	instanceChain := nft.InstanceChain(instanceId)
+	loggingChain := fmt.Sprintf("%s-log", instanceChain)
+
+	var script bytes.Buffer
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&script, format+"\n", args...)
+	}
+
+	// Bind nat instance chain to nat prerouting chain
+	line("create chain %s nat %s", family, instanceChain)
+	line("add rule %s nat %s jump %s", family, nft.preroutingChain, instanceChain)
+
+	// Enable NAT for traffic coming from containers, once per subnet
+	masquerade := fmt.Sprintf("ip saddr %s ip daddr != %s masquerade", network, network)
+	if !cc.postroutingContains(fmt.Sprintf("ip saddr %s ", network), "masquerade") {
+		line("add rule %s nat %s %s", family, nft.postroutingChain, masquerade)
+	}
+
+	// Allow intra-subnet traffic (Linux ethernet bridging goes through ip
+	// stack), otherwise use the default filter chain
+	line("create chain %s filter %s", family, instanceChain)
+	line("add rule %s filter %s ip saddr %s ip daddr %s accept", family, instanceChain, network, network)
+	line("add rule %s filter %s goto %s", family, instanceChain, nft.defaultChain)
+
+	// Bind filter instance chain to filter forward chain, after the rule
+	// accepting inbound traffic
+	line("insert rule %s filter %s index 1 iifname %q ip saddr %s goto %s", family, nft.forwardChain, bridgeName, ip, instanceChain)
+
+	if len(handle) > 29 {
+		handle = handle[0:29]
+	}
+
+	line("create chain %s filter %s", family, loggingChain)
+	line("add rule %s filter %s ct state new,untracked,invalid meta l4proto tcp log prefix %q", family, loggingChain, handle)
+	line("add rule %s filter %s return", family, loggingChain)
+
+	return nft.apply("create-instance-chains", script.String())
+}
+
+func (cc *InstanceChainCreator) postroutingContains(substrings ...string) bool {
+	lines, err := cc.nft.listChain("nat", cc.nft.postroutingChain)
+	if err != nil {
+		return false
+	}
+
+	for _, line := range lines {
+		matches := true
+		for _, s := range substrings {
+			matches = matches && strings.Contains(line, s)
+		}
+
+		if matches {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (cc *InstanceChainCreator) Destroy(logger lager.Logger, instanceId string) error {
+	nft := cc.nft
+	instanceChain := nft.InstanceChain(instanceId)
+
+	// Prune nat prerouting chain
+	if err := nft.DeleteChainReferences("nat", nft.preroutingChain, instanceChain); err != nil {
+		return err
+	}
+
+	// Flush and delete nat instance chain
+	nft.FlushChain("nat", instanceChain)
+	nft.DeleteChain("nat", instanceChain)
+
+	// Prune forward chain
+	if err := nft.DeleteChainReferences("filter", nft.forwardChain, instanceChain); err != nil {
+		return err
+	}
+
+	// Flush and delete filter instance chain, and the logging chain
+	instanceLoggingChain := fmt.Sprintf("%s-log", instanceChain)
+	nft.FlushChain("filter", instanceChain)
+	nft.FlushChain("filter", instanceLoggingChain)
+	nft.DeleteChain("filter", instanceChain)
+	nft.DeleteChain("filter", instanceLoggingChain)
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package nftables_test
+
+import (
+	"errors"
+	"net"
+
+	"code.cloudfoundry.org/guardian/kawasaki/nftables"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InstanceChainCreator", func() {
+	var (
+		fakeRunner *fake_command_runner.FakeCommandRunner
+		scripts    *[]string
+		applyErr   error
+		creator    *nftables.InstanceChainCreator
+		ip         net.IP
+		network    *net.IPNet
+		logger     lager.Logger
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		fakeRunner = fake_command_runner.New()
+		applyErr = nil
+		scripts = recordScripts(fakeRunner, &applyErr)
+		logger = lagertest.NewTestLogger("test")
+
+		ip, network, err = net.ParseCIDR("1.2.3.4/28")
+		Expect(err).NotTo(HaveOccurred())
+
+		creator = nftables.NewInstanceChainCreator(nftables.New("/usr/sbin/nft", fakeRunner, "prefix-"))
+	})
+
+	Describe("Create", func() {
+		It("creates the instance chains in a single transaction", func() {
+			Expect(creator.Create(logger, "some-handle-that-is-longer-than-29-characters-long", "some-id", "some-bridge", ip, network)).To(Succeed())
+
+			Expect(*scripts).To(Equal([]string{`create chain ip nat prefix-instance-some-id
+add rule ip nat prefix-prerouting jump prefix-instance-some-id
+add rule ip nat prefix-postrouting ip saddr 1.2.3.0/28 ip daddr != 1.2.3.0/28 masquerade
+create chain ip filter prefix-instance-some-id
+add rule ip filter prefix-instance-some-id ip saddr 1.2.3.0/28 ip daddr 1.2.3.0/28 accept
+add rule ip filter prefix-instance-some-id goto prefix-default
+insert rule ip filter prefix-forward index 1 iifname "some-bridge" ip saddr 1.2.3.4 goto prefix-instance-some-id
+create chain ip filter prefix-instance-some-id-log
+add rule ip filter prefix-instance-some-id-log ct state new,untracked,invalid meta l4proto tcp log prefix "some-handle-that-is-longer-th"
+add rule ip filter prefix-instance-some-id-log return
+`}))
+		})
+
+		Context("when the subnet is already masqueraded", func() {
+			BeforeEach(func() {
+				listChainReturns(fakeRunner, "nat", "prefix-postrouting", `table ip nat {
+	chain prefix-postrouting { # handle 2
+		ip saddr 1.2.3.0/28 ip daddr != 1.2.3.0/28 masquerade # handle 9
+	}
+}
+`)
+			})
+
+			It("does not masquerade it again", func() {
+				Expect(creator.Create(logger, "some-handle", "some-id", "some-bridge", ip, network)).To(Succeed())
+				Expect((*scripts)[0]).NotTo(ContainSubstring("masquerade"))
+			})
+		})
+
+		Context("when nft fails", func() {
+			BeforeEach(func() {
+				applyErr = errors.New("exit status 1")
+			})
+
+			It("returns an error", func() {
+				Expect(creator.Create(logger, "some-handle", "some-id", "some-bridge", ip, network)).To(MatchError(ContainSubstring("create-instance-chains")))
+			})
+		})
+	})
+
+	Describe("Destroy", func() {
+		BeforeEach(func() {
+			listChainReturns(fakeRunner, "nat", "prefix-prerouting", `table ip nat {
+	chain prefix-prerouting { # handle 2
+		jump prefix-instance-some-id # handle 5
+		jump prefix-instance-other-id # handle 6
+	}
+}
+`)
+			listChainReturns(fakeRunner, "filter", "prefix-forward", `table ip filter {
+	chain prefix-forward { # handle 3
+		iifname "eth0" accept # handle 4
+		iifname "some-bridge" ip saddr 1.2.3.4 goto prefix-instance-some-id # handle 7
+		drop # handle 8
+	}
+}
+`)
+		})
+
+		It("removes the references to the instance chains, then the chains", func() {
+			Expect(creator.Destroy(logger, "some-id")).To(Succeed())
+
+			Expect(*scripts).To(Equal([]string{
+				"delete rule ip nat prefix-prerouting handle 5\n",
+				"flush chain ip nat prefix-instance-some-id\n",
+				"delete chain ip nat prefix-instance-some-id\n",
+				"delete rule ip filter prefix-forward handle 7\n",
+				"flush chain ip filter prefix-instance-some-id\n",
+				"flush chain ip filter prefix-instance-some-id-log\n",
+				"delete chain ip filter prefix-instance-some-id\n",
+				"delete chain ip filter prefix-instance-some-id-log\n",
+			}))
+		})
+
+		Context("when deleting a reference fails", func() {
+			BeforeEach(func() {
+				applyErr = errors.New("exit status 1")
+			})
+
+			It("returns an error", func() {
+				Expect(creator.Destroy(logger, "some-id")).To(MatchError(ContainSubstring("delete-referenced-chains")))
+			})
+		})
+	})
+})
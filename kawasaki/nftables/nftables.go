@@ -0,0 +1,254 @@
+package nftables
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"code.cloudfoundry.org/guardian/kawasaki/iptables"
+	"github.com/cloudfoundry/gunk/command_runner"
+)
+
+// family is the nft address family of the tables kawasaki manages. The
+// tables are named filter and nat, as iptables-nft names them, so that the
+// rules are visible to both tools.
+const family = "ip"
+
+var handlePattern = regexp.MustCompile(`# handle (\d+)$`)
+
+// NFTablesController implements iptables.IPTables using nft. The chains are
+// named exactly as IPTablesController names them.
+type NFTablesController struct {
+	runner                                                                                         command_runner.CommandRunner
+	binPath, chainPrefix                                                                           string
+	preroutingChain, postroutingChain, inputChain, forwardChain, defaultChain, instanceChainPrefix string
+}
+
+func New(binPath string, runner command_runner.CommandRunner, chainPrefix string) *NFTablesController {
+	return &NFTablesController{
+		runner:      runner,
+		binPath:     binPath,
+		chainPrefix: chainPrefix,
+
+		preroutingChain:     chainPrefix + "prerouting",
+		postroutingChain:    chainPrefix + "postrouting",
+		inputChain:          chainPrefix + "input",
+		forwardChain:        chainPrefix + "forward",
+		defaultChain:        chainPrefix + "default",
+		instanceChainPrefix: chainPrefix + "instance-",
+	}
+}
+
+func (nft *NFTablesController) CreateChain(table, chain string) error {
+	return nft.apply("create-instance-chains", fmt.Sprintf("create chain %s %s %s\n", family, table, chain))
+}
+
+func (nft *NFTablesController) DeleteChain(table, chain string) error {
+	// like the iptables backend, a chain which does not exist is not an error
+	nft.apply("delete-instance-chains", fmt.Sprintf("delete chain %s %s %s\n", family, table, chain))
+	return nil
+}
+
+func (nft *NFTablesController) FlushChain(table, chain string) error {
+	nft.apply("flush-instance-chains", fmt.Sprintf("flush chain %s %s %s\n", family, table, chain))
+	return nil
+}
+
+// DeleteChainReferences deletes the rules in targetChain which jump or go to
+// referencedChain
+func (nft *NFTablesController) DeleteChainReferences(table, targetChain, referencedChain string) error {
+	return nft.deleteReferences(table, targetChain, func(chain string) bool {
+		return chain == referencedChain
+	})
+}
+
+// deleteReferences deletes the rules in targetChain which jump or go to a
+// chain matching the predicate. A targetChain which does not exist has no
+// references, so is not an error.
+func (nft *NFTablesController) deleteReferences(table, targetChain string, references func(chain string) bool) error {
+	lines, err := nft.listChain(table, targetChain)
+	if err != nil {
+		return nil
+	}
+
+	var script bytes.Buffer
+	for _, line := range lines {
+		if !references(verdictChain(line)) {
+			continue
+		}
+
+		if handle, ok := ruleHandle(line); ok {
+			fmt.Fprintf(&script, "delete rule %s %s %s handle %s\n", family, table, targetChain, handle)
+		}
+	}
+
+	if script.Len() == 0 {
+		return nil
+	}
+
+	return nft.apply("delete-referenced-chains", script.String())
+}
+
+func (nft *NFTablesController) AppendRule(chain string, r iptables.Rule) error {
+	return nft.addRules("append", "add", chain, []iptables.Rule{r})
+}
+
+func (nft *NFTablesController) PrependRule(chain string, r iptables.Rule) error {
+	return nft.addRules("prepend", "insert", chain, []iptables.Rule{r})
+}
+
+// BulkPrependRules prepends all of the rules in a single nft transaction, so
+// either all of them are applied or none are
+func (nft *NFTablesController) BulkPrependRules(chain string, rules []iptables.Rule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	return nft.addRules("bulk-prepend", "insert", chain, rules)
+}
+
+func (nft *NFTablesController) DeleteRule(chain string, r iptables.Rule) error {
+	translated, err := translate(r.Flags(chain))
+	if err != nil {
+		return err
+	}
+
+	lines, err := nft.listChain(translated.table, chain)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		if !strings.Contains(line, fmt.Sprintf("comment %q", translated.id)) {
+			continue
+		}
+
+		if handle, ok := ruleHandle(line); ok {
+			return nft.apply("delete", fmt.Sprintf("delete rule %s %s %s handle %s\n", family, translated.table, chain, handle))
+		}
+	}
+
+	return fmt.Errorf("%s delete: rule not found in chain %s: %s", nft.binPath, chain, translated.expr)
+}
+
+func (nft *NFTablesController) InstanceChain(instanceId string) string {
+	return nft.instanceChainPrefix + instanceId
+}
+
+// InstanceChains returns the instance ids of the instance chains in the filter table
+func (nft *NFTablesController) InstanceChains() ([]string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command(nft.binPath, "list", "chains", family)
+	cmd.Stdout = &stdout
+	if err := nft.run("list-instance-chains", cmd); err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	table := ""
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "table":
+			table = fields[len(fields)-2]
+		case "chain":
+			if table != "filter" || !strings.HasPrefix(fields[1], nft.instanceChainPrefix) {
+				continue
+			}
+
+			// each instance also has a logging chain, named <instance chain>-log
+			id := strings.TrimPrefix(fields[1], nft.instanceChainPrefix)
+			if strings.HasSuffix(id, "-log") {
+				continue
+			}
+
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, scanner.Err()
+}
+
+func (nft *NFTablesController) addRules(action, verb, chain string, rules []iptables.Rule) error {
+	var script bytes.Buffer
+	for _, r := range rules {
+		translated, err := translate(r.Flags(chain))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&script, "%s rule %s %s %s %s comment %q\n", verb, family, translated.table, chain, translated.expr, translated.id)
+	}
+
+	return nft.apply(action, script.String())
+}
+
+func (nft *NFTablesController) chainExists(table, chain string) bool {
+	_, err := nft.listChain(table, chain)
+	return err == nil
+}
+
+// listChain returns the lines of the chain listing, annotated with rule handles
+func (nft *NFTablesController) listChain(table, chain string) ([]string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command(nft.binPath, "--handle", "list", "chain", family, table, chain)
+	cmd.Stdout = &stdout
+	if err := nft.run("list-chain", cmd); err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSpace(scanner.Text()))
+	}
+
+	return lines, scanner.Err()
+}
+
+// apply runs an nft script, which nft applies as a single transaction
+func (nft *NFTablesController) apply(action, script string) error {
+	cmd := exec.Command(nft.binPath, "--file", "-")
+	cmd.Stdin = strings.NewReader(script)
+	return nft.run(action, cmd)
+}
+
+func (nft *NFTablesController) run(action string, cmd *exec.Cmd) error {
+	var buff bytes.Buffer
+	cmd.Stderr = &buff
+
+	if err := nft.runner.Run(cmd); err != nil {
+		return fmt.Errorf("%s %s: %s", nft.binPath, action, buff.String())
+	}
+
+	return nil
+}
+
+// verdictChain returns the chain a listed rule jumps or goes to, if any
+func verdictChain(line string) string {
+	fields := strings.Fields(line)
+	for i := 0; i < len(fields)-1; i++ {
+		if fields[i] == "jump" || fields[i] == "goto" {
+			return fields[i+1]
+		}
+	}
+
+	return ""
+}
+
+func ruleHandle(line string) (string, bool) {
+	match := handlePattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", false
+	}
+
+	return match[1], true
+}
@@ -0,0 +1,318 @@
+package nftables_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os/exec"
+	"regexp"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/guardian/kawasaki/iptables"
+	fakes "code.cloudfoundry.org/guardian/kawasaki/iptables/iptablesfakes"
+	"code.cloudfoundry.org/guardian/kawasaki/nftables"
+	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
+	. "github.com/cloudfoundry/gunk/command_runner/fake_command_runner/matchers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var commentPattern = regexp.MustCompile(` comment "[0-9a-f]+"`)
+
+// withoutComments strips the rule ids, which are a hash of the iptables flags
+func withoutComments(script string) string {
+	return commentPattern.ReplaceAllString(script, "")
+}
+
+// recordScripts records the scripts applied with nft --file -, which then
+// fails with *result, if set
+func recordScripts(fakeRunner *fake_command_runner.FakeCommandRunner, result *error) *[]string {
+	scripts := &[]string{}
+	fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+		Path: "/usr/sbin/nft",
+		Args: []string{"--file", "-"},
+	}, func(cmd *exec.Cmd) error {
+		script, err := ioutil.ReadAll(cmd.Stdin)
+		Expect(err).NotTo(HaveOccurred())
+		*scripts = append(*scripts, string(script))
+		return *result
+	})
+
+	return scripts
+}
+
+func icmpCode(code garden.ICMPCode) *garden.ICMPCode {
+	return &code
+}
+
+func listChainReturns(fakeRunner *fake_command_runner.FakeCommandRunner, table, chain, listing string) {
+	fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+		Path: "/usr/sbin/nft",
+		Args: []string{"--handle", "list", "chain", "ip", table, chain},
+	}, func(cmd *exec.Cmd) error {
+		_, err := cmd.Stdout.Write([]byte(listing))
+		return err
+	})
+}
+
+var _ = Describe("NFTablesController", func() {
+	var (
+		fakeRunner *fake_command_runner.FakeCommandRunner
+		controller iptables.IPTables
+		scripts    *[]string
+		applyErr   error
+	)
+
+	BeforeEach(func() {
+		fakeRunner = fake_command_runner.New()
+		applyErr = nil
+		scripts = recordScripts(fakeRunner, &applyErr)
+		controller = nftables.New("/usr/sbin/nft", fakeRunner, "prefix-")
+	})
+
+	Describe("CreateChain", func() {
+		It("creates the chain in the table", func() {
+			Expect(controller.CreateChain("nat", "some-chain")).To(Succeed())
+			Expect(*scripts).To(Equal([]string{"create chain ip nat some-chain\n"}))
+		})
+
+		Context("when nft fails", func() {
+			BeforeEach(func() {
+				applyErr = errors.New("exit status 1")
+			})
+
+			It("returns an error", func() {
+				Expect(controller.CreateChain("nat", "some-chain")).To(MatchError(ContainSubstring("/usr/sbin/nft create-instance-chains")))
+			})
+		})
+	})
+
+	Describe("DeleteChain and FlushChain", func() {
+		BeforeEach(func() {
+			applyErr = errors.New("No such file or directory")
+		})
+
+		It("ignores chains which do not exist, like the iptables backend", func() {
+			Expect(controller.FlushChain("filter", "some-chain")).To(Succeed())
+			Expect(controller.DeleteChain("filter", "some-chain")).To(Succeed())
+
+			Expect(*scripts).To(Equal([]string{
+				"flush chain ip filter some-chain\n",
+				"delete chain ip filter some-chain\n",
+			}))
+		})
+	})
+
+	Describe("DeleteChainReferences", func() {
+		It("deletes the rules which jump or go to the chain, by handle", func() {
+			listChainReturns(fakeRunner, "nat", "prefix-prerouting", `table ip nat {
+	chain prefix-prerouting { # handle 3
+		jump prefix-instance-abc # handle 7
+		jump prefix-instance-abcd # handle 8
+		goto prefix-instance-abc comment "0123" # handle 9
+	}
+}
+`)
+
+			Expect(controller.DeleteChainReferences("nat", "prefix-prerouting", "prefix-instance-abc")).To(Succeed())
+			Expect(*scripts).To(Equal([]string{
+				"delete rule ip nat prefix-prerouting handle 7\n" +
+					"delete rule ip nat prefix-prerouting handle 9\n",
+			}))
+		})
+
+		It("does nothing when the chain does not exist", func() {
+			fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+				Path: "/usr/sbin/nft",
+				Args: []string{"--handle", "list", "chain", "ip", "nat", "prefix-prerouting"},
+			}, func(*exec.Cmd) error {
+				return errors.New("exit status 1")
+			})
+
+			Expect(controller.DeleteChainReferences("nat", "prefix-prerouting", "prefix-instance-abc")).To(Succeed())
+			Expect(*scripts).To(BeEmpty())
+		})
+	})
+
+	Describe("adding rules", func() {
+		var rule *fakes.FakeRule
+
+		BeforeEach(func() {
+			rule = new(fakes.FakeRule)
+			rule.FlagsReturns([]string{"--protocol", "tcp", "--jump", "ACCEPT"})
+		})
+
+		It("appends the rule, identified by a comment", func() {
+			Expect(controller.AppendRule("some-chain", rule)).To(Succeed())
+
+			Expect(*scripts).To(HaveLen(1))
+			Expect((*scripts)[0]).To(MatchRegexp(`^add rule ip filter some-chain meta l4proto tcp accept comment "[0-9a-f]{16}"\n$`))
+		})
+
+		It("prepends the rule", func() {
+			Expect(controller.PrependRule("some-chain", rule)).To(Succeed())
+			Expect(withoutComments((*scripts)[0])).To(Equal("insert rule ip filter some-chain meta l4proto tcp accept\n"))
+		})
+
+		It("prepends rules in bulk in a single transaction", func() {
+			udpRule := new(fakes.FakeRule)
+			udpRule.FlagsReturns([]string{"--protocol", "udp", "--jump", "RETURN"})
+
+			Expect(controller.BulkPrependRules("some-chain", []iptables.Rule{rule, udpRule})).To(Succeed())
+			Expect(*scripts).To(HaveLen(1))
+			Expect(withoutComments((*scripts)[0])).To(Equal(
+				"insert rule ip filter some-chain meta l4proto tcp accept\n" +
+					"insert rule ip filter some-chain meta l4proto udp return\n",
+			))
+		})
+
+		It("applies nothing when any rule in the bulk cannot be translated", func() {
+			badRule := new(fakes.FakeRule)
+			badRule.FlagsReturns([]string{"--banana", "split"})
+
+			Expect(controller.BulkPrependRules("some-chain", []iptables.Rule{rule, badRule})).To(MatchError("nftables: unsupported rule flag: --banana"))
+			Expect(*scripts).To(BeEmpty())
+		})
+
+		It("uses the table given in the rule flags", func() {
+			rule.FlagsReturns([]string{"--table", "nat", "--jump", "MASQUERADE"})
+
+			Expect(controller.AppendRule("some-chain", rule)).To(Succeed())
+			Expect(withoutComments((*scripts)[0])).To(Equal("add rule ip nat some-chain masquerade\n"))
+		})
+	})
+
+	Describe("DeleteRule", func() {
+		var (
+			rule  *fakes.FakeRule
+			added string
+		)
+
+		BeforeEach(func() {
+			rule = new(fakes.FakeRule)
+			rule.FlagsReturns([]string{"--protocol", "tcp", "--jump", "RETURN"})
+
+			Expect(controller.AppendRule("some-chain", rule)).To(Succeed())
+			added = (*scripts)[0]
+			*scripts = nil
+		})
+
+		It("deletes the rule with the matching comment by handle", func() {
+			comment := commentPattern.FindString(added)
+			listChainReturns(fakeRunner, "filter", "some-chain", "table ip filter {\n"+
+				"\tchain some-chain { # handle 1\n"+
+				"\t\tmeta l4proto udp return comment \"ffffffffffffffff\" # handle 4\n"+
+				"\t\tmeta l4proto tcp return"+comment+" # handle 5\n"+
+				"\t}\n}\n")
+
+			Expect(controller.DeleteRule("some-chain", rule)).To(Succeed())
+			Expect(*scripts).To(Equal([]string{"delete rule ip filter some-chain handle 5\n"}))
+		})
+
+		It("returns an error when the rule does not exist", func() {
+			listChainReturns(fakeRunner, "filter", "some-chain", "table ip filter {\n\tchain some-chain { # handle 1\n\t}\n}\n")
+
+			Expect(controller.DeleteRule("some-chain", rule)).To(MatchError(ContainSubstring("rule not found")))
+			Expect(*scripts).To(BeEmpty())
+		})
+	})
+
+	Describe("InstanceChain", func() {
+		It("names the chain as the iptables backend does", func() {
+			Expect(controller.InstanceChain("some-id")).To(Equal("prefix-instance-some-id"))
+		})
+	})
+
+	Describe("InstanceChains", func() {
+		It("returns the ids of the filter instance chains, ignoring logging chains", func() {
+			fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+				Path: "/usr/sbin/nft",
+				Args: []string{"list", "chains", "ip"},
+			}, func(cmd *exec.Cmd) error {
+				_, err := cmd.Stdout.Write([]byte(`table ip filter {
+	chain INPUT {
+		type filter hook input priority filter; policy accept;
+	}
+	chain prefix-instance-abc {
+	}
+	chain prefix-instance-abc-log {
+	}
+	chain other-instance-def {
+	}
+}
+table ip nat {
+	chain prefix-instance-abc {
+	}
+	chain prefix-instance-xyz {
+	}
+}
+`))
+				return err
+			})
+
+			Expect(controller.InstanceChains()).To(Equal([]string{"abc"}))
+		})
+	})
+
+	Describe("translating kawasaki rules", func() {
+		DescribeTable("filter rules",
+			func(rule iptables.SingleFilterRule, expected string) {
+				Expect(controller.AppendRule("some-chain", rule)).To(Succeed())
+				Expect(withoutComments((*scripts)[0])).To(Equal("add rule ip filter some-chain " + expected + "\n"))
+			},
+			Entry("all protocols", iptables.SingleFilterRule{}, "return"),
+			Entry("a network and port range",
+				iptables.SingleFilterRule{
+					Protocol: garden.ProtocolTCP,
+					Networks: &garden.IPRange{Start: net.ParseIP("1.2.3.4"), End: net.ParseIP("1.2.3.9")},
+					Ports:    &garden.PortRange{Start: 1000, End: 2000},
+				},
+				"meta l4proto tcp ip daddr 1.2.3.4-1.2.3.9 tcp dport 1000-2000 return",
+			),
+			Entry("a single destination and port",
+				iptables.SingleFilterRule{
+					Protocol: garden.ProtocolUDP,
+					Networks: &garden.IPRange{Start: net.ParseIP("8.8.8.8")},
+					Ports:    &garden.PortRange{Start: 53, End: 53},
+				},
+				"meta l4proto udp ip daddr 8.8.8.8 udp dport 53 return",
+			),
+			Entry("icmp type and code",
+				iptables.SingleFilterRule{
+					Protocol: garden.ProtocolICMP,
+					ICMPs:    &garden.ICMPControl{Type: 8, Code: icmpCode(0)},
+				},
+				"meta l4proto icmp icmp type 8 icmp code 0 return",
+			),
+			Entry("logging",
+				iptables.SingleFilterRule{Protocol: garden.ProtocolTCP, Log: true},
+				"meta l4proto tcp goto some-chain-log",
+			),
+		)
+
+		It("translates port forwarding rules", func() {
+			forwarder := iptables.NewPortForwarder(controller)
+			Expect(forwarder.Forward(kawasaki.PortForwarderSpec{
+				InstanceID:  "some-instance",
+				ExternalIP:  net.ParseIP("5.6.7.8"),
+				ContainerIP: net.ParseIP("1.2.3.4"),
+				FromPort:    22,
+				ToPort:      33,
+			})).To(Succeed())
+
+			Expect(withoutComments((*scripts)[0])).To(Equal(
+				"add rule ip nat prefix-instance-some-instance meta l4proto tcp ip daddr 5.6.7.8 tcp dport 22 dnat to 1.2.3.4:33\n",
+			))
+		})
+
+		It("returns an error for flags it does not support", func() {
+			rule := new(fakes.FakeRule)
+			rule.FlagsReturns([]string{"--protocol", "icmp", "--destination-port", "22"})
+
+			Expect(controller.AppendRule("some-chain", rule)).To(MatchError(ContainSubstring("requires protocol tcp or udp")))
+			Expect(fakeRunner).NotTo(HaveExecutedSerially(fake_command_runner.CommandSpec{Path: "/usr/sbin/nft"}))
+		})
+	})
+})
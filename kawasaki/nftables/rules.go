@@ -0,0 +1,165 @@
+package nftables
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+)
+
+// rule is an iptables rule, as produced by iptables.Rule.Flags, translated
+// into an nft statement for a table in the ip family
+type rule struct {
+	table string
+	expr  string
+	id    string
+}
+
+// translate converts the iptables flags used by kawasaki into nft syntax.
+// Only the matches and targets which kawasaki produces are supported.
+func translate(flags []string) (rule, error) {
+	var (
+		r        = rule{table: "filter", id: ruleID(flags)}
+		exprs    []string
+		protocol string
+		negate   bool
+	)
+
+	next := func(i *int) (string, error) {
+		*i++
+		if *i >= len(flags) {
+			return "", fmt.Errorf("nftables: missing value for %s", flags[*i-1])
+		}
+		return flags[*i], nil
+	}
+
+	op := func() string {
+		if negate {
+			negate = false
+			return "!= "
+		}
+		return ""
+	}
+
+	for i := 0; i < len(flags); i++ {
+		flag := flags[i]
+
+		switch flag {
+		case "!":
+			negate = true
+			continue
+		case "-m":
+			// match extensions are implied by the flags which follow them
+			if _, err := next(&i); err != nil {
+				return rule{}, err
+			}
+			continue
+		}
+
+		value, err := next(&i)
+		if err != nil {
+			return rule{}, err
+		}
+
+		switch flag {
+		case "--table", "-t":
+			r.table = value
+		case "--protocol", "-p":
+			if value != "all" {
+				protocol = value
+				exprs = append(exprs, "meta l4proto "+value)
+			}
+		case "--source", "-s":
+			exprs = append(exprs, "ip saddr "+op()+value)
+		case "--destination", "-d":
+			exprs = append(exprs, "ip daddr "+op()+value)
+		case "--dst-range":
+			exprs = append(exprs, "ip daddr "+op()+value)
+		case "--destination-port", "--dport":
+			if protocol != "tcp" && protocol != "udp" {
+				return rule{}, fmt.Errorf("nftables: %s requires protocol tcp or udp", flag)
+			}
+			exprs = append(exprs, protocol+" dport "+op()+strings.Replace(value, ":", "-", 1))
+		case "--icmp-type":
+			parts := strings.SplitN(value, "/", 2)
+			exprs = append(exprs, "icmp type "+parts[0])
+			if len(parts) == 2 {
+				exprs = append(exprs, "icmp code "+parts[1])
+			}
+		case "--ctstate":
+			exprs = append(exprs, "ct state "+strings.ToLower(value))
+		case "--in-interface", "-i":
+			exprs = append(exprs, "iifname "+op()+interfaceName(value))
+		case "--out-interface", "-o":
+			exprs = append(exprs, "oifname "+op()+interfaceName(value))
+		case "--goto", "-g":
+			exprs = append(exprs, "goto "+value)
+		case "--jump", "-j":
+			verdict, err := target(value, flags, &i, next)
+			if err != nil {
+				return rule{}, err
+			}
+			exprs = append(exprs, verdict)
+		default:
+			return rule{}, fmt.Errorf("nftables: unsupported rule flag: %s", flag)
+		}
+	}
+
+	r.expr = strings.Join(exprs, " ")
+	return r, nil
+}
+
+func target(name string, flags []string, i *int, next func(*int) (string, error)) (string, error) {
+	option := func(flag string) (string, bool, error) {
+		if *i+1 >= len(flags) || flags[*i+1] != flag {
+			return "", false, nil
+		}
+
+		*i++
+		value, err := next(i)
+		return value, true, err
+	}
+
+	switch name {
+	case "ACCEPT", "DROP", "RETURN":
+		return strings.ToLower(name), nil
+	case "MASQUERADE":
+		return "masquerade", nil
+	case "REJECT":
+		with, ok, err := option("--reject-with")
+		if err != nil || !ok {
+			return "reject", err
+		}
+		return "reject with icmp type " + strings.TrimPrefix(with, "icmp-"), nil
+	case "DNAT":
+		to, ok, err := option("--to-destination")
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", fmt.Errorf("nftables: DNAT requires --to-destination")
+		}
+		return "dnat to " + to, nil
+	case "LOG":
+		prefix, ok, err := option("--log-prefix")
+		if err != nil || !ok {
+			return "log", err
+		}
+		return fmt.Sprintf("log prefix %q", prefix), nil
+	default:
+		return "jump " + name, nil
+	}
+}
+
+// interfaceName converts the iptables "+" wildcard suffix to nft's "*"
+func interfaceName(name string) string {
+	if strings.HasSuffix(name, "+") {
+		name = strings.TrimSuffix(name, "+") + "*"
+	}
+	return fmt.Sprintf("%q", name)
+}
+
+// ruleID identifies a rule by its flags, so that it can be found again when
+// it is deleted. nft only deletes rules by handle, not by their contents.
+func ruleID(flags []string) string {
+	return fmt.Sprintf("%x", sha1.Sum([]byte(strings.Join(flags, " "))))[:16]
+}
@@ -73,6 +73,14 @@ func (p *PortPool) Acquire() (uint32, error) {
 	return port, nil
 }
 
+// Available returns the number of ports which can still be acquired.
+func (p *PortPool) Available() int {
+	p.poolMutex.Lock()
+	defer p.poolMutex.Unlock()
+
+	return len(p.pool)
+}
+
 func (p *PortPool) Remove(port uint32) error {
 	idx := 0
 	found := false
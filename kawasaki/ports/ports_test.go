@@ -131,6 +131,22 @@ var _ = Describe("Port pool", func() {
 		})
 	})
 
+	Describe("Available", func() {
+		It("returns the number of ports which can still be acquired", func() {
+			pool, err := ports.NewPool(10000, 3, initialState)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pool.Available()).To(Equal(3))
+
+			port, err := pool.Acquire()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pool.Remove(10002)).To(Succeed())
+			Expect(pool.Available()).To(Equal(1))
+
+			pool.Release(port)
+			Expect(pool.Available()).To(Equal(2))
+		})
+	})
+
 	Describe("releasing", func() {
 		It("places a port back at the end of the pool", func() {
 			pool, err := ports.NewPool(10000, 2, initialState)
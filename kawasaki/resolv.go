@@ -99,6 +99,10 @@ func (d *ResolvConfigurer) Configure(log lager.Logger, cfg NetworkConfig, pid in
 		return err
 	}
 
+	if cfg.ContainerIPv6 != nil {
+		contents = appendNameservers(contents, cfg.IPv6DNSServers)
+	}
+
 	if err := d.FileWriter.WriteFile(log, "/etc/resolv.conf", contents, fmt.Sprintf("/proc/%d/root", pid), rootUid, rootGid); err != nil {
 		log.Error("writting-resolv-file", err)
 		return fmt.Errorf("writting file '/etc/resolv.conf': %s", err)
@@ -106,3 +110,15 @@ func (d *ResolvConfigurer) Configure(log lager.Logger, cfg NetworkConfig, pid in
 
 	return nil
 }
+
+func appendNameservers(resolvConf []byte, servers []net.IP) []byte {
+	if len(servers) > 0 && len(resolvConf) > 0 && resolvConf[len(resolvConf)-1] != '\n' {
+		resolvConf = append(resolvConf, '\n')
+	}
+
+	for _, server := range servers {
+		resolvConf = append(resolvConf, fmt.Sprintf("nameserver %s\n", server)...)
+	}
+
+	return resolvConf
+}
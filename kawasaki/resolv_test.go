@@ -3,6 +3,7 @@ package kawasaki_test
 import (
 	"errors"
 	"io/ioutil"
+	"net"
 	"os"
 
 	"code.cloudfoundry.org/guardian/kawasaki"
@@ -79,6 +80,34 @@ var _ = Describe("ResolvConfigurer", func() {
 		Expect(gid).To(Equal(13))
 	})
 
+	Context("when the container has an IPv6 address", func() {
+		It("appends the IPv6 DNS servers to the resolv file", func() {
+			fakeResolvFileCompiler.CompileReturns([]byte("nameserver 8.8.8.8"), nil)
+
+			Expect(dnsResolv.Configure(log, kawasaki.NetworkConfig{
+				ContainerIPv6:  net.ParseIP("fd00::2"),
+				IPv6DNSServers: []net.IP{net.ParseIP("2001:4860:4860::8888")},
+			}, 42)).To(Succeed())
+
+			_, filePath, contents, _, _, _ := fakeFileWriter.WriteFileArgsForCall(1)
+			Expect(filePath).To(Equal("/etc/resolv.conf"))
+			Expect(string(contents)).To(Equal("nameserver 8.8.8.8\nnameserver 2001:4860:4860::8888\n"))
+		})
+	})
+
+	Context("when the container has no IPv6 address", func() {
+		It("does not add the IPv6 DNS servers", func() {
+			fakeResolvFileCompiler.CompileReturns([]byte("nameserver 8.8.8.8\n"), nil)
+
+			Expect(dnsResolv.Configure(log, kawasaki.NetworkConfig{
+				IPv6DNSServers: []net.IP{net.ParseIP("2001:4860:4860::8888")},
+			}, 42)).To(Succeed())
+
+			_, _, contents, _, _, _ := fakeFileWriter.WriteFileArgsForCall(1)
+			Expect(string(contents)).To(Equal("nameserver 8.8.8.8\n"))
+		})
+	})
+
 	Context("when compiling the resolv.conf file fails", func() {
 		It("should return an error", func() {
 			fakeResolvFileCompiler.CompileReturns(nil, errors.New("banana error"))
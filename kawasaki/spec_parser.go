@@ -1,6 +1,7 @@
 package kawasaki
 
 import (
+	"fmt"
 	"net"
 	"strings"
 
@@ -15,11 +16,23 @@ func (fn SpecParserFunc) Parse(log lager.Logger, spec string) (subnets.SubnetSel
 }
 
 func ParseSpec(spec string) (subnets.SubnetSelector, subnets.IPSelector, error) {
+	return parseSpec(spec, subnets.DefaultSubnetPrefix)
+}
+
+// ParseSpecWithSubnetPrefix returns a parser which gives specs without a
+// prefix length the given one, rather than /30
+func ParseSpecWithSubnetPrefix(prefix int) SpecParserFunc {
+	return func(spec string) (subnets.SubnetSelector, subnets.IPSelector, error) {
+		return parseSpec(spec, prefix)
+	}
+}
+
+func parseSpec(spec string, prefix int) (subnets.SubnetSelector, subnets.IPSelector, error) {
 	var ipSelector subnets.IPSelector = subnets.DynamicIPSelector
 	var subnetSelector subnets.SubnetSelector = subnets.DynamicSubnetSelector
 
 	if spec != "" {
-		specifiedIP, ipn, err := net.ParseCIDR(suffixIfNeeded(spec))
+		specifiedIP, ipn, err := net.ParseCIDR(suffixIfNeeded(spec, prefix))
 		if err != nil {
 			return nil, nil, err
 		}
@@ -34,9 +47,20 @@ func ParseSpec(spec string) (subnets.SubnetSelector, subnets.IPSelector, error)
 	return subnetSelector, ipSelector, nil
 }
 
-func suffixIfNeeded(spec string) string {
+// SplitPoolSpec splits a network spec of the form <pool>:<spec> into the
+// name of the network pool and the spec of the subnet within it. The pool
+// name is empty if the spec does not have one.
+func SplitPoolSpec(spec string) (pool, subnetSpec string) {
+	if i := strings.Index(spec, ":"); i >= 0 {
+		return spec[:i], spec[i+1:]
+	}
+
+	return "", spec
+}
+
+func suffixIfNeeded(spec string, prefix int) string {
 	if !strings.Contains(spec, "/") {
-		spec = spec + "/30"
+		spec = fmt.Sprintf("%s/%d", spec, prefix)
 	}
 
 	return spec
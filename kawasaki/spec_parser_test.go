@@ -73,3 +73,42 @@ var _ = Describe("ParseSpec", func() {
 		})
 	})
 })
+
+var _ = Describe("ParseSpecWithSubnetPrefix", func() {
+	It("gives a spec without a prefix length the configured one", func() {
+		subnetReq, ipReq, err := kawasaki.ParseSpecWithSubnetPrefix(24)("1.2.3.0")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, sn, _ := net.ParseCIDR("1.2.3.0/24")
+		Expect(subnetReq).To(Equal(subnets.StaticSubnetSelector{IPNet: sn}))
+		Expect(ipReq).To(Equal(subnets.DynamicIPSelector))
+	})
+
+	It("leaves a spec with a prefix length unchanged", func() {
+		subnetReq, _, err := kawasaki.ParseSpecWithSubnetPrefix(24)("1.2.3.4/30")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, sn, _ := net.ParseCIDR("1.2.3.4/30")
+		Expect(subnetReq).To(Equal(subnets.StaticSubnetSelector{IPNet: sn}))
+	})
+})
+
+var _ = Describe("SplitPoolSpec", func() {
+	It("returns an empty pool name when the spec has no pool prefix", func() {
+		pool, spec := kawasaki.SplitPoolSpec("1.2.3.0/30")
+		Expect(pool).To(BeEmpty())
+		Expect(spec).To(Equal("1.2.3.0/30"))
+	})
+
+	It("splits the pool name from the subnet spec", func() {
+		pool, spec := kawasaki.SplitPoolSpec("dmz:1.2.3.0/30")
+		Expect(pool).To(Equal("dmz"))
+		Expect(spec).To(Equal("1.2.3.0/30"))
+	})
+
+	It("returns an empty subnet spec when only the pool is given", func() {
+		pool, spec := kawasaki.SplitPoolSpec("dmz:")
+		Expect(pool).To(Equal("dmz"))
+		Expect(spec).To(BeEmpty())
+	})
+})
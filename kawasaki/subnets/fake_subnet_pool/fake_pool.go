@@ -46,6 +46,12 @@ type FakePool struct {
 	capacityReturns     struct {
 		result1 int
 	}
+	AvailableStub        func() int
+	availableMutex       sync.RWMutex
+	availableArgsForCall []struct{}
+	availableReturns     struct {
+		result1 int
+	}
 	RunIfFreeStub        func(*net.IPNet, func() error) error
 	runIfFreeMutex       sync.RWMutex
 	runIfFreeArgsForCall []struct {
@@ -189,6 +195,31 @@ func (fake *FakePool) CapacityReturns(result1 int) {
 	}{result1}
 }
 
+func (fake *FakePool) Available() int {
+	fake.availableMutex.Lock()
+	fake.availableArgsForCall = append(fake.availableArgsForCall, struct{}{})
+	fake.recordInvocation("Available", []interface{}{})
+	fake.availableMutex.Unlock()
+	if fake.AvailableStub != nil {
+		return fake.AvailableStub()
+	} else {
+		return fake.availableReturns.result1
+	}
+}
+
+func (fake *FakePool) AvailableCallCount() int {
+	fake.availableMutex.RLock()
+	defer fake.availableMutex.RUnlock()
+	return len(fake.availableArgsForCall)
+}
+
+func (fake *FakePool) AvailableReturns(result1 int) {
+	fake.AvailableStub = nil
+	fake.availableReturns = struct {
+		result1 int
+	}{result1}
+}
+
 func (fake *FakePool) RunIfFree(arg1 *net.IPNet, arg2 func() error) error {
 	fake.runIfFreeMutex.Lock()
 	fake.runIfFreeArgsForCall = append(fake.runIfFreeArgsForCall, struct {
@@ -234,6 +265,8 @@ func (fake *FakePool) Invocations() map[string][][]interface{} {
 	defer fake.removeMutex.RUnlock()
 	fake.capacityMutex.RLock()
 	defer fake.capacityMutex.RUnlock()
+	fake.availableMutex.RLock()
+	defer fake.availableMutex.RUnlock()
 	fake.runIfFreeMutex.RLock()
 	defer fake.runIfFreeMutex.RUnlock()
 	return fake.invocations
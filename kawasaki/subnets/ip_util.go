@@ -12,6 +12,16 @@ func overlaps(a *net.IPNet, b *net.IPNet) bool {
 	return a.Contains(b.IP) || b.Contains(a.IP)
 }
 
+func overlapsAny(a *net.IPNet, existing []*net.IPNet) bool {
+	for _, e := range existing {
+		if overlaps(a, e) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func next(ip net.IP) net.IP {
 	next := clone(ip)
 	for i := len(next) - 1; i >= 0; i-- {
@@ -24,6 +34,22 @@ func next(ip net.IP) net.IP {
 	panic("overflowed maximum IP")
 }
 
+// nextSubnet returns the network IP of the subnet with the given prefix length
+// following the one starting at ip, or nil if there is none
+func nextSubnet(ip net.IP, prefix int) net.IP {
+	next := clone(ip)
+	carry := uint(1) << uint(7-(prefix-1)%8)
+	for i := (prefix - 1) / 8; i >= 0; i-- {
+		sum := uint(next[i]) + carry
+		next[i] = byte(sum)
+		if carry = sum >> 8; carry == 0 {
+			return next
+		}
+	}
+
+	return nil
+}
+
 func clone(ip net.IP) net.IP {
 	clone := make([]byte, len(ip))
 	copy(clone, ip)
@@ -3,9 +3,11 @@
 package subnets
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"net"
+	"sort"
 	"sync"
 
 	"code.cloudfoundry.org/lager"
@@ -26,16 +28,25 @@ type Pool interface {
 	// Remove an IP address so it appears to be associated with the given subnet.
 	Remove(*net.IPNet, net.IP) error
 
-	// Returns the number of /30 subnets which can be Acquired by a DynamicSubnetSelector.
+	// Returns the number of IP addresses which can be Acquired by a DynamicSubnetSelector.
 	Capacity() int
 
+	// Returns the number of IP addresses which can still be Acquired by a DynamicSubnetSelector,
+	// i.e. the Capacity less the IP addresses currently allocated from the dynamic range.
+	Available() int
+
 	// Run the provided callback if the given subnet is not in use
 	RunIfFree(*net.IPNet, func() error) error
 }
 
+// DefaultSubnetPrefix is the prefix length of the subnets acquired by a
+// DynamicSubnetSelector from a pool created by NewPool
+const DefaultSubnetPrefix = 30
+
 type pool struct {
 	allocated    map[string][]net.IP // net.IPNet.String +> seq net.IP
 	dynamicRange *net.IPNet
+	subnetPrefix int
 	mu           sync.Mutex
 }
 
@@ -58,7 +69,14 @@ type IPSelector interface {
 }
 
 func NewPool(ipNet *net.IPNet) Pool {
-	return &pool{dynamicRange: ipNet, allocated: make(map[string][]net.IP)}
+	return NewPoolWithSubnetPrefix(ipNet, DefaultSubnetPrefix)
+}
+
+// NewPoolWithSubnetPrefix returns a pool whose dynamic subnets have the given
+// prefix length. Dynamic subnets with room for more than one IP address are
+// shared, so that they fill up before another is allocated.
+func NewPoolWithSubnetPrefix(ipNet *net.IPNet, subnetPrefix int) Pool {
+	return &pool{dynamicRange: ipNet, subnetPrefix: subnetPrefix, allocated: make(map[string][]net.IP)}
 }
 
 // Acquire uses the given subnet and IP selectors to request a subnet, container IP address combination
@@ -67,6 +85,14 @@ func (p *pool) Acquire(log lager.Logger, sn SubnetSelector, i IPSelector) (subne
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if _, ok := sn.(dynamicSubnetSelector); ok {
+		if subnet, ip, ok := p.acquireShared(i); ok {
+			return subnet, ip, nil
+		}
+
+		sn = sizedDynamicSubnetSelector(p.subnetPrefix)
+	}
+
 	if subnet, err = sn.SelectSubnet(p.dynamicRange, existingSubnets(p.allocated)); err != nil {
 		return nil, nil, err
 	}
@@ -81,6 +107,30 @@ func (p *pool) Acquire(log lager.Logger, sn SubnetSelector, i IPSelector) (subne
 	return subnet, ip, err
 }
 
+// acquireShared selects an IP address in an already allocated dynamic
+// subnet, if any of them has room for one
+func (p *pool) acquireShared(i IPSelector) (*net.IPNet, net.IP, bool) {
+	existing := existingSubnets(p.allocated)
+	sort.Slice(existing, func(a, b int) bool {
+		return bytes.Compare(existing[a].IP, existing[b].IP) < 0
+	})
+
+	for _, subnet := range existing {
+		if !p.dynamicRange.Contains(subnet.IP) {
+			continue
+		}
+
+		ips := p.allocated[subnet.String()]
+		existingIPs := append(ips, NetworkIP(subnet), GatewayIP(subnet), BroadcastIP(subnet))
+		if ip, err := i.SelectIP(subnet, existingIPs); err == nil {
+			p.allocated[subnet.String()] = append(ips, ip)
+			return subnet, ip, true
+		}
+	}
+
+	return nil, nil, false
+}
+
 // Recover re-allocates a given subnet and ip address combination in the pool. It returns
 // an error if the combination is already allocated.
 func (p *pool) Remove(subnet *net.IPNet, ip net.IP) error {
@@ -121,11 +171,39 @@ func (p *pool) Release(subnet *net.IPNet, ip net.IP) error {
 	return ErrReleasedUnallocatedSubnet
 }
 
-// Capacity returns the number of /30 subnets that can be allocated
-// from the pool's dynamic allocation range.
+// Capacity returns the number of container IP addresses that can be
+// allocated from the pool's dynamic allocation range, which excludes the
+// network, gateway and broadcast addresses of each subnet.
 func (m *pool) Capacity() int {
 	masked, total := m.dynamicRange.Mask.Size()
-	return int(math.Pow(2, float64(total-masked)) / 4)
+	if masked > m.subnetPrefix {
+		return 0
+	}
+
+	subnets := math.Pow(2, float64(m.subnetPrefix-masked))
+	ipsPerSubnet := math.Pow(2, float64(total-m.subnetPrefix)) - 3
+	return int(subnets * ipsPerSubnet)
+}
+
+// Available returns the number of container IP addresses in the pool's
+// dynamic allocation range which are not currently allocated.
+func (p *pool) Available() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	available := p.Capacity()
+	for subnet, ips := range p.allocated {
+		ip, _, err := net.ParseCIDR(subnet)
+		if err == nil && p.dynamicRange.Contains(ip) {
+			available -= len(ips)
+		}
+	}
+
+	if available < 0 {
+		return 0
+	}
+
+	return available
 }
 
 func (p *pool) RunIfFree(subnet *net.IPNet, cb func() error) error {
@@ -31,17 +31,52 @@ type dynamicSubnetSelector int
 var DynamicSubnetSelector dynamicSubnetSelector = 0
 
 func (dynamicSubnetSelector) SelectSubnet(dynamic *net.IPNet, existing []*net.IPNet) (*net.IPNet, error) {
+	return sizedDynamicSubnetSelector(DefaultSubnetPrefix).SelectSubnet(dynamic, existing)
+}
+
+// sizedDynamicSubnetSelector requests the next unallocated subnet with the
+// given prefix length from the dynamic range. Pools use it in place of the
+// DynamicSubnetSelector to allocate subnets of their own size.
+type sizedDynamicSubnetSelector int
+
+func (s sizedDynamicSubnetSelector) SelectSubnet(dynamic *net.IPNet, existing []*net.IPNet) (*net.IPNet, error) {
+	prefix := int(s)
+	masked, bits := dynamic.Mask.Size()
+	if masked > prefix {
+		return nil, ErrInsufficientSubnets
+	}
+
+	mask := net.CIDRMask(prefix, bits)
+	for ip := dynamic.IP.Mask(mask); ip != nil && dynamic.Contains(ip); ip = nextSubnet(ip, prefix) {
+		subnet := &net.IPNet{IP: ip, Mask: mask}
+		if !overlapsAny(subnet, existing) {
+			return subnet, nil
+		}
+	}
+
+	return nil, ErrInsufficientSubnets
+}
+
+// IPv6SubnetPrefix is the prefix length of the subnets acquired by the
+// DynamicIPv6SubnetSelector
+const IPv6SubnetPrefix = 64
+
+type dynamicIPv6SubnetSelector int
+
+// DynamicIPv6SubnetSelector requests the next unallocated /64 subnet from an IPv6 dynamic range.
+// Returns an error if there are no remaining subnets in the dynamic range.
+var DynamicIPv6SubnetSelector dynamicIPv6SubnetSelector = 0
+
+func (dynamicIPv6SubnetSelector) SelectSubnet(dynamic *net.IPNet, existing []*net.IPNet) (*net.IPNet, error) {
 	exists := make(map[string]bool)
 	for _, e := range existing {
 		exists[e.String()] = true
 	}
 
-	min := dynamic.IP
-	mask := net.CIDRMask(30, 32) // /30
-	for ip := min; dynamic.Contains(ip); ip = next(ip) {
+	mask := net.CIDRMask(IPv6SubnetPrefix, 128)
+	for ip := dynamic.IP.Mask(mask); dynamic.Contains(ip); ip = nextSubnet(ip, IPv6SubnetPrefix) {
 		subnet := &net.IPNet{IP: ip, Mask: mask}
-		ip = next(next(next(ip)))
-		if dynamic.Contains(ip) && !exists[subnet.String()] {
+		if !exists[subnet.String()] {
 			return subnet, nil
 		}
 	}
@@ -63,6 +63,36 @@ var _ = Describe("Subnet Pool", func() {
 		})
 	})
 
+	Describe("Available", func() {
+		BeforeEach(func() {
+			defaultSubnetPool = subnetPool("10.2.3.0/27")
+		})
+
+		It("returns the capacity when nothing is allocated", func() {
+			Expect(subnetpool.Available()).To(Equal(8))
+		})
+
+		It("decreases as dynamic subnets are acquired and increases as they are released", func() {
+			subnet, ip, err := subnetpool.Acquire(logger, subnets.DynamicSubnetSelector, subnets.DynamicIPSelector)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, err = subnetpool.Acquire(logger, subnets.DynamicSubnetSelector, subnets.DynamicIPSelector)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(subnetpool.Available()).To(Equal(6))
+
+			Expect(subnetpool.Release(subnet, ip)).To(Succeed())
+			Expect(subnetpool.Available()).To(Equal(7))
+		})
+
+		It("does not count static subnets outside the dynamic range", func() {
+			_, static := networkParms("11.0.0.0/30")
+			_, _, err := subnetpool.Acquire(logger, subnets.StaticSubnetSelector{IPNet: static}, subnets.DynamicIPSelector)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(subnetpool.Available()).To(Equal(8))
+		})
+	})
+
 	Describe("Allocating and Releasing", func() {
 		Describe("Static Subnet Allocation", func() {
 			Context("when the requested subnet is within the dynamic allocation range", func() {
@@ -589,6 +619,107 @@ var _ = Describe("Subnet Pool", func() {
 			})
 		})
 
+		Describe("Dynamic Subnet Allocation with a larger subnet size", func() {
+			var sizedPool subnets.Pool
+
+			JustBeforeEach(func() {
+				sizedPool = subnets.NewPoolWithSubnetPrefix(subnetPool("10.2.0.0/23"), 24)
+			})
+
+			It("reports the capacity as the number of container IPs", func() {
+				Expect(sizedPool.Capacity()).To(Equal(2 * 253))
+				Expect(sizedPool.Available()).To(Equal(2 * 253))
+			})
+
+			It("shares a subnet between containers until it is full", func() {
+				network, ip, err := sizedPool.Acquire(logger, subnets.DynamicSubnetSelector, subnets.DynamicIPSelector)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(network.String()).To(Equal("10.2.0.0/24"))
+				Expect(ip.String()).To(Equal("10.2.0.2"))
+
+				network, ip, err = sizedPool.Acquire(logger, subnets.DynamicSubnetSelector, subnets.DynamicIPSelector)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(network.String()).To(Equal("10.2.0.0/24"))
+				Expect(ip.String()).To(Equal("10.2.0.3"))
+
+				for i := 0; i < 251; i++ {
+					network, _, err = sizedPool.Acquire(logger, subnets.DynamicSubnetSelector, subnets.DynamicIPSelector)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(network.String()).To(Equal("10.2.0.0/24"))
+				}
+
+				network, ip, err = sizedPool.Acquire(logger, subnets.DynamicSubnetSelector, subnets.DynamicIPSelector)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(network.String()).To(Equal("10.2.1.0/24"))
+				Expect(ip.String()).To(Equal("10.2.1.2"))
+
+				Expect(sizedPool.Available()).To(Equal(2*253 - 254))
+			})
+
+			It("keeps a shared subnet allocated until its last IP is released", func() {
+				network, ip1, err := sizedPool.Acquire(logger, subnets.DynamicSubnetSelector, subnets.DynamicIPSelector)
+				Expect(err).ToNot(HaveOccurred())
+				_, ip2, err := sizedPool.Acquire(logger, subnets.DynamicSubnetSelector, subnets.DynamicIPSelector)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(sizedPool.Release(network, ip1)).To(Succeed())
+
+				called := false
+				Expect(sizedPool.RunIfFree(network, func() error { called = true; return nil })).To(Succeed())
+				Expect(called).To(BeFalse())
+
+				Expect(sizedPool.Release(network, ip2)).To(Succeed())
+				Expect(sizedPool.RunIfFree(network, func() error { called = true; return nil })).To(Succeed())
+				Expect(called).To(BeTrue())
+			})
+		})
+
+		Describe("Dynamic IPv6 /64 Subnet Allocation", func() {
+			BeforeEach(func() {
+				defaultSubnetPool = subnetPool("fd00:0:0:fffe::/63")
+			})
+
+			It("allocates the first /64 within the range, and the address after the gateway", func() {
+				subnet, ip, err := subnetpool.Acquire(logger, subnets.DynamicIPv6SubnetSelector, subnets.DynamicIPSelector)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(subnet.String()).To(Equal("fd00:0:0:fffe::/64"))
+				Expect(subnets.GatewayIP(subnet).String()).To(Equal("fd00:0:0:fffe::1"))
+				Expect(ip.String()).To(Equal("fd00:0:0:fffe::2"))
+			})
+
+			It("allocates the next /64 for a subsequent request", func() {
+				_, _, err := subnetpool.Acquire(logger, subnets.DynamicIPv6SubnetSelector, subnets.DynamicIPSelector)
+				Expect(err).NotTo(HaveOccurred())
+
+				subnet, _, err := subnetpool.Acquire(logger, subnets.DynamicIPv6SubnetSelector, subnets.DynamicIPSelector)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(subnet.String()).To(Equal("fd00:0:0:ffff::/64"))
+			})
+
+			It("returns an error once the range is exhausted", func() {
+				for i := 0; i < 2; i++ {
+					_, _, err := subnetpool.Acquire(logger, subnets.DynamicIPv6SubnetSelector, subnets.DynamicIPSelector)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				_, _, err := subnetpool.Acquire(logger, subnets.DynamicIPv6SubnetSelector, subnets.DynamicIPSelector)
+				Expect(err).To(Equal(subnets.ErrInsufficientSubnets))
+			})
+
+			Context("when an allocated subnet is released", func() {
+				It("reallocates it", func() {
+					subnet, ip, err := subnetpool.Acquire(logger, subnets.DynamicIPv6SubnetSelector, subnets.DynamicIPSelector)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(subnetpool.Release(subnet, ip)).To(Succeed())
+
+					again, _, err := subnetpool.Acquire(logger, subnets.DynamicIPv6SubnetSelector, subnets.DynamicIPSelector)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(again.String()).To(Equal(subnet.String()))
+				})
+			})
+		})
+
 		Describe("Removeing", func() {
 			BeforeEach(func() {
 				defaultSubnetPool = subnetPool("10.2.3.0/29")
@@ -3,6 +3,7 @@ package netplugin
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -10,6 +11,7 @@ import (
 	"strings"
 
 	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
 	"code.cloudfoundry.org/guardian/kawasaki"
 	"code.cloudfoundry.org/lager"
 	"github.com/cloudfoundry/gunk/command_runner"
@@ -17,6 +19,10 @@ import (
 
 const NetworkPropertyPrefix = "network."
 
+// ErrNetOutRulesNotSupported is returned when listing or revoking net out
+// rules, which the external plugin does not record
+var ErrNetOutRulesNotSupported = errors.New("listing and revoking net out rules is not supported by the network plugin")
+
 type ExternalBinaryNetworker struct {
 	commandRunner command_runner.CommandRunner
 	configStore   kawasaki.ConfigStore
@@ -127,10 +133,38 @@ func (p *ExternalBinaryNetworker) Capacity() (m uint64) {
 	return math.MaxUint64
 }
 
-func (p *ExternalBinaryNetworker) NetIn(log lager.Logger, handle string, externalPort, containerPort uint32) (uint32, uint32, error) {
+func (p *ExternalBinaryNetworker) AvailableSubnets() uint64 {
+	return math.MaxUint64
+}
+
+func (p *ExternalBinaryNetworker) AvailablePorts() uint64 {
+	return math.MaxUint64
+}
+
+func (p *ExternalBinaryNetworker) PoolCapacities() map[string]gardener.PoolCapacity {
+	return nil
+}
+
+func (p *ExternalBinaryNetworker) NetIn(log lager.Logger, handle string, externalPort, containerPort uint32, protocol string) (uint32, uint32, error) {
 	return 0, 0, nil
 }
 
+func (p *ExternalBinaryNetworker) RemoveNetIn(log lager.Logger, handle string, externalPort, containerPort uint32, protocol string) error {
+	return nil
+}
+
 func (p *ExternalBinaryNetworker) NetOut(log lager.Logger, handle string, rule garden.NetOutRule) error {
 	return nil
 }
+
+func (p *ExternalBinaryNetworker) BulkNetOut(log lager.Logger, handle string, rules []garden.NetOutRule) error {
+	return nil
+}
+
+func (p *ExternalBinaryNetworker) NetOutRules(log lager.Logger, handle string) ([]gardener.NetOutRule, error) {
+	return nil, ErrNetOutRulesNotSupported
+}
+
+func (p *ExternalBinaryNetworker) RevokeNetOut(log lager.Logger, handle string, id string) error {
+	return ErrNetOutRulesNotSupported
+}
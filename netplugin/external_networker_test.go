@@ -194,4 +194,14 @@ var _ = Describe("ExternalBinaryNetworker", func() {
 			})
 		})
 	})
+
+	Describe("NetOutRules and RevokeNetOut", func() {
+		It("returns an error as the plugin does not record net out rules", func() {
+			plugin := netplugin.New(fakeCommandRunner, configStore, "some/path")
+
+			_, err := plugin.NetOutRules(lagertest.NewTestLogger("test"), "some-handle")
+			Expect(err).To(Equal(netplugin.ErrNetOutRulesNotSupported))
+			Expect(plugin.RevokeNetOut(lagertest.NewTestLogger("test"), "some-handle", "1")).To(Equal(netplugin.ErrNetOutRulesNotSupported))
+		})
+	})
 })
@@ -0,0 +1,28 @@
+package properties
+
+import "code.cloudfoundry.org/lager"
+
+// Inventory finds key spaces which belong to no container. Containers need
+// not have any properties, so none are ever reported missing.
+type Inventory struct {
+	Manager *Manager
+}
+
+func (i *Inventory) Check(log lager.Logger, handles []string) (missing, orphans []string, err error) {
+	known := make(map[string]bool)
+	for _, handle := range handles {
+		known[handle] = true
+	}
+
+	for _, handle := range i.Manager.Handles() {
+		if !known[handle] {
+			orphans = append(orphans, handle)
+		}
+	}
+
+	return nil, orphans, nil
+}
+
+func (i *Inventory) RemoveOrphan(log lager.Logger, handle string) error {
+	return i.Manager.DestroyKeySpace(handle)
+}
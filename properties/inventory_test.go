@@ -0,0 +1,37 @@
+package properties_test
+
+import (
+	"code.cloudfoundry.org/guardian/properties"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inventory", func() {
+	var (
+		propertyManager *properties.Manager
+		inventory       *properties.Inventory
+		logger          *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		propertyManager = properties.NewManager()
+		propertyManager.Set("container", "name", "value")
+		propertyManager.Set("orphan", "name", "value")
+
+		inventory = &properties.Inventory{Manager: propertyManager}
+	})
+
+	It("reports key spaces which belong to no container as orphans", func() {
+		missing, orphans, err := inventory.Check(logger, []string{"container", "no-properties"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(missing).To(BeEmpty())
+		Expect(orphans).To(ConsistOf("orphan"))
+	})
+
+	It("removes orphans by destroying their key space", func() {
+		Expect(inventory.RemoveOrphan(logger, "orphan")).To(Succeed())
+		Expect(propertyManager.Handles()).To(ConsistOf("container"))
+	})
+})
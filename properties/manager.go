@@ -28,6 +28,19 @@ func (m *Manager) DestroyKeySpace(handle string) error {
 	return nil
 }
 
+// Handles returns the handles which have a key space
+func (m *Manager) Handles() []string {
+	m.propMutex.RLock()
+	defer m.propMutex.RUnlock()
+
+	handles := []string{}
+	for handle := range m.prop {
+		handles = append(handles, handle)
+	}
+
+	return handles
+}
+
 func (m *Manager) MarshalJSON() ([]byte, error) {
 	return json.Marshal(m.prop)
 }
@@ -21,6 +21,18 @@ var _ = Describe("Properties", func() {
 		propertyManager.Set("handle", "name", "value")
 	})
 
+	Describe("Handles", func() {
+		It("returns the handles with a key space", func() {
+			propertyManager.Set("other-handle", "name", "value")
+			Expect(propertyManager.Handles()).To(ConsistOf("handle", "other-handle"))
+		})
+
+		It("does not return destroyed key spaces", func() {
+			Expect(propertyManager.DestroyKeySpace("handle")).To(Succeed())
+			Expect(propertyManager.Handles()).To(BeEmpty())
+		})
+	})
+
 	Describe("DestroyKeySpace", func() {
 		It("removes key space", func() {
 			err := propertyManager.DestroyKeySpace("handle")
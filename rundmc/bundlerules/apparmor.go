@@ -0,0 +1,30 @@
+package bundlerules
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+// Apparmor sets the AppArmor profile requested in the container spec on the
+// bundle's process of unprivileged containers, provided it is one of the
+// allowed profiles. When no profile is requested the profile of the base
+// bundle is left untouched.
+type Apparmor struct {
+	AllowedProfiles []string
+}
+
+func (a Apparmor) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	if spec.Privileged || spec.ApparmorProfile == "" {
+		return bndl, nil
+	}
+
+	if !contains(a.AllowedProfiles, spec.ApparmorProfile) {
+		return goci.Bndl{}, fmt.Errorf("apparmor profile not allowed: %s", spec.ApparmorProfile)
+	}
+
+	process := bndl.Process()
+	process.ApparmorProfile = spec.ApparmorProfile
+	return bndl.WithProcess(process), nil
+}
@@ -0,0 +1,69 @@
+package bundlerules_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+var _ = Describe("ApparmorRule", func() {
+	var (
+		rule bundlerules.Apparmor
+		bndl goci.Bndl
+	)
+
+	BeforeEach(func() {
+		rule = bundlerules.Apparmor{
+			AllowedProfiles: []string{"strict", "relaxed"},
+		}
+
+		process := goci.Process("/tmp/garden-init")
+		process.ApparmorProfile = "default-profile"
+		bndl = goci.Bundle().WithProcess(process)
+	})
+
+	Context("when no profile is requested", func() {
+		It("keeps the profile of the base bundle", func() {
+			newBndl, err := rule.Apply(bndl, gardener.DesiredContainerSpec{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(newBndl.Process().ApparmorProfile).To(Equal("default-profile"))
+		})
+	})
+
+	Context("when an allowed profile is requested", func() {
+		It("sets the profile on the bundle process", func() {
+			newBndl, err := rule.Apply(bndl, gardener.DesiredContainerSpec{
+				ApparmorProfile: "relaxed",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(newBndl.Process().ApparmorProfile).To(Equal("relaxed"))
+			Expect(newBndl.Process().Args).To(Equal([]string{"/tmp/garden-init"}))
+		})
+	})
+
+	Context("when a profile which is not allowed is requested", func() {
+		It("returns an error", func() {
+			_, err := rule.Apply(bndl, gardener.DesiredContainerSpec{
+				ApparmorProfile: "unconfined",
+			})
+			Expect(err).To(MatchError("apparmor profile not allowed: unconfined"))
+		})
+	})
+
+	Context("when the container is privileged", func() {
+		It("leaves the bundle untouched", func() {
+			newBndl, err := rule.Apply(bndl, gardener.DesiredContainerSpec{
+				Privileged:      true,
+				ApparmorProfile: "relaxed",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(newBndl).To(Equal(bndl))
+		})
+	})
+})
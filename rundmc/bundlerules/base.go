@@ -11,18 +11,18 @@ type Base struct {
 	UnprivilegedBase goci.Bndl
 }
 
-func (r Base) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) goci.Bndl {
+func (r Base) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
 	if spec.Privileged {
 		copiedBndl, err := copystructure.Copy(r.PrivilegedBase)
 		if err != nil {
 			panic(err)
 		}
-		return copiedBndl.(goci.Bndl)
+		return copiedBndl.(goci.Bndl), nil
 	} else {
 		copiedBndl, err := copystructure.Copy(r.UnprivilegedBase)
 		if err != nil {
 			panic(err)
 		}
-		return copiedBndl.(goci.Bndl)
+		return copiedBndl.(goci.Bndl), nil
 	}
 }
@@ -30,17 +30,19 @@ var _ = Describe("Base", func() {
 
 	Context("when it is privileged", func() {
 		It("should use the correct base", func() {
-			retBndl := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
+			retBndl, err := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
 				Privileged: true,
 			})
+			Expect(err).NotTo(HaveOccurred())
 
 			Expect(retBndl).To(Equal(privilegeBndl))
 		})
 
 		It("returns a copy of the original Bndl data structure", func() {
-			retBndl := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
+			retBndl, err := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
 				Privileged: true,
 			})
+			Expect(err).NotTo(HaveOccurred())
 
 			// Spec.Linux.Resources is a pointer
 			Expect(retBndl.Spec.Linux.Resources.DisableOOMKiller).NotTo(BeIdenticalTo(privilegeBndl.Spec.Linux.Resources.DisableOOMKiller))
@@ -49,17 +51,19 @@ var _ = Describe("Base", func() {
 
 	Context("when it is not privileged", func() {
 		It("should use the correct base", func() {
-			retBndl := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
+			retBndl, err := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
 				Privileged: false,
 			})
+			Expect(err).NotTo(HaveOccurred())
 
 			Expect(retBndl).To(Equal(unprivilegeBndl))
 		})
 
 		It("returns a copy of the original Bndl data structure", func() {
-			retBndl := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
+			retBndl, err := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
 				Privileged: false,
 			})
+			Expect(err).NotTo(HaveOccurred())
 
 			// Spec.Linux.Resources is a pointer
 			Expect(retBndl.Spec.Linux.Resources.DisableOOMKiller).NotTo(BeIdenticalTo(unprivilegeBndl.Spec.Linux.Resources.DisableOOMKiller))
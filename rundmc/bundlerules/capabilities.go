@@ -0,0 +1,75 @@
+package bundlerules
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+// Capabilities adds and removes the capabilities requested in the container
+// spec to and from the capabilities of the bundle. Only the capabilities the
+// operator allowed may be added. Added capabilities are granted to the
+// container's root processes only; non-root processes exec'd into the
+// container are still limited to the non-root capabilities.
+type Capabilities struct {
+	// All capabilities which may be dropped
+	ValidCaps []string
+	// Capabilities which may be added
+	AllowedAddCaps []string
+}
+
+func (c Capabilities) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	if len(spec.CapAdd) == 0 && len(spec.CapDrop) == 0 {
+		return bndl, nil
+	}
+
+	if err := c.validate(spec.CapAdd, spec.CapDrop); err != nil {
+		return goci.Bndl{}, err
+	}
+
+	var caps []string
+	for _, cap := range bndl.Capabilities() {
+		if !contains(spec.CapDrop, cap) {
+			caps = append(caps, cap)
+		}
+	}
+
+	for _, cap := range spec.CapAdd {
+		if contains(spec.CapDrop, cap) {
+			return goci.Bndl{}, fmt.Errorf("capability %s can not be both added and dropped", cap)
+		}
+
+		if !contains(caps, cap) {
+			caps = append(caps, cap)
+		}
+	}
+
+	return bndl.WithCapabilities(caps...), nil
+}
+
+func (c Capabilities) validate(added, dropped []string) error {
+	for _, cap := range dropped {
+		if !contains(c.ValidCaps, cap) {
+			return fmt.Errorf("unknown capability: %s", cap)
+		}
+	}
+
+	for _, cap := range added {
+		if !contains(c.AllowedAddCaps, cap) {
+			return fmt.Errorf("capability not allowed: %s", cap)
+		}
+	}
+
+	return nil
+}
+
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package bundlerules_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+var _ = Describe("CapabilitiesRule", func() {
+	var (
+		rule bundlerules.Capabilities
+		bndl goci.Bndl
+	)
+
+	BeforeEach(func() {
+		rule = bundlerules.Capabilities{
+			ValidCaps:      []string{"CAP_CHOWN", "CAP_NET_RAW", "CAP_NET_ADMIN", "CAP_SYS_PTRACE"},
+			AllowedAddCaps: []string{"CAP_CHOWN", "CAP_NET_ADMIN"},
+		}
+
+		bndl = goci.Bundle().WithCapabilities("CAP_CHOWN", "CAP_NET_RAW")
+	})
+
+	Context("when no capabilities are added or dropped", func() {
+		It("keeps the capabilities of the bundle", func() {
+			newBndl, err := rule.Apply(bndl, gardener.DesiredContainerSpec{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(newBndl).To(Equal(bndl))
+		})
+	})
+
+	It("adds the requested capabilities", func() {
+		newBndl, err := rule.Apply(bndl, gardener.DesiredContainerSpec{
+			CapAdd: []string{"CAP_NET_ADMIN", "CAP_CHOWN"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(newBndl.Capabilities()).To(Equal([]string{"CAP_CHOWN", "CAP_NET_RAW", "CAP_NET_ADMIN"}))
+	})
+
+	It("drops the requested capabilities", func() {
+		newBndl, err := rule.Apply(bndl, gardener.DesiredContainerSpec{
+			CapDrop: []string{"CAP_NET_RAW"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(newBndl.Capabilities()).To(Equal([]string{"CAP_CHOWN"}))
+	})
+
+	Context("when an unknown capability is dropped", func() {
+		It("returns an error", func() {
+			_, err := rule.Apply(bndl, gardener.DesiredContainerSpec{
+				CapDrop: []string{"CAP_BANANA"},
+			})
+			Expect(err).To(MatchError("unknown capability: CAP_BANANA"))
+		})
+	})
+
+	Context("when a capability which is not allowed is added", func() {
+		It("returns an error", func() {
+			_, err := rule.Apply(bndl, gardener.DesiredContainerSpec{
+				CapAdd: []string{"CAP_SYS_PTRACE"},
+			})
+			Expect(err).To(MatchError("capability not allowed: CAP_SYS_PTRACE"))
+		})
+	})
+
+	Context("when a capability is both added and dropped", func() {
+		It("returns an error", func() {
+			_, err := rule.Apply(bndl, gardener.DesiredContainerSpec{
+				CapAdd:  []string{"CAP_NET_ADMIN"},
+				CapDrop: []string{"CAP_NET_ADMIN"},
+			})
+			Expect(err).To(MatchError("capability CAP_NET_ADMIN can not be both added and dropped"))
+		})
+	})
+})
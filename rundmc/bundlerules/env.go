@@ -8,8 +8,8 @@ import (
 type Env struct {
 }
 
-func (r Env) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) goci.Bndl {
+func (r Env) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
 	process := bndl.Process()
 	process.Env = spec.Env
-	return bndl.WithProcess(process)
+	return bndl.WithProcess(process), nil
 }
@@ -16,12 +16,14 @@ var _ = Describe("Env Rule", func() {
 
 	JustBeforeEach(func() {
 		rule = bundlerules.Env{}
-		newBndl = rule.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+		var err error
+		newBndl, err = rule.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
 			Env: []string{
 				"TEST=banana",
 				"CONTAINER_NAME=hello",
 			},
 		})
+		Expect(err).NotTo(HaveOccurred())
 	})
 
 	It("sets the environment onto the bundle process", func() {
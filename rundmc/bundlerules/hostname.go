@@ -8,11 +8,11 @@ import (
 type Hostname struct {
 }
 
-func (l Hostname) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) goci.Bndl {
+func (l Hostname) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
 	hostname := spec.Hostname
 	if len(hostname) > 49 {
 		hostname = hostname[len(hostname)-49:]
 	}
 
-	return bndl.WithHostname(hostname)
+	return bndl.WithHostname(hostname), nil
 }
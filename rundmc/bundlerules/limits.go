@@ -9,9 +9,9 @@ import (
 type Limits struct {
 }
 
-func (l Limits) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) goci.Bndl {
+func (l Limits) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
 	limit := uint64(spec.Limits.Memory.LimitInBytes)
 	bndl = bndl.WithMemoryLimit(specs.Memory{Limit: &limit, Swap: &limit})
 	shares := uint64(spec.Limits.CPU.LimitInShares)
-	return bndl.WithCPUShares(specs.CPU{Shares: &shares})
+	return bndl.WithCPUShares(specs.CPU{Shares: &shares}), nil
 }
@@ -13,22 +13,24 @@ import (
 
 var _ = Describe("LimitsRule", func() {
 	It("sets the correct memory limit in bundle resources", func() {
-		newBndl := bundlerules.Limits{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+		newBndl, err := bundlerules.Limits{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
 			Limits: garden.Limits{
 				Memory: garden.MemoryLimits{LimitInBytes: 4096},
 			},
 		})
+		Expect(err).NotTo(HaveOccurred())
 
 		Expect(*(newBndl.Resources().Memory.Limit)).To(BeNumerically("==", 4096))
 		Expect(*(newBndl.Resources().Memory.Swap)).To(BeNumerically("==", 4096))
 	})
 
 	It("sets the correct CPU limit in bundle resources", func() {
-		newBndl := bundlerules.Limits{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+		newBndl, err := bundlerules.Limits{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
 			Limits: garden.Limits{
 				CPU: garden.CPULimits{LimitInShares: 1},
 			},
 		})
+		Expect(err).NotTo(HaveOccurred())
 
 		Expect(*(newBndl.Resources().CPU.Shares)).To(BeNumerically("==", 1))
 	})
@@ -41,11 +43,12 @@ var _ = Describe("LimitsRule", func() {
 			},
 		)
 
-		newBndl := bundlerules.Limits{}.Apply(bndl, gardener.DesiredContainerSpec{
+		newBndl, err := bundlerules.Limits{}.Apply(bndl, gardener.DesiredContainerSpec{
 			Limits: garden.Limits{
 				Memory: garden.MemoryLimits{LimitInBytes: 4096},
 			},
 		})
+		Expect(err).NotTo(HaveOccurred())
 
 		Expect(*(newBndl.Resources().Memory.Limit)).To(BeNumerically("==", 4096))
 		Expect(newBndl.Resources().Devices).To(Equal(bndl.Resources().Devices))
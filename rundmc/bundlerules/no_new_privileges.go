@@ -0,0 +1,15 @@
+package bundlerules
+
+import (
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+type NoNewPrivileges struct {
+}
+
+func (n NoNewPrivileges) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	process := bndl.Process()
+	process.NoNewPrivileges = spec.NoNewPrivileges
+	return bndl.WithProcess(process), nil
+}
@@ -0,0 +1,28 @@
+package bundlerules_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+var _ = Describe("NoNewPrivilegesRule", func() {
+	It("sets no_new_privileges on the bundle process when requested", func() {
+		newBndl, err := bundlerules.NoNewPrivileges{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+			NoNewPrivileges: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(newBndl.Process().NoNewPrivileges).To(BeTrue())
+	})
+
+	It("does not set no_new_privileges on the bundle process otherwise", func() {
+		newBndl, err := bundlerules.NoNewPrivileges{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(newBndl.Process().NoNewPrivileges).To(BeFalse())
+	})
+})
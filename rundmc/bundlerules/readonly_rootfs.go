@@ -0,0 +1,89 @@
+package bundlerules
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// files which are written by the networker after the container has started,
+// and so must remain writable when the rootfs is read-only
+var networkConfigFiles = []string{"/etc/hosts", "/etc/resolv.conf"}
+
+// ReadOnlyRootFS marks the rootfs of the bundle as read-only when requested
+// and adds the requested tmpfs mounts. It must be applied after the RootFS
+// rule, which replaces the bundle's root. The network config files are
+// bind-mounted from the container's depot directory; the containerizer
+// creates them once the bundle is in the depot.
+type ReadOnlyRootFS struct {
+	DepotDir string
+}
+
+func (r ReadOnlyRootFS) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	for _, tmpfs := range spec.TmpfsMounts {
+		bndl = withTmpfsMount(bndl, tmpfs)
+	}
+
+	if !spec.ReadOnlyRootFS {
+		return bndl, nil
+	}
+
+	var mounts []specs.Mount
+	for _, path := range networkConfigFiles {
+		mounts = append(mounts, specs.Mount{
+			Destination: path,
+			Source:      filepath.Join(r.DepotDir, spec.Handle, filepath.Base(path)),
+			Type:        "bind",
+			Options:     []string{"bind", "rw"},
+		})
+	}
+
+	return bndl.WithMounts(mounts...).WithReadonlyRootFS(true), nil
+}
+
+// withTmpfsMount adds a tmpfs mount to the bundle, replacing any existing
+// mount with the same destination. The mount is placed before any existing
+// mounts nested beneath it (e.g. /tmp/garden-init) so that they are not hidden.
+func withTmpfsMount(bndl goci.Bndl, tmpfs gardener.TmpfsMount) goci.Bndl {
+	options := []string{"nosuid", "nodev", "mode=1777"}
+	if tmpfs.SizeInBytes > 0 {
+		options = append(options, fmt.Sprintf("size=%d", tmpfs.SizeInBytes))
+	}
+
+	mount := specs.Mount{
+		Destination: tmpfs.Path,
+		Source:      "tmpfs",
+		Type:        "tmpfs",
+		Options:     options,
+	}
+
+	var mounts []specs.Mount
+	inserted := false
+	for _, m := range bndl.Mounts() {
+		if m.Destination == mount.Destination {
+			if !inserted {
+				mounts = append(mounts, mount)
+				inserted = true
+			}
+			continue
+		}
+
+		if !inserted && strings.HasPrefix(m.Destination, strings.TrimSuffix(mount.Destination, "/")+"/") {
+			mounts = append(mounts, mount)
+			inserted = true
+		}
+
+		mounts = append(mounts, m)
+	}
+
+	if !inserted {
+		mounts = append(mounts, mount)
+	}
+
+	bndl.Spec.Mounts = mounts
+	return bndl
+}
@@ -0,0 +1,118 @@
+package bundlerules_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+var _ = Describe("ReadOnlyRootFSRule", func() {
+	var (
+		rule bundlerules.ReadOnlyRootFS
+		bndl goci.Bndl
+		spec gardener.DesiredContainerSpec
+
+		newBndl  goci.Bndl
+		applyErr error
+	)
+
+	BeforeEach(func() {
+		rule = bundlerules.ReadOnlyRootFS{
+			DepotDir: "/the/depot",
+		}
+
+		bndl = goci.Bundle().WithRootFS("/the/rootfs").WithMounts(
+			specs.Mount{Type: "tmpfs", Source: "tmpfs", Destination: "/dev/shm"},
+			specs.Mount{Type: "bind", Source: "/path/to/init", Destination: "/tmp/garden-init"},
+		)
+
+		spec = gardener.DesiredContainerSpec{Handle: "some-handle"}
+	})
+
+	JustBeforeEach(func() {
+		newBndl, applyErr = rule.Apply(bndl, spec)
+	})
+
+	Context("when a read-only rootfs is not requested", func() {
+		It("leaves the rootfs writable", func() {
+			Expect(applyErr).NotTo(HaveOccurred())
+			Expect(newBndl.ReadonlyRootFS()).To(BeFalse())
+			Expect(newBndl.Mounts()).To(Equal(bndl.Mounts()))
+		})
+	})
+
+	Context("when a read-only rootfs is requested", func() {
+		BeforeEach(func() {
+			spec.ReadOnlyRootFS = true
+		})
+
+		It("marks the rootfs as read-only", func() {
+			Expect(applyErr).NotTo(HaveOccurred())
+			Expect(newBndl.ReadonlyRootFS()).To(BeTrue())
+			Expect(newBndl.RootFS()).To(Equal("/the/rootfs"))
+		})
+
+		It("bind-mounts writable network config files from the depot", func() {
+			Expect(applyErr).NotTo(HaveOccurred())
+
+			Expect(newBndl.Mounts()).To(ContainElement(specs.Mount{
+				Destination: "/etc/hosts", Source: "/the/depot/some-handle/hosts", Type: "bind", Options: []string{"bind", "rw"},
+			}))
+			Expect(newBndl.Mounts()).To(ContainElement(specs.Mount{
+				Destination: "/etc/resolv.conf", Source: "/the/depot/some-handle/resolv.conf", Type: "bind", Options: []string{"bind", "rw"},
+			}))
+		})
+	})
+
+	Context("when tmpfs mounts are requested", func() {
+		BeforeEach(func() {
+			spec.TmpfsMounts = []gardener.TmpfsMount{
+				{Path: "/tmp", SizeInBytes: 1024},
+				{Path: "/run"},
+			}
+		})
+
+		It("adds the tmpfs mounts with their size limits", func() {
+			Expect(applyErr).NotTo(HaveOccurred())
+
+			Expect(newBndl.Mounts()).To(ContainElement(specs.Mount{
+				Destination: "/tmp", Source: "tmpfs", Type: "tmpfs",
+				Options: []string{"nosuid", "nodev", "mode=1777", "size=1024"},
+			}))
+			Expect(newBndl.Mounts()).To(ContainElement(specs.Mount{
+				Destination: "/run", Source: "tmpfs", Type: "tmpfs",
+				Options: []string{"nosuid", "nodev", "mode=1777"},
+			}))
+		})
+
+		It("places them before any mounts nested beneath them", func() {
+			Expect(applyErr).NotTo(HaveOccurred())
+
+			var destinations []string
+			for _, m := range newBndl.Mounts() {
+				destinations = append(destinations, m.Destination)
+			}
+
+			Expect(destinations).To(Equal([]string{"/dev/shm", "/tmp", "/tmp/garden-init", "/run"}))
+		})
+
+		Context("when a mount already exists at the destination", func() {
+			BeforeEach(func() {
+				spec.TmpfsMounts = []gardener.TmpfsMount{
+					{Path: "/dev/shm", SizeInBytes: 2048},
+				}
+			})
+
+			It("replaces it", func() {
+				Expect(applyErr).NotTo(HaveOccurred())
+
+				Expect(newBndl.Mounts()).To(HaveLen(2))
+				Expect(newBndl.Mounts()[0].Options).To(ContainElement("size=2048"))
+			})
+		})
+	})
+})
@@ -0,0 +1,43 @@
+package bundlerules
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+// RestrictedPaths adds the masked and read-only paths requested in the
+// container spec to those of the bundle.
+type RestrictedPaths struct {
+}
+
+func (r RestrictedPaths) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	maskedPaths, err := mergePaths(bndl.MaskedPaths(), spec.MaskedPaths)
+	if err != nil {
+		return goci.Bndl{}, err
+	}
+
+	readonlyPaths, err := mergePaths(bndl.ReadonlyPaths(), spec.ReadonlyPaths)
+	if err != nil {
+		return goci.Bndl{}, err
+	}
+
+	return bndl.WithMaskedPaths(maskedPaths).WithReadonlyPaths(readonlyPaths), nil
+}
+
+func mergePaths(existing, extra []string) ([]string, error) {
+	merged := append([]string{}, existing...)
+	for _, path := range extra {
+		if !filepath.IsAbs(path) {
+			return nil, fmt.Errorf("path must be absolute: %s", path)
+		}
+
+		if !contains(merged, path) {
+			merged = append(merged, path)
+		}
+	}
+
+	return merged, nil
+}
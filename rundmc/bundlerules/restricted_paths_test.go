@@ -0,0 +1,57 @@
+package bundlerules_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+var _ = Describe("RestrictedPathsRule", func() {
+	var bndl goci.Bndl
+
+	BeforeEach(func() {
+		bndl = goci.Bundle().
+			WithMaskedPaths([]string{"/proc/kcore"}).
+			WithReadonlyPaths([]string{"/proc/sys"})
+	})
+
+	It("adds the requested masked paths to those of the bundle", func() {
+		newBndl, err := bundlerules.RestrictedPaths{}.Apply(bndl, gardener.DesiredContainerSpec{
+			MaskedPaths: []string{"/proc/foo", "/proc/kcore"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(newBndl.MaskedPaths()).To(Equal([]string{"/proc/kcore", "/proc/foo"}))
+		Expect(newBndl.ReadonlyPaths()).To(Equal([]string{"/proc/sys"}))
+	})
+
+	It("adds the requested read-only paths to those of the bundle", func() {
+		newBndl, err := bundlerules.RestrictedPaths{}.Apply(bndl, gardener.DesiredContainerSpec{
+			ReadonlyPaths: []string{"/proc/sysrq-trigger"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(newBndl.ReadonlyPaths()).To(Equal([]string{"/proc/sys", "/proc/sysrq-trigger"}))
+	})
+
+	It("does not modify the paths of the original bundle", func() {
+		_, err := bundlerules.RestrictedPaths{}.Apply(bndl, gardener.DesiredContainerSpec{
+			MaskedPaths: []string{"/proc/foo"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(bndl.MaskedPaths()).To(Equal([]string{"/proc/kcore"}))
+	})
+
+	Context("when a requested path is not absolute", func() {
+		It("returns an error", func() {
+			_, err := bundlerules.RestrictedPaths{}.Apply(bndl, gardener.DesiredContainerSpec{
+				ReadonlyPaths: []string{"proc/sys"},
+			})
+			Expect(err).To(MatchError("path must be absolute: proc/sys"))
+		})
+	})
+})
@@ -1,8 +1,10 @@
 package bundlerules
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 
 	"code.cloudfoundry.org/guardian/gardener"
 	"code.cloudfoundry.org/guardian/rundmc/goci"
@@ -20,7 +22,7 @@ type RootFS struct {
 	MkdirChown MkdirChowner
 }
 
-func (r RootFS) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) goci.Bndl {
+func (r RootFS) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
 	var uid, gid int
 	if !spec.Privileged {
 		uid = r.ContainerRootUID
@@ -38,7 +40,21 @@ func (r RootFS) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) goci.B
 		"tmp",
 	)
 
-	return bndl.WithRootFS(spec.RootFSPath)
+	var tmpfsPaths []string
+	for _, tmpfs := range spec.TmpfsMounts {
+		tmpfsPaths = append(tmpfsPaths, strings.TrimPrefix(tmpfs.Path, "/"))
+	}
+
+	if len(tmpfsPaths) > 0 {
+		if err := r.MkdirChown.MkdirAs(
+			spec.RootFSPath, uid, gid, 0755, false,
+			tmpfsPaths...,
+		); err != nil {
+			return goci.Bndl{}, fmt.Errorf("create tmpfs mount points: %s", err)
+		}
+	}
+
+	return bndl.WithRootFS(spec.RootFSPath), nil
 }
 
 type ChrootMkdir struct {
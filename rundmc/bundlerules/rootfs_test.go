@@ -1,6 +1,7 @@
 package bundlerules_test
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -24,10 +25,12 @@ var _ = Describe("RootFS", func() {
 		rootfsPath     string
 		returnedBundle goci.Bndl
 		privileged     bool
+		tmpfsMounts    []gardener.TmpfsMount
 	)
 
 	BeforeEach(func() {
 		privileged = false
+		tmpfsMounts = nil
 	})
 
 	JustBeforeEach(func() {
@@ -54,10 +57,13 @@ var _ = Describe("RootFS", func() {
 			},
 		}
 
-		returnedBundle = rule.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
-			RootFSPath: rootfsPath,
-			Privileged: privileged,
+		var err error
+		returnedBundle, err = rule.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+			RootFSPath:  rootfsPath,
+			Privileged:  privileged,
+			TmpfsMounts: tmpfsMounts,
 		})
+		Expect(err).NotTo(HaveOccurred())
 	})
 
 	AfterEach(func() {
@@ -164,5 +170,57 @@ var _ = Describe("RootFS", func() {
 					}))
 			})
 		})
+
+		Context("when tmpfs mounts are requested", func() {
+			BeforeEach(func() {
+				tmpfsMounts = []gardener.TmpfsMount{
+					{Path: "/run"},
+					{Path: "/var/scratch"},
+				}
+			})
+
+			It("pre-creates the mount points", func() {
+				Expect(commandRunner).To(HaveExecutedSerially(
+					fake_command_runner.CommandSpec{
+						Path: "reexeced-thing",
+						Args: []string{
+							"-rootfsPath", rootfsPath,
+							"-uid", "999",
+							"-gid", "888",
+							"-recreate", "false",
+							"-perm", "755",
+							"run",
+							"var/scratch",
+						},
+					}))
+			})
+
+			It("returns an error when the mount points can not be created", func() {
+				failingRunner := fake_command_runner.New()
+				failingRunner.WhenRunning(fake_command_runner.CommandSpec{
+					Path: "reexeced-thing",
+				}, func(*exec.Cmd) error {
+					return errors.New("mkdir failed")
+				})
+				rule.MkdirChown = bundlerules.ChrootMkdir{
+					Command: func(rootfsPath string, uid, gid int, mode os.FileMode, recreate bool, paths ...string) *exec.Cmd {
+						return exec.Command("reexeced-thing", paths...)
+					},
+					CommandRunner: failingRunner,
+				}
+
+				_, err := rule.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+					RootFSPath:  rootfsPath,
+					TmpfsMounts: tmpfsMounts,
+				})
+				Expect(err).To(MatchError("create tmpfs mount points: mkdir failed"))
+			})
+		})
+
+		Context("when no tmpfs mounts are requested", func() {
+			It("only creates the standard directories", func() {
+				Expect(commandRunner.ExecutedCommands()).To(HaveLen(2))
+			})
+		})
 	})
 })
@@ -0,0 +1,27 @@
+package bundlerules
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+// Runtime records the OCI runtime requested in the container spec in the
+// bundle's annotations (under gardener.RuntimeKey), where it is picked up by
+// the runtime multiplexer when the container is created.
+type Runtime struct {
+	Runtimes []string
+}
+
+func (r Runtime) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	if spec.Runtime == "" {
+		return bndl, nil
+	}
+
+	if !contains(r.Runtimes, spec.Runtime) {
+		return goci.Bndl{}, fmt.Errorf("unknown runtime: %s", spec.Runtime)
+	}
+
+	return bndl.WithAnnotation(gardener.RuntimeKey, spec.Runtime), nil
+}
@@ -0,0 +1,43 @@
+package bundlerules_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+var _ = Describe("RuntimeRule", func() {
+	var rule bundlerules.Runtime
+
+	BeforeEach(func() {
+		rule = bundlerules.Runtime{Runtimes: []string{"runc", "runsc"}}
+	})
+
+	Context("when no runtime is requested", func() {
+		It("does not annotate the bundle", func() {
+			newBndl, err := rule.Apply(goci.Bundle(), gardener.DesiredContainerSpec{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(newBndl.Annotation(gardener.RuntimeKey)).To(BeEmpty())
+		})
+	})
+
+	Context("when a known runtime is requested", func() {
+		It("records it in the bundle annotations", func() {
+			newBndl, err := rule.Apply(goci.Bundle(), gardener.DesiredContainerSpec{Runtime: "runsc"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(newBndl.Annotation(gardener.RuntimeKey)).To(Equal("runsc"))
+		})
+	})
+
+	Context("when an unknown runtime is requested", func() {
+		It("returns an error", func() {
+			_, err := rule.Apply(goci.Bundle(), gardener.DesiredContainerSpec{Runtime: "kata"})
+			Expect(err).To(MatchError("unknown runtime: kata"))
+		})
+	})
+})
@@ -0,0 +1,42 @@
+package bundlerules
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+// Seccomp sets the seccomp profile of unprivileged containers to the named
+// profile requested in the container spec, or to the Default profile when no
+// profile is requested. The profile is resolved against the capabilities of
+// the bundle, so this rule must run after the Capabilities rule. When neither
+// a named nor a Default profile applies the profile of the base bundle is left
+// untouched.
+type Seccomp struct {
+	// Architecture the profiles are resolved for, see goci.SeccompArch
+	Arch     string
+	Default  *goci.SeccompProfile
+	Profiles map[string]*goci.SeccompProfile
+}
+
+func (s Seccomp) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	if spec.Privileged {
+		return bndl, nil
+	}
+
+	profile := s.Default
+	if spec.SeccompProfile != "" {
+		var ok bool
+		profile, ok = s.Profiles[spec.SeccompProfile]
+		if !ok {
+			return goci.Bndl{}, fmt.Errorf("unknown seccomp profile: %s", spec.SeccompProfile)
+		}
+	}
+
+	if profile == nil {
+		return bndl, nil
+	}
+
+	return bndl.WithSeccomp(profile.Resolve(s.Arch, bndl.Capabilities())), nil
+}
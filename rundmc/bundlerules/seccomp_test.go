@@ -0,0 +1,112 @@
+package bundlerules_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+var _ = Describe("SeccompRule", func() {
+	var (
+		rule        bundlerules.Seccomp
+		baseProfile *specs.Seccomp
+		bndl        goci.Bndl
+	)
+
+	parseProfile := func(contents string) *goci.SeccompProfile {
+		profile, err := goci.ParseSeccompProfile([]byte(contents))
+		Expect(err).NotTo(HaveOccurred())
+		return profile
+	}
+
+	BeforeEach(func() {
+		baseProfile = &specs.Seccomp{DefaultAction: specs.ActErrno}
+
+		rule = bundlerules.Seccomp{
+			Arch: "amd64",
+			Profiles: map[string]*goci.SeccompProfile{
+				"relaxed": parseProfile(`{"defaultAction": "SCMP_ACT_ALLOW"}`),
+				"mount": parseProfile(`{
+					"defaultAction": "SCMP_ACT_ERRNO",
+					"syscalls": [
+						{"names": ["mount"], "action": "SCMP_ACT_ALLOW", "includes": {"caps": ["CAP_SYS_ADMIN"]}}
+					]
+				}`),
+			},
+		}
+
+		bndl = goci.Bundle().WithSeccomp(baseProfile).WithCapabilities("CAP_CHOWN")
+	})
+
+	Context("when no profile is requested", func() {
+		It("keeps the profile of the base bundle", func() {
+			newBndl, err := rule.Apply(bndl, gardener.DesiredContainerSpec{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(newBndl.Seccomp()).To(Equal(baseProfile))
+		})
+
+		Context("and a default profile is configured", func() {
+			BeforeEach(func() {
+				rule.Default = parseProfile(`{"defaultAction": "SCMP_ACT_KILL"}`)
+			})
+
+			It("sets the default profile on the bundle", func() {
+				newBndl, err := rule.Apply(bndl, gardener.DesiredContainerSpec{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(newBndl.Seccomp().DefaultAction).To(Equal(specs.ActKill))
+			})
+		})
+	})
+
+	Context("when a named profile is requested", func() {
+		It("sets the named profile on the bundle", func() {
+			newBndl, err := rule.Apply(bndl, gardener.DesiredContainerSpec{
+				SeccompProfile: "relaxed",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(newBndl.Seccomp().DefaultAction).To(Equal(specs.ActAllow))
+		})
+
+		It("resolves the profile against the capabilities of the bundle", func() {
+			newBndl, err := rule.Apply(bndl, gardener.DesiredContainerSpec{
+				SeccompProfile: "mount",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newBndl.Seccomp().Syscalls).To(BeEmpty())
+
+			newBndl, err = rule.Apply(bndl.WithCapabilities("CAP_SYS_ADMIN"), gardener.DesiredContainerSpec{
+				SeccompProfile: "mount",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newBndl.Seccomp().Syscalls).To(HaveLen(1))
+		})
+	})
+
+	Context("when the requested profile does not exist", func() {
+		It("returns an error", func() {
+			_, err := rule.Apply(bndl, gardener.DesiredContainerSpec{
+				SeccompProfile: "banana",
+			})
+			Expect(err).To(MatchError("unknown seccomp profile: banana"))
+		})
+	})
+
+	Context("when the container is privileged", func() {
+		It("leaves the bundle untouched", func() {
+			newBndl, err := rule.Apply(bndl, gardener.DesiredContainerSpec{
+				Privileged:     true,
+				SeccompProfile: "relaxed",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(newBndl).To(Equal(bndl))
+		})
+	})
+})
@@ -3,6 +3,8 @@ package rundmc
 import (
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 
 	"code.cloudfoundry.org/garden"
 	"code.cloudfoundry.org/guardian/gardener"
@@ -10,6 +12,7 @@ import (
 	"code.cloudfoundry.org/guardian/rundmc/goci"
 	"code.cloudfoundry.org/guardian/rundmc/runrunc"
 	"code.cloudfoundry.org/lager"
+	"github.com/opencontainers/runtime-spec/specs-go"
 )
 
 //go:generate counterfeiter . Depot
@@ -29,7 +32,7 @@ type Depot interface {
 }
 
 type BundleGenerator interface {
-	Generate(spec gardener.DesiredContainerSpec) goci.Bndl
+	Generate(spec gardener.DesiredContainerSpec) (goci.Bndl, error)
 }
 
 type BundleLoader interface {
@@ -38,13 +41,15 @@ type BundleLoader interface {
 
 type OCIRuntime interface {
 	Create(log lager.Logger, bundlePath, id string, io garden.ProcessIO) error
-	Exec(log lager.Logger, id, bundlePath string, spec garden.ProcessSpec, io garden.ProcessIO) (garden.Process, error)
-	Attach(log lager.Logger, id, bundlePath, processId string, io garden.ProcessIO) (garden.Process, error)
-	Kill(log lager.Logger, bundlePath string) error
-	Delete(log lager.Logger, bundlePath string) error
+	Exec(log lager.Logger, bundlePath, id string, spec garden.ProcessSpec, io garden.ProcessIO) (garden.Process, error)
+	Attach(log lager.Logger, bundlePath, id, processId string, io garden.ProcessIO) (garden.Process, error)
+	Kill(log lager.Logger, handle string) error
+	Delete(log lager.Logger, handle string) error
 	State(log lager.Logger, id string) (runrunc.State, error)
 	Stats(log lager.Logger, id string) (gardener.ActualContainerMetrics, error)
 	WatchEvents(log lager.Logger, id string, eventsNotifier runrunc.EventsNotifier) error
+	StopWatchingEvents(log lager.Logger)
+	List(log lager.Logger) ([]runrunc.ListEntry, error)
 }
 
 type NstarRunner interface {
@@ -98,7 +103,13 @@ func (c *Containerizer) Create(log lager.Logger, spec gardener.DesiredContainerS
 	log.Info("start")
 	defer log.Info("finished")
 
-	if err := c.depot.Create(log, spec.Handle, c.bundler.Generate(spec)); err != nil {
+	bundle, err := c.bundler.Generate(spec)
+	if err != nil {
+		log.Error("generate-bundle-failed", err)
+		return err
+	}
+
+	if err := c.depot.Create(log, spec.Handle, bundle); err != nil {
 		log.Error("depot-create-failed", err)
 		return err
 	}
@@ -109,6 +120,11 @@ func (c *Containerizer) Create(log lager.Logger, spec gardener.DesiredContainerS
 		return err
 	}
 
+	if err := createBundleFiles(path, bundle); err != nil {
+		log.Error("create-bundle-files-failed", err)
+		return err
+	}
+
 	if err = c.runtime.Create(log, path, spec.Handle, garden.ProcessIO{}); err != nil {
 		log.Error("runtime-create-failed", err)
 		return err
@@ -123,6 +139,56 @@ func (c *Containerizer) Create(log lager.Logger, spec gardener.DesiredContainerS
 	return nil
 }
 
+// createBundleFiles creates the sources of the bind mounts which live in the
+// bundle directory (e.g. the writable network config files of containers with
+// a read-only rootfs) as empty files owned by the container's root user.
+func createBundleFiles(bundlePath string, bundle goci.Bndl) error {
+	var paths []string
+	for _, mount := range bundle.Mounts() {
+		if mount.Type == "bind" && filepath.Dir(mount.Source) == bundlePath {
+			paths = append(paths, mount.Source)
+		}
+	}
+
+	if len(paths) == 0 {
+		return nil
+	}
+
+	// the container's root user must be able to reach the files in order to
+	// bind-mount them, but not list the directory
+	if err := os.Chmod(bundlePath, 0711); err != nil {
+		return fmt.Errorf("chmod bundle dir: %s", err)
+	}
+
+	uid := rootID(bundle.UIDMappings())
+	gid := rootID(bundle.GIDMappings())
+	for _, path := range paths {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("create %s: %s", path, err)
+		}
+		file.Close()
+
+		if err := os.Chown(path, uid, gid); err != nil {
+			return fmt.Errorf("chown %s: %s", path, err)
+		}
+	}
+
+	return nil
+}
+
+// rootID returns the host id which the given mappings map the container's
+// root id to
+func rootID(mappings []specs.IDMapping) int {
+	for _, mapping := range mappings {
+		if mapping.ContainerID == 0 {
+			return int(mapping.HostID)
+		}
+	}
+
+	return 0
+}
+
 // Run runs a process inside a running container
 func (c *Containerizer) Run(log lager.Logger, handle string, spec garden.ProcessSpec, io garden.ProcessIO) (garden.Process, error) {
 	log = log.Session("run", lager.Data{"handle": handle, "path": spec.Path})
@@ -279,6 +345,11 @@ func (c *Containerizer) Metrics(log lager.Logger, handle string) (gardener.Actua
 	return c.runtime.Stats(log, handle)
 }
 
+// StopWatchingEvents stops watching all containers for events such as OOMs
+func (c *Containerizer) StopWatchingEvents(log lager.Logger) {
+	c.runtime.StopWatchingEvents(log)
+}
+
 // Handles returns a list of all container handles
 func (c *Containerizer) Handles() ([]string, error) {
 	return c.depot.Handles()
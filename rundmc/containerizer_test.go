@@ -2,7 +2,9 @@ package rundmc_test
 
 import (
 	"errors"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"time"
 
 	"code.cloudfoundry.org/garden"
@@ -55,8 +57,8 @@ var _ = Describe("Rundmc", func() {
 	Describe("Create", func() {
 		It("should ask the depot to create a container", func() {
 			var returnedBundle goci.Bndl
-			fakeBundler.GenerateStub = func(spec gardener.DesiredContainerSpec) goci.Bndl {
-				return returnedBundle
+			fakeBundler.GenerateStub = func(spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+				return returnedBundle, nil
 			}
 
 			containerizer.Create(logger, gardener.DesiredContainerSpec{
@@ -70,6 +72,26 @@ var _ = Describe("Rundmc", func() {
 			Expect(bundle).To(Equal(returnedBundle))
 		})
 
+		Context("when generating the bundle fails", func() {
+			BeforeEach(func() {
+				fakeBundler.GenerateReturns(goci.Bndl{}, errors.New("bad-spec"))
+			})
+
+			It("returns the error", func() {
+				Expect(containerizer.Create(logger, gardener.DesiredContainerSpec{
+					Handle: "exuberant!",
+				})).To(MatchError("bad-spec"))
+			})
+
+			It("does not create the depot directory", func() {
+				containerizer.Create(logger, gardener.DesiredContainerSpec{
+					Handle: "exuberant!",
+				})
+
+				Expect(fakeDepot.CreateCallCount()).To(Equal(0))
+			})
+		})
+
 		Context("when creating the depot directory fails", func() {
 			It("returns an error", func() {
 				fakeDepot.CreateReturns(errors.New("blam"))
@@ -98,6 +120,43 @@ var _ = Describe("Rundmc", func() {
 
 		})
 
+		Context("when the bundle bind-mounts files from the bundle directory", func() {
+			var bundleDir string
+
+			BeforeEach(func() {
+				var err error
+				bundleDir, err = ioutil.TempDir("", "bundle")
+				Expect(err).NotTo(HaveOccurred())
+
+				fakeDepot.LookupReturns(bundleDir, nil)
+				fakeBundler.GenerateReturns(goci.Bundle().
+					WithUIDMappings(specs.IDMapping{ContainerID: 0, HostID: uint32(os.Getuid()), Size: 1}).
+					WithGIDMappings(specs.IDMapping{ContainerID: 0, HostID: uint32(os.Getgid()), Size: 1}).
+					WithMounts(
+						specs.Mount{Type: "bind", Source: filepath.Join(bundleDir, "hosts"), Destination: "/etc/hosts"},
+						specs.Mount{Type: "bind", Source: "/etc/resolv.conf", Destination: "/etc/resolv.conf"},
+					), nil)
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(bundleDir)).To(Succeed())
+			})
+
+			It("creates them after the bundle is in the depot", func() {
+				Expect(containerizer.Create(logger, gardener.DesiredContainerSpec{Handle: "exuberant!"})).To(Succeed())
+
+				Expect(filepath.Join(bundleDir, "hosts")).To(BeAnExistingFile())
+			})
+
+			It("allows the container's root user to traverse the bundle directory", func() {
+				Expect(containerizer.Create(logger, gardener.DesiredContainerSpec{Handle: "exuberant!"})).To(Succeed())
+
+				info, err := os.Stat(bundleDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0711)))
+			})
+		})
+
 		Context("when the container creation fails", func() {
 			BeforeEach(func() {
 				fakeOCIRuntime.CreateReturns(errors.New("banana"))
@@ -542,6 +601,13 @@ var _ = Describe("Rundmc", func() {
 			})
 		})
 	})
+
+	Describe("StopWatchingEvents", func() {
+		It("stops the runtime watching events", func() {
+			containerizer.StopWatchingEvents(logger)
+			Expect(fakeOCIRuntime.StopWatchingEventsCallCount()).To(Equal(1))
+		})
+	})
 })
 
 func arg2(_ lager.Logger, i interface{}) interface{} {
@@ -16,6 +16,7 @@ import (
 	"syscall"
 
 	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
 	"code.cloudfoundry.org/guardian/rundmc/runrunc"
 	"code.cloudfoundry.org/lager"
 	"github.com/cloudfoundry/gunk/command_runner"
@@ -27,18 +28,20 @@ type PidGetter interface {
 	Pid(pidFilePath string) (int, error)
 }
 
+var ErrTTYNotSupported = errors.New("runtime does not support tty processes")
+
 type ExecRunner struct {
 	dadooPath     string
-	runcPath      string
+	runtime       goci.RuntimeBinary
 	processIDGen  runrunc.UidGenerator
 	pidGetter     PidGetter
 	commandRunner command_runner.CommandRunner
 }
 
-func NewExecRunner(dadooPath, runcPath string, processIDGen runrunc.UidGenerator, pidGetter PidGetter, commandRunner command_runner.CommandRunner) *ExecRunner {
+func NewExecRunner(dadooPath string, runtime goci.RuntimeBinary, processIDGen runrunc.UidGenerator, pidGetter PidGetter, commandRunner command_runner.CommandRunner) *ExecRunner {
 	return &ExecRunner{
 		dadooPath:     dadooPath,
-		runcPath:      runcPath,
+		runtime:       runtime,
 		processIDGen:  processIDGen,
 		pidGetter:     pidGetter,
 		commandRunner: commandRunner,
@@ -51,6 +54,10 @@ func (d *ExecRunner) Run(log lager.Logger, spec *runrunc.PreparedSpec, processes
 	log.Info("start")
 	defer log.Info("done")
 
+	if tty != nil && !d.runtime.Features.ConsolePath {
+		return nil, ErrTTYNotSupported
+	}
+
 	processID := d.processIDGen.Generate()
 
 	processPath := filepath.Join(processesPath, processID)
@@ -77,7 +84,7 @@ func (d *ExecRunner) Run(log lager.Logger, spec *runrunc.PreparedSpec, processes
 		return nil, err
 	}
 
-	var cmd *exec.Cmd
+	var args []string
 	if tty != nil {
 		var rows, cols int
 		if tty.WindowSize != nil {
@@ -85,11 +92,15 @@ func (d *ExecRunner) Run(log lager.Logger, spec *runrunc.PreparedSpec, processes
 			cols = tty.WindowSize.Columns
 		}
 
-		cmd = exec.Command(d.dadooPath, "-tty", "-rows", strconv.Itoa(rows), "-cols", strconv.Itoa(cols), "-uid", strconv.Itoa(spec.HostUID), "-gid", strconv.Itoa(spec.HostGID), "exec", d.runcPath, processPath, handle)
-	} else {
-		cmd = exec.Command(d.dadooPath, "exec", d.runcPath, processPath, handle)
+		args = []string{"-tty", "-rows", strconv.Itoa(rows), "-cols", strconv.Itoa(cols), "-uid", strconv.Itoa(spec.HostUID), "-gid", strconv.Itoa(spec.HostGID)}
 	}
 
+	if d.runtime.Flavour != goci.RuncFlavour {
+		args = append(args, "-runtime-flavour", string(d.runtime.Flavour))
+	}
+
+	cmd := exec.Command(d.dadooPath, append(args, "exec", d.runtime.Path, processPath, handle)...)
+
 	cmd.ExtraFiles = []*os.File{
 		fd3w,
 		logw,
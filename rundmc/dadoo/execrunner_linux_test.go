@@ -17,6 +17,7 @@ import (
 	"code.cloudfoundry.org/garden"
 	"code.cloudfoundry.org/guardian/rundmc/dadoo"
 	dadoofakes "code.cloudfoundry.org/guardian/rundmc/dadoo/dadoofakes"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
 	"code.cloudfoundry.org/guardian/rundmc/runrunc"
 	fakes "code.cloudfoundry.org/guardian/rundmc/runrunc/runruncfakes"
 	"code.cloudfoundry.org/lager"
@@ -60,7 +61,8 @@ var _ = Describe("Dadoo ExecRunner", func() {
 		processPath = filepath.Join(bundlePath, "the-process")
 		pidPath = filepath.Join(processPath, "0.pid")
 
-		runner = dadoo.NewExecRunner("path-to-dadoo", "path-to-runc", fakeProcessIDGenerator, fakePidGetter, fakeCommandRunner)
+		runtime := goci.RuntimeBinary{Path: "path-to-runc", Flavour: goci.RuncFlavour, Features: goci.AllRuntimeFeatures}
+		runner = dadoo.NewExecRunner("path-to-dadoo", runtime, fakeProcessIDGenerator, fakePidGetter, fakeCommandRunner)
 		log = lagertest.NewTestLogger("test")
 
 		runcReturns = 0
@@ -179,6 +181,36 @@ var _ = Describe("Dadoo ExecRunner", func() {
 					}),
 				)
 			})
+
+			Context("and the runtime does not support tty processes", func() {
+				BeforeEach(func() {
+					runtime := goci.RuntimeBinary{Path: "path-to-crun", Flavour: goci.CrunFlavour}
+					runner = dadoo.NewExecRunner("path-to-dadoo", runtime, fakeProcessIDGenerator, fakePidGetter, fakeCommandRunner)
+				})
+
+				It("returns an error without executing dadoo", func() {
+					_, err := runner.Run(log, &runrunc.PreparedSpec{}, processPath, "some-handle", &garden.TTYSpec{}, garden.ProcessIO{})
+					Expect(err).To(Equal(dadoo.ErrTTYNotSupported))
+					Expect(fakeCommandRunner.StartedCommands()).To(BeEmpty())
+				})
+			})
+		})
+
+		Context("when the runtime is not runc", func() {
+			BeforeEach(func() {
+				runtime := goci.RuntimeBinary{Path: "path-to-runsc", Flavour: goci.RunscFlavour}
+				runner = dadoo.NewExecRunner("path-to-dadoo", runtime, fakeProcessIDGenerator, fakePidGetter, fakeCommandRunner)
+			})
+
+			It("passes the runtime flavour to dadoo", func() {
+				runner.Run(log, &runrunc.PreparedSpec{}, processPath, "some-handle", nil, garden.ProcessIO{})
+
+				Expect(fakeCommandRunner.StartedCommands()[0].Args).To(Equal([]string{
+					"path-to-dadoo",
+					"-runtime-flavour", "runsc",
+					"exec", "path-to-runsc", filepath.Join(processPath, "the-pid"), "some-handle",
+				}))
+			})
 		})
 
 		It("does not block on dadoo returning before returning", func() {
@@ -49,6 +49,16 @@ func (b Bndl) WithRootFS(absolutePath string) Bndl {
 	return b
 }
 
+// WithReadonlyRootFS returns a bundle whose rootfs is mounted read-only, or not. The original bundle is not modified.
+func (b Bndl) WithReadonlyRootFS(readonly bool) Bndl {
+	b.Spec.Root.Readonly = readonly
+	return b
+}
+
+func (b Bndl) ReadonlyRootFS() bool {
+	return b.Spec.Root.Readonly
+}
+
 // GetRootfsPath returns the path to the rootfs of this bundle. Nothing is modified
 func (b Bndl) RootFS() string {
 	return b.Spec.Root.Path
@@ -163,6 +173,22 @@ func (b Bndl) Capabilities() []string {
 	return b.Spec.Process.Capabilities
 }
 
+// WithAnnotation returns a bundle with the given annotation set. The original bundle is not modified.
+func (b Bndl) WithAnnotation(key, value string) Bndl {
+	annotations := map[string]string{}
+	for k, v := range b.Spec.Annotations {
+		annotations[k] = v
+	}
+
+	annotations[key] = value
+	b.Spec.Annotations = annotations
+	return b
+}
+
+func (b Bndl) Annotation(key string) string {
+	return b.Spec.Annotations[key]
+}
+
 // WithMounts returns a bundle with the given mounts added. The original bundle is not modified.
 func (b Bndl) WithMounts(mounts ...specs.Mount) Bndl {
 	b.Spec.Mounts = append(b.Spec.Mounts, mounts...)
@@ -182,6 +208,25 @@ func (b Bndl) MaskedPaths() []string {
 	return b.Spec.Linux.MaskedPaths
 }
 
+func (b Bndl) WithReadonlyPaths(readonlyPaths []string) Bndl {
+	b.Spec.Linux.ReadonlyPaths = readonlyPaths
+	return b
+}
+
+func (b Bndl) ReadonlyPaths() []string {
+	return b.Spec.Linux.ReadonlyPaths
+}
+
+// WithSeccomp returns a bundle with the seccomp profile replaced with the given profile. The original bundle is not modified.
+func (b Bndl) WithSeccomp(seccomp *specs.Seccomp) Bndl {
+	b.Spec.Linux.Seccomp = seccomp
+	return b
+}
+
+func (b Bndl) Seccomp() *specs.Seccomp {
+	return b.Spec.Linux.Seccomp
+}
+
 type NamespaceSlice []specs.Namespace
 
 func (slice NamespaceSlice) Set(ns specs.Namespace) NamespaceSlice {
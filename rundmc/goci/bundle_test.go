@@ -39,6 +39,19 @@ var _ = Describe("Bundle", func() {
 		})
 	})
 
+	Describe("WithAnnotation", func() {
+		It("sets the annotation in the bundle", func() {
+			returnedBundle := initialBundle.WithAnnotation("foo", "bar")
+			Expect(returnedBundle.Annotation("foo")).To(Equal("bar"))
+		})
+
+		It("does not modify the original bundle", func() {
+			withFoo := initialBundle.WithAnnotation("foo", "bar")
+			withFoo.WithAnnotation("baz", "qux")
+			Expect(withFoo.Spec.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+		})
+	})
+
 	Describe("WithProcess", func() {
 		It("adds the process to the bundle", func() {
 			returnedBundle := initialBundle.WithProcess(goci.Process("echo", "foo"))
@@ -63,6 +76,14 @@ var _ = Describe("Bundle", func() {
 		})
 	})
 
+	Describe("WithReadonlyRootFS", func() {
+		It("marks the rootfs as read-only in the spec", func() {
+			returnedBundle := initialBundle.WithRootFS("/foo").WithReadonlyRootFS(true)
+			Expect(returnedBundle.ReadonlyRootFS()).To(BeTrue())
+			Expect(returnedBundle.RootFS()).To(Equal("/foo"))
+		})
+	})
+
 	Describe("WithPrestartHooks", func() {
 		It("adds the hook to the runtime spec", func() {
 			returnedBundle := initialBundle.WithPrestartHooks(specs.Hook{
@@ -300,4 +321,19 @@ var _ = Describe("Bundle", func() {
 		})
 	})
 
+	Describe("WithReadonlyPaths", func() {
+		It("sets the ReadonlyPaths in the bundle", func() {
+			returnedBundle := initialBundle.WithReadonlyPaths([]string{"path1", "path2"})
+			Expect(returnedBundle.ReadonlyPaths()).To(Equal([]string{"path1", "path2"}))
+		})
+	})
+
+	Describe("WithSeccomp", func() {
+		It("sets the Seccomp profile in the bundle", func() {
+			profile := &specs.Seccomp{DefaultAction: specs.ActErrno}
+			returnedBundle := initialBundle.WithSeccomp(profile)
+			Expect(returnedBundle.Seccomp()).To(Equal(profile))
+		})
+	})
+
 })
@@ -42,57 +42,68 @@ func EventsCommand(id string) *exec.Cmd {
 	return DefaultRuncBinary.EventsCommand(id)
 }
 
+// ListCommand creates a command that lists containers using the default runc binary name.
+func ListCommand(logFile string) *exec.Cmd {
+	return DefaultRuncBinary.ListCommand(logFile)
+}
+
+func (runc RuncBinary) runtime() RuntimeBinary {
+	return RuntimeBinary{Path: string(runc), Flavour: RuncFlavour, Features: AllRuntimeFeatures}
+}
+
 // StartCommand returns an *exec.Cmd that, when run, will execute a given bundle.
 func (runc RuncBinary) StartCommand(path, id string, detach bool, log string) *exec.Cmd {
-	args := []string{"--debug", "--log", log, "start"}
-	if detach {
-		args = append(args, "-d")
-	}
-
-	args = append(args, id)
+	return runc.runtime().StartCommand(path, id, detach, log)
+}
 
-	cmd := exec.Command(string(runc), args...)
-	cmd.Dir = path
-	return cmd
+// CreateCommand returns an *exec.Cmd that, when run, will create a container
+// from the given bundle.
+func (runc RuncBinary) CreateCommand(bundlePath, id, pidFilePath, logFile string) *exec.Cmd {
+	return runc.runtime().CreateCommand(bundlePath, id, pidFilePath, logFile)
 }
 
 // ExecCommand returns an *exec.Cmd that, when run, will execute a process spec
 // in a running container.
 func (runc RuncBinary) ExecCommand(id, processJSONPath, pidFilePath string) *exec.Cmd {
-	return exec.Command(
-		string(runc), "exec", id, "--pid-file", pidFilePath, "-p", processJSONPath,
-	)
+	return runc.runtime().ExecCommand(id, processJSONPath, pidFilePath)
 }
 
 // EventsCommand returns an *exec.Cmd that, when run, will retrieve events for the container
 func (runc RuncBinary) EventsCommand(id string) *exec.Cmd {
-	return exec.Command(
-		string(runc), "events", id,
-	)
+	return runc.runtime().EventsCommand(id)
 }
 
 // KillCommand returns an *exec.Cmd that, when run, will signal the running
 // container.
 func (runc RuncBinary) KillCommand(id, signal, logFile string) *exec.Cmd {
-	return exec.Command(
-		string(runc), "--debug", "--log", logFile, "kill", id, signal,
-	)
+	return runc.runtime().KillCommand(id, signal, logFile)
 }
 
 // StateCommand returns an *exec.Cmd that, when run, will get the state of the
 // container.
 func (runc RuncBinary) StateCommand(id, logFile string) *exec.Cmd {
-	return exec.Command(string(runc), "--debug", "--log", logFile, "state", id)
+	return runc.runtime().StateCommand(id, logFile)
 }
 
 // StatsCommand returns an *exec.Cmd that, when run, will get the stats of the
 // container.
 func (runc RuncBinary) StatsCommand(id, logFile string) *exec.Cmd {
-	return exec.Command(string(runc), "--debug", "--log", logFile, "events", "--stats", id)
+	return runc.runtime().StatsCommand(id, logFile)
 }
 
 // DeleteCommand returns an *exec.Cmd that, when run, will signal the running
 // container.
 func (runc RuncBinary) DeleteCommand(id, logFile string) *exec.Cmd {
-	return exec.Command(string(runc), "--debug", "--log", logFile, "delete", id)
+	return runc.runtime().DeleteCommand(id, logFile)
+}
+
+// ListCommand returns an *exec.Cmd that, when run, will list the containers
+// as JSON.
+func (runc RuncBinary) ListCommand(logFile string) *exec.Cmd {
+	return runc.runtime().ListCommand(logFile)
+}
+
+// SupportedFeatures returns the features supported by runc.
+func (runc RuncBinary) SupportedFeatures() RuntimeFeatures {
+	return AllRuntimeFeatures
 }
@@ -70,4 +70,11 @@ var _ = Describe("Commands", func() {
 			Expect(cmd.Args).To(Equal([]string{"funC", "--debug", "--log", "log.file", "delete", "my-bundle-id"}))
 		})
 	})
+
+	Describe("ListCommand", func() {
+		It("creates an *exec.Cmd to list the containers as JSON", func() {
+			cmd := goci.ListCommand("log.file")
+			Expect(cmd.Args).To(Equal([]string{"funC", "--debug", "--log", "log.file", "list", "--format", "json"}))
+		})
+	})
 })
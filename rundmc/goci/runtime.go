@@ -0,0 +1,209 @@
+package goci
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// RuntimeFlavour identifies the command line dialect of an OCI runtime.
+type RuntimeFlavour string
+
+const (
+	RuncFlavour  RuntimeFlavour = "runc"
+	CrunFlavour  RuntimeFlavour = "crun"
+	RunscFlavour RuntimeFlavour = "runsc"
+)
+
+// ParseRuntimeFlavour returns the named runtime flavour, or an error if it is
+// not supported.
+func ParseRuntimeFlavour(name string) (RuntimeFlavour, error) {
+	switch flavour := RuntimeFlavour(name); flavour {
+	case RuncFlavour, CrunFlavour, RunscFlavour:
+		return flavour, nil
+	default:
+		return "", fmt.Errorf("unsupported runtime flavour '%s', expected one of runc, crun or runsc", name)
+	}
+}
+
+// RuntimeFeatures records the optional features supported by an OCI runtime.
+type RuntimeFeatures struct {
+	// Events is true when 'events' streams OOM notifications.
+	Events bool
+	// Stats is true when 'events --stats' reports container statistics.
+	Stats bool
+	// ConsolePath is true when 'exec --console' accepts the path of a pty.
+	ConsolePath bool
+}
+
+// AllRuntimeFeatures is the feature set of runc, which the command builders
+// were originally written against.
+var AllRuntimeFeatures = RuntimeFeatures{Events: true, Stats: true, ConsolePath: true}
+
+var consolePathFlag = regexp.MustCompile(`-console[\s=]`)
+
+// ProbeRuntimeFeatures determines which optional features the runtime at path
+// supports by inspecting the help output of its subcommands.
+func ProbeRuntimeFeatures(path string, flavour RuntimeFlavour) (RuntimeFeatures, error) {
+	if _, err := exec.LookPath(path); err != nil {
+		return RuntimeFeatures{}, fmt.Errorf("runtime binary: %s", err)
+	}
+
+	var features RuntimeFeatures
+	if help, ok := subcommandHelp(path, "events"); ok {
+		// runsc's events only reports statistics, never OOM notifications
+		features.Events = flavour != RunscFlavour
+		features.Stats = strings.Contains(help, "stats")
+	}
+
+	if help, ok := subcommandHelp(path, "exec"); ok {
+		features.ConsolePath = consolePathFlag.MatchString(help)
+	}
+
+	return features, nil
+}
+
+func subcommandHelp(path, subcommand string) (string, bool) {
+	output, err := exec.Command(path, subcommand, "--help").CombinedOutput()
+	if err != nil {
+		return "", false
+	}
+
+	return string(output), true
+}
+
+// RuntimeBinary builds commands for an OCI runtime of a particular flavour.
+type RuntimeBinary struct {
+	Path     string
+	Flavour  RuntimeFlavour
+	Features RuntimeFeatures
+}
+
+// StartCommand returns an *exec.Cmd that, when run, will execute a given bundle.
+func (r RuntimeBinary) StartCommand(path, id string, detach bool, log string) *exec.Cmd {
+	args := []string{"--debug", "--log", log, "start"}
+	if detach {
+		args = append(args, r.detachFlag())
+	}
+
+	args = append(args, id)
+
+	cmd := exec.Command(r.Path, args...)
+	cmd.Dir = path
+	return cmd
+}
+
+// CreateCommand returns an *exec.Cmd that, when run, will create a container
+// from the given bundle. All supported flavours accept the same flags for
+// create, as long as they precede the id.
+func (r RuntimeBinary) CreateCommand(bundlePath, id, pidFilePath, logFile string) *exec.Cmd {
+	return exec.Command(r.Path, "--debug", "--log", logFile, "create", "--bundle", bundlePath, "--pid-file", pidFilePath, id)
+}
+
+// ExecCommand returns an *exec.Cmd that, when run, will execute a process spec
+// in a running container.
+func (r RuntimeBinary) ExecCommand(id, processJSONPath, pidFilePath string) *exec.Cmd {
+	if r.Flavour == RunscFlavour {
+		// runsc stops parsing flags at the first positional argument
+		return exec.Command(r.Path, "exec", "--pid-file", pidFilePath, "--process", processJSONPath, id)
+	}
+
+	return exec.Command(
+		r.Path, "exec", id, "--pid-file", pidFilePath, "-p", processJSONPath,
+	)
+}
+
+// DetachedExecCommand returns an *exec.Cmd that, when run, will execute a
+// process spec in a running container without waiting for it to exit. When
+// consolePath is not empty the process is attached to the given pty.
+func (r RuntimeBinary) DetachedExecCommand(id, processJSONPath, pidFilePath, logFile, consolePath string) *exec.Cmd {
+	if r.Flavour == RuncFlavour {
+		if consolePath != "" {
+			return exec.Command(r.Path, "-debug", "-log", logFile, "exec", "-d", "-tty", "-console", consolePath, "-p", processJSONPath, "-pid-file", pidFilePath, id)
+		}
+
+		return exec.Command(r.Path, "-debug", "-log", logFile, "exec", "-p", processJSONPath, "-d", "-pid-file", pidFilePath, id)
+	}
+
+	args := []string{"--debug", "--log", logFile, "exec", r.detachFlag()}
+	if consolePath != "" {
+		args = append(args, "--tty", "--console", consolePath)
+	}
+
+	args = append(args, r.processFlag(), processJSONPath, "--pid-file", pidFilePath, id)
+	return exec.Command(r.Path, args...)
+}
+
+// EventsCommand returns an *exec.Cmd that, when run, will retrieve events for the container
+func (r RuntimeBinary) EventsCommand(id string) *exec.Cmd {
+	return exec.Command(
+		r.Path, "events", id,
+	)
+}
+
+// KillCommand returns an *exec.Cmd that, when run, will signal the running
+// container.
+func (r RuntimeBinary) KillCommand(id, signal, logFile string) *exec.Cmd {
+	return exec.Command(
+		r.Path, "--debug", "--log", logFile, "kill", id, signal,
+	)
+}
+
+// StateCommand returns an *exec.Cmd that, when run, will get the state of the
+// container.
+func (r RuntimeBinary) StateCommand(id, logFile string) *exec.Cmd {
+	return exec.Command(r.Path, "--debug", "--log", logFile, "state", id)
+}
+
+// StatsCommand returns an *exec.Cmd that, when run, will get the stats of the
+// container.
+func (r RuntimeBinary) StatsCommand(id, logFile string) *exec.Cmd {
+	return exec.Command(r.Path, "--debug", "--log", logFile, "events", "--stats", id)
+}
+
+// DeleteCommand returns an *exec.Cmd that, when run, will signal the running
+// container.
+func (r RuntimeBinary) DeleteCommand(id, logFile string) *exec.Cmd {
+	return exec.Command(r.Path, "--debug", "--log", logFile, "delete", id)
+}
+
+// ListCommand returns an *exec.Cmd that, when run, will list the runtime's
+// containers as JSON.
+func (r RuntimeBinary) ListCommand(logFile string) *exec.Cmd {
+	return exec.Command(r.Path, "--debug", "--log", logFile, "list", "--format", "json")
+}
+
+// StateDir returns the directory in which the runtime keeps the state of its
+// containers when it is not given a --root.
+func (r RuntimeBinary) StateDir() string {
+	switch r.Flavour {
+	case CrunFlavour:
+		return "/run/crun"
+	case RunscFlavour:
+		return "/var/run/runsc"
+	default:
+		return "/run/runc"
+	}
+}
+
+// SupportedFeatures returns the features supported by the runtime.
+func (r RuntimeBinary) SupportedFeatures() RuntimeFeatures {
+	return r.Features
+}
+
+func (r RuntimeBinary) detachFlag() string {
+	if r.Flavour == RunscFlavour {
+		return "--detach"
+	}
+
+	return "-d"
+}
+
+func (r RuntimeBinary) processFlag() string {
+	if r.Flavour == RunscFlavour {
+		return "--process"
+	}
+
+	return "-p"
+}
@@ -0,0 +1,158 @@
+package goci_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Runtimes", func() {
+	Describe("ParseRuntimeFlavour", func() {
+		It("accepts the supported flavours", func() {
+			for _, name := range []string{"runc", "crun", "runsc"} {
+				flavour, err := goci.ParseRuntimeFlavour(name)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(flavour).To(Equal(goci.RuntimeFlavour(name)))
+			}
+		})
+
+		It("rejects unknown flavours", func() {
+			_, err := goci.ParseRuntimeFlavour("kata")
+			Expect(err).To(MatchError(ContainSubstring("unsupported runtime flavour 'kata'")))
+		})
+	})
+
+	Describe("RuntimeBinary", func() {
+		Describe("ExecCommand", func() {
+			It("places the flags after the id for runc", func() {
+				cmd := goci.RuntimeBinary{Path: "funC", Flavour: goci.RuncFlavour}.ExecCommand("id", "process.json", "pidfile")
+				Expect(cmd.Args).To(Equal([]string{"funC", "exec", "id", "--pid-file", "pidfile", "-p", "process.json"}))
+			})
+
+			It("places the flags before the id for runsc", func() {
+				cmd := goci.RuntimeBinary{Path: "runsc", Flavour: goci.RunscFlavour}.ExecCommand("id", "process.json", "pidfile")
+				Expect(cmd.Args).To(Equal([]string{"runsc", "exec", "--pid-file", "pidfile", "--process", "process.json", "id"}))
+			})
+		})
+
+		Describe("CreateCommand", func() {
+			It("places the flags before the id for every flavour", func() {
+				for _, flavour := range []goci.RuntimeFlavour{goci.RuncFlavour, goci.CrunFlavour, goci.RunscFlavour} {
+					cmd := goci.RuntimeBinary{Path: "funC", Flavour: flavour}.CreateCommand("bundle", "id", "pidfile", "log")
+					Expect(cmd.Args).To(Equal([]string{"funC", "--debug", "--log", "log", "create", "--bundle", "bundle", "--pid-file", "pidfile", "id"}))
+				}
+			})
+		})
+
+		Describe("StateDir", func() {
+			It("returns the default state directory of the flavour", func() {
+				Expect(goci.RuntimeBinary{Flavour: goci.RuncFlavour}.StateDir()).To(Equal("/run/runc"))
+				Expect(goci.RuntimeBinary{Flavour: goci.CrunFlavour}.StateDir()).To(Equal("/run/crun"))
+				Expect(goci.RuntimeBinary{Flavour: goci.RunscFlavour}.StateDir()).To(Equal("/var/run/runsc"))
+			})
+		})
+
+		Describe("DetachedExecCommand", func() {
+			Context("for runc", func() {
+				runc := goci.RuntimeBinary{Path: "funC", Flavour: goci.RuncFlavour}
+
+				It("creates a detached exec command", func() {
+					cmd := runc.DetachedExecCommand("id", "process.json", "pidfile", "log", "")
+					Expect(cmd.Args).To(Equal([]string{"funC", "-debug", "-log", "log", "exec", "-p", "process.json", "-d", "-pid-file", "pidfile", "id"}))
+				})
+
+				It("attaches the console when given", func() {
+					cmd := runc.DetachedExecCommand("id", "process.json", "pidfile", "log", "/dev/pts/1")
+					Expect(cmd.Args).To(Equal([]string{"funC", "-debug", "-log", "log", "exec", "-d", "-tty", "-console", "/dev/pts/1", "-p", "process.json", "-pid-file", "pidfile", "id"}))
+				})
+			})
+
+			Context("for crun", func() {
+				It("creates a detached exec command", func() {
+					cmd := goci.RuntimeBinary{Path: "crun", Flavour: goci.CrunFlavour}.DetachedExecCommand("id", "process.json", "pidfile", "log", "")
+					Expect(cmd.Args).To(Equal([]string{"crun", "--debug", "--log", "log", "exec", "-d", "-p", "process.json", "--pid-file", "pidfile", "id"}))
+				})
+			})
+
+			Context("for runsc", func() {
+				It("creates a detached exec command", func() {
+					cmd := goci.RuntimeBinary{Path: "runsc", Flavour: goci.RunscFlavour}.DetachedExecCommand("id", "process.json", "pidfile", "log", "/dev/pts/1")
+					Expect(cmd.Args).To(Equal([]string{"runsc", "--debug", "--log", "log", "exec", "--detach", "--tty", "--console", "/dev/pts/1", "--process", "process.json", "--pid-file", "pidfile", "id"}))
+				})
+			})
+		})
+	})
+
+	Describe("ProbeRuntimeFeatures", func() {
+		var (
+			tmpDir      string
+			runtimePath string
+		)
+
+		writeRuntime := func(script string) {
+			Expect(ioutil.WriteFile(runtimePath, []byte("#!/bin/sh\n"+script), 0755)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "runtime")
+			Expect(err).NotTo(HaveOccurred())
+
+			runtimePath = filepath.Join(tmpDir, "fake-runtime")
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(tmpDir)).To(Succeed())
+		})
+
+		Context("when the runtime supports everything", func() {
+			BeforeEach(func() {
+				writeRuntime(`case "$1" in
+events) echo "   --stats   display the container's stats then exit" ;;
+exec) echo "   --console value   specify the pty slave path" ;;
+esac`)
+			})
+
+			It("reports all features", func() {
+				features, err := goci.ProbeRuntimeFeatures(runtimePath, goci.RuncFlavour)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(features).To(Equal(goci.AllRuntimeFeatures))
+			})
+
+			Context("and it is runsc", func() {
+				It("does not report OOM events", func() {
+					features, err := goci.ProbeRuntimeFeatures(runtimePath, goci.RunscFlavour)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(features.Events).To(BeFalse())
+					Expect(features.Stats).To(BeTrue())
+				})
+			})
+		})
+
+		Context("when the runtime has no events subcommand and only a console socket", func() {
+			BeforeEach(func() {
+				writeRuntime(`case "$1" in
+events) exit 1 ;;
+exec) echo "   --console-socket=SOCKET   path to a unix socket" ;;
+esac`)
+			})
+
+			It("reports the missing features", func() {
+				features, err := goci.ProbeRuntimeFeatures(runtimePath, goci.CrunFlavour)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(features).To(Equal(goci.RuntimeFeatures{}))
+			})
+		})
+
+		Context("when the runtime binary does not exist", func() {
+			It("returns an error", func() {
+				_, err := goci.ProbeRuntimeFeatures(filepath.Join(tmpDir, "nope"), goci.RuncFlavour)
+				Expect(err).To(MatchError(ContainSubstring("runtime binary")))
+			})
+		})
+	})
+})
@@ -0,0 +1,244 @@
+package goci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// seccompProfile is the on-disk representation of a seccomp profile. It
+// accepts both the OCI format (one syscall per entry, keyed by "name") and the
+// Docker format (several syscalls per entry, keyed by "names", with optional
+// "archMap").
+type seccompProfile struct {
+	DefaultAction specs.Action     `json:"defaultAction"`
+	Architectures []specs.Arch     `json:"architectures"`
+	ArchMap       []seccompArchMap `json:"archMap"`
+	Syscalls      []seccompSyscall `json:"syscalls"`
+}
+
+type seccompArchMap struct {
+	Architecture     specs.Arch   `json:"architecture"`
+	SubArchitectures []specs.Arch `json:"subArchitectures"`
+}
+
+type seccompSyscall struct {
+	Name     string           `json:"name"`
+	Names    []string         `json:"names"`
+	Action   specs.Action     `json:"action"`
+	Args     []specs.Arg      `json:"args"`
+	Includes seccompCondition `json:"includes"`
+	Excludes seccompCondition `json:"excludes"`
+}
+
+// seccompCondition is a Docker "includes" or "excludes" clause. The
+// minKernel condition is not evaluated, since guardian requires a kernel
+// newer than any Docker profile asks for.
+type seccompCondition struct {
+	Arches []string `json:"arches"`
+	Caps   []string `json:"caps"`
+}
+
+// SeccompProfile is a validated seccomp profile. Entries of Docker profiles
+// may be conditional on the architecture and the capabilities of the
+// container, so the OCI configuration is only produced by Resolve.
+type SeccompProfile struct {
+	defaultAction specs.Action
+	architectures []specs.Arch
+	syscalls      []conditionalSyscall
+}
+
+type conditionalSyscall struct {
+	syscall  specs.Syscall
+	includes seccompCondition
+	excludes seccompCondition
+}
+
+// Resolve returns the OCI seccomp configuration for a container with the
+// given capabilities on the given architecture, which is named as Docker
+// names it (see SeccompArch). An entry is kept if the architecture and all of
+// the capabilities it includes match, and neither the architecture nor any of
+// the capabilities it excludes do.
+func (p *SeccompProfile) Resolve(arch string, caps []string) *specs.Seccomp {
+	seccomp := &specs.Seccomp{
+		DefaultAction: p.defaultAction,
+		Architectures: p.architectures,
+		Syscalls:      []specs.Syscall{},
+	}
+
+	for _, syscall := range p.syscalls {
+		if len(syscall.includes.Arches) > 0 && !contains(syscall.includes.Arches, arch) {
+			continue
+		}
+
+		if !containsAll(caps, syscall.includes.Caps) {
+			continue
+		}
+
+		if contains(syscall.excludes.Arches, arch) || containsAny(caps, syscall.excludes.Caps) {
+			continue
+		}
+
+		seccomp.Syscalls = append(seccomp.Syscalls, syscall.syscall)
+	}
+
+	return seccomp
+}
+
+// SeccompArch returns the name Docker seccomp profiles use for the
+// architecture of the host
+func SeccompArch() string {
+	if runtime.GOARCH == "386" {
+		return "x86"
+	}
+
+	return runtime.GOARCH
+}
+
+var seccompActions = map[specs.Action]bool{
+	specs.ActKill:  true,
+	specs.ActTrap:  true,
+	specs.ActErrno: true,
+	specs.ActTrace: true,
+	specs.ActAllow: true,
+}
+
+var seccompOperators = map[specs.Operator]bool{
+	specs.OpNotEqual:     true,
+	specs.OpLessThan:     true,
+	specs.OpLessEqual:    true,
+	specs.OpEqualTo:      true,
+	specs.OpGreaterEqual: true,
+	specs.OpGreaterThan:  true,
+	specs.OpMaskedEqual:  true,
+}
+
+// LoadSeccompProfile reads and validates the seccomp profile at path.
+func LoadSeccompProfile(path string) (*SeccompProfile, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading seccomp profile: %s", err)
+	}
+
+	seccomp, err := ParseSeccompProfile(contents)
+	if err != nil {
+		return nil, fmt.Errorf("parsing seccomp profile '%s': %s", path, err)
+	}
+
+	return seccomp, nil
+}
+
+// ParseSeccompProfile parses and validates a JSON seccomp profile in either
+// OCI or Docker format.
+func ParseSeccompProfile(contents []byte) (*SeccompProfile, error) {
+	var profile seccompProfile
+	if err := json.Unmarshal(contents, &profile); err != nil {
+		return nil, err
+	}
+
+	if !seccompActions[profile.DefaultAction] {
+		return nil, fmt.Errorf("invalid default action '%s'", profile.DefaultAction)
+	}
+
+	if len(profile.Architectures) > 0 && len(profile.ArchMap) > 0 {
+		return nil, fmt.Errorf("'architectures' and 'archMap' can not both be specified")
+	}
+
+	seccomp := &SeccompProfile{
+		defaultAction: profile.DefaultAction,
+		architectures: profile.Architectures,
+	}
+
+	for _, archMap := range profile.ArchMap {
+		seccomp.architectures = append(seccomp.architectures, archMap.Architecture)
+		seccomp.architectures = append(seccomp.architectures, archMap.SubArchitectures...)
+	}
+
+	for _, arch := range seccomp.architectures {
+		if !strings.HasPrefix(string(arch), "SCMP_ARCH_") {
+			return nil, fmt.Errorf("invalid architecture '%s'", arch)
+		}
+	}
+
+	for i, syscall := range profile.Syscalls {
+		if syscall.Name != "" && len(syscall.Names) > 0 {
+			return nil, fmt.Errorf("syscall entry %d: 'name' and 'names' can not both be specified", i)
+		}
+
+		names := syscall.Names
+		if syscall.Name != "" {
+			names = []string{syscall.Name}
+		}
+
+		if len(names) == 0 {
+			return nil, fmt.Errorf("syscall entry %d: no syscall name specified", i)
+		}
+
+		if !seccompActions[syscall.Action] {
+			return nil, fmt.Errorf("syscall entry %d: invalid action '%s'", i, syscall.Action)
+		}
+
+		for _, arg := range syscall.Args {
+			if !seccompOperators[arg.Op] {
+				return nil, fmt.Errorf("syscall entry %d: invalid operator '%s'", i, arg.Op)
+			}
+		}
+
+		args := syscall.Args
+		if args == nil {
+			args = []specs.Arg{}
+		}
+
+		for _, name := range names {
+			if name == "" {
+				return nil, fmt.Errorf("syscall entry %d: empty syscall name", i)
+			}
+
+			seccomp.syscalls = append(seccomp.syscalls, conditionalSyscall{
+				syscall: specs.Syscall{
+					Name:   name,
+					Action: syscall.Action,
+					Args:   args,
+				},
+				includes: syscall.Includes,
+				excludes: syscall.Excludes,
+			})
+		}
+	}
+
+	return seccomp, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsAll(values, wanted []string) bool {
+	for _, w := range wanted {
+		if !contains(values, w) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsAny(values, wanted []string) bool {
+	for _, w := range wanted {
+		if contains(values, w) {
+			return true
+		}
+	}
+
+	return false
+}
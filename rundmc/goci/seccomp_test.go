@@ -0,0 +1,159 @@
+package goci_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var _ = Describe("Seccomp profiles", func() {
+	Describe("ParseSeccompProfile", func() {
+		Context("when the profile is in OCI format", func() {
+			It("returns the equivalent OCI seccomp configuration", func() {
+				seccomp, err := goci.ParseSeccompProfile([]byte(`{
+					"defaultAction": "SCMP_ACT_ERRNO",
+					"architectures": ["SCMP_ARCH_X86_64", "SCMP_ARCH_X86"],
+					"syscalls": [
+						{"name": "accept", "action": "SCMP_ACT_ALLOW"},
+						{"name": "personality", "action": "SCMP_ACT_ALLOW", "args": [{"index": 0, "value": 8, "op": "SCMP_CMP_EQ"}]}
+					]
+				}`))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(seccomp.Resolve("amd64", nil)).To(Equal(&specs.Seccomp{
+					DefaultAction: specs.ActErrno,
+					Architectures: []specs.Arch{specs.ArchX86_64, specs.ArchX86},
+					Syscalls: []specs.Syscall{
+						{Name: "accept", Action: specs.ActAllow, Args: []specs.Arg{}},
+						{Name: "personality", Action: specs.ActAllow, Args: []specs.Arg{
+							{Index: 0, Value: 8, Op: specs.OpEqualTo},
+						}},
+					},
+				}))
+			})
+		})
+
+		Context("when the profile is in Docker format", func() {
+			It("expands the names and architecture map", func() {
+				seccomp, err := goci.ParseSeccompProfile([]byte(`{
+					"defaultAction": "SCMP_ACT_ERRNO",
+					"archMap": [
+						{"architecture": "SCMP_ARCH_X86_64", "subArchitectures": ["SCMP_ARCH_X86", "SCMP_ARCH_X32"]}
+					],
+					"syscalls": [
+						{"names": ["accept", "accept4"], "action": "SCMP_ACT_ALLOW", "args": [], "includes": {}}
+					]
+				}`))
+				Expect(err).NotTo(HaveOccurred())
+
+				resolved := seccomp.Resolve("amd64", nil)
+				Expect(resolved.Architectures).To(Equal([]specs.Arch{
+					specs.ArchX86_64, specs.ArchX86, specs.ArchX32,
+				}))
+				Expect(resolved.Syscalls).To(Equal([]specs.Syscall{
+					{Name: "accept", Action: specs.ActAllow, Args: []specs.Arg{}},
+					{Name: "accept4", Action: specs.ActAllow, Args: []specs.Arg{}},
+				}))
+			})
+
+			Describe("resolving conditional entries", func() {
+				var profile *goci.SeccompProfile
+
+				BeforeEach(func() {
+					var err error
+					profile, err = goci.ParseSeccompProfile([]byte(`{
+						"defaultAction": "SCMP_ACT_ERRNO",
+						"syscalls": [
+							{"names": ["accept"], "action": "SCMP_ACT_ALLOW"},
+							{"names": ["arch_prctl"], "action": "SCMP_ACT_ALLOW", "includes": {"arches": ["amd64", "x32"]}},
+							{"names": ["mount"], "action": "SCMP_ACT_ALLOW", "includes": {"caps": ["CAP_SYS_ADMIN"]}},
+							{"names": ["chroot"], "action": "SCMP_ACT_ALLOW", "excludes": {"caps": ["CAP_SYS_CHROOT"]}},
+							{"names": ["s390_pci_mmio_read"], "action": "SCMP_ACT_ALLOW", "excludes": {"arches": ["amd64"]}},
+							{"names": ["clone"], "action": "SCMP_ACT_ALLOW", "includes": {"minKernel": "4.8"}}
+						]
+					}`))
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				names := func(seccomp *specs.Seccomp) []string {
+					var names []string
+					for _, syscall := range seccomp.Syscalls {
+						names = append(names, syscall.Name)
+					}
+					return names
+				}
+
+				It("evaluates the architecture conditions", func() {
+					Expect(names(profile.Resolve("amd64", nil))).To(Equal([]string{"accept", "arch_prctl", "chroot", "clone"}))
+					Expect(names(profile.Resolve("s390x", nil))).To(Equal([]string{"accept", "chroot", "s390_pci_mmio_read", "clone"}))
+				})
+
+				It("evaluates the capability conditions", func() {
+					Expect(names(profile.Resolve("amd64", []string{"CAP_SYS_ADMIN", "CAP_SYS_CHROOT"}))).To(Equal([]string{"accept", "arch_prctl", "mount", "clone"}))
+				})
+			})
+		})
+
+		DescribeTable("invalid profiles",
+			func(profile, expectedErr string) {
+				_, err := goci.ParseSeccompProfile([]byte(profile))
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+			},
+			Entry("malformed JSON", `{`, "unexpected end of JSON input"),
+			Entry("missing default action", `{}`, "invalid default action ''"),
+			Entry("unknown default action", `{"defaultAction": "SCMP_ACT_BANANA"}`, "invalid default action 'SCMP_ACT_BANANA'"),
+			Entry("unknown architecture", `{"defaultAction": "SCMP_ACT_ERRNO", "architectures": ["x86"]}`, "invalid architecture 'x86'"),
+			Entry("both architectures and archMap", `{"defaultAction": "SCMP_ACT_ERRNO", "architectures": ["SCMP_ARCH_X86"], "archMap": [{"architecture": "SCMP_ARCH_X86_64"}]}`, "can not both be specified"),
+			Entry("syscall without a name", `{"defaultAction": "SCMP_ACT_ERRNO", "syscalls": [{"action": "SCMP_ACT_ALLOW"}]}`, "syscall entry 0: no syscall name specified"),
+			Entry("syscall with name and names", `{"defaultAction": "SCMP_ACT_ERRNO", "syscalls": [{"name": "a", "names": ["b"], "action": "SCMP_ACT_ALLOW"}]}`, "syscall entry 0: 'name' and 'names' can not both be specified"),
+			Entry("syscall with unknown action", `{"defaultAction": "SCMP_ACT_ERRNO", "syscalls": [{"name": "accept", "action": "SCMP_ACT_BANANA"}]}`, "syscall entry 0: invalid action 'SCMP_ACT_BANANA'"),
+			Entry("syscall with unknown operator", `{"defaultAction": "SCMP_ACT_ERRNO", "syscalls": [{"name": "accept", "action": "SCMP_ACT_ALLOW", "args": [{"op": "SCMP_CMP_BANANA"}]}]}`, "syscall entry 0: invalid operator 'SCMP_CMP_BANANA'"),
+		)
+	})
+
+	Describe("LoadSeccompProfile", func() {
+		var tmpDir string
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "seccomp")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(tmpDir)).To(Succeed())
+		})
+
+		It("loads the profile from disk", func() {
+			profilePath := filepath.Join(tmpDir, "profile.json")
+			Expect(ioutil.WriteFile(profilePath, []byte(`{"defaultAction": "SCMP_ACT_ALLOW"}`), 0644)).To(Succeed())
+
+			seccomp, err := goci.LoadSeccompProfile(profilePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(seccomp.Resolve(goci.SeccompArch(), nil).DefaultAction).To(Equal(specs.ActAllow))
+		})
+
+		Context("when the file does not exist", func() {
+			It("returns an error", func() {
+				_, err := goci.LoadSeccompProfile(filepath.Join(tmpDir, "nope.json"))
+				Expect(err).To(MatchError(ContainSubstring("reading seccomp profile")))
+			})
+		})
+
+		Context("when the profile is invalid", func() {
+			It("returns an error including the path", func() {
+				profilePath := filepath.Join(tmpDir, "profile.json")
+				Expect(ioutil.WriteFile(profilePath, []byte(`{}`), 0644)).To(Succeed())
+
+				_, err := goci.LoadSeccompProfile(profilePath)
+				Expect(err).To(MatchError(ContainSubstring(profilePath)))
+			})
+		})
+	})
+})
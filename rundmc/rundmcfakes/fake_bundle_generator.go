@@ -10,19 +10,20 @@ import (
 )
 
 type FakeBundleGenerator struct {
-	GenerateStub        func(spec gardener.DesiredContainerSpec) goci.Bndl
+	GenerateStub        func(spec gardener.DesiredContainerSpec) (goci.Bndl, error)
 	generateMutex       sync.RWMutex
 	generateArgsForCall []struct {
 		spec gardener.DesiredContainerSpec
 	}
 	generateReturns struct {
 		result1 goci.Bndl
+		result2 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeBundleGenerator) Generate(spec gardener.DesiredContainerSpec) goci.Bndl {
+func (fake *FakeBundleGenerator) Generate(spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
 	fake.generateMutex.Lock()
 	fake.generateArgsForCall = append(fake.generateArgsForCall, struct {
 		spec gardener.DesiredContainerSpec
@@ -32,7 +33,7 @@ func (fake *FakeBundleGenerator) Generate(spec gardener.DesiredContainerSpec) go
 	if fake.GenerateStub != nil {
 		return fake.GenerateStub(spec)
 	} else {
-		return fake.generateReturns.result1
+		return fake.generateReturns.result1, fake.generateReturns.result2
 	}
 }
 
@@ -48,11 +49,12 @@ func (fake *FakeBundleGenerator) GenerateArgsForCall(i int) gardener.DesiredCont
 	return fake.generateArgsForCall[i].spec
 }
 
-func (fake *FakeBundleGenerator) GenerateReturns(result1 goci.Bndl) {
+func (fake *FakeBundleGenerator) GenerateReturns(result1 goci.Bndl, result2 error) {
 	fake.GenerateStub = nil
 	fake.generateReturns = struct {
 		result1 goci.Bndl
-	}{result1}
+		result2 error
+	}{result1, result2}
 }
 
 func (fake *FakeBundleGenerator) Invocations() map[string][][]interface{} {
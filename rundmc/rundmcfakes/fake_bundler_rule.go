@@ -10,7 +10,7 @@ import (
 )
 
 type FakeBundlerRule struct {
-	ApplyStub        func(bndle goci.Bndl, spec gardener.DesiredContainerSpec) goci.Bndl
+	ApplyStub        func(bndle goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error)
 	applyMutex       sync.RWMutex
 	applyArgsForCall []struct {
 		bndle goci.Bndl
@@ -18,12 +18,13 @@ type FakeBundlerRule struct {
 	}
 	applyReturns struct {
 		result1 goci.Bndl
+		result2 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeBundlerRule) Apply(bndle goci.Bndl, spec gardener.DesiredContainerSpec) goci.Bndl {
+func (fake *FakeBundlerRule) Apply(bndle goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
 	fake.applyMutex.Lock()
 	fake.applyArgsForCall = append(fake.applyArgsForCall, struct {
 		bndle goci.Bndl
@@ -34,7 +35,7 @@ func (fake *FakeBundlerRule) Apply(bndle goci.Bndl, spec gardener.DesiredContain
 	if fake.ApplyStub != nil {
 		return fake.ApplyStub(bndle, spec)
 	} else {
-		return fake.applyReturns.result1
+		return fake.applyReturns.result1, fake.applyReturns.result2
 	}
 }
 
@@ -50,11 +51,12 @@ func (fake *FakeBundlerRule) ApplyArgsForCall(i int) (goci.Bndl, gardener.Desire
 	return fake.applyArgsForCall[i].bndle, fake.applyArgsForCall[i].spec
 }
 
-func (fake *FakeBundlerRule) ApplyReturns(result1 goci.Bndl) {
+func (fake *FakeBundlerRule) ApplyReturns(result1 goci.Bndl, result2 error) {
 	fake.ApplyStub = nil
 	fake.applyReturns = struct {
 		result1 goci.Bndl
-	}{result1}
+		result2 error
+	}{result1, result2}
 }
 
 func (fake *FakeBundlerRule) Invocations() map[string][][]interface{} {
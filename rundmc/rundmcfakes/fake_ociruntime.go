@@ -23,12 +23,12 @@ type FakeOCIRuntime struct {
 	createReturns struct {
 		result1 error
 	}
-	ExecStub        func(log lager.Logger, id, bundlePath string, spec garden.ProcessSpec, io garden.ProcessIO) (garden.Process, error)
+	ExecStub        func(log lager.Logger, bundlePath, id string, spec garden.ProcessSpec, io garden.ProcessIO) (garden.Process, error)
 	execMutex       sync.RWMutex
 	execArgsForCall []struct {
 		log        lager.Logger
-		id         string
 		bundlePath string
+		id         string
 		spec       garden.ProcessSpec
 		io         garden.ProcessIO
 	}
@@ -36,12 +36,12 @@ type FakeOCIRuntime struct {
 		result1 garden.Process
 		result2 error
 	}
-	AttachStub        func(log lager.Logger, id, bundlePath, processId string, io garden.ProcessIO) (garden.Process, error)
+	AttachStub        func(log lager.Logger, bundlePath, id, processId string, io garden.ProcessIO) (garden.Process, error)
 	attachMutex       sync.RWMutex
 	attachArgsForCall []struct {
 		log        lager.Logger
-		id         string
 		bundlePath string
+		id         string
 		processId  string
 		io         garden.ProcessIO
 	}
@@ -49,20 +49,20 @@ type FakeOCIRuntime struct {
 		result1 garden.Process
 		result2 error
 	}
-	KillStub        func(log lager.Logger, bundlePath string) error
+	KillStub        func(log lager.Logger, handle string) error
 	killMutex       sync.RWMutex
 	killArgsForCall []struct {
-		log        lager.Logger
-		bundlePath string
+		log    lager.Logger
+		handle string
 	}
 	killReturns struct {
 		result1 error
 	}
-	DeleteStub        func(log lager.Logger, bundlePath string) error
+	DeleteStub        func(log lager.Logger, handle string) error
 	deleteMutex       sync.RWMutex
 	deleteArgsForCall []struct {
-		log        lager.Logger
-		bundlePath string
+		log    lager.Logger
+		handle string
 	}
 	deleteReturns struct {
 		result1 error
@@ -97,6 +97,20 @@ type FakeOCIRuntime struct {
 	watchEventsReturns struct {
 		result1 error
 	}
+	StopWatchingEventsStub        func(log lager.Logger)
+	stopWatchingEventsMutex       sync.RWMutex
+	stopWatchingEventsArgsForCall []struct {
+		log lager.Logger
+	}
+	ListStub        func(log lager.Logger) ([]runrunc.ListEntry, error)
+	listMutex       sync.RWMutex
+	listArgsForCall []struct {
+		log lager.Logger
+	}
+	listReturns struct {
+		result1 []runrunc.ListEntry
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -137,19 +151,19 @@ func (fake *FakeOCIRuntime) CreateReturns(result1 error) {
 	}{result1}
 }
 
-func (fake *FakeOCIRuntime) Exec(log lager.Logger, id string, bundlePath string, spec garden.ProcessSpec, io garden.ProcessIO) (garden.Process, error) {
+func (fake *FakeOCIRuntime) Exec(log lager.Logger, bundlePath string, id string, spec garden.ProcessSpec, io garden.ProcessIO) (garden.Process, error) {
 	fake.execMutex.Lock()
 	fake.execArgsForCall = append(fake.execArgsForCall, struct {
 		log        lager.Logger
-		id         string
 		bundlePath string
+		id         string
 		spec       garden.ProcessSpec
 		io         garden.ProcessIO
-	}{log, id, bundlePath, spec, io})
-	fake.recordInvocation("Exec", []interface{}{log, id, bundlePath, spec, io})
+	}{log, bundlePath, id, spec, io})
+	fake.recordInvocation("Exec", []interface{}{log, bundlePath, id, spec, io})
 	fake.execMutex.Unlock()
 	if fake.ExecStub != nil {
-		return fake.ExecStub(log, id, bundlePath, spec, io)
+		return fake.ExecStub(log, bundlePath, id, spec, io)
 	} else {
 		return fake.execReturns.result1, fake.execReturns.result2
 	}
@@ -164,7 +178,7 @@ func (fake *FakeOCIRuntime) ExecCallCount() int {
 func (fake *FakeOCIRuntime) ExecArgsForCall(i int) (lager.Logger, string, string, garden.ProcessSpec, garden.ProcessIO) {
 	fake.execMutex.RLock()
 	defer fake.execMutex.RUnlock()
-	return fake.execArgsForCall[i].log, fake.execArgsForCall[i].id, fake.execArgsForCall[i].bundlePath, fake.execArgsForCall[i].spec, fake.execArgsForCall[i].io
+	return fake.execArgsForCall[i].log, fake.execArgsForCall[i].bundlePath, fake.execArgsForCall[i].id, fake.execArgsForCall[i].spec, fake.execArgsForCall[i].io
 }
 
 func (fake *FakeOCIRuntime) ExecReturns(result1 garden.Process, result2 error) {
@@ -175,19 +189,19 @@ func (fake *FakeOCIRuntime) ExecReturns(result1 garden.Process, result2 error) {
 	}{result1, result2}
 }
 
-func (fake *FakeOCIRuntime) Attach(log lager.Logger, id string, bundlePath string, processId string, io garden.ProcessIO) (garden.Process, error) {
+func (fake *FakeOCIRuntime) Attach(log lager.Logger, bundlePath string, id string, processId string, io garden.ProcessIO) (garden.Process, error) {
 	fake.attachMutex.Lock()
 	fake.attachArgsForCall = append(fake.attachArgsForCall, struct {
 		log        lager.Logger
-		id         string
 		bundlePath string
+		id         string
 		processId  string
 		io         garden.ProcessIO
-	}{log, id, bundlePath, processId, io})
-	fake.recordInvocation("Attach", []interface{}{log, id, bundlePath, processId, io})
+	}{log, bundlePath, id, processId, io})
+	fake.recordInvocation("Attach", []interface{}{log, bundlePath, id, processId, io})
 	fake.attachMutex.Unlock()
 	if fake.AttachStub != nil {
-		return fake.AttachStub(log, id, bundlePath, processId, io)
+		return fake.AttachStub(log, bundlePath, id, processId, io)
 	} else {
 		return fake.attachReturns.result1, fake.attachReturns.result2
 	}
@@ -202,7 +216,7 @@ func (fake *FakeOCIRuntime) AttachCallCount() int {
 func (fake *FakeOCIRuntime) AttachArgsForCall(i int) (lager.Logger, string, string, string, garden.ProcessIO) {
 	fake.attachMutex.RLock()
 	defer fake.attachMutex.RUnlock()
-	return fake.attachArgsForCall[i].log, fake.attachArgsForCall[i].id, fake.attachArgsForCall[i].bundlePath, fake.attachArgsForCall[i].processId, fake.attachArgsForCall[i].io
+	return fake.attachArgsForCall[i].log, fake.attachArgsForCall[i].bundlePath, fake.attachArgsForCall[i].id, fake.attachArgsForCall[i].processId, fake.attachArgsForCall[i].io
 }
 
 func (fake *FakeOCIRuntime) AttachReturns(result1 garden.Process, result2 error) {
@@ -213,16 +227,16 @@ func (fake *FakeOCIRuntime) AttachReturns(result1 garden.Process, result2 error)
 	}{result1, result2}
 }
 
-func (fake *FakeOCIRuntime) Kill(log lager.Logger, bundlePath string) error {
+func (fake *FakeOCIRuntime) Kill(log lager.Logger, handle string) error {
 	fake.killMutex.Lock()
 	fake.killArgsForCall = append(fake.killArgsForCall, struct {
-		log        lager.Logger
-		bundlePath string
-	}{log, bundlePath})
-	fake.recordInvocation("Kill", []interface{}{log, bundlePath})
+		log    lager.Logger
+		handle string
+	}{log, handle})
+	fake.recordInvocation("Kill", []interface{}{log, handle})
 	fake.killMutex.Unlock()
 	if fake.KillStub != nil {
-		return fake.KillStub(log, bundlePath)
+		return fake.KillStub(log, handle)
 	} else {
 		return fake.killReturns.result1
 	}
@@ -237,7 +251,7 @@ func (fake *FakeOCIRuntime) KillCallCount() int {
 func (fake *FakeOCIRuntime) KillArgsForCall(i int) (lager.Logger, string) {
 	fake.killMutex.RLock()
 	defer fake.killMutex.RUnlock()
-	return fake.killArgsForCall[i].log, fake.killArgsForCall[i].bundlePath
+	return fake.killArgsForCall[i].log, fake.killArgsForCall[i].handle
 }
 
 func (fake *FakeOCIRuntime) KillReturns(result1 error) {
@@ -247,16 +261,16 @@ func (fake *FakeOCIRuntime) KillReturns(result1 error) {
 	}{result1}
 }
 
-func (fake *FakeOCIRuntime) Delete(log lager.Logger, bundlePath string) error {
+func (fake *FakeOCIRuntime) Delete(log lager.Logger, handle string) error {
 	fake.deleteMutex.Lock()
 	fake.deleteArgsForCall = append(fake.deleteArgsForCall, struct {
-		log        lager.Logger
-		bundlePath string
-	}{log, bundlePath})
-	fake.recordInvocation("Delete", []interface{}{log, bundlePath})
+		log    lager.Logger
+		handle string
+	}{log, handle})
+	fake.recordInvocation("Delete", []interface{}{log, handle})
 	fake.deleteMutex.Unlock()
 	if fake.DeleteStub != nil {
-		return fake.DeleteStub(log, bundlePath)
+		return fake.DeleteStub(log, handle)
 	} else {
 		return fake.deleteReturns.result1
 	}
@@ -271,7 +285,7 @@ func (fake *FakeOCIRuntime) DeleteCallCount() int {
 func (fake *FakeOCIRuntime) DeleteArgsForCall(i int) (lager.Logger, string) {
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
-	return fake.deleteArgsForCall[i].log, fake.deleteArgsForCall[i].bundlePath
+	return fake.deleteArgsForCall[i].log, fake.deleteArgsForCall[i].handle
 }
 
 func (fake *FakeOCIRuntime) DeleteReturns(result1 error) {
@@ -386,6 +400,64 @@ func (fake *FakeOCIRuntime) WatchEventsReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeOCIRuntime) StopWatchingEvents(log lager.Logger) {
+	fake.stopWatchingEventsMutex.Lock()
+	fake.stopWatchingEventsArgsForCall = append(fake.stopWatchingEventsArgsForCall, struct {
+		log lager.Logger
+	}{log})
+	fake.recordInvocation("StopWatchingEvents", []interface{}{log})
+	fake.stopWatchingEventsMutex.Unlock()
+	if fake.StopWatchingEventsStub != nil {
+		fake.StopWatchingEventsStub(log)
+	}
+}
+
+func (fake *FakeOCIRuntime) StopWatchingEventsCallCount() int {
+	fake.stopWatchingEventsMutex.RLock()
+	defer fake.stopWatchingEventsMutex.RUnlock()
+	return len(fake.stopWatchingEventsArgsForCall)
+}
+
+func (fake *FakeOCIRuntime) StopWatchingEventsArgsForCall(i int) lager.Logger {
+	fake.stopWatchingEventsMutex.RLock()
+	defer fake.stopWatchingEventsMutex.RUnlock()
+	return fake.stopWatchingEventsArgsForCall[i].log
+}
+
+func (fake *FakeOCIRuntime) List(log lager.Logger) ([]runrunc.ListEntry, error) {
+	fake.listMutex.Lock()
+	fake.listArgsForCall = append(fake.listArgsForCall, struct {
+		log lager.Logger
+	}{log})
+	fake.recordInvocation("List", []interface{}{log})
+	fake.listMutex.Unlock()
+	if fake.ListStub != nil {
+		return fake.ListStub(log)
+	} else {
+		return fake.listReturns.result1, fake.listReturns.result2
+	}
+}
+
+func (fake *FakeOCIRuntime) ListCallCount() int {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	return len(fake.listArgsForCall)
+}
+
+func (fake *FakeOCIRuntime) ListArgsForCall(i int) lager.Logger {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	return fake.listArgsForCall[i].log
+}
+
+func (fake *FakeOCIRuntime) ListReturns(result1 []runrunc.ListEntry, result2 error) {
+	fake.ListStub = nil
+	fake.listReturns = struct {
+		result1 []runrunc.ListEntry
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeOCIRuntime) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -405,6 +477,10 @@ func (fake *FakeOCIRuntime) Invocations() map[string][][]interface{} {
 	defer fake.statsMutex.RUnlock()
 	fake.watchEventsMutex.RLock()
 	defer fake.watchEventsMutex.RUnlock()
+	fake.stopWatchingEventsMutex.RLock()
+	defer fake.stopWatchingEventsMutex.RUnlock()
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
 	return fake.invocations
 }
 
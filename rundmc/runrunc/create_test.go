@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 
 	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
 	"code.cloudfoundry.org/guardian/rundmc/runrunc"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
@@ -43,7 +44,7 @@ var _ = Describe("Create", func() {
 		logFilePath = filepath.Join(bundlePath, "create.log")
 		pidFilePath = filepath.Join(bundlePath, "pidfile")
 
-		runner = runrunc.NewCreator("funC", commandRunner)
+		runner = runrunc.NewCreator(goci.RuncBinary("funC"), commandRunner)
 	})
 
 	JustBeforeEach(func() {
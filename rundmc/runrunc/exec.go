@@ -178,6 +178,7 @@ func (r *execPreparer) Prepare(log lager.Logger, bundlePath string, spec garden.
 			Rlimits:         toRlimits(spec.Limits),
 			Terminal:        spec.TTY != nil,
 			ApparmorProfile: bndl.Process().ApparmorProfile,
+			NoNewPrivileges: bndl.Process().NoNewPrivileges,
 		},
 	}, nil
 }
@@ -256,6 +257,7 @@ func intersect(l1 []string, l2 []string) (result []string) {
 		for _, b := range l2 {
 			if a == b {
 				result = append(result, a)
+				break
 			}
 		}
 	}
@@ -516,6 +516,21 @@ var _ = Describe("ExecPreparer", func() {
 			Expect(spec.Process.ApparmorProfile).To(Equal("default-profile"))
 		})
 	})
+
+	Context("when NoNewPrivileges is set in the base process", func() {
+		BeforeEach(func() {
+			bundleLoader.LoadReturns(goci.Bndl{}.WithProcess(specs.Process{
+				NoNewPrivileges: true,
+			}), nil)
+		})
+
+		It("should pass it to the process spec", func() {
+			spec, err := preparer.Prepare(logger, bundlePath, garden.ProcessSpec{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(spec.Process.NoNewPrivileges).To(BeTrue())
+		})
+	})
 })
 
 var _ = Describe("WaitWatcher", func() {
@@ -0,0 +1,60 @@
+package runrunc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+
+	"code.cloudfoundry.org/lager"
+)
+
+type Lister struct {
+	runner RuncCmdRunner
+	runc   RuncBinary
+}
+
+func NewLister(runner RuncCmdRunner, runc RuncBinary) *Lister {
+	return &Lister{
+		runner: runner,
+		runc:   runc,
+	}
+}
+
+// ListEntry is a container the runtime knows about. Bundle is the path of the
+// bundle it was created from, which tells whether guardian created it.
+type ListEntry struct {
+	ID     string `json:"id"`
+	Bundle string `json:"bundle"`
+}
+
+// List returns the containers the runtime knows about
+func (l *Lister) List(log lager.Logger) ([]ListEntry, error) {
+	log = log.Session("list")
+
+	log.Info("started")
+	defer log.Info("finished")
+
+	buf := new(bytes.Buffer)
+	err := l.runner.RunAndLog(log, func(logFile string) *exec.Cmd {
+		cmd := l.runc.ListCommand(logFile)
+		cmd.Stdout = buf
+		return cmd
+	})
+	if err != nil {
+		return nil, fmt.Errorf("runc list: %s", err)
+	}
+
+	// runc prints "null" rather than an empty list when there are no containers
+	entries := []ListEntry{}
+	if err := json.NewDecoder(buf).Decode(&entries); err != nil {
+		log.Error("decode-list-failed", err)
+		return nil, fmt.Errorf("runc list: %s", err)
+	}
+
+	if entries == nil {
+		return []ListEntry{}, nil
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,108 @@
+package runrunc_test
+
+import (
+	"errors"
+	"os/exec"
+
+	"code.cloudfoundry.org/guardian/rundmc/runrunc"
+	fakes "code.cloudfoundry.org/guardian/rundmc/runrunc/runruncfakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
+	. "github.com/cloudfoundry/gunk/command_runner/fake_command_runner/matchers"
+)
+
+var _ = Describe("List", func() {
+	var (
+		commandRunner *fake_command_runner.FakeCommandRunner
+		runner        *fakes.FakeRuncCmdRunner
+		runcBinary    *fakes.FakeRuncBinary
+		logger        *lagertest.TestLogger
+
+		listCmdOutput string
+		listCmdExit   error
+
+		lister *runrunc.Lister
+	)
+
+	BeforeEach(func() {
+		runner = new(fakes.FakeRuncCmdRunner)
+		runcBinary = new(fakes.FakeRuncBinary)
+		commandRunner = fake_command_runner.New()
+		logger = lagertest.NewTestLogger("test")
+
+		lister = runrunc.NewLister(runner, runcBinary)
+
+		runcBinary.ListCommandStub = func(logFile string) *exec.Cmd {
+			return exec.Command("funC-list", "--log", logFile, "list", "--format", "json")
+		}
+
+		listCmdExit = nil
+		listCmdOutput = `[{"id":"banana","pid":4,"bundle":"/depot/banana"},{"id":"apple","pid":5,"bundle":"/elsewhere/apple"}]`
+	})
+
+	JustBeforeEach(func() {
+		runner.RunAndLogStub = func(_ lager.Logger, fn runrunc.LoggingCmd) error {
+			return commandRunner.Run(fn("potato.log"))
+		}
+
+		commandRunner.WhenRunning(fake_command_runner.CommandSpec{
+			Path: "funC-list",
+		}, func(cmd *exec.Cmd) error {
+			cmd.Stdout.Write([]byte(listCmdOutput))
+			return listCmdExit
+		})
+	})
+
+	It("runs 'runc list' using the logging runner", func() {
+		_, err := lister.List(logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(commandRunner).To(HaveExecutedSerially(fake_command_runner.CommandSpec{
+			Path: "funC-list",
+			Args: []string{"--log", "potato.log", "list", "--format", "json"},
+		}))
+	})
+
+	It("returns the ids and bundles of the containers", func() {
+		Expect(lister.List(logger)).To(Equal([]runrunc.ListEntry{
+			{ID: "banana", Bundle: "/depot/banana"},
+			{ID: "apple", Bundle: "/elsewhere/apple"},
+		}))
+	})
+
+	Context("when there are no containers", func() {
+		BeforeEach(func() {
+			listCmdOutput = "null"
+		})
+
+		It("returns an empty list", func() {
+			Expect(lister.List(logger)).To(BeEmpty())
+		})
+	})
+
+	Context("when the output is not valid JSON", func() {
+		BeforeEach(func() {
+			listCmdOutput = "potato"
+		})
+
+		It("returns an error", func() {
+			_, err := lister.List(logger)
+			Expect(err).To(MatchError(ContainSubstring("runc list")))
+		})
+	})
+
+	Context("when running runc list fails", func() {
+		BeforeEach(func() {
+			listCmdExit = errors.New("boom")
+		})
+
+		It("returns the error", func() {
+			_, err := lister.List(logger)
+			Expect(err).To(MatchError("runc list: boom"))
+		})
+	})
+})
@@ -3,6 +3,8 @@ package runrunc
 import (
 	"os/exec"
 
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+
 	"github.com/cloudfoundry/gunk/command_runner"
 )
 
@@ -21,21 +23,25 @@ type RunRunc struct {
 	*Stater
 	*Killer
 	*Deleter
+	*Lister
 }
 
 //go:generate counterfeiter . RuncBinary
 type RuncBinary interface {
+	CreateCommand(bundlePath, id, pidFilePath, logFile string) *exec.Cmd
 	ExecCommand(id, processJSONPath, pidFilePath string) *exec.Cmd
 	EventsCommand(id string) *exec.Cmd
 	StateCommand(id, logFile string) *exec.Cmd
 	StatsCommand(id, logFile string) *exec.Cmd
 	KillCommand(id, signal, logFile string) *exec.Cmd
 	DeleteCommand(id, logFile string) *exec.Cmd
+	ListCommand(logFile string) *exec.Cmd
+	SupportedFeatures() goci.RuntimeFeatures
 }
 
 func New(runner command_runner.CommandRunner, runcCmdRunner RuncCmdRunner, runc RuncBinary, dadooPath, runcPath string, execPreparer ExecPreparer, execRunner ExecRunner) *RunRunc {
 	return &RunRunc{
-		Creator: NewCreator(runcPath, runner),
+		Creator: NewCreator(runc, runner),
 		Execer:  NewExecer(execPreparer, execRunner),
 
 		OomWatcher: NewOomWatcher(runner, runc),
@@ -43,5 +49,6 @@ func New(runner command_runner.CommandRunner, runcCmdRunner RuncCmdRunner, runc
 		Stater:     NewStater(runcCmdRunner, runc),
 		Killer:     NewKiller(runcCmdRunner, runc),
 		Deleter:    NewDeleter(runcCmdRunner, runc),
+		Lister:     NewLister(runcCmdRunner, runc),
 	}
 }
@@ -5,10 +5,22 @@ import (
 	"os/exec"
 	"sync"
 
+	"code.cloudfoundry.org/guardian/rundmc/goci"
 	"code.cloudfoundry.org/guardian/rundmc/runrunc"
 )
 
 type FakeRuncBinary struct {
+	CreateCommandStub        func(bundlePath, id, pidFilePath, logFile string) *exec.Cmd
+	createCommandMutex       sync.RWMutex
+	createCommandArgsForCall []struct {
+		bundlePath  string
+		id          string
+		pidFilePath string
+		logFile     string
+	}
+	createCommandReturns struct {
+		result1 *exec.Cmd
+	}
 	ExecCommandStub        func(id, processJSONPath, pidFilePath string) *exec.Cmd
 	execCommandMutex       sync.RWMutex
 	execCommandArgsForCall []struct {
@@ -64,10 +76,60 @@ type FakeRuncBinary struct {
 	deleteCommandReturns struct {
 		result1 *exec.Cmd
 	}
+	ListCommandStub        func(logFile string) *exec.Cmd
+	listCommandMutex       sync.RWMutex
+	listCommandArgsForCall []struct {
+		logFile string
+	}
+	listCommandReturns struct {
+		result1 *exec.Cmd
+	}
+	SupportedFeaturesStub        func() goci.RuntimeFeatures
+	supportedFeaturesMutex       sync.RWMutex
+	supportedFeaturesArgsForCall []struct{}
+	supportedFeaturesReturns     struct {
+		result1 goci.RuntimeFeatures
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeRuncBinary) CreateCommand(bundlePath string, id string, pidFilePath string, logFile string) *exec.Cmd {
+	fake.createCommandMutex.Lock()
+	fake.createCommandArgsForCall = append(fake.createCommandArgsForCall, struct {
+		bundlePath  string
+		id          string
+		pidFilePath string
+		logFile     string
+	}{bundlePath, id, pidFilePath, logFile})
+	fake.recordInvocation("CreateCommand", []interface{}{bundlePath, id, pidFilePath, logFile})
+	fake.createCommandMutex.Unlock()
+	if fake.CreateCommandStub != nil {
+		return fake.CreateCommandStub(bundlePath, id, pidFilePath, logFile)
+	} else {
+		return fake.createCommandReturns.result1
+	}
+}
+
+func (fake *FakeRuncBinary) CreateCommandCallCount() int {
+	fake.createCommandMutex.RLock()
+	defer fake.createCommandMutex.RUnlock()
+	return len(fake.createCommandArgsForCall)
+}
+
+func (fake *FakeRuncBinary) CreateCommandArgsForCall(i int) (string, string, string, string) {
+	fake.createCommandMutex.RLock()
+	defer fake.createCommandMutex.RUnlock()
+	return fake.createCommandArgsForCall[i].bundlePath, fake.createCommandArgsForCall[i].id, fake.createCommandArgsForCall[i].pidFilePath, fake.createCommandArgsForCall[i].logFile
+}
+
+func (fake *FakeRuncBinary) CreateCommandReturns(result1 *exec.Cmd) {
+	fake.CreateCommandStub = nil
+	fake.createCommandReturns = struct {
+		result1 *exec.Cmd
+	}{result1}
+}
+
 func (fake *FakeRuncBinary) ExecCommand(id string, processJSONPath string, pidFilePath string) *exec.Cmd {
 	fake.execCommandMutex.Lock()
 	fake.execCommandArgsForCall = append(fake.execCommandArgsForCall, struct {
@@ -273,9 +335,69 @@ func (fake *FakeRuncBinary) DeleteCommandReturns(result1 *exec.Cmd) {
 	}{result1}
 }
 
+func (fake *FakeRuncBinary) ListCommand(logFile string) *exec.Cmd {
+	fake.listCommandMutex.Lock()
+	fake.listCommandArgsForCall = append(fake.listCommandArgsForCall, struct {
+		logFile string
+	}{logFile})
+	fake.recordInvocation("ListCommand", []interface{}{logFile})
+	fake.listCommandMutex.Unlock()
+	if fake.ListCommandStub != nil {
+		return fake.ListCommandStub(logFile)
+	} else {
+		return fake.listCommandReturns.result1
+	}
+}
+
+func (fake *FakeRuncBinary) ListCommandCallCount() int {
+	fake.listCommandMutex.RLock()
+	defer fake.listCommandMutex.RUnlock()
+	return len(fake.listCommandArgsForCall)
+}
+
+func (fake *FakeRuncBinary) ListCommandArgsForCall(i int) string {
+	fake.listCommandMutex.RLock()
+	defer fake.listCommandMutex.RUnlock()
+	return fake.listCommandArgsForCall[i].logFile
+}
+
+func (fake *FakeRuncBinary) ListCommandReturns(result1 *exec.Cmd) {
+	fake.ListCommandStub = nil
+	fake.listCommandReturns = struct {
+		result1 *exec.Cmd
+	}{result1}
+}
+
+func (fake *FakeRuncBinary) SupportedFeatures() goci.RuntimeFeatures {
+	fake.supportedFeaturesMutex.Lock()
+	fake.supportedFeaturesArgsForCall = append(fake.supportedFeaturesArgsForCall, struct{}{})
+	fake.recordInvocation("SupportedFeatures", []interface{}{})
+	fake.supportedFeaturesMutex.Unlock()
+	if fake.SupportedFeaturesStub != nil {
+		return fake.SupportedFeaturesStub()
+	} else {
+		return fake.supportedFeaturesReturns.result1
+	}
+}
+
+func (fake *FakeRuncBinary) SupportedFeaturesCallCount() int {
+	fake.supportedFeaturesMutex.RLock()
+	defer fake.supportedFeaturesMutex.RUnlock()
+	return len(fake.supportedFeaturesArgsForCall)
+}
+
+func (fake *FakeRuncBinary) SupportedFeaturesReturns(result1 goci.RuntimeFeatures) {
+	fake.SupportedFeaturesStub = nil
+	fake.supportedFeaturesReturns = struct {
+		result1 goci.RuntimeFeatures
+	}{result1}
+}
+
 func (fake *FakeRuncBinary) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.createCommandMutex.RLock()
+	defer fake.createCommandMutex.RUnlock()
 	fake.execCommandMutex.RLock()
 	defer fake.execCommandMutex.RUnlock()
 	fake.eventsCommandMutex.RLock()
@@ -288,6 +410,10 @@ func (fake *FakeRuncBinary) Invocations() map[string][][]interface{} {
 	defer fake.killCommandMutex.RUnlock()
 	fake.deleteCommandMutex.RLock()
 	defer fake.deleteCommandMutex.RUnlock()
+	fake.listCommandMutex.RLock()
+	defer fake.listCommandMutex.RUnlock()
+	fake.supportedFeaturesMutex.RLock()
+	defer fake.supportedFeaturesMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -3,6 +3,7 @@ package runrunc
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
 
@@ -16,6 +17,8 @@ type StatsNotifier interface {
 	OnStat(handle string, cpuStat garden.ContainerCPUStat, memoryStat garden.ContainerMemoryStat)
 }
 
+var ErrStatsNotSupported = errors.New("runtime does not support stats")
+
 type runcStats struct {
 	Data struct {
 		CPUStats struct {
@@ -43,6 +46,10 @@ func NewStatser(runner RuncCmdRunner, runc RuncBinary) *Statser {
 }
 
 func (r *Statser) Stats(log lager.Logger, id string) (gardener.ActualContainerMetrics, error) {
+	if !r.runc.SupportedFeatures().Stats {
+		return gardener.ActualContainerMetrics{}, ErrStatsNotSupported
+	}
+
 	buf := new(bytes.Buffer)
 
 	if err := r.runner.RunAndLog(log, func(logFile string) *exec.Cmd {
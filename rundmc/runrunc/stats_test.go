@@ -10,6 +10,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
 	"code.cloudfoundry.org/guardian/rundmc/runrunc"
 	fakes "code.cloudfoundry.org/guardian/rundmc/runrunc/runruncfakes"
 	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
@@ -34,6 +35,8 @@ var _ = Describe("Stats", func() {
 
 		statser = runrunc.NewStatser(runner, runcBinary)
 
+		runcBinary.SupportedFeaturesReturns(goci.AllRuntimeFeatures)
+
 		runcBinary.StatsCommandStub = func(id string, logFile string) *exec.Cmd {
 			return exec.Command("funC-stats", "--log", logFile, id)
 		}
@@ -42,6 +45,18 @@ var _ = Describe("Stats", func() {
 		}
 	})
 
+	Context("when the runtime does not support stats", func() {
+		BeforeEach(func() {
+			runcBinary.SupportedFeaturesReturns(goci.RuntimeFeatures{})
+		})
+
+		It("returns an error without running the stats command", func() {
+			_, err := statser.Stats(logger, "some-container")
+			Expect(err).To(Equal(runrunc.ErrStatsNotSupported))
+			Expect(runner.RunAndLogCallCount()).To(Equal(0))
+		})
+	})
+
 	Context("when runC reports valid JSON", func() {
 		BeforeEach(func() {
 			commandRunner.WhenRunning(fake_command_runner.CommandSpec{
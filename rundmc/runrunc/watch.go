@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"os/exec"
+	"sync"
 
 	"code.cloudfoundry.org/lager"
 	"github.com/cloudfoundry/gunk/command_runner"
@@ -17,10 +19,18 @@ type EventsNotifier interface {
 type OomWatcher struct {
 	commandRunner command_runner.CommandRunner
 	runc          RuncBinary
+
+	mu       sync.Mutex
+	stopped  bool
+	watching map[string]*exec.Cmd
 }
 
 func NewOomWatcher(runner command_runner.CommandRunner, runc RuncBinary) *OomWatcher {
-	return &OomWatcher{runner, runc}
+	return &OomWatcher{
+		commandRunner: runner,
+		runc:          runc,
+		watching:      make(map[string]*exec.Cmd),
+	}
 }
 
 type runcEvent struct {
@@ -29,14 +39,20 @@ type runcEvent struct {
 }
 
 func (r *OomWatcher) WatchEvents(log lager.Logger, handle string, eventsNotifier EventsNotifier) error {
+	log = log.Session("watch", lager.Data{
+		"handle": handle,
+	})
+
+	if !r.runc.SupportedFeatures().Events {
+		log.Info("events-not-supported-by-runtime")
+		return nil
+	}
+
 	stdoutR, w := io.Pipe()
 
 	cmd := r.runc.EventsCommand(handle)
 	cmd.Stdout = w
 
-	log = log.Session("watch", lager.Data{
-		"handle": handle,
-	})
 	log.Info("watching")
 
 	defer func() {
@@ -49,6 +65,12 @@ func (r *OomWatcher) WatchEvents(log lager.Logger, handle string, eventsNotifier
 		return fmt.Errorf("start: %s", err)
 	}
 
+	if !r.track(handle, cmd) {
+		log.Info("watching-stopped")
+		r.commandRunner.Kill(cmd)
+	}
+	defer r.untrack(handle, cmd)
+
 	go func() {
 		defer w.Close()
 		r.commandRunner.Wait(cmd) // avoid zombie
@@ -78,3 +100,38 @@ func (r *OomWatcher) WatchEvents(log lager.Logger, handle string, eventsNotifier
 		}
 	}
 }
+
+// StopWatchingEvents kills any running events commands, causing their
+// WatchEvents calls to return, and stops new ones from being watched
+func (r *OomWatcher) StopWatchingEvents(log lager.Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stopped = true
+	for handle, cmd := range r.watching {
+		if err := r.commandRunner.Kill(cmd); err != nil {
+			log.Error("stop-watching-events", err, lager.Data{"handle": handle})
+		}
+	}
+}
+
+func (r *OomWatcher) track(handle string, cmd *exec.Cmd) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		return false
+	}
+
+	r.watching[handle] = cmd
+	return true
+}
+
+func (r *OomWatcher) untrack(handle string, cmd *exec.Cmd) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.watching[handle] == cmd {
+		delete(r.watching, handle)
+	}
+}
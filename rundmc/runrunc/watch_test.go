@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"code.cloudfoundry.org/guardian/rundmc/goci"
 	"code.cloudfoundry.org/guardian/rundmc/runrunc"
 	fakes "code.cloudfoundry.org/guardian/rundmc/runrunc/runruncfakes"
 	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
@@ -30,11 +31,24 @@ var _ = Describe("Watching for Events", func() {
 
 		runner = runrunc.NewOomWatcher(commandRunner, runcBinary)
 
+		runcBinary.SupportedFeaturesReturns(goci.AllRuntimeFeatures)
+
 		runcBinary.EventsCommandStub = func(handle string) *exec.Cmd {
 			return exec.Command("funC-events", "events", handle)
 		}
 	})
 
+	Context("when the runtime does not support events", func() {
+		BeforeEach(func() {
+			runcBinary.SupportedFeaturesReturns(goci.RuntimeFeatures{})
+		})
+
+		It("returns without running the events command", func() {
+			Expect(runner.WatchEvents(logger, "some-container", nil)).To(Succeed())
+			Expect(commandRunner.StartedCommands()).To(BeEmpty())
+		})
+	})
+
 	It("blows up if `runc events` returns an error", func() {
 		commandRunner.WhenRunning(fake_command_runner.CommandSpec{
 			Path: "funC-events",
@@ -110,6 +124,27 @@ var _ = Describe("Watching for Events", func() {
 			Consistently(eventsNotifier.OnEventCallCount).Should(Equal(0))
 		})
 
+		Context("when watching is stopped", func() {
+			It("kills the running events commands", func() {
+				defer close(eventsCh)
+
+				go runner.WatchEvents(logger, "some-container", eventsNotifier)
+				Eventually(commandRunner.StartedCommands).Should(HaveLen(1))
+
+				runner.StopWatchingEvents(logger)
+				Eventually(commandRunner.KilledCommands).Should(HaveLen(1))
+				Expect(commandRunner.KilledCommands()[0].Path).To(Equal("funC-events"))
+			})
+
+			It("kills events commands started afterwards", func() {
+				close(eventsCh)
+
+				runner.StopWatchingEvents(logger)
+				Expect(runner.WatchEvents(logger, "some-container", eventsNotifier)).To(Succeed())
+				Expect(commandRunner.KilledCommands()).To(HaveLen(1))
+			})
+		})
+
 		It("waits on the process to avoid zombies", func() {
 			close(eventsCh)
 
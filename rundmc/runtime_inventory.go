@@ -0,0 +1,125 @@
+package rundmc
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// RuntimeInventory compares the containers in the depot with those the OCI
+// runtimes know about. Containers in the depot which their runtime has lost
+// are reported missing; runtime containers whose bundle is in the depot
+// directory but which are no longer in the depot are orphans. Containers with
+// a bundle elsewhere were not created by guardian (the runtime state
+// directory may be shared) and are left alone.
+type RuntimeInventory struct {
+	mux      *RuntimeMux
+	depotDir string
+
+	mu sync.Mutex
+	// orphans maps each orphan found by the last Check to its runtime
+	orphans map[string]string
+}
+
+func NewRuntimeInventory(mux *RuntimeMux, depotDir string) *RuntimeInventory {
+	return &RuntimeInventory{
+		mux:      mux,
+		depotDir: depotDir,
+		orphans:  make(map[string]string),
+	}
+}
+
+func (i *RuntimeInventory) Check(log lager.Logger, handles []string) (missing, orphans []string, err error) {
+	inDepot := make(map[string]bool)
+	for _, handle := range handles {
+		inDepot[handle] = true
+	}
+
+	// listed holds the ids of each runtime which could list its containers
+	listed := make(map[string]map[string]bool)
+	found := make(map[string]string)
+	var lastErr error
+	for name, runtime := range i.mux.Runtimes {
+		entries, err := runtime.List(log)
+		if err != nil {
+			log.Error("list-runtime-failed", err, lager.Data{"runtime": name})
+			lastErr = err
+			continue
+		}
+
+		ids := make(map[string]bool)
+		for _, entry := range entries {
+			ids[entry.ID] = true
+			if !inDepot[entry.ID] && i.created(entry.Bundle) {
+				found[entry.ID] = name
+				orphans = append(orphans, entry.ID)
+			}
+		}
+		listed[name] = ids
+	}
+
+	if len(listed) == 0 && lastErr != nil {
+		return nil, nil, lastErr
+	}
+
+	for _, handle := range handles {
+		name, err := i.mux.lookupName(log, handle)
+		if err != nil {
+			continue
+		}
+
+		// containers of a runtime which failed to list are not known to be lost
+		if ids, ok := listed[name]; ok && !ids[handle] {
+			missing = append(missing, handle)
+		}
+	}
+
+	i.mu.Lock()
+	i.orphans = found
+	i.mu.Unlock()
+
+	return missing, orphans, nil
+}
+
+// RemoveOrphan kills and deletes an orphan found by the last Check, using the
+// runtime which listed it. The kill may fail if the container has already
+// stopped, so only the delete's error is returned.
+func (i *RuntimeInventory) RemoveOrphan(log lager.Logger, id string) error {
+	i.mu.Lock()
+	name, ok := i.orphans[id]
+	i.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("not an orphan: %s", id)
+	}
+
+	runtime, err := i.mux.named(name)
+	if err != nil {
+		return err
+	}
+
+	if err := runtime.Kill(log, id); err != nil {
+		log.Info("kill-orphan-failed", lager.Data{"id": id, "error": err.Error()})
+	}
+
+	if err := runtime.Delete(log, id); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	delete(i.orphans, id)
+	i.mu.Unlock()
+
+	return nil
+}
+
+// created returns true if a bundle path is a directory of the depot
+func (i *RuntimeInventory) created(bundlePath string) bool {
+	if bundlePath == "" {
+		return false
+	}
+
+	return filepath.Dir(filepath.Clean(bundlePath)) == filepath.Clean(i.depotDir)
+}
@@ -0,0 +1,147 @@
+package rundmc_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	fakes "code.cloudfoundry.org/guardian/rundmc/rundmcfakes"
+	"code.cloudfoundry.org/guardian/rundmc/runrunc"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RuntimeInventory", func() {
+	var (
+		runc         *fakes.FakeOCIRuntime
+		runsc        *fakes.FakeOCIRuntime
+		bundleLoader *fakes.FakeBundleLoader
+		depot        *fakes.FakeDepot
+		logger       *lagertest.TestLogger
+		inventory    *rundmc.RuntimeInventory
+	)
+
+	BeforeEach(func() {
+		runc = new(fakes.FakeOCIRuntime)
+		runsc = new(fakes.FakeOCIRuntime)
+		bundleLoader = new(fakes.FakeBundleLoader)
+		depot = new(fakes.FakeDepot)
+		logger = lagertest.NewTestLogger("test")
+
+		inventory = rundmc.NewRuntimeInventory(&rundmc.RuntimeMux{
+			Runtimes: map[string]rundmc.OCIRuntime{"runc": runc, "runsc": runsc},
+			Default:  "runc",
+			Depot:    depot,
+			Loader:   bundleLoader,
+		}, "/depot")
+
+		depot.LookupStub = func(_ lager.Logger, handle string) (string, error) {
+			return "/depot/" + handle, nil
+		}
+		bundleLoader.LoadStub = func(bundlePath string) (goci.Bndl, error) {
+			if bundlePath == "/depot/sandboxed" {
+				return goci.Bundle().WithAnnotation(gardener.RuntimeKey, "runsc"), nil
+			}
+			return goci.Bundle(), nil
+		}
+
+		runc.ListReturns([]runrunc.ListEntry{
+			{ID: "running", Bundle: "/depot/running"},
+			{ID: "orphan", Bundle: "/depot/orphan"},
+			{ID: "someone-elses", Bundle: "/var/lib/other/someone-elses"},
+		}, nil)
+		runsc.ListReturns([]runrunc.ListEntry{
+			{ID: "sandboxed", Bundle: "/depot/sandboxed"},
+			{ID: "sandboxed-orphan", Bundle: "/depot/sandboxed-orphan/"},
+		}, nil)
+	})
+
+	Describe("Check", func() {
+		It("reports containers their runtime has lost as missing", func() {
+			missing, _, err := inventory.Check(logger, []string{"running", "sandboxed", "lost"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(missing).To(Equal([]string{"lost"}))
+		})
+
+		It("reports runtime containers with a bundle in the depot directory but no depot entry as orphans", func() {
+			_, orphans, err := inventory.Check(logger, []string{"running", "sandboxed"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orphans).To(ConsistOf("orphan", "sandboxed-orphan"))
+		})
+
+		Context("when a runtime fails to list its containers", func() {
+			BeforeEach(func() {
+				runsc.ListReturns(nil, errors.New("boom"))
+			})
+
+			It("does not report its containers as missing", func() {
+				missing, _, err := inventory.Check(logger, []string{"running", "sandboxed"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(missing).To(BeEmpty())
+			})
+
+			It("still reports the orphans of the other runtimes", func() {
+				_, orphans, err := inventory.Check(logger, []string{"running", "sandboxed"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(orphans).To(ConsistOf("orphan"))
+			})
+		})
+
+		Context("when every runtime fails to list its containers", func() {
+			It("returns the error", func() {
+				runc.ListReturns(nil, errors.New("boom"))
+				runsc.ListReturns(nil, errors.New("boom"))
+
+				_, _, err := inventory.Check(logger, []string{"running"})
+				Expect(err).To(MatchError("boom"))
+			})
+		})
+	})
+
+	Describe("RemoveOrphan", func() {
+		BeforeEach(func() {
+			_, _, err := inventory.Check(logger, []string{"running", "sandboxed"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("kills and deletes the container with the runtime which listed it", func() {
+			Expect(inventory.RemoveOrphan(logger, "sandboxed-orphan")).To(Succeed())
+
+			Expect(runsc.KillCallCount()).To(Equal(1))
+			_, id := runsc.KillArgsForCall(0)
+			Expect(id).To(Equal("sandboxed-orphan"))
+
+			Expect(runsc.DeleteCallCount()).To(Equal(1))
+			_, id = runsc.DeleteArgsForCall(0)
+			Expect(id).To(Equal("sandboxed-orphan"))
+
+			Expect(runc.KillCallCount()).To(Equal(0))
+			Expect(runc.DeleteCallCount()).To(Equal(0))
+		})
+
+		It("refuses to remove a container which was not created by guardian", func() {
+			Expect(inventory.RemoveOrphan(logger, "someone-elses")).To(MatchError("not an orphan: someone-elses"))
+			Expect(runc.DeleteCallCount()).To(Equal(0))
+		})
+
+		Context("when the kill fails", func() {
+			It("still deletes the container", func() {
+				runc.KillReturns(errors.New("already stopped"))
+
+				Expect(inventory.RemoveOrphan(logger, "orphan")).To(Succeed())
+				Expect(runc.DeleteCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the delete fails", func() {
+			It("returns the error", func() {
+				runc.DeleteReturns(errors.New("boom"))
+
+				Expect(inventory.RemoveOrphan(logger, "orphan")).To(MatchError("boom"))
+			})
+		})
+	})
+})
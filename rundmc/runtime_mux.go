@@ -0,0 +1,194 @@
+package rundmc
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	"code.cloudfoundry.org/guardian/rundmc/runrunc"
+	"code.cloudfoundry.org/lager"
+)
+
+// RuntimeMux dispatches to one of several named OCI runtimes. The runtime of
+// a container is read from the gardener.RuntimeKey annotation of its bundle in
+// the depot, so that later operations go to the runtime it was created with.
+// The mux also stops the processes of containers, using the stopper of their
+// runtime.
+type RuntimeMux struct {
+	Runtimes map[string]OCIRuntime
+	Stoppers map[string]Stopper
+	Default  string
+	Depot    Depot
+	Loader   BundleLoader
+}
+
+func (m *RuntimeMux) Create(log lager.Logger, bundlePath, id string, io garden.ProcessIO) error {
+	bndl, err := m.Loader.Load(bundlePath)
+	if err != nil {
+		log.Error("runtime-mux-load-bundle-failed", err)
+		return err
+	}
+
+	runtime, err := m.named(m.runtimeName(bndl))
+	if err != nil {
+		return err
+	}
+
+	return runtime.Create(log, bundlePath, id, io)
+}
+
+func (m *RuntimeMux) Exec(log lager.Logger, bundlePath, id string, spec garden.ProcessSpec, io garden.ProcessIO) (garden.Process, error) {
+	runtime, err := m.lookup(log, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return runtime.Exec(log, bundlePath, id, spec, io)
+}
+
+func (m *RuntimeMux) Attach(log lager.Logger, bundlePath, id, processId string, io garden.ProcessIO) (garden.Process, error) {
+	runtime, err := m.lookup(log, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return runtime.Attach(log, bundlePath, id, processId, io)
+}
+
+func (m *RuntimeMux) Kill(log lager.Logger, handle string) error {
+	runtime, err := m.lookup(log, handle)
+	if err != nil {
+		return err
+	}
+
+	return runtime.Kill(log, handle)
+}
+
+func (m *RuntimeMux) Delete(log lager.Logger, handle string) error {
+	runtime, err := m.lookup(log, handle)
+	if err != nil {
+		return err
+	}
+
+	return runtime.Delete(log, handle)
+}
+
+func (m *RuntimeMux) State(log lager.Logger, id string) (runrunc.State, error) {
+	runtime, err := m.lookup(log, id)
+	if err != nil {
+		return runrunc.State{}, err
+	}
+
+	return runtime.State(log, id)
+}
+
+func (m *RuntimeMux) Stats(log lager.Logger, id string) (gardener.ActualContainerMetrics, error) {
+	runtime, err := m.lookup(log, id)
+	if err != nil {
+		return gardener.ActualContainerMetrics{}, err
+	}
+
+	return runtime.Stats(log, id)
+}
+
+func (m *RuntimeMux) WatchEvents(log lager.Logger, id string, eventsNotifier runrunc.EventsNotifier) error {
+	runtime, err := m.lookup(log, id)
+	if err != nil {
+		return err
+	}
+
+	return runtime.WatchEvents(log, id, eventsNotifier)
+}
+
+func (m *RuntimeMux) StopWatchingEvents(log lager.Logger) {
+	for _, runtime := range m.Runtimes {
+		runtime.StopWatchingEvents(log)
+	}
+}
+
+// List returns the containers known to the runtimes. A runtime which fails
+// to list its containers is logged and skipped, so that the containers of the
+// others are still returned.
+func (m *RuntimeMux) List(log lager.Logger) ([]runrunc.ListEntry, error) {
+	entries := []runrunc.ListEntry{}
+	listed := 0
+	var lastErr error
+	for name, runtime := range m.Runtimes {
+		runtimeEntries, err := runtime.List(log)
+		if err != nil {
+			log.Error("runtime-mux-list-failed", err, lager.Data{"runtime": name})
+			lastErr = err
+			continue
+		}
+
+		listed++
+		entries = append(entries, runtimeEntries...)
+	}
+
+	if listed == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	return entries, nil
+}
+
+// StopAll stops the processes of the container using the stopper of the
+// runtime it was created with
+func (m *RuntimeMux) StopAll(log lager.Logger, handle string, save []int, kill bool) error {
+	name, err := m.lookupName(log, handle)
+	if err != nil {
+		return err
+	}
+
+	stopper, ok := m.Stoppers[name]
+	if !ok {
+		return fmt.Errorf("stopping processes is not supported by runtime: %s", name)
+	}
+
+	return stopper.StopAll(log, handle, save, kill)
+}
+
+// lookup returns the runtime a container was created with
+func (m *RuntimeMux) lookup(log lager.Logger, id string) (OCIRuntime, error) {
+	name, err := m.lookupName(log, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.named(name)
+}
+
+// lookupName reads the runtime of a container from its bundle in the depot,
+// falling back to the default for containers which are not in the depot
+func (m *RuntimeMux) lookupName(log lager.Logger, id string) (string, error) {
+	bundlePath, err := m.Depot.Lookup(log, id)
+	if err != nil {
+		return m.Default, nil
+	}
+
+	bndl, err := m.Loader.Load(bundlePath)
+	if err != nil {
+		log.Error("runtime-mux-load-bundle-failed", err)
+		return "", err
+	}
+
+	return m.runtimeName(bndl), nil
+}
+
+func (m *RuntimeMux) runtimeName(bndl goci.Bndl) string {
+	if name := bndl.Annotation(gardener.RuntimeKey); name != "" {
+		return name
+	}
+
+	return m.Default
+}
+
+func (m *RuntimeMux) named(name string) (OCIRuntime, error) {
+	runtime, ok := m.Runtimes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown runtime: %s", name)
+	}
+
+	return runtime, nil
+}
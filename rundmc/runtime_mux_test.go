@@ -0,0 +1,255 @@
+package rundmc_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	fakes "code.cloudfoundry.org/guardian/rundmc/rundmcfakes"
+	"code.cloudfoundry.org/guardian/rundmc/runrunc"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RuntimeMux", func() {
+	var (
+		runc         *fakes.FakeOCIRuntime
+		runsc        *fakes.FakeOCIRuntime
+		bundleLoader *fakes.FakeBundleLoader
+		depot        *fakes.FakeDepot
+		runcStopper  *fakes.FakeStopper
+		logger       *lagertest.TestLogger
+
+		mux *rundmc.RuntimeMux
+	)
+
+	BeforeEach(func() {
+		runc = new(fakes.FakeOCIRuntime)
+		runsc = new(fakes.FakeOCIRuntime)
+		bundleLoader = new(fakes.FakeBundleLoader)
+		depot = new(fakes.FakeDepot)
+		runcStopper = new(fakes.FakeStopper)
+		logger = lagertest.NewTestLogger("test")
+
+		mux = &rundmc.RuntimeMux{
+			Runtimes: map[string]rundmc.OCIRuntime{"runc": runc, "runsc": runsc},
+			Stoppers: map[string]rundmc.Stopper{"runc": runcStopper},
+			Default:  "runc",
+			Depot:    depot,
+			Loader:   bundleLoader,
+		}
+
+		depot.LookupStub = func(_ lager.Logger, handle string) (string, error) {
+			return "/depot/" + handle, nil
+		}
+	})
+
+	Describe("Create", func() {
+		Context("when the bundle names a runtime", func() {
+			BeforeEach(func() {
+				bundleLoader.LoadReturns(goci.Bundle().WithAnnotation(gardener.RuntimeKey, "runsc"), nil)
+			})
+
+			It("creates the container with that runtime", func() {
+				Expect(mux.Create(logger, "/path/to/bundle", "some-handle", garden.ProcessIO{})).To(Succeed())
+
+				Expect(bundleLoader.LoadArgsForCall(0)).To(Equal("/path/to/bundle"))
+				Expect(runc.CreateCallCount()).To(Equal(0))
+				Expect(runsc.CreateCallCount()).To(Equal(1))
+
+				_, bundlePath, id, _ := runsc.CreateArgsForCall(0)
+				Expect(bundlePath).To(Equal("/path/to/bundle"))
+				Expect(id).To(Equal("some-handle"))
+			})
+		})
+
+		Context("when the bundle does not name a runtime", func() {
+			BeforeEach(func() {
+				bundleLoader.LoadReturns(goci.Bundle(), nil)
+			})
+
+			It("uses the default runtime", func() {
+				Expect(mux.Create(logger, "/path/to/bundle", "some-handle", garden.ProcessIO{})).To(Succeed())
+
+				Expect(runc.CreateCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the bundle names an unknown runtime", func() {
+			BeforeEach(func() {
+				bundleLoader.LoadReturns(goci.Bundle().WithAnnotation(gardener.RuntimeKey, "kata"), nil)
+			})
+
+			It("returns an error without creating the container", func() {
+				Expect(mux.Create(logger, "/path/to/bundle", "some-handle", garden.ProcessIO{})).To(MatchError("unknown runtime: kata"))
+				Expect(runc.CreateCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when loading the bundle fails", func() {
+			BeforeEach(func() {
+				bundleLoader.LoadReturns(goci.Bndl{}, errors.New("boom"))
+			})
+
+			It("returns the error", func() {
+				Expect(mux.Create(logger, "/path/to/bundle", "some-handle", garden.ProcessIO{})).To(MatchError("boom"))
+			})
+		})
+
+		It("returns errors from the runtime", func() {
+			bundleLoader.LoadReturns(goci.Bundle(), nil)
+			runc.CreateReturns(errors.New("create-failed"))
+
+			Expect(mux.Create(logger, "/path/to/bundle", "some-handle", garden.ProcessIO{})).To(MatchError("create-failed"))
+		})
+	})
+
+	Describe("operations on existing containers", func() {
+		Context("when the container's bundle names a runtime", func() {
+			BeforeEach(func() {
+				bundleLoader.LoadStub = func(path string) (goci.Bndl, error) {
+					if path == "/depot/some-handle" {
+						return goci.Bundle().WithAnnotation(gardener.RuntimeKey, "runsc"), nil
+					}
+
+					return goci.Bundle(), nil
+				}
+			})
+
+			It("dispatches to that runtime", func() {
+				_, err := mux.Exec(logger, "/path/to/bundle", "some-handle", garden.ProcessSpec{}, garden.ProcessIO{})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = mux.Attach(logger, "/path/to/bundle", "some-handle", "some-process", garden.ProcessIO{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mux.Kill(logger, "some-handle")).To(Succeed())
+				Expect(mux.Delete(logger, "some-handle")).To(Succeed())
+				_, err = mux.State(logger, "some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = mux.Stats(logger, "some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mux.WatchEvents(logger, "some-handle", nil)).To(Succeed())
+
+				Expect(runsc.ExecCallCount()).To(Equal(1))
+				Expect(runsc.AttachCallCount()).To(Equal(1))
+				Expect(runsc.KillCallCount()).To(Equal(1))
+				Expect(runsc.DeleteCallCount()).To(Equal(1))
+				Expect(runsc.StateCallCount()).To(Equal(1))
+				Expect(runsc.StatsCallCount()).To(Equal(1))
+				Expect(runsc.WatchEventsCallCount()).To(Equal(1))
+				Expect(runc.ExecCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the container's bundle does not name a runtime", func() {
+			BeforeEach(func() {
+				bundleLoader.LoadReturns(goci.Bundle(), nil)
+			})
+
+			It("uses the default runtime", func() {
+				Expect(mux.Kill(logger, "old-handle")).To(Succeed())
+				Expect(runc.KillCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the container is not in the depot", func() {
+			BeforeEach(func() {
+				depot.LookupReturns("", errors.New("not found"))
+			})
+
+			It("uses the default runtime", func() {
+				Expect(mux.Delete(logger, "gone-handle")).To(Succeed())
+				Expect(runc.DeleteCallCount()).To(Equal(1))
+				Expect(bundleLoader.LoadCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the container's bundle can not be loaded", func() {
+			BeforeEach(func() {
+				bundleLoader.LoadReturns(goci.Bndl{}, errors.New("corrupt"))
+			})
+
+			It("returns the error", func() {
+				_, err := mux.State(logger, "some-handle")
+				Expect(err).To(MatchError("corrupt"))
+			})
+		})
+	})
+
+	Describe("List", func() {
+		It("returns the containers of every runtime", func() {
+			runc.ListReturns([]runrunc.ListEntry{{ID: "a"}, {ID: "b"}}, nil)
+			runsc.ListReturns([]runrunc.ListEntry{{ID: "c"}}, nil)
+
+			Expect(mux.List(logger)).To(ConsistOf(
+				runrunc.ListEntry{ID: "a"},
+				runrunc.ListEntry{ID: "b"},
+				runrunc.ListEntry{ID: "c"},
+			))
+		})
+
+		Context("when a runtime fails to list its containers", func() {
+			It("still returns the containers of the others", func() {
+				runc.ListReturns([]runrunc.ListEntry{{ID: "a"}}, nil)
+				runsc.ListReturns(nil, errors.New("boom"))
+
+				Expect(mux.List(logger)).To(ConsistOf(runrunc.ListEntry{ID: "a"}))
+			})
+		})
+
+		Context("when every runtime fails to list its containers", func() {
+			It("returns the error", func() {
+				runc.ListReturns(nil, errors.New("boom"))
+				runsc.ListReturns(nil, errors.New("boom"))
+
+				_, err := mux.List(logger)
+				Expect(err).To(MatchError("boom"))
+			})
+		})
+	})
+
+	Describe("StopAll", func() {
+		It("stops the processes with the stopper of the container's runtime", func() {
+			bundleLoader.LoadReturns(goci.Bundle(), nil)
+
+			Expect(mux.StopAll(logger, "some-handle", []int{42}, true)).To(Succeed())
+
+			Expect(runcStopper.StopAllCallCount()).To(Equal(1))
+			_, handle, save, kill := runcStopper.StopAllArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(save).To(Equal([]int{42}))
+			Expect(kill).To(BeTrue())
+		})
+
+		Context("when the container's runtime has no stopper", func() {
+			It("returns an error", func() {
+				bundleLoader.LoadReturns(goci.Bundle().WithAnnotation(gardener.RuntimeKey, "runsc"), nil)
+
+				Expect(mux.StopAll(logger, "some-handle", nil, false)).To(MatchError("stopping processes is not supported by runtime: runsc"))
+			})
+		})
+	})
+
+	Describe("StopWatchingEvents", func() {
+		It("stops watching events in every runtime", func() {
+			mux.StopWatchingEvents(logger)
+
+			Expect(runc.StopWatchingEventsCallCount()).To(Equal(1))
+			Expect(runsc.StopWatchingEventsCallCount()).To(Equal(1))
+		})
+
+		Context("when the container's runtime is no longer configured", func() {
+			BeforeEach(func() {
+				bundleLoader.LoadReturns(goci.Bundle().WithAnnotation(gardener.RuntimeKey, "kata"), nil)
+			})
+
+			It("returns an error", func() {
+				_, err := mux.State(logger, "some-handle")
+				Expect(err).To(MatchError("unknown runtime: kata"))
+			})
+		})
+	})
+})
@@ -0,0 +1,153 @@
+package rundmc
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/rundmc/runrunc"
+	"code.cloudfoundry.org/lager"
+	"github.com/pivotal-golang/clock"
+)
+
+// StateCache is an OCIRuntime which remembers the state of each container so
+// that frequent calls (Info, BulkInfo, StreamIn etc.) do not each fork the
+// runtime. Entries are filled when the container is created, dropped whenever
+// something happens which may change the state (an event from the runtime, a
+// process exiting, a kill or a delete) and refreshed from the wrapped
+// runtime once they are older than the TTL.
+type StateCache struct {
+	OCIRuntime
+
+	clock clock.Clock
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[string]stateCacheEntry
+	// generation is incremented by every invalidation, so that a state read
+	// from the runtime while an invalidation happens is not cached
+	generation uint64
+}
+
+type stateCacheEntry struct {
+	state     runrunc.State
+	fetchedAt time.Time
+}
+
+func NewStateCache(runtime OCIRuntime, clock clock.Clock, ttl time.Duration) *StateCache {
+	return &StateCache{
+		OCIRuntime: runtime,
+		clock:      clock,
+		ttl:        ttl,
+		entries:    make(map[string]stateCacheEntry),
+	}
+}
+
+func (s *StateCache) Create(log lager.Logger, bundlePath, id string, io garden.ProcessIO) error {
+	if err := s.OCIRuntime.Create(log, bundlePath, id, io); err != nil {
+		return err
+	}
+
+	if _, err := s.refresh(log, id); err != nil {
+		log.Info("state-cache-fill-failed", lager.Data{"handle": id, "error": err.Error()})
+	}
+
+	return nil
+}
+
+func (s *StateCache) State(log lager.Logger, id string) (runrunc.State, error) {
+	s.mu.Lock()
+	entry, ok := s.entries[id]
+	s.mu.Unlock()
+
+	if ok && s.clock.Now().Sub(entry.fetchedAt) < s.ttl {
+		return entry.state, nil
+	}
+
+	return s.refresh(log, id)
+}
+
+func (s *StateCache) Exec(log lager.Logger, bundlePath, id string, spec garden.ProcessSpec, io garden.ProcessIO) (garden.Process, error) {
+	process, err := s.OCIRuntime.Exec(log, bundlePath, id, spec, io)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invalidatingProcess{Process: process, invalidate: func() { s.Invalidate(id) }}, nil
+}
+
+func (s *StateCache) Attach(log lager.Logger, bundlePath, id, processId string, io garden.ProcessIO) (garden.Process, error) {
+	process, err := s.OCIRuntime.Attach(log, bundlePath, id, processId, io)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invalidatingProcess{Process: process, invalidate: func() { s.Invalidate(id) }}, nil
+}
+
+func (s *StateCache) Kill(log lager.Logger, handle string) error {
+	defer s.Invalidate(handle)
+	return s.OCIRuntime.Kill(log, handle)
+}
+
+func (s *StateCache) Delete(log lager.Logger, handle string) error {
+	defer s.Invalidate(handle)
+	return s.OCIRuntime.Delete(log, handle)
+}
+
+func (s *StateCache) WatchEvents(log lager.Logger, id string, eventsNotifier runrunc.EventsNotifier) error {
+	// the events stream ends when the container's init process exits
+	defer s.Invalidate(id)
+	return s.OCIRuntime.WatchEvents(log, id, &invalidatingNotifier{EventsNotifier: eventsNotifier, cache: s})
+}
+
+// Invalidate drops the cached state of the container, so that the next call
+// to State asks the wrapped runtime.
+func (s *StateCache) Invalidate(handle string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, handle)
+	s.generation++
+}
+
+func (s *StateCache) refresh(log lager.Logger, id string) (runrunc.State, error) {
+	s.mu.Lock()
+	generation := s.generation
+	s.mu.Unlock()
+
+	state, err := s.OCIRuntime.State(log, id)
+	if err != nil {
+		s.Invalidate(id)
+		return runrunc.State{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.generation == generation {
+		s.entries[id] = stateCacheEntry{state: state, fetchedAt: s.clock.Now()}
+	}
+
+	return state, nil
+}
+
+type invalidatingNotifier struct {
+	runrunc.EventsNotifier
+	cache *StateCache
+}
+
+func (n *invalidatingNotifier) OnEvent(handle, event string) error {
+	n.cache.Invalidate(handle)
+	return n.EventsNotifier.OnEvent(handle, event)
+}
+
+type invalidatingProcess struct {
+	garden.Process
+	invalidate func()
+}
+
+func (p *invalidatingProcess) Wait() (int, error) {
+	defer p.invalidate()
+	return p.Process.Wait()
+}
@@ -0,0 +1,196 @@
+package rundmc_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/rundmc"
+	fakes "code.cloudfoundry.org/guardian/rundmc/rundmcfakes"
+	"code.cloudfoundry.org/guardian/rundmc/runrunc"
+	"code.cloudfoundry.org/guardian/rundmc/runrunc/runruncfakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-golang/clock/fakeclock"
+)
+
+var _ = Describe("StateCache", func() {
+	var (
+		runtime *fakes.FakeOCIRuntime
+		clk     *fakeclock.FakeClock
+		logger  *lagertest.TestLogger
+
+		cache *rundmc.StateCache
+	)
+
+	BeforeEach(func() {
+		runtime = new(fakes.FakeOCIRuntime)
+		clk = fakeclock.NewFakeClock(time.Now())
+		logger = lagertest.NewTestLogger("test")
+
+		runtime.StateReturns(runrunc.State{Pid: 42, Status: "running"}, nil)
+
+		cache = rundmc.NewStateCache(runtime, clk, 10*time.Second)
+	})
+
+	Describe("Create", func() {
+		It("fills the cache with the new container's state", func() {
+			Expect(cache.Create(logger, "/bundle", "some-handle", garden.ProcessIO{})).To(Succeed())
+			Expect(runtime.StateCallCount()).To(Equal(1))
+
+			state, err := cache.State(logger, "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(runrunc.State{Pid: 42, Status: "running"}))
+			Expect(runtime.StateCallCount()).To(Equal(1))
+		})
+
+		It("returns errors from the runtime without filling the cache", func() {
+			runtime.CreateReturns(errors.New("boom"))
+
+			Expect(cache.Create(logger, "/bundle", "some-handle", garden.ProcessIO{})).To(MatchError("boom"))
+			Expect(runtime.StateCallCount()).To(Equal(0))
+		})
+
+		It("does not fail when the state can not be read", func() {
+			runtime.StateReturns(runrunc.State{}, errors.New("no state"))
+
+			Expect(cache.Create(logger, "/bundle", "some-handle", garden.ProcessIO{})).To(Succeed())
+		})
+	})
+
+	Describe("State", func() {
+		It("asks the runtime on the first call", func() {
+			state, err := cache.State(logger, "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Pid).To(Equal(42))
+
+			_, id := runtime.StateArgsForCall(0)
+			Expect(id).To(Equal("some-handle"))
+		})
+
+		It("serves repeated calls from the cache", func() {
+			cache.State(logger, "some-handle")
+			cache.State(logger, "some-handle")
+
+			Expect(runtime.StateCallCount()).To(Equal(1))
+		})
+
+		It("asks the runtime again once the entry is stale", func() {
+			cache.State(logger, "some-handle")
+			clk.Increment(11 * time.Second)
+
+			runtime.StateReturns(runrunc.State{Pid: 43, Status: "stopped"}, nil)
+			state, err := cache.State(logger, "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Pid).To(Equal(43))
+			Expect(runtime.StateCallCount()).To(Equal(2))
+		})
+
+		It("does not cache a state read while the container is invalidated", func() {
+			runtime.StateStub = func(_ lager.Logger, id string) (runrunc.State, error) {
+				cache.Invalidate(id)
+				return runrunc.State{Pid: 42, Status: "running"}, nil
+			}
+
+			_, err := cache.State(logger, "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			runtime.StateStub = nil
+			cache.State(logger, "some-handle")
+			Expect(runtime.StateCallCount()).To(Equal(2))
+		})
+
+		It("does not cache errors", func() {
+			runtime.StateReturns(runrunc.State{}, errors.New("no state"))
+
+			_, err := cache.State(logger, "some-handle")
+			Expect(err).To(MatchError("no state"))
+			_, err = cache.State(logger, "some-handle")
+			Expect(err).To(MatchError("no state"))
+			Expect(runtime.StateCallCount()).To(Equal(2))
+		})
+	})
+
+	Describe("invalidation", func() {
+		BeforeEach(func() {
+			cache.State(logger, "some-handle")
+			Expect(runtime.StateCallCount()).To(Equal(1))
+		})
+
+		It("happens when the container is killed", func() {
+			Expect(cache.Kill(logger, "some-handle")).To(Succeed())
+			cache.State(logger, "some-handle")
+
+			Expect(runtime.KillCallCount()).To(Equal(1))
+			Expect(runtime.StateCallCount()).To(Equal(2))
+		})
+
+		It("happens when the container is deleted", func() {
+			Expect(cache.Delete(logger, "some-handle")).To(Succeed())
+			cache.State(logger, "some-handle")
+
+			Expect(runtime.DeleteCallCount()).To(Equal(1))
+			Expect(runtime.StateCallCount()).To(Equal(2))
+		})
+
+		It("happens when Invalidate is called", func() {
+			cache.Invalidate("some-handle")
+			cache.State(logger, "some-handle")
+
+			Expect(runtime.StateCallCount()).To(Equal(2))
+		})
+
+		It("does not affect other containers", func() {
+			cache.State(logger, "other-handle")
+			cache.Invalidate("some-handle")
+			cache.State(logger, "other-handle")
+
+			Expect(runtime.StateCallCount()).To(Equal(2))
+		})
+
+		It("happens when the runtime reports an event, which is passed on", func() {
+			notifier := new(runruncfakes.FakeEventsNotifier)
+			runtime.WatchEventsStub = func(_ lager.Logger, id string, n runrunc.EventsNotifier) error {
+				cache.State(logger, id)
+				Expect(runtime.StateCallCount()).To(Equal(1))
+
+				n.OnEvent(id, "Out of memory")
+				cache.State(logger, id)
+				Expect(runtime.StateCallCount()).To(Equal(2))
+				return nil
+			}
+
+			Expect(cache.WatchEvents(logger, "some-handle", notifier)).To(Succeed())
+
+			Expect(notifier.OnEventCallCount()).To(Equal(1))
+			handle, event := notifier.OnEventArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(event).To(Equal("Out of memory"))
+		})
+
+		It("happens when the events stream ends", func() {
+			Expect(cache.WatchEvents(logger, "some-handle", new(runruncfakes.FakeEventsNotifier))).To(Succeed())
+			cache.State(logger, "some-handle")
+
+			Expect(runtime.StateCallCount()).To(Equal(2))
+		})
+
+		It("happens when a process run in the container exits", func() {
+			process := new(runruncfakes.FakeProcess)
+			process.WaitReturns(12, nil)
+			runtime.ExecReturns(process, nil)
+
+			p, err := cache.Exec(logger, "/bundle", "some-handle", garden.ProcessSpec{}, garden.ProcessIO{})
+			Expect(err).NotTo(HaveOccurred())
+
+			cache.State(logger, "some-handle")
+			Expect(runtime.StateCallCount()).To(Equal(1))
+
+			Expect(p.Wait()).To(Equal(12))
+			cache.State(logger, "some-handle")
+			Expect(runtime.StateCallCount()).To(Equal(2))
+		})
+	})
+})
@@ -33,3 +33,37 @@ func (r resolver) Resolve(name, subsystem string) (string, error) {
 
 	return s.CgroupPaths["devices"], nil
 }
+
+type crunStatus struct {
+	CgroupPath string `json:"cgroup-path"`
+}
+
+type crunResolver struct {
+	stateStore string
+	cgroupRoot string
+}
+
+// NewCrunStatusCgroupPathResolver resolves cgroup paths from the status files
+// crun keeps in its state directory. crun records the cgroup relative to the
+// cgroup mount point, so the subsystem is resolved beneath cgroupRoot.
+func NewCrunStatusCgroupPathResolver(stateStorePath, cgroupRoot string) *crunResolver {
+	return &crunResolver{
+		stateStore: stateStorePath,
+		cgroupRoot: cgroupRoot,
+	}
+}
+
+func (r crunResolver) Resolve(name, subsystem string) (string, error) {
+	status, err := os.Open(filepath.Join(r.stateStore, name, "status"))
+	if err != nil {
+		return "", err
+	}
+	defer status.Close()
+
+	var s crunStatus
+	if err := json.NewDecoder(status).Decode(&s); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(r.cgroupRoot, subsystem, s.CgroupPath), nil
+}
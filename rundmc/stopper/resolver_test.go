@@ -67,4 +67,30 @@ var _ = Describe("Resolver", func() {
 		_, err := stopper.NewRuncStateCgroupPathResolver(fakeStateDir).Resolve("some-handle", "devices")
 		Expect(err).To(MatchError(ContainSubstring("no such file")))
 	})
+
+	Context("with a crun status file", func() {
+		BeforeEach(func() {
+			status, err := os.Create(filepath.Join(fakeStateDir, "some-handle", "status"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(json.NewEncoder(status).Encode(map[string]interface{}{
+				"pid":         42,
+				"cgroup-path": "/garden/some-handle",
+			})).To(Succeed())
+			Expect(status.Close()).To(Succeed())
+		})
+
+		It("resolves the cgroup beneath the cgroup root", func() {
+			path, err := stopper.NewCrunStatusCgroupPathResolver(fakeStateDir, "/sys/fs/cgroup").Resolve("some-handle", "devices")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal("/sys/fs/cgroup/devices/garden/some-handle"))
+		})
+	})
+
+	Context("without a crun status file", func() {
+		It("returns an error", func() {
+			_, err := stopper.NewCrunStatusCgroupPathResolver(fakeStateDir, "/sys/fs/cgroup").Resolve("some-handle", "devices")
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })